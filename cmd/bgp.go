@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"fmt"
+	"net/netip"
+	"os"
+
+	routing "netlab/modules/04-routing"
+
+	"github.com/spf13/cobra"
+)
+
+var bgpNetwork string
+
+var bgpCmd = &cobra.Command{
+	Use:   "bgp <speaker> [prefix]",
+	Short: "Explain which BGP path a speaker picks, and why",
+	Long: `Run BGP over a network of speakers until it converges, then show a
+speaker's routes and the tie-breaker that picked each one.
+
+With a prefix, every path the speaker holds to it is listed with its
+AS_PATH, LOCAL_PREF, MED and communities, followed by each step of the
+decision process and the paths rejected for looping. Without --network, the
+routing module's sample network is used.
+
+Exits with status 1 when the speaker has no usable path to the prefix, and
+with status 2 when the network or arguments can't be read.`,
+	Example: `  netlab bgp tor
+  netlab bgp border1 198.51.100.0/24
+  netlab bgp --network fabric.yaml leaf1 10.244.0.0/16`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		network, err := routing.SampleBGP()
+		if bgpNetwork != "" {
+			network, err = routing.ReadBGP(bgpNetwork)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		speaker, ok := network.Speaker(args[0])
+		if !ok {
+			fmt.Fprintf(os.Stderr, "no speaker named %q\n", args[0])
+			os.Exit(2)
+		}
+		rib, err := network.Converge()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+
+		if len(args) == 1 {
+			fmt.Printf("%s (AS %d, router ID %s)\n", speaker.Name, speaker.AS, speaker.ID)
+			for _, prefix := range rib.Prefixes(speaker.Name) {
+				d := rib.Decisions[speaker.Name][prefix]
+				best, ok := d.BestPath()
+				if !ok {
+					fmt.Printf("  %-18s unreachable\n", prefix)
+					continue
+				}
+				fmt.Printf("  %-18s %-14s %-22s %s\n", prefix, best.Label(), best.ASPathString(), d.DecidedBy())
+			}
+			return
+		}
+
+		prefix, err := netip.ParsePrefix(args[1])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%q is not a valid prefix\n", args[1])
+			os.Exit(2)
+		}
+		d, ok := rib.Decisions[speaker.Name][prefix.Masked()]
+		if !ok {
+			fmt.Printf("❌ %s has no paths to %s\n", speaker.Name, prefix.Masked())
+			os.Exit(1)
+		}
+		printDecision(speaker.Name, d)
+		if _, ok := d.BestPath(); !ok {
+			os.Exit(1)
+		}
+	},
+}
+
+func printDecision(speaker string, d routing.Decision) {
+	fmt.Printf("Paths to %s on %s\n", d.Prefix, speaker)
+	for i, p := range d.Paths {
+		marker := " "
+		if i == d.Best {
+			marker = "★"
+		}
+		fmt.Printf("%s %-14s AS_PATH %-22s LOCAL_PREF %-4d MED %-4d %s\n", marker, p.Label(), p.ASPathString(), p.LocalPref, p.MED, p.Origin)
+	}
+	for _, r := range d.Rejected {
+		fmt.Printf("✗ %-14s %s\n", r.Path.Label(), r.Reason)
+	}
+
+	fmt.Println()
+	explanation := d.Explain()
+	for i, line := range explanation[:len(explanation)-1] {
+		fmt.Printf("%d. %s\n", i+1, line)
+	}
+	if _, ok := d.BestPath(); ok {
+		fmt.Println("✅", explanation[len(explanation)-1])
+	} else {
+		fmt.Println("❌", explanation[len(explanation)-1])
+	}
+}
+
+func init() {
+	bgpCmd.Flags().StringVar(&bgpNetwork, "network", "", "BGP network YAML file (default: the routing module's sample)")
+	rootCmd.AddCommand(bgpCmd)
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"netlab/internal/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var cleanupYes bool
+
+var cleanupCmd = &cobra.Command{
+	Use:   "cleanup",
+	Short: "Delete lab clusters and namespaces left running",
+	Long: `Tear down every kind cluster and namespace created by NetLab labs, including ones left behind after a crash or an interrupted setup.
+
+Kind clusters named netlab-* that this NetLab install didn't record are listed
+and only deleted after confirmation, or with --yes.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		orphans, err := utils.FindOrphanedLabResources()
+		var unknown *utils.UnknownLabStateError
+		switch {
+		case errors.As(err, &unknown):
+			fmt.Fprintf(os.Stderr, "⚠️  %v\n", err)
+		case err != nil:
+			fmt.Fprintf(os.Stderr, "failed to read lab state: %v\n", err)
+			os.Exit(1)
+		}
+
+		var resources, untracked []utils.LabResource
+		for _, r := range orphans {
+			if r.Tracked {
+				resources = append(resources, r)
+			} else {
+				untracked = append(untracked, r)
+			}
+		}
+		if len(untracked) > 0 {
+			fmt.Println("These kind clusters look like NetLab labs but weren't created by this install:")
+			for _, r := range untracked {
+				fmt.Printf("   • %s %s\n", r.Type, r.Name)
+			}
+			if cleanupYes || confirmCleanup(bufio.NewReader(os.Stdin), "Delete them too? [y/N]: ") {
+				resources = append(resources, untracked...)
+			} else {
+				fmt.Println("Left running. Rerun with --yes to delete them.")
+			}
+		}
+
+		if len(resources) == 0 {
+			if unknown != nil {
+				os.Exit(1)
+			}
+			if len(untracked) == 0 {
+				fmt.Println("✅ No lab resources left running.")
+			}
+			return
+		}
+
+		output, err := utils.CleanupLabResources(resources)
+		for _, line := range output {
+			fmt.Println(line)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cleanup incomplete: %v\n", err)
+			os.Exit(1)
+		}
+		if unknown != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+// confirmCleanup asks a yes/no question, treating anything but yes as no
+func confirmCleanup(in *bufio.Reader, prompt string) bool {
+	fmt.Print(prompt)
+	answer, _ := in.ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+func init() {
+	cleanupCmd.Flags().BoolVarP(&cleanupYes, "yes", "y", false, "Also delete netlab-* clusters this install didn't create, without asking")
+	rootCmd.AddCommand(cleanupCmd)
+}
@@ -1,20 +1,107 @@
 package cmd
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
 	"netlab/internal/utils"
 
 	"github.com/spf13/cobra"
 )
 
+var (
+	doctorJSON   bool
+	doctorModule string
+	doctorFix    bool
+	doctorDryRun bool
+	doctorYes    bool
+)
+
 var doctorCmd = &cobra.Command{
 	Use:   "doctor",
 	Short: "Run environment diagnostics",
-	Long:  "Check system requirements and validate that all necessary tools are installed and configured correctly.",
-	Run: func(cmd *cobra.Command, args []string) {
-		utils.RunDiagnostics()
+	Long: `Check system requirements and validate that all necessary tools are installed and configured correctly.
+
+Exits with status 1 when a required tool is missing or fails, so scripts and CI can gate on it,
+and with status 2 when a content pack fails validation.
+With --fix, offers to run the install or remediation command for each problem found.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if doctorJSON && doctorFix {
+			return errors.New("--fix cannot be combined with --json")
+		}
+		if (doctorDryRun || doctorYes) && !doctorFix {
+			return errors.New("--dry-run and --yes only apply with --fix")
+		}
+
+		var report utils.DiagnosticsReport
+		var err error
+
+		if doctorJSON {
+			report, err = utils.BuildDiagnosticsReport(cmd.Context(), doctorModule)
+			if err != nil {
+				return err
+			}
+			if err := utils.WriteDiagnosticsJSON(os.Stdout, report); err != nil {
+				return err
+			}
+		} else {
+			report, err = utils.RunDiagnostics(cmd.Context(), doctorModule)
+			if err != nil {
+				return err
+			}
+		}
+
+		if doctorFix {
+			report, err = runDoctorFix(cmd.Context(), report)
+			if err != nil {
+				return err
+			}
+		}
+
+		if code := report.ExitCode(); code != utils.ExitOK {
+			os.Exit(code)
+		}
+		return nil
 	},
 }
 
+// runDoctorFix walks through the fixes for the report's failures and prints
+// the re-checked report if anything ran
+func runDoctorFix(ctx context.Context, report utils.DiagnosticsReport) (utils.DiagnosticsReport, error) {
+	fmt.Println()
+	fmt.Println("🔧 Guided fixes")
+
+	fixed, results, err := utils.RunFix(utils.FixOptions{
+		Context:  ctx,
+		ModuleID: doctorModule,
+		Report:   &report,
+		DryRun:   doctorDryRun,
+		Yes:      doctorYes,
+		In:       os.Stdin,
+		Out:      os.Stdout,
+		Exec:     utils.ShellExecutor{},
+	})
+	if err != nil {
+		return report, err
+	}
+
+	for _, r := range results {
+		if r.Ran {
+			fmt.Println()
+			utils.PrintDiagnostics(fixed)
+			return fixed, nil
+		}
+	}
+	return fixed, nil
+}
+
 func init() {
+	doctorCmd.Flags().BoolVar(&doctorJSON, "json", false, "Print the report as JSON")
+	doctorCmd.Flags().StringVar(&doctorModule, "module", "", "Only check the dependencies of one module (e.g. 01-osi-model)")
+	doctorCmd.Flags().BoolVar(&doctorFix, "fix", false, "Offer to run install and remediation commands for each problem")
+	doctorCmd.Flags().BoolVar(&doctorDryRun, "dry-run", false, "With --fix, show the commands without running them")
+	doctorCmd.Flags().BoolVarP(&doctorYes, "yes", "y", false, "With --fix, run every command without asking")
 	rootCmd.AddCommand(doctorCmd)
 }
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"netlab/internal/lint"
+
+	"github.com/spf13/cobra"
+)
+
+var lintRoot string
+
+var lintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Check module content for mistakes",
+	Long: `Check the module content in a NetLab checkout: the OSI layers, their
+documentation links, Kubernetes context and CLI tools, that quiz questions
+point at existing README sections, and that every registered module has a
+README. Content packs loaded with --pack are checked too.
+
+Exits with status 1 when any problem is found.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		problems, err := lint.Run(lintRoot)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "lint failed: %v\n", err)
+			os.Exit(1)
+		}
+		if len(problems) == 0 {
+			fmt.Println("✅ Module content looks good.")
+			return
+		}
+
+		for _, p := range problems {
+			fmt.Println("❌", p)
+		}
+		fmt.Printf("\n%d problem(s) found\n", len(problems))
+		os.Exit(1)
+	},
+}
+
+func init() {
+	lintCmd.Flags().StringVar(&lintRoot, "root", ".", "Root of the NetLab checkout")
+	rootCmd.AddCommand(lintCmd)
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"netlab/internal/scaffold"
+
+	"github.com/spf13/cobra"
+)
+
+var newCmd = &cobra.Command{
+	Use:   "new",
+	Short: "Generate code for NetLab contributors",
+}
+
+var (
+	newModuleName string
+	newModuleDesc string
+	newModuleDeps []string
+	newModuleRoot string
+)
+
+var newModuleCmd = &cobra.Command{
+	Use:   "module [module-id]",
+	Short: "Create a built-in module skeleton and register it",
+	Long: `Create modules/<module-id> with a README, a Bubble Tea model using the
+NetLab styles, a quiz stub and a test, then register the module with the
+registry, the module runner and the dependency checks.
+
+Run it from the root of a NetLab checkout. For a module already listed as
+planned, the name, description and dependencies default to its entry.`,
+	Example: `  netlab new module 08-dns --name "DNS Resolution" --description "Follow a query from stub resolver to authoritative server" --deps kubectl,tcpdump`,
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		opts := scaffold.Options{
+			ID:          args[0],
+			Name:        newModuleName,
+			Description: newModuleDesc,
+		}
+		if cmd.Flags().Changed("deps") {
+			opts.Dependencies = append([]string{}, newModuleDeps...)
+		}
+
+		result, err := scaffold.NewModule(newModuleRoot, opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to create module: %v\n", err)
+			os.Exit(1)
+		}
+
+		for _, path := range result.Created {
+			fmt.Println("✅ created", path)
+		}
+		for _, path := range result.Updated {
+			fmt.Println("✏️  updated", path)
+		}
+		fmt.Printf("\nNext: fill in the README and quiz, then record the view snapshots with\n  go test ./modules/%s/ -update\n", opts.ID)
+	},
+}
+
+func init() {
+	newModuleCmd.Flags().StringVar(&newModuleName, "name", "", "Module name shown in menus")
+	newModuleCmd.Flags().StringVar(&newModuleDesc, "description", "", "One-line description shown in menus")
+	newModuleCmd.Flags().StringSliceVar(&newModuleDeps, "deps", nil, "Tools the module needs, as named by 'netlab doctor'")
+	newModuleCmd.Flags().StringVar(&newModuleRoot, "root", ".", "Root of the NetLab checkout")
+	newCmd.AddCommand(newModuleCmd)
+	rootCmd.AddCommand(newCmd)
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"fmt"
+	"math/big"
+	"net/netip"
+	"os"
+	"strings"
+
+	subnetting "netlab/modules/03-subnetting"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	overlapConfig string
+	overlapWithin []string
+)
+
+var overlapCmd = &cobra.Command{
+	Use:   "overlap [name=cidr ...]",
+	Short: "Check cluster CIDR ranges for overlaps",
+	Long: `Check a cluster's pod, Service, node and VPN ranges for overlaps and
+containment, and report the free space left around them.
+
+Free space is reported within the --within blocks, or by default within the
+private ranges the given ranges fall in. With --config, the pod and Service
+subnets are read from a kind or kubeadm config file, filling in the tool's
+defaults for any it leaves out. Ranges given as arguments replace those of the
+same name from the config.
+
+Exits with status 1 when any ranges overlap, and with status 2 when the ranges
+or config can't be read.`,
+	Example: `  netlab overlap pods=10.244.0.0/16 services=10.96.0.0/12 nodes=172.18.0.0/16 vpn=10.100.0.0/16
+  netlab overlap --config kind.yaml nodes=172.18.0.0/16 --within 10.0.0.0/8`,
+	Run: func(cmd *cobra.Command, args []string) {
+		var ranges []subnetting.Range
+		if overlapConfig != "" {
+			loaded, err := subnetting.ReadClusterConfig(overlapConfig)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(2)
+			}
+			ranges = loaded
+		}
+		if len(args) > 0 {
+			given, err := subnetting.ParseRanges(strings.Join(args, " "))
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(2)
+			}
+			ranges = subnetting.MergeRanges(ranges, given)
+		}
+		if len(ranges) == 0 {
+			fmt.Fprintln(os.Stderr, "give the ranges to check as name=cidr, or a cluster config with --config")
+			os.Exit(2)
+		}
+
+		var within []netip.Prefix
+		for _, w := range overlapWithin {
+			prefix, err := netip.ParsePrefix(w)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "--within: %q is not a valid CIDR block\n", w)
+				os.Exit(2)
+			}
+			within = append(within, prefix)
+		}
+
+		report := subnetting.CheckOverlaps(ranges, within)
+		printOverlapReport(report)
+		if len(report.Conflicts) > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func printOverlapReport(report subnetting.OverlapReport) {
+	nameWidth := 0
+	for _, r := range report.Ranges {
+		nameWidth = max(nameWidth, len(r.Name))
+	}
+	fmt.Println("Ranges")
+	for _, r := range report.Ranges {
+		line := fmt.Sprintf("  %-*s  %-20s  %s", nameWidth, r.Name, r.Prefix, r.Source)
+		fmt.Println(strings.TrimRight(line, " "))
+	}
+
+	fmt.Println()
+	if len(report.Conflicts) == 0 {
+		fmt.Println("✅ No overlaps")
+	}
+	for _, c := range report.Conflicts {
+		fmt.Println("❌", c)
+	}
+
+	for _, free := range report.Free {
+		total := new(big.Int).Lsh(big.NewInt(1), uint(free.Within.Addr().BitLen()-free.Within.Bits()))
+		fmt.Printf("\nFree in %s: %s of %s addresses\n", free.Within, subnetting.FormatCount(free.Free), subnetting.FormatCount(total))
+		for _, block := range free.Blocks {
+			fmt.Println("  " + block.String())
+		}
+	}
+}
+
+func init() {
+	overlapCmd.Flags().StringVar(&overlapConfig, "config", "", "kind or kubeadm config file to read the pod and Service subnets from")
+	overlapCmd.Flags().StringSliceVar(&overlapWithin, "within", nil, "Blocks to report free space in (default: the private ranges used)")
+	rootCmd.AddCommand(overlapCmd)
+}
@@ -3,9 +3,14 @@ package cmd
 import (
 	"fmt"
 
+	"netlab/internal/registry"
+
 	"github.com/spf13/cobra"
 )
 
+// packDirs are extra content pack directories given with --pack
+var packDirs []string
+
 var rootCmd = &cobra.Command{
 	Use:   "netlab",
 	Short: "NetLab - Interactive Learning Environment for Networking Fundamentals",
@@ -14,6 +19,10 @@ fundamentals and Kubernetes networking through interactive terminal modules.
 
 NetLab emphasizes conceptual understanding, visual feedback, and hands-on learning—all 
 within a fast, efficient CLI application built with the Charm ecosystem.`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		// Broken packs are skipped here and reported by 'netlab doctor'
+		registry.LoadPacks(packDirs)
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 		fmt.Println("Welcome to NetLab! Use 'netlab start' to begin or 'netlab --help' for more options.")
 	},
@@ -25,4 +34,5 @@ func Execute() error {
 
 func init() {
 	rootCmd.Flags().BoolP("version", "v", false, "Show version information")
+	rootCmd.PersistentFlags().StringArrayVar(&packDirs, "pack", nil, "Load a content pack from this directory (repeatable), in addition to ~/.config/netlab/packs")
 }
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	routing "netlab/modules/04-routing"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	traceTopology string
+	traceFrom     string
+	traceTTL      int
+)
+
+var traceCmd = &cobra.Command{
+	Use:   "trace <destination>",
+	Short: "Trace a packet hop by hop through a routing topology",
+	Long: `Trace a packet through a topology of routers, hop by hop, and the reply
+back to its source.
+
+The destination is an IP address or a router's name. Without --topology, the
+routing module's sample topology is used. Each hop shows the route it matched
+and the TTL the packet arrived with, and the trace reports loops, blackholes,
+TTL expiry and replies that come back by another path.
+
+Exits with status 1 when the packet or its reply is not delivered, and with
+status 2 when the topology or arguments can't be read.`,
+	Example: `  netlab trace web
+  netlab trace 172.16.5.5 --ttl 8
+  netlab trace --topology lab.yaml --from client 10.0.2.1`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		topology, err := routing.SampleTopology()
+		if traceTopology != "" {
+			topology, err = routing.ReadTopology(traceTopology)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+
+		from := traceFrom
+		if from == "" {
+			from = topology.Routers[0].Name
+		}
+		dst, err := topology.Resolve(args[0])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		trip, err := topology.RoundTrip(from, dst, traceTTL)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+
+		printTrace(trip.Forward)
+		if back := trip.Return; back != nil {
+			fmt.Printf("\nReply: %s\n", strings.Join(back.Path(), " → "))
+			switch {
+			case back.Outcome != routing.OutcomeDelivered:
+				fmt.Println("❌", back.Reason)
+			case trip.Asymmetric:
+				fmt.Println("⚠️  Asymmetric: the reply takes a different path from the request")
+			default:
+				fmt.Println("✅ Symmetric")
+			}
+		}
+		if trip.Return == nil || trip.Return.Outcome != routing.OutcomeDelivered {
+			os.Exit(1)
+		}
+	},
+}
+
+func printTrace(tr routing.Trace) {
+	fmt.Printf("%s → %s\n", tr.From, tr.Destination)
+	for i, hop := range tr.Hops {
+		in := ""
+		if hop.In != "" {
+			in = " in " + hop.In
+		}
+		fmt.Printf("%2d  %s%s, TTL %d\n", i, hop.Router, in, hop.TTL)
+		if hop.Route.Prefix.IsValid() {
+			fmt.Printf("      %s %s\n", hop.Route.Destination(), hop.Route.Next())
+		}
+	}
+	if tr.Outcome == routing.OutcomeDelivered {
+		fmt.Println("✅", tr.Reason)
+	} else {
+		fmt.Println("❌", tr.Reason)
+	}
+}
+
+func init() {
+	traceCmd.Flags().StringVar(&traceTopology, "topology", "", "Topology YAML file (default: the routing module's sample)")
+	traceCmd.Flags().StringVar(&traceFrom, "from", "", "Router to send from (default: the first router)")
+	traceCmd.Flags().IntVar(&traceTTL, "ttl", routing.DefaultTTL, "TTL to send the packet with")
+	rootCmd.AddCommand(traceCmd)
+}
@@ -0,0 +1,196 @@
+// Package lint checks module content in a NetLab checkout for the mistakes
+// the loaders can't catch on their own: gaps in the OSI data, tools the
+// dependency checks don't know about, quiz questions pointing at sections
+// that were renamed, and modules without a README.
+package lint
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"netlab/internal/packs"
+	"netlab/internal/registry"
+	"netlab/internal/utils"
+	osimodel "netlab/modules/01-osi-model"
+)
+
+// Problem is one thing wrong with a module's content
+type Problem struct {
+	Module  string
+	File    string // Relative to the checkout root, empty for the module as a whole
+	Message string
+}
+
+func (p Problem) String() string {
+	if p.File == "" {
+		return fmt.Sprintf("%s: %s", p.Module, p.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", p.Module, p.File, p.Message)
+}
+
+// referenceTools are CLI tools the OSI layers mention for further reading.
+// Labs never run them, so 'netlab doctor' has no check for them. Any other
+// tool must have a check in internal/utils/diagnostics.go.
+var referenceTools = map[string]bool{
+	"arp": true, "base64": true, "brctl": true, "bridge": true, "curl": true,
+	"dig": true, "dmesg": true, "ethtool": true, "ftp": true, "gpg": true,
+	"gzip": true, "iwconfig": true, "iwlist": true, "lshw": true, "lsof": true,
+	"lsusb": true, "mtr": true, "netstat": true, "nmap": true, "nslookup": true,
+	"openssl": true, "ping": true, "route": true, "rpcinfo": true, "showmount": true,
+	"ss": true, "ssh": true, "tar": true, "telnet": true, "traceroute": true,
+	"wget": true, "wireshark": true,
+}
+
+// osiModuleID is the module whose layer data gets the OSI-specific checks
+const osiModuleID = "01-osi-model"
+
+// Run checks the modules in the checkout at root against the registry and
+// returns every problem found, sorted by module
+func Run(root string) ([]Problem, error) {
+	modulesDir := filepath.Join(root, "modules")
+	entries, err := os.ReadDir(modulesDir)
+	if err != nil {
+		return nil, fmt.Errorf("not a netlab checkout: %w", err)
+	}
+
+	var problems []Problem
+	registered := map[string]bool{}
+	for _, m := range registry.Modules() {
+		if m.Pack != "" {
+			continue
+		}
+		registered[m.ID] = true
+		dir := filepath.Join(modulesDir, m.ID)
+		if _, err := os.Stat(dir); errors.Is(err, fs.ErrNotExist) {
+			if m.Status != "planned" {
+				problems = append(problems, Problem{Module: m.ID, Message: fmt.Sprintf("status is %q but modules/%s does not exist", m.Status, m.ID)})
+			}
+			continue
+		}
+		problems = append(problems, checkModule(root, m.ID)...)
+	}
+
+	for _, e := range entries {
+		if e.IsDir() && !registered[e.Name()] {
+			problems = append(problems, Problem{Module: e.Name(), Message: "not in the registry; add it to internal/registry/registry.go"})
+		}
+	}
+
+	for _, pack := range utils.ContentPacks() {
+		for _, msg := range pack.Errors {
+			problems = append(problems, Problem{Module: "pack " + pack.Name, File: pack.Path, Message: msg})
+		}
+	}
+
+	sort.SliceStable(problems, func(i, j int) bool { return problems[i].Module < problems[j].Module })
+	return problems, nil
+}
+
+// checkModule checks one built-in module's directory
+func checkModule(root, id string) []Problem {
+	dir := filepath.Join("modules", id)
+	readmePath := filepath.Join(dir, "README.md")
+
+	readme, err := os.ReadFile(filepath.Join(root, readmePath))
+	if err != nil {
+		return []Problem{{Module: id, File: readmePath, Message: "missing; every module needs a README with learning objectives"}}
+	}
+
+	var problems []Problem
+	if id == osiModuleID {
+		problems = append(problems, checkOSIContent(os.DirFS(filepath.Join(root, dir)))...)
+	}
+
+	quizPath := filepath.Join(dir, "content", "quiz.yaml")
+	data, err := os.ReadFile(filepath.Join(root, quizPath))
+	if errors.Is(err, fs.ErrNotExist) {
+		return problems
+	}
+	if err != nil {
+		return append(problems, Problem{Module: id, File: quizPath, Message: err.Error()})
+	}
+	questions, err := packs.ParseQuiz(data)
+	if err != nil {
+		return append(problems, Problem{Module: id, File: quizPath, Message: err.Error()})
+	}
+
+	sections := headings(string(readme))
+	for i, q := range questions {
+		switch {
+		case q.Lesson == "":
+			problems = append(problems, Problem{Module: id, File: quizPath, Message: fmt.Sprintf("question %d: lesson is required, name the README section that covers it", i+1)})
+		case !sections[q.Lesson]:
+			problems = append(problems, Problem{Module: id, File: quizPath, Message: fmt.Sprintf("question %d: README has no section %q", i+1, q.Lesson)})
+		}
+	}
+	return problems
+}
+
+// checkOSIContent checks the OSI layer data in the module directory fsys
+func checkOSIContent(fsys fs.FS) []Problem {
+	at := func(file, msg string) Problem {
+		return Problem{Module: osiModuleID, File: filepath.Join("modules", osiModuleID, file), Message: msg}
+	}
+
+	// LoadContent already rejects missing or repeated layers and malformed
+	// documentation URLs, one error per line
+	content, err := osimodel.LoadContent(fsys)
+	if err != nil {
+		// Only the first line names the file, the rest are its problems
+		lines := strings.Split(err.Error(), "\n")
+		file := ""
+		for _, name := range []string{"content/osi.yaml", "content/sample-packet.json"} {
+			if rest, ok := strings.CutPrefix(lines[0], name+": "); ok {
+				file, lines[0] = name, rest
+			}
+		}
+		var problems []Problem
+		for _, line := range lines {
+			problems = append(problems, at(file, line))
+		}
+		return problems
+	}
+
+	var problems []Problem
+	for n := 1; n <= 7; n++ {
+		if content.KubernetesContext[n] == "" {
+			problems = append(problems, at("content/osi.yaml", fmt.Sprintf("layer %d has no kubernetes context", n)))
+		}
+	}
+	for _, layer := range content.Layers {
+		for _, tool := range layer.CLITools {
+			if !utils.KnownTool(tool) && !referenceTools[tool] {
+				problems = append(problems, at("content/osi.yaml", fmt.Sprintf("layer %d: tool %q has no 'netlab doctor' check and is not listed as reference-only in internal/lint", layer.Number, tool)))
+			}
+		}
+	}
+	return problems
+}
+
+// headings returns the text of every Markdown heading outside code blocks
+func headings(markdown string) map[string]bool {
+	found := map[string]bool{}
+	inCode := false
+	scanner := bufio.NewScanner(strings.NewReader(markdown))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCode = !inCode
+			continue
+		}
+		if inCode || !strings.HasPrefix(line, "#") {
+			continue
+		}
+		text := strings.TrimSpace(strings.TrimLeft(line, "#"))
+		if text != "" {
+			found[text] = true
+		}
+	}
+	return found
+}
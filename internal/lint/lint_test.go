@@ -0,0 +1,154 @@
+package lint
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestRepositoryContent lints the content shipped in this repository, so
+// drift fails CI rather than showing up in the TUI
+func TestRepositoryContent(t *testing.T) {
+	problems, err := Run(filepath.Join("..", ".."))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range problems {
+		t.Error(p)
+	}
+}
+
+const osiFile = "modules/01-osi-model/content/osi.yaml"
+
+// edit replaces the first occurrence of old in a copied file
+type edit struct {
+	path, old, new string
+}
+
+// checkout copies the fixture modules in testdata/checkout into a temporary
+// root and applies edits to the copied files. The fixture has a README for
+// every registered module that isn't planned, and the OSI content.
+func checkout(t *testing.T, edits ...edit) string {
+	t.Helper()
+	root := t.TempDir()
+	src := filepath.Join("testdata", "checkout")
+	err := filepath.WalkDir(filepath.Join(src, "modules"), func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		write(t, root, rel, string(data))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range edits {
+		data, err := os.ReadFile(filepath.Join(root, e.path))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(data), e.old) {
+			t.Fatalf("%s does not contain %q", e.path, e.old)
+		}
+		write(t, root, e.path, strings.Replace(string(data), e.old, e.new, 1))
+	}
+	return root
+}
+
+func write(t *testing.T, root, path, data string) {
+	t.Helper()
+	path = filepath.Join(root, path)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func messages(problems []Problem) []string {
+	var got []string
+	for _, p := range problems {
+		got = append(got, p.String())
+	}
+	return got
+}
+
+func TestRunFixture(t *testing.T) {
+	problems, err := Run(checkout(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range problems {
+		t.Error(p)
+	}
+}
+
+func TestRunReportsProblems(t *testing.T) {
+	root := checkout(t,
+		edit{osiFile, "    kubernetes: Pod IPs, Service IPs, cluster CIDR, CNI manages IP address allocation\n", ""},
+		edit{osiFile, "mtr]", "mtr, hping3]"},
+	)
+
+	write(t, root, "modules/05-k8s-networking/module.go", "package k8snetworking\n")
+	write(t, root, "modules/03-subnetting/README.md", "# Subnetting\n\n## Key Concepts\n\n```bash\n# Not a section\n```\n")
+	write(t, root, "modules/03-subnetting/content/quiz.yaml", `questions:
+  - {lesson: Key Concepts, question: Q1, options: [a, b], answer: 0}
+  - {lesson: Not a section, question: Q2, options: [a, b], answer: 1}
+  - {question: Q3, options: [a, b], answer: 1}
+`)
+	write(t, root, "modules/99-stray/README.md", "# Stray\n")
+
+	problems, err := Run(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"01-osi-model: modules/01-osi-model/content/osi.yaml: layer 3 has no kubernetes context",
+		`01-osi-model: modules/01-osi-model/content/osi.yaml: layer 3: tool "hping3" has no 'netlab doctor' check and is not listed as reference-only in internal/lint`,
+		`03-subnetting: modules/03-subnetting/content/quiz.yaml: question 2: README has no section "Not a section"`,
+		"03-subnetting: modules/03-subnetting/content/quiz.yaml: question 3: lesson is required, name the README section that covers it",
+		"05-k8s-networking: modules/05-k8s-networking/README.md: missing; every module needs a README with learning objectives",
+		"99-stray: not in the registry; add it to internal/registry/registry.go",
+	}
+	if got := messages(problems); !reflect.DeepEqual(got, want) {
+		t.Errorf("problems:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestRunReportsInvalidOSIContent(t *testing.T) {
+	root := checkout(t,
+		edit{osiFile, "external_doc: https://tools.ietf.org/html/rfc791", "external_doc: tools.ietf.org/html/rfc791"},
+		edit{osiFile, "number: 2", "number: 1"},
+	)
+
+	problems, err := Run(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"01-osi-model: modules/01-osi-model/content/osi.yaml: layer 2 is missing",
+		`01-osi-model: modules/01-osi-model/content/osi.yaml: layers[4]: external_doc "tools.ietf.org/html/rfc791" is not an http(s) URL`,
+		"01-osi-model: modules/01-osi-model/content/osi.yaml: layers[6]: layer 1 is listed twice",
+	}
+	if got := messages(problems); !reflect.DeepEqual(got, want) {
+		t.Errorf("problems:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestRunOutsideCheckout(t *testing.T) {
+	if _, err := Run(t.TempDir()); err == nil || !strings.Contains(err.Error(), "not a netlab checkout") {
+		t.Fatalf("got error %v, want not a netlab checkout", err)
+	}
+}
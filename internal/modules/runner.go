@@ -3,8 +3,12 @@ package modules
 import (
 	"fmt"
 
+	"netlab/internal/registry"
 	"netlab/internal/tui"
 	osimodel "netlab/modules/01-osi-model"
+	tcpip "netlab/modules/02-tcp-ip"
+	subnetting "netlab/modules/03-subnetting"
+	routing "netlab/modules/04-routing"
 )
 
 // ModuleInfo contains metadata about a module
@@ -15,19 +19,9 @@ type ModuleInfo struct {
 
 // GetModuleInfo returns the module info for a given ID
 func GetModuleInfo(moduleID string) ModuleInfo {
-	moduleNames := map[string]string{
-		"01-osi-model":      "OSI Model Fundamentals",
-		"02-tcp-ip":         "TCP/IP Stack Deep Dive",
-		"03-subnetting":     "Subnetting and CIDR",
-		"04-routing":        "Routing Protocols",
-		"05-k8s-networking": "Kubernetes Networking",
-		"06-cni":            "Container Network Interface",
-		"07-service-mesh":   "Service Mesh Concepts",
-	}
-
-	name, exists := moduleNames[moduleID]
-	if !exists {
-		name = "Unknown Module"
+	name := "Unknown Module"
+	if m, exists := registry.Find(moduleID); exists {
+		name = m.Name
 	}
 
 	return ModuleInfo{
@@ -74,11 +68,11 @@ func runModuleImplementation(moduleID string) error {
 	case "01-osi-model", "01", "osi":
 		return osimodel.Run()
 	case "02-tcp-ip":
-		return fmt.Errorf("module %s is not implemented yet", moduleID)
+		return tcpip.Run()
 	case "03-subnetting":
-		return fmt.Errorf("module %s is not implemented yet", moduleID)
+		return subnetting.Run()
 	case "04-routing":
-		return fmt.Errorf("module %s is not implemented yet", moduleID)
+		return routing.Run()
 	case "05-k8s-networking":
 		return fmt.Errorf("module %s is not implemented yet", moduleID)
 	case "06-cni":
@@ -86,19 +80,18 @@ func runModuleImplementation(moduleID string) error {
 	case "07-service-mesh":
 		return fmt.Errorf("module %s is not implemented yet", moduleID)
 	default:
+		if m, exists := registry.Find(moduleID); exists && m.Content != nil {
+			return tui.RunPackModule(*m.Content)
+		}
 		return fmt.Errorf("unknown module: %s", moduleID)
 	}
 }
 
-// ListModules returns all available modules
+// ListModules returns all available modules, including those from content packs
 func ListModules() []string {
-	return []string{
-		"01-osi-model",
-		"02-tcp-ip",
-		"03-subnetting",
-		"04-routing",
-		"05-k8s-networking",
-		"06-cni",
-		"07-service-mesh",
+	var ids []string
+	for _, m := range registry.Modules() {
+		ids = append(ids, m.ID)
 	}
+	return ids
 }
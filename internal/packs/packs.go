@@ -0,0 +1,458 @@
+// Package packs loads content packs: directories of extra modules that ship
+// outside the netlab binary. A pack has a pack.yaml manifest listing its
+// modules, each with Markdown lessons, an optional quiz, lab scripts and the
+// tools it depends on.
+package packs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"netlab/internal/utils"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ManifestFile is the name of the manifest at the root of every pack
+const ManifestFile = "pack.yaml"
+
+// manifestVersion is the manifest schema version this build reads
+const manifestVersion = 1
+
+// idPattern is what pack names and module, lesson and lab ids look like
+var idPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// Pack is a loaded and validated content pack
+type Pack struct {
+	Version     int      `yaml:"version"`
+	Name        string   `yaml:"name"`
+	Title       string   `yaml:"title"`
+	Description string   `yaml:"description"`
+	Modules     []Module `yaml:"modules"`
+	Dir         string   `yaml:"-"`
+}
+
+// Module is a learning module provided by a pack
+type Module struct {
+	ID           string       `yaml:"id"`
+	Title        string       `yaml:"title"`
+	Description  string       `yaml:"description"`
+	Lessons      []Lesson     `yaml:"lessons"`
+	QuizFile     string       `yaml:"quiz"`
+	Labs         []Lab        `yaml:"labs"`
+	Dependencies []Dependency `yaml:"dependencies"`
+	Quiz         []Question   `yaml:"-"`
+	Pack         string       `yaml:"-"`
+}
+
+// Lesson is one Markdown page of a module
+type Lesson struct {
+	ID    string `yaml:"id"`
+	Title string `yaml:"title"`
+	File  string `yaml:"file"`
+	Body  string `yaml:"-"`
+}
+
+// Question is a multiple choice quiz question about one lesson
+type Question struct {
+	Lesson      string   `yaml:"lesson"`
+	Question    string   `yaml:"question"`
+	Options     []string `yaml:"options"`
+	Answer      int      `yaml:"answer"` // Index into Options
+	Explanation string   `yaml:"explanation"`
+}
+
+// Lab is a hands-on exercise driven by setup and cleanup scripts in the pack.
+// Script paths are absolute once the pack is loaded.
+type Lab struct {
+	ID          string   `yaml:"id"`
+	Title       string   `yaml:"title"`
+	Description string   `yaml:"description"`
+	Setup       string   `yaml:"setup"`
+	Cleanup     string   `yaml:"cleanup"`
+	Cluster     string   `yaml:"cluster"`    // kind cluster the setup creates
+	Namespaces  []string `yaml:"namespaces"` // Namespaces it creates, in Cluster when set
+}
+
+// Resources returns what the lab's setup creates, for the lab state file
+func (l Lab) Resources(module string) []utils.LabResource {
+	var resources []utils.LabResource
+	var kubeContext string
+	if l.Cluster != "" {
+		kubeContext = "kind-" + l.Cluster
+		resources = append(resources, utils.LabResource{
+			Type:    utils.ResourceKindCluster,
+			Name:    l.Cluster,
+			Context: kubeContext,
+			Module:  module,
+		})
+	}
+	for _, ns := range l.Namespaces {
+		resources = append(resources, utils.LabResource{
+			Type:    utils.ResourceNamespace,
+			Name:    ns,
+			Context: kubeContext,
+			Module:  module,
+		})
+	}
+	return resources
+}
+
+// Dependency is a tool a module needs. Tools NetLab already knows only need a
+// name; others also need the command that prints their version.
+type Dependency struct {
+	Name       string   `yaml:"name"`
+	Command    string   `yaml:"command"`
+	Args       []string `yaml:"args"`
+	MinVersion string   `yaml:"min_version"`
+	Install    string   `yaml:"install"`
+}
+
+// quizDoc is the schema of a quiz file
+type quizDoc struct {
+	Questions []Question `yaml:"questions"`
+}
+
+// ValidationError lists every problem found in a pack
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return strings.Join(e.Problems, "; ")
+}
+
+// Load reads and validates the pack in dir
+func Load(dir string) (Pack, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return Pack{}, err
+	}
+
+	var pack Pack
+	if err := decodeYAML(filepath.Join(dir, ManifestFile), &pack); err != nil {
+		return Pack{Dir: dir}, err
+	}
+	pack.Dir = dir
+
+	v := validator{dir: dir}
+	v.checkManifest(&pack)
+	if len(v.problems) > 0 {
+		return pack, &ValidationError{Problems: v.problems}
+	}
+	return pack, nil
+}
+
+// Discover loads every pack directory under root, which contains one
+// directory per pack, followed by the extra pack directories given. A
+// missing root is not an error. Packs that fail to load are returned with
+// their error so they can be reported; ids already taken by builtinIDs or
+// an earlier pack are errors too.
+func Discover(root string, extra []string, builtinIDs []string) ([]Pack, map[string]error) {
+	var dirs []string
+	if root != "" {
+		entries, err := os.ReadDir(root)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, map[string]error{root: err}
+		}
+		for _, e := range entries {
+			if e.IsDir() {
+				dirs = append(dirs, filepath.Join(root, e.Name()))
+			}
+		}
+	}
+	dirs = append(dirs, extra...)
+
+	taken := map[string]string{}
+	for _, id := range builtinIDs {
+		taken[id] = "a built-in module"
+	}
+
+	var loaded []Pack
+	failed := map[string]error{}
+	for _, dir := range dirs {
+		pack, err := Load(dir)
+		if err == nil {
+			var problems []string
+			for _, m := range pack.Modules {
+				if owner, ok := taken[m.ID]; ok {
+					problems = append(problems, fmt.Sprintf("modules: id %q is already used by %s", m.ID, owner))
+				}
+			}
+			if len(problems) > 0 {
+				err = &ValidationError{Problems: problems}
+			}
+		}
+		if err != nil {
+			failed[pack.Dir] = err
+			continue
+		}
+		for _, m := range pack.Modules {
+			taken[m.ID] = "pack " + pack.Name
+		}
+		loaded = append(loaded, pack)
+	}
+	return loaded, failed
+}
+
+// decodeYAML decodes a YAML file, rejecting fields the schema doesn't have
+func decodeYAML(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(v); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// validator collects problems instead of stopping at the first, so authors
+// can fix a pack in one pass
+type validator struct {
+	dir      string
+	problems []string
+}
+
+func (v *validator) addf(format string, args ...any) {
+	v.problems = append(v.problems, fmt.Sprintf(format, args...))
+}
+
+func (v *validator) checkManifest(p *Pack) {
+	if p.Version != manifestVersion {
+		v.addf("version %d is not supported, this build reads version %d", p.Version, manifestVersion)
+	}
+	if !idPattern.MatchString(p.Name) {
+		v.addf("name %q must be lowercase letters, digits and dashes", p.Name)
+	}
+	if p.Title == "" {
+		v.addf("title is required")
+	}
+	if len(p.Modules) == 0 {
+		v.addf("modules: at least one is required")
+	}
+
+	seen := map[string]bool{}
+	for i := range p.Modules {
+		m := &p.Modules[i]
+		m.Pack = p.Name
+		where := fmt.Sprintf("modules[%d]", i)
+		if m.ID != "" {
+			where = "module " + m.ID
+		}
+
+		if !idPattern.MatchString(m.ID) {
+			v.addf("%s: id %q must be lowercase letters, digits and dashes", where, m.ID)
+		} else if seen[m.ID] {
+			v.addf("%s: id is used twice", where)
+		}
+		seen[m.ID] = true
+		if m.Title == "" {
+			v.addf("%s: title is required", where)
+		}
+		v.checkModule(where, m)
+	}
+}
+
+func (v *validator) checkModule(where string, m *Module) {
+	if len(m.Lessons) == 0 {
+		v.addf("%s: lessons: at least one is required", where)
+	}
+	lessons := map[string]bool{}
+	for i := range m.Lessons {
+		l := &m.Lessons[i]
+		at := fmt.Sprintf("%s: lessons[%d]", where, i)
+		if !idPattern.MatchString(l.ID) {
+			v.addf("%s: id %q must be lowercase letters, digits and dashes", at, l.ID)
+		} else if lessons[l.ID] {
+			v.addf("%s: id %q is used twice", at, l.ID)
+		}
+		lessons[l.ID] = true
+		if l.Title == "" {
+			v.addf("%s: title is required", at)
+		}
+		if path, ok := v.file(at, "file", l.File); ok {
+			body, err := os.ReadFile(path)
+			if err != nil {
+				v.addf("%s: %v", at, err)
+			}
+			l.Body = string(body)
+		}
+	}
+
+	if m.QuizFile != "" {
+		if path, ok := v.file(where, "quiz", m.QuizFile); ok {
+			var quiz quizDoc
+			if err := decodeYAML(path, &quiz); err != nil {
+				v.addf("%s: %v", where, err)
+			}
+			m.Quiz = quiz.Questions
+			v.checkQuiz(where, m.Quiz, lessons)
+		}
+	}
+
+	labs := map[string]bool{}
+	for i := range m.Labs {
+		lab := &m.Labs[i]
+		at := fmt.Sprintf("%s: labs[%d]", where, i)
+		if !idPattern.MatchString(lab.ID) {
+			v.addf("%s: id %q must be lowercase letters, digits and dashes", at, lab.ID)
+		} else if labs[lab.ID] {
+			v.addf("%s: id %q is used twice", at, lab.ID)
+		}
+		labs[lab.ID] = true
+		if lab.Title == "" {
+			v.addf("%s: title is required", at)
+		}
+		if path, ok := v.script(at, "setup", lab.Setup); ok {
+			lab.Setup = path
+		}
+		if lab.Cleanup != "" {
+			if path, ok := v.script(at, "cleanup", lab.Cleanup); ok {
+				lab.Cleanup = path
+			}
+		}
+		if lab.Cluster != "" && (!strings.HasPrefix(lab.Cluster, utils.LabClusterPrefix) || !idPattern.MatchString(lab.Cluster)) {
+			v.addf("%s: cluster %q must start with %q and use lowercase letters, digits and dashes", at, lab.Cluster, utils.LabClusterPrefix)
+		}
+		for _, ns := range lab.Namespaces {
+			if !idPattern.MatchString(ns) {
+				v.addf("%s: namespace %q must be lowercase letters, digits and dashes", at, ns)
+			}
+		}
+	}
+
+	for i, dep := range m.Dependencies {
+		at := fmt.Sprintf("%s: dependencies[%d]", where, i)
+		if dep.Name == "" {
+			v.addf("%s: name is required", at)
+			continue
+		}
+		if dep.Command == "" && !utils.KnownTool(dep.Name) {
+			v.addf("%s: %s is not a tool NetLab knows, so it needs a command", at, dep.Name)
+		}
+		if dep.MinVersion != "" {
+			if err := utils.ValidVersionConstraint(dep.MinVersion); err != nil {
+				v.addf("%s: %v", at, err)
+			}
+		}
+	}
+}
+
+func (v *validator) checkQuiz(where string, questions []Question, lessons map[string]bool) {
+	for _, problem := range quizProblems(questions) {
+		v.addf("%s: %s", where, problem)
+	}
+	for i, q := range questions {
+		if q.Lesson != "" && !lessons[q.Lesson] {
+			v.addf("%s: quiz question %d: lesson %q does not exist", where, i+1, q.Lesson)
+		}
+	}
+}
+
+// quizProblems checks the questions themselves, leaving lesson references
+// to the caller
+func quizProblems(questions []Question) []string {
+	var problems []string
+	if len(questions) == 0 {
+		problems = append(problems, "quiz has no questions")
+	}
+	for i, q := range questions {
+		at := fmt.Sprintf("quiz question %d", i+1)
+		if q.Question == "" {
+			problems = append(problems, at+": question is required")
+		}
+		if len(q.Options) < 2 {
+			problems = append(problems, at+": needs at least two options")
+		}
+		if q.Answer < 0 || q.Answer >= len(q.Options) {
+			problems = append(problems, fmt.Sprintf("%s: answer %d is not one of the %d options", at, q.Answer, len(q.Options)))
+		}
+	}
+	return problems
+}
+
+// ParseQuiz decodes and checks a quiz file, for modules that embed their
+// quiz instead of shipping it in a pack
+func ParseQuiz(data []byte) ([]Question, error) {
+	var quiz quizDoc
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&quiz); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if problems := quizProblems(quiz.Questions); len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+	return quiz.Questions, nil
+}
+
+// file resolves a path inside the pack and checks that the file exists
+func (v *validator) file(where, field, rel string) (string, bool) {
+	if rel == "" {
+		v.addf("%s: %s is required", where, field)
+		return "", false
+	}
+	if filepath.IsAbs(rel) || !filepath.IsLocal(rel) {
+		v.addf("%s: %s %q must be a path inside the pack", where, field, rel)
+		return "", false
+	}
+	path := filepath.Join(v.dir, rel)
+	info, err := os.Stat(path)
+	if err != nil {
+		v.addf("%s: %s %q does not exist", where, field, rel)
+		return "", false
+	}
+	if info.IsDir() {
+		v.addf("%s: %s %q is a directory", where, field, rel)
+		return "", false
+	}
+	return path, true
+}
+
+// script resolves a lab script and checks that it can be run
+func (v *validator) script(where, field, rel string) (string, bool) {
+	path, ok := v.file(where, field, rel)
+	if !ok {
+		return "", false
+	}
+	if info, err := os.Stat(path); err == nil && info.Mode()&0o111 == 0 {
+		v.addf("%s: %s %q is not executable (chmod +x %s)", where, field, rel, rel)
+		return "", false
+	}
+	return path, true
+}
+
+// Statuses summarises loaded and failed packs for 'netlab doctor', in path
+// order
+func Statuses(loaded []Pack, failed map[string]error) []utils.ContentPackStatus {
+	var statuses []utils.ContentPackStatus
+	for _, p := range loaded {
+		status := utils.ContentPackStatus{Name: p.Name, Path: p.Dir}
+		for _, m := range p.Modules {
+			status.Modules = append(status.Modules, m.ID)
+		}
+		statuses = append(statuses, status)
+	}
+	for dir, err := range failed {
+		status := utils.ContentPackStatus{Name: filepath.Base(dir), Path: dir}
+		var verr *ValidationError
+		if errors.As(err, &verr) {
+			status.Errors = verr.Problems
+		} else {
+			status.Errors = []string{err.Error()}
+		}
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Path < statuses[j].Path })
+	return statuses
+}
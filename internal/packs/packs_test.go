@@ -0,0 +1,145 @@
+package packs
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	pack, err := Load("testdata/acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pack.Name != "acme" || len(pack.Modules) != 1 {
+		t.Fatalf("got pack %q with %d modules", pack.Name, len(pack.Modules))
+	}
+
+	m := pack.Modules[0]
+	if m.Pack != "acme" {
+		t.Errorf("module Pack = %q", m.Pack)
+	}
+	if len(m.Lessons) != 2 || !strings.Contains(m.Lessons[0].Body, "BGP mode") {
+		t.Errorf("lessons not loaded: %+v", m.Lessons)
+	}
+	if len(m.Quiz) != 2 || m.Quiz[0].Answer != 1 {
+		t.Errorf("quiz not loaded: %+v", m.Quiz)
+	}
+	if !filepath.IsAbs(m.Labs[0].Setup) || filepath.Base(m.Labs[0].Cleanup) != "cleanup.sh" {
+		t.Errorf("lab scripts not resolved: %+v", m.Labs[0])
+	}
+	resources := m.Labs[0].Resources(m.ID)
+	if len(resources) != 2 || resources[0].Name != "netlab-acme" || resources[1].Name != "calico-system" || resources[1].Context != "kind-netlab-acme" {
+		t.Errorf("lab resources = %+v", resources)
+	}
+	if len(m.Dependencies) != 2 || m.Dependencies[1].Command != "calicoctl" {
+		t.Errorf("dependencies = %+v", m.Dependencies)
+	}
+}
+
+func TestLoadReportsEveryProblem(t *testing.T) {
+	_, err := Load("testdata/broken")
+
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("error = %v, want a ValidationError", err)
+	}
+
+	want := []string{
+		`name "Broken Pack" must be lowercase letters, digits and dashes`,
+		"title is required",
+		`lessons[0]: file "lessons/missing.md" does not exist`,
+		`lessons[1]: file "../acme/lessons/overview.md" must be a path inside the pack`,
+		`quiz "quizzes/none.yaml" does not exist`,
+		`setup "lessons/not-executable.md" is not executable`,
+		`cluster "acme" must start with "netlab-"`,
+		`namespace "Calico" must be lowercase letters, digits and dashes`,
+		"mystery-tool is not a tool NetLab knows, so it needs a command",
+		`invalid version constraint ">=one"`,
+	}
+	all := strings.Join(verr.Problems, "\n")
+	for _, w := range want {
+		if !strings.Contains(all, w) {
+			t.Errorf("missing problem %q in:\n%s", w, all)
+		}
+	}
+}
+
+func TestLoadRejectsUnknownFields(t *testing.T) {
+	_, err := Load("testdata/typo")
+	if err == nil || !strings.Contains(err.Error(), "field lesson not found") {
+		t.Errorf("error = %v", err)
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	loaded, failed := Discover("testdata", nil, []string{"01-osi-model"})
+
+	if len(loaded) != 1 || loaded[0].Name != "acme" {
+		t.Fatalf("loaded %+v", loaded)
+	}
+	if len(failed) != 2 {
+		t.Fatalf("failed = %v, want broken and typo", failed)
+	}
+
+	statuses := Statuses(loaded, failed)
+	if len(statuses) != 3 {
+		t.Fatalf("got %d statuses", len(statuses))
+	}
+	if statuses[0].Name != "acme" || statuses[0].Modules[0] != "acme-cni" || len(statuses[0].Errors) != 0 {
+		t.Errorf("acme status = %+v", statuses[0])
+	}
+	if statuses[1].Name != "broken" || len(statuses[1].Errors) < 8 {
+		t.Errorf("broken status = %+v", statuses[1])
+	}
+}
+
+func TestDiscoverRejectsTakenIDs(t *testing.T) {
+	// The same pack twice: the second copy clashes with the first
+	loaded, failed := Discover("", []string{"testdata/acme", "testdata/acme/."}, nil)
+	if len(loaded) != 1 || len(failed) != 1 {
+		t.Fatalf("loaded %d, failed %v", len(loaded), failed)
+	}
+	for _, err := range failed {
+		if !strings.Contains(err.Error(), `id "acme-cni" is already used by pack acme`) {
+			t.Errorf("error = %v", err)
+		}
+	}
+
+	_, failed = Discover("", []string{"testdata/acme"}, []string{"acme-cni"})
+	for _, err := range failed {
+		if !strings.Contains(err.Error(), "already used by a built-in module") {
+			t.Errorf("error = %v", err)
+		}
+	}
+	if len(failed) != 1 {
+		t.Errorf("clash with a built-in module not reported")
+	}
+}
+
+func TestDiscoverMissingRoot(t *testing.T) {
+	loaded, failed := Discover(filepath.Join(t.TempDir(), "packs"), nil, nil)
+	if len(loaded) != 0 || len(failed) != 0 {
+		t.Errorf("loaded %v, failed %v", loaded, failed)
+	}
+}
+
+func TestParseQuiz(t *testing.T) {
+	questions, err := ParseQuiz([]byte("questions:\n  - {question: Which layer routes, options: [Network, Session], answer: 0}\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(questions) != 1 || questions[0].Options[0] != "Network" {
+		t.Errorf("questions = %+v", questions)
+	}
+
+	_, err = ParseQuiz([]byte("questions:\n  - {question: Which layer routes, options: [Network], answer: 1}\n"))
+	if err == nil || !strings.Contains(err.Error(), "needs at least two options") || !strings.Contains(err.Error(), "answer 1 is not one of the 1 options") {
+		t.Errorf("error = %v", err)
+	}
+
+	if _, err := ParseQuiz([]byte("questions: []\nquestoins: []\n")); err == nil {
+		t.Error("unknown field accepted")
+	}
+}
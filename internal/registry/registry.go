@@ -0,0 +1,145 @@
+// Package registry lists every learning module NetLab can run: the built-in
+// ones and those loaded from content packs.
+package registry
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+
+	"netlab/internal/packs"
+	"netlab/internal/utils"
+)
+
+// Module describes a learning module for menus and the module runner
+type Module struct {
+	ID          string
+	Name        string
+	Description string
+	Status      string // "ready", "planned", "wip"
+	Pack        string // Name of the content pack, empty for built-in modules
+	Content     *packs.Module
+}
+
+// builtins are the modules compiled into netlab, in learning order
+var builtins = []Module{
+	{ID: "01-osi-model", Name: "OSI Model Fundamentals", Description: "Learn the seven layers of network communication", Status: "ready"},
+	{ID: "02-tcp-ip", Name: "TCP/IP Stack Deep Dive", Description: "Explore the Internet Protocol suite in detail", Status: "wip"},
+	{ID: "03-subnetting", Name: "Subnetting and CIDR", Description: "Master network segmentation and addressing", Status: "wip"},
+	{ID: "04-routing", Name: "Routing Protocols", Description: "Understand how packets find their destination", Status: "wip"},
+	{ID: "05-k8s-networking", Name: "Kubernetes Networking", Description: "Container networking in orchestrated environments", Status: "planned"},
+	{ID: "06-cni", Name: "Container Network Interface", Description: "CNI specifications and implementations", Status: "planned"},
+	{ID: "07-service-mesh", Name: "Service Mesh Concepts", Description: "Advanced traffic management and observability", Status: "planned"},
+}
+
+var (
+	mu          sync.RWMutex
+	packModules []Module
+)
+
+// Modules returns the built-in modules followed by those from content packs
+func Modules() []Module {
+	mu.RLock()
+	defer mu.RUnlock()
+	return append(append([]Module(nil), builtins...), packModules...)
+}
+
+// Find looks up a module by ID
+func Find(id string) (Module, bool) {
+	for _, m := range Modules() {
+		if m.ID == id {
+			return m, true
+		}
+	}
+	return Module{}, false
+}
+
+// BuiltinIDs returns the IDs of the modules compiled into netlab
+func BuiltinIDs() []string {
+	ids := make([]string, len(builtins))
+	for i, m := range builtins {
+		ids[i] = m.ID
+	}
+	return ids
+}
+
+// PacksDir returns where content packs are installed, one directory per pack
+func PacksDir() (string, error) {
+	dir, err := utils.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "packs"), nil
+}
+
+// LoadPacks loads the installed content packs plus the pack directories
+// given, registers their modules and dependencies, and records the outcome
+// for 'netlab doctor'. Broken packs are skipped rather than stopping netlab.
+func LoadPacks(extra []string) []utils.ContentPackStatus {
+	root, err := PacksDir()
+	if err != nil {
+		root = ""
+	}
+	loaded, failed := packs.Discover(root, extra, BuiltinIDs())
+
+	var modules []Module
+	var registered []packs.Pack
+	for i := range loaded {
+		pack := &loaded[i]
+		if err := registerDependencies(pack); err != nil {
+			failed[pack.Dir] = err
+			continue
+		}
+		registered = append(registered, *pack)
+		for j := range pack.Modules {
+			m := &pack.Modules[j]
+			modules = append(modules, Module{
+				ID:          m.ID,
+				Name:        m.Title,
+				Description: m.Description,
+				Status:      "ready",
+				Pack:        pack.Name,
+				Content:     m,
+			})
+		}
+	}
+
+	mu.Lock()
+	packModules = modules
+	mu.Unlock()
+
+	statuses := packs.Statuses(registered, failed)
+	utils.SetContentPacks(statuses)
+	return statuses
+}
+
+// registerDependencies makes the dependency checks aware of a pack's
+// modules and any new tools they declare. Every module is validated first,
+// so a pack that fails registers nothing.
+func registerDependencies(pack *packs.Pack) error {
+	for _, m := range pack.Modules {
+		if err := utils.ValidateModuleDependencies(m.ID, toolSpecs(m.Dependencies)); err != nil {
+			return fmt.Errorf("module %s: %w", m.ID, err)
+		}
+	}
+	for _, m := range pack.Modules {
+		if err := utils.RegisterModuleDependencies(m.ID, toolSpecs(m.Dependencies)); err != nil {
+			return fmt.Errorf("module %s: %w", m.ID, err)
+		}
+	}
+	return nil
+}
+
+func toolSpecs(deps []packs.Dependency) []utils.ToolSpec {
+	specs := make([]utils.ToolSpec, len(deps))
+	for i, d := range deps {
+		specs[i] = utils.ToolSpec{
+			Name:       d.Name,
+			Command:    d.Command,
+			Args:       d.Args,
+			MinVersion: d.MinVersion,
+			Install:    d.Install,
+		}
+	}
+	return specs
+}
@@ -0,0 +1,65 @@
+package registry
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"netlab/internal/packs"
+	"netlab/internal/utils"
+)
+
+func TestLoadPacks(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	statuses := LoadPacks([]string{"../packs/testdata/acme", "../packs/testdata/broken"})
+	if len(statuses) != 2 {
+		t.Fatalf("got %d statuses", len(statuses))
+	}
+	if !reflect.DeepEqual(statuses, utils.ContentPacks()) {
+		t.Error("statuses not recorded for doctor")
+	}
+
+	m, ok := Find("acme-cni")
+	if !ok || m.Pack != "acme" || m.Content == nil || m.Status != "ready" {
+		t.Fatalf("acme-cni = %+v, %v", m, ok)
+	}
+	all := Modules()
+	if all[0].ID != "01-osi-model" || all[len(all)-1].ID != "acme-cni" {
+		t.Errorf("pack modules should follow the built-in ones")
+	}
+	if got := utils.ModuleDependencyNames("acme-cni"); !reflect.DeepEqual(got, []string{"kubectl", "calicoctl"}) {
+		t.Errorf("dependencies = %v", got)
+	}
+
+	// The broken pack's module clashes with a built-in and is not registered
+	if m, _ := Find("01-osi-model"); m.Pack != "" {
+		t.Errorf("built-in module replaced by %s", m.Pack)
+	}
+}
+
+func TestRegisterDependenciesAllOrNothing(t *testing.T) {
+	pack := &packs.Pack{
+		Name: "half-broken",
+		Modules: []packs.Module{
+			{ID: "half-broken-ok", Dependencies: []packs.Dependency{
+				{Name: "kubectl"},
+				{Name: "half-broken-tool", Command: "hbtool"},
+			}},
+			{ID: "half-broken-bad", Dependencies: []packs.Dependency{
+				{Name: "half-broken-mystery"}, // Unknown and without a command
+			}},
+		},
+	}
+
+	err := registerDependencies(pack)
+	if err == nil || !strings.Contains(err.Error(), "half-broken-bad") {
+		t.Fatalf("err = %v, want the second module's error", err)
+	}
+	if names := utils.ModuleDependencyNames("half-broken-ok"); len(names) != 0 {
+		t.Errorf("first module registered %v", names)
+	}
+	if utils.KnownTool("half-broken-tool") {
+		t.Error("first module's new tool registered")
+	}
+}
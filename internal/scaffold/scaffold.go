@@ -0,0 +1,264 @@
+// Package scaffold generates the skeleton of a built-in learning module and
+// registers it with the module registry, the runner and the dependency
+// checks, so a new module is one command instead of six manual steps.
+package scaffold
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"netlab/internal/utils"
+)
+
+//go:embed templates
+var templates embed.FS
+
+// Files edited to register a module, relative to the repository root
+const (
+	registryFile    = "internal/registry/registry.go"
+	runnerFile      = "internal/modules/runner.go"
+	diagnosticsFile = "internal/utils/diagnostics.go"
+)
+
+// idPattern matches module IDs such as 03-subnetting: a two-digit position
+// in the learning order, then lowercase words separated by dashes
+var idPattern = regexp.MustCompile(`^\d{2}-[a-z][a-z0-9]*(-[a-z0-9]+)*$`)
+
+// builtinPattern matches one entry of the registry's builtins list
+var builtinPattern = regexp.MustCompile(`\{ID: "([^"]+)", Name: "([^"]*)", Description: "([^"]*)", Status: "[^"]*"\},`)
+
+// Options describe the module to create
+type Options struct {
+	ID           string
+	Name         string   // Defaults to the registry entry for a planned module
+	Description  string   // Defaults to the registry entry for a planned module
+	Dependencies []string // Tool names from 'netlab doctor'; nil keeps a planned module's list
+}
+
+// Result lists the files written, relative to the repository root
+type Result struct {
+	Created []string
+	Updated []string
+}
+
+// module is the data the templates are rendered with
+type module struct {
+	Options
+	Package string // Go package name, e.g. subnetting for 03-subnetting
+	Short   string // Prefix for golden file names
+}
+
+// NewModule creates modules/<id> under root and registers it. Every file is
+// generated and checked before any is written, so a failure leaves the tree
+// untouched.
+func NewModule(root string, opts Options) (Result, error) {
+	if !idPattern.MatchString(opts.ID) {
+		return Result{}, fmt.Errorf("invalid module ID %q: want two digits and lowercase words, like 03-subnetting", opts.ID)
+	}
+	for _, dep := range opts.Dependencies {
+		if !utils.KnownTool(dep) {
+			return Result{}, fmt.Errorf("unknown tool %q: add a check for it to internal/utils/diagnostics.go first", dep)
+		}
+	}
+
+	dir := filepath.Join("modules", opts.ID)
+	if _, err := os.Stat(filepath.Join(root, dir)); err == nil {
+		return Result{}, fmt.Errorf("%s already exists", dir)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return Result{}, err
+	}
+
+	sources := map[string][]byte{}
+	for _, path := range []string{registryFile, runnerFile, diagnosticsFile} {
+		data, err := os.ReadFile(filepath.Join(root, path))
+		if err != nil {
+			return Result{}, fmt.Errorf("not a netlab checkout: %w", err)
+		}
+		sources[path] = data
+	}
+
+	pkg := packageName(opts.ID)
+	m := module{Options: opts, Package: pkg, Short: pkg}
+
+	registry, err := registerBuiltin(string(sources[registryFile]), &m.Options)
+	if err != nil {
+		return Result{}, fmt.Errorf("%s: %w", registryFile, err)
+	}
+	runner, err := registerRunner(string(sources[runnerFile]), opts.ID, pkg)
+	if err != nil {
+		return Result{}, fmt.Errorf("%s: %w", runnerFile, err)
+	}
+	diagnostics, err := registerDependencies(string(sources[diagnosticsFile]), opts.ID, opts.Dependencies)
+	if err != nil {
+		return Result{}, fmt.Errorf("%s: %w", diagnosticsFile, err)
+	}
+	if m.Name == "" || m.Description == "" {
+		return Result{}, fmt.Errorf("module %s needs a name and a description", opts.ID)
+	}
+
+	created := map[string]string{
+		"README.md":         "README.md.tmpl",
+		"module.go":         "module.go.tmpl",
+		"module_test.go":    "module_test.go.tmpl",
+		"content/quiz.yaml": "quiz.yaml.tmpl",
+	}
+	files := map[string][]byte{}
+	for name, tmpl := range created {
+		data, err := render(tmpl, m)
+		if err != nil {
+			return Result{}, err
+		}
+		files[filepath.Join(dir, name)] = data
+	}
+
+	updated := map[string]string{registryFile: registry, runnerFile: runner, diagnosticsFile: diagnostics}
+	for path, src := range updated {
+		formatted, err := format.Source([]byte(src))
+		if err != nil {
+			return Result{}, fmt.Errorf("%s: %w", path, err)
+		}
+		files[path] = formatted
+	}
+
+	var result Result
+	for _, name := range []string{"README.md", "module.go", "module_test.go", "content/quiz.yaml"} {
+		result.Created = append(result.Created, filepath.Join(dir, name))
+	}
+	for _, path := range []string{registryFile, runnerFile, diagnosticsFile} {
+		if !bytes.Equal(files[path], sources[path]) {
+			result.Updated = append(result.Updated, path)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Join(root, dir, "content"), 0o755); err != nil {
+		return Result{}, err
+	}
+	for _, path := range append(append([]string(nil), result.Created...), result.Updated...) {
+		if err := os.WriteFile(filepath.Join(root, path), files[path], 0o644); err != nil {
+			return Result{}, err
+		}
+	}
+	return result, nil
+}
+
+// packageName turns a module ID into a Go package name: 01-osi-model
+// becomes osimodel
+func packageName(id string) string {
+	return strings.ReplaceAll(id[3:], "-", "")
+}
+
+func render(name string, m module) ([]byte, error) {
+	tmpl, err := template.ParseFS(templates, "templates/"+name)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, m); err != nil {
+		return nil, fmt.Errorf("rendering %s: %w", name, err)
+	}
+	if strings.HasSuffix(name, ".go.tmpl") {
+		return format.Source(buf.Bytes())
+	}
+	return buf.Bytes(), nil
+}
+
+// registerBuiltin adds the module to the registry's builtins, or marks a
+// planned module as in progress. Missing name and description are filled
+// in from an existing entry.
+func registerBuiltin(src string, opts *Options) (string, error) {
+	for _, match := range builtinPattern.FindAllStringSubmatchIndex(src, -1) {
+		if src[match[2]:match[3]] != opts.ID {
+			continue
+		}
+		if opts.Name == "" {
+			opts.Name = src[match[4]:match[5]]
+		}
+		if opts.Description == "" {
+			opts.Description = src[match[6]:match[7]]
+		}
+		return src[:match[0]] + builtinEntry(*opts) + src[match[1]:], nil
+	}
+	return insertBeforeClose(src, "var builtins = []Module{", "\t"+builtinEntry(*opts)+"\n")
+}
+
+func builtinEntry(opts Options) string {
+	return fmt.Sprintf("{ID: %q, Name: %q, Description: %q, Status: \"wip\"},", opts.ID, opts.Name, opts.Description)
+}
+
+// registerRunner imports the module package and makes runModuleImplementation
+// call its Run, replacing the "not implemented yet" case of a planned module
+func registerRunner(src, id, pkg string) (string, error) {
+	importPath := fmt.Sprintf("%s \"netlab/modules/%s\"", pkg, id)
+	if strings.Contains(src, importPath) {
+		return "", fmt.Errorf("module %s is already imported", id)
+	}
+	src, err := insertBeforeClose(src, "import (", "\t"+importPath+"\n")
+	if err != nil {
+		return "", err
+	}
+
+	start := strings.Index(src, "func runModuleImplementation(")
+	if start < 0 {
+		return "", errors.New("runModuleImplementation not found")
+	}
+	call := fmt.Sprintf("\t\treturn %s.Run()\n", pkg)
+
+	caseLine := fmt.Sprintf("\tcase %q:\n", id)
+	if at := strings.Index(src[start:], caseLine); at >= 0 {
+		bodyStart := start + at + len(caseLine)
+		bodyEnd := bodyStart + strings.Index(src[bodyStart:], "\n") + 1
+		return src[:bodyStart] + call + src[bodyEnd:], nil
+	}
+
+	def := strings.Index(src[start:], "\tdefault:\n")
+	if def < 0 {
+		return "", errors.New("default case of runModuleImplementation not found")
+	}
+	def += start
+	return src[:def] + caseLine + call + src[def:], nil
+}
+
+// registerDependencies declares the module's tools in ModuleDependencies.
+// A planned module keeps its existing list unless deps are given.
+func registerDependencies(src, id string, deps []string) (string, error) {
+	quoted := make([]string, len(deps))
+	for i, dep := range deps {
+		quoted[i] = fmt.Sprintf("%q", dep)
+	}
+	entry := fmt.Sprintf("%q: {%s},", id, strings.Join(quoted, ", "))
+
+	existing := regexp.MustCompile(`(?m)^\t` + regexp.QuoteMeta(fmt.Sprintf("%q:", id)) + `.*,$`)
+	if loc := existing.FindStringIndex(src); loc != nil {
+		if deps == nil {
+			return src, nil
+		}
+		return src[:loc[0]] + "\t" + entry + src[loc[1]:], nil
+	}
+	return insertBeforeClose(src, "var ModuleDependencies = map[string][]string{", "\t"+entry+"\n")
+}
+
+// insertBeforeClose inserts text as the last line of the block opened by
+// the line starting with open
+func insertBeforeClose(src, open, text string) (string, error) {
+	start := strings.Index(src, "\n"+open+"\n")
+	if start < 0 {
+		return "", fmt.Errorf("%q not found", open)
+	}
+	end := strings.Index(src[start:], "\n)\n")
+	if strings.HasSuffix(open, "{") {
+		end = strings.Index(src[start:], "\n}\n")
+	}
+	if end < 0 {
+		return "", fmt.Errorf("end of %q not found", open)
+	}
+	at := start + end + 1
+	return src[:at] + text + src[at:], nil
+}
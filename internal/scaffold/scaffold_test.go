@@ -0,0 +1,161 @@
+package scaffold
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// checkout copies the trimmed registry, runner and diagnostics files in
+// testdata/checkout into a temporary root
+func checkout(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	for _, path := range []string{registryFile, runnerFile, diagnosticsFile} {
+		data, err := os.ReadFile(filepath.Join("testdata", "checkout", path))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(filepath.Join(root, filepath.Dir(path)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(root, path), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func read(t *testing.T, root, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(root, path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestNewModule(t *testing.T) {
+	root := checkout(t)
+	result, err := NewModule(root, Options{
+		ID:           "08-dns",
+		Name:         "DNS Resolution",
+		Description:  "Follow a query to the authoritative server",
+		Dependencies: []string{"kubectl", "tcpdump"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantCreated := []string{
+		"modules/08-dns/README.md",
+		"modules/08-dns/module.go",
+		"modules/08-dns/module_test.go",
+		"modules/08-dns/content/quiz.yaml",
+	}
+	if !reflect.DeepEqual(result.Created, wantCreated) {
+		t.Errorf("created %v, want %v", result.Created, wantCreated)
+	}
+	wantUpdated := []string{registryFile, runnerFile, diagnosticsFile}
+	if !reflect.DeepEqual(result.Updated, wantUpdated) {
+		t.Errorf("updated %v, want %v", result.Updated, wantUpdated)
+	}
+
+	fset := token.NewFileSet()
+	goFiles := append([]string{"modules/08-dns/module.go", "modules/08-dns/module_test.go"}, result.Updated...)
+	for _, path := range goFiles {
+		if _, err := parser.ParseFile(fset, path, read(t, root, path), 0); err != nil {
+			t.Errorf("%s does not parse: %v", path, err)
+		}
+	}
+
+	checks := map[string][]string{
+		registryFile:               {`{ID: "08-dns", Name: "DNS Resolution", Description: "Follow a query to the authoritative server", Status: "wip"},`},
+		runnerFile:                 {`dns "netlab/modules/08-dns"`, "case \"08-dns\":\n\t\treturn dns.Run()"},
+		diagnosticsFile:            {`"08-dns":            {"kubectl", "tcpdump"},`},
+		"modules/08-dns/module.go": {"package dns", `"NetLab DNS Resolution"`},
+		"modules/08-dns/README.md": {"# DNS Resolution Module", "## Learning Objectives", "`kubectl`, `tcpdump`"},
+	}
+	for path, wants := range checks {
+		got := read(t, root, path)
+		for _, want := range wants {
+			if !strings.Contains(got, want) {
+				t.Errorf("%s does not contain %q", path, want)
+			}
+		}
+	}
+}
+
+func TestNewModulePlanned(t *testing.T) {
+	root := checkout(t)
+	diagnostics := read(t, root, diagnosticsFile)
+
+	result, err := NewModule(root, Options{ID: "05-k8s-networking"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{registryFile, runnerFile}; !reflect.DeepEqual(result.Updated, want) {
+		t.Errorf("updated %v, want %v", result.Updated, want)
+	}
+
+	registry := read(t, root, registryFile)
+	if !strings.Contains(registry, `{ID: "05-k8s-networking", Name: "Kubernetes Networking", Description: "Container networking in orchestrated environments", Status: "wip"},`) {
+		t.Errorf("planned entry not marked as in progress:\n%s", registry)
+	}
+	runner := read(t, root, runnerFile)
+	if n := strings.Count(runner, `case "05-k8s-networking":`); n != 1 {
+		t.Errorf("runner has %d cases for the module, want 1", n)
+	}
+	if !strings.Contains(runner, "case \"05-k8s-networking\":\n\t\treturn k8snetworking.Run()") {
+		t.Errorf("runner does not run the module:\n%s", runner)
+	}
+	if got := read(t, root, diagnosticsFile); got != diagnostics {
+		t.Error("dependencies of a planned module changed without --deps")
+	}
+}
+
+func TestNewModuleErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{"bad id", Options{ID: "dns", Name: "DNS", Description: "d"}, "invalid module ID"},
+		{"uppercase id", Options{ID: "08-DNS", Name: "DNS", Description: "d"}, "invalid module ID"},
+		{"unknown tool", Options{ID: "08-dns", Name: "DNS", Description: "d", Dependencies: []string{"dig"}}, `unknown tool "dig"`},
+		{"existing module", Options{ID: "01-osi-model"}, "modules/01-osi-model already exists"},
+		{"no name", Options{ID: "08-dns"}, "needs a name and a description"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := checkout(t)
+			if err := os.MkdirAll(filepath.Join(root, "modules", "01-osi-model"), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			registry := read(t, root, registryFile)
+
+			_, err := NewModule(root, tt.opts)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("got error %v, want %q", err, tt.want)
+			}
+			if read(t, root, registryFile) != registry {
+				t.Error("registry changed although the module was not created")
+			}
+			if _, err := os.Stat(filepath.Join(root, "modules", "08-dns")); err == nil {
+				t.Error("module directory created although the module was not")
+			}
+		})
+	}
+}
+
+func TestNewModuleOutsideCheckout(t *testing.T) {
+	_, err := NewModule(t.TempDir(), Options{ID: "08-dns", Name: "DNS", Description: "d"})
+	if err == nil || !strings.Contains(err.Error(), "not a netlab checkout") {
+		t.Fatalf("got error %v, want not a netlab checkout", err)
+	}
+}
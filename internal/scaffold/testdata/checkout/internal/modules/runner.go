@@ -0,0 +1,24 @@
+// Package modules is a trimmed copy of internal/modules with only what the
+// scaffold edits
+package modules
+
+import (
+	"fmt"
+
+	osimodel "netlab/modules/01-osi-model"
+	tcpip "netlab/modules/02-tcp-ip"
+)
+
+// runModuleImplementation contains the actual module execution logic
+func runModuleImplementation(moduleID string) error {
+	switch moduleID {
+	case "01-osi-model", "01", "osi":
+		return osimodel.Run()
+	case "02-tcp-ip":
+		return tcpip.Run()
+	case "05-k8s-networking":
+		return fmt.Errorf("module %s is not implemented yet", moduleID)
+	default:
+		return fmt.Errorf("unknown module: %s", moduleID)
+	}
+}
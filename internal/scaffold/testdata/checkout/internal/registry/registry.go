@@ -0,0 +1,18 @@
+// Package registry is a trimmed copy of internal/registry with only what
+// the scaffold edits
+package registry
+
+// Module describes a learning module for menus and the module runner
+type Module struct {
+	ID          string
+	Name        string
+	Description string
+	Status      string // "ready", "planned", "wip"
+}
+
+// builtins are the modules compiled into netlab, in learning order
+var builtins = []Module{
+	{ID: "01-osi-model", Name: "OSI Model Fundamentals", Description: "Learn the seven layers of network communication", Status: "ready"},
+	{ID: "02-tcp-ip", Name: "TCP/IP Stack Deep Dive", Description: "Explore the Internet Protocol suite in detail", Status: "wip"},
+	{ID: "05-k8s-networking", Name: "Kubernetes Networking", Description: "Container networking in orchestrated environments", Status: "planned"},
+}
@@ -0,0 +1,10 @@
+// Package utils is a trimmed copy of internal/utils with only what the
+// scaffold edits
+package utils
+
+// ModuleDependencies defines what each module actually needs
+var ModuleDependencies = map[string][]string{
+	"01-osi-model":      {"Docker", "kubectl", "kind", "tcpdump", "tshark"},
+	"02-tcp-ip":         {"tcpdump", "tshark"},
+	"05-k8s-networking": {"Docker", "kubectl", "kind"},
+}
@@ -1,10 +1,12 @@
 package tui
 
 import (
+	"context"
 	"fmt"
 	"netlab/internal/utils"
 	"strings"
 
+	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -24,6 +26,10 @@ var (
 			Foreground(lipgloss.Color("9")).
 			Bold(true)
 
+	outdatedStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("11")).
+			Bold(true)
+
 	depHelpStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("8")).
 			Italic(true).
@@ -56,9 +62,13 @@ const (
 type dependencyCheckModel struct {
 	moduleID       string
 	moduleName     string
-	dependencies   []utils.DependencyStatus
+	dependencies   []utils.DependencyStatus // Status is "" while a check is pending
 	missingDeps    []utils.DependencyStatus
 	allGood        bool
+	checking       bool
+	results        <-chan utils.DependencyResult
+	cancel         context.CancelFunc
+	spinner        spinner.Model
 	showingGuide   bool
 	viewport       viewport.Model
 	selectedButton int
@@ -68,36 +78,84 @@ type dependencyCheckModel struct {
 	done           bool
 }
 
-func NewDependencyCheck(moduleID, moduleName string) *dependencyCheckModel {
-	// Check dependencies for the module
-	deps, allGood := utils.CheckModuleDependencies(moduleID)
+// depResultMsg delivers one finished check from the run that produced it
+type depResultMsg struct {
+	results <-chan utils.DependencyResult
+	result  utils.DependencyResult
+}
 
-	// Filter missing dependencies
-	var missing []utils.DependencyStatus
-	for _, dep := range deps {
-		if dep.Status != "ok" {
-			missing = append(missing, dep)
-		}
-	}
+// depChecksDoneMsg is sent once every check in a run has finished
+type depChecksDoneMsg struct {
+	results <-chan utils.DependencyResult
+}
 
+func NewDependencyCheck(moduleID, moduleName string) *dependencyCheckModel {
 	vp := viewport.New(60, 10)
 	vp.Style = lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
 		BorderForeground(lipgloss.Color("8")).
 		Padding(1, 2)
 
+	s := spinner.New()
+	s.Spinner = spinner.Dot
+	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("12"))
+
 	return &dependencyCheckModel{
-		moduleID:     moduleID,
-		moduleName:   moduleName,
-		dependencies: deps,
-		missingDeps:  missing,
-		allGood:      allGood,
-		viewport:     vp,
+		moduleID:   moduleID,
+		moduleName: moduleName,
+		viewport:   vp,
+		spinner:    s,
 	}
 }
 
 func (m *dependencyCheckModel) Init() tea.Cmd {
-	return nil
+	return m.startChecks()
+}
+
+// startChecks launches every check for the module at once, cancelling any
+// run still in flight. Rows fill in as results arrive.
+func (m *dependencyCheckModel) startChecks() tea.Cmd {
+	m.stopChecks()
+
+	names := utils.ModuleDependencyNames(m.moduleID)
+	m.dependencies = make([]utils.DependencyStatus, len(names))
+	for i, name := range names {
+		m.dependencies[i] = utils.DependencyStatus{Name: name}
+	}
+	m.missingDeps = nil
+	m.allGood = false
+	m.selectedButton = 0
+
+	if len(names) == 0 {
+		m.allGood = true
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+	m.results = utils.StreamModuleDependencies(ctx, m.moduleID)
+	m.checking = true
+
+	return tea.Batch(m.spinner.Tick, waitForDependency(m.results))
+}
+
+// stopChecks cancels the current run, if any
+func (m *dependencyCheckModel) stopChecks() {
+	if m.cancel != nil {
+		m.cancel()
+		m.cancel = nil
+	}
+	m.checking = false
+}
+
+func waitForDependency(results <-chan utils.DependencyResult) tea.Cmd {
+	return func() tea.Msg {
+		r, ok := <-results
+		if !ok {
+			return depChecksDoneMsg{results: results}
+		}
+		return depResultMsg{results: results, result: r}
+	}
 }
 
 func (m *dependencyCheckModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -109,8 +167,26 @@ func (m *dependencyCheckModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.viewport.Height = m.height - 12
 		return m, nil
 
-	case recheckMsg:
-		m.updateDependencies(msg)
+	case spinner.TickMsg:
+		if !m.checking {
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
+	case depResultMsg:
+		if msg.results != m.results {
+			return m, nil // Left over from a cancelled run
+		}
+		m.dependencies[msg.result.Index] = msg.result.Status
+		return m, waitForDependency(m.results)
+
+	case depChecksDoneMsg:
+		if msg.results != m.results {
+			return m, nil
+		}
+		m.finishChecks()
 		return m, nil
 
 	case tea.KeyMsg:
@@ -128,10 +204,17 @@ func (m *dependencyCheckModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		switch msg.String() {
 		case "ctrl+c", "q":
+			m.stopChecks()
 			m.result = CheckAbort
 			m.done = true
 			return m, tea.Quit
+		}
 
+		if m.checking {
+			return m, nil // Buttons appear once every check has finished
+		}
+
+		switch msg.String() {
 		case "left", "h":
 			if m.selectedButton > 0 {
 				m.selectedButton--
@@ -162,11 +245,11 @@ func (m *dependencyCheckModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				} else {
 					// This is "Check Again" when all good
 					m.result = CheckRecheck
-					return m, m.recheckDependencies()
+					return m, m.startChecks()
 				}
 			case 2: // Check Again
 				m.result = CheckRecheck
-				return m, m.recheckDependencies()
+				return m, m.startChecks()
 			}
 		}
 	}
@@ -174,31 +257,17 @@ func (m *dependencyCheckModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
-func (m *dependencyCheckModel) recheckDependencies() tea.Cmd {
-	return func() tea.Msg {
-		// Re-check dependencies
-		deps, allGood := utils.CheckModuleDependencies(m.moduleID)
-
-		// Filter missing dependencies
-		var missing []utils.DependencyStatus
-		for _, dep := range deps {
-			if dep.Status != "ok" {
-				missing = append(missing, dep)
-			}
-		}
+// finishChecks summarises a completed run
+func (m *dependencyCheckModel) finishChecks() {
+	m.stopChecks()
 
-		return recheckMsg{
-			dependencies: deps,
-			missingDeps:  missing,
-			allGood:      allGood,
+	m.missingDeps = nil
+	for _, dep := range m.dependencies {
+		if dep.Status != "ok" {
+			m.missingDeps = append(m.missingDeps, dep)
 		}
 	}
-}
-
-type recheckMsg struct {
-	dependencies []utils.DependencyStatus
-	missingDeps  []utils.DependencyStatus
-	allGood      bool
+	m.allGood = len(m.missingDeps) == 0
 }
 
 func (m *dependencyCheckModel) View() string {
@@ -217,29 +286,43 @@ func (m *dependencyCheckModel) View() string {
 	s.WriteString("\n\n")
 
 	// Status summary
-	if m.allGood {
+	if m.checking {
+		s.WriteString(depTitleStyle.Copy().UnsetMargins().Render(m.progressText()))
+		s.WriteString("\n\n")
+	} else if m.allGood {
 		s.WriteString(okStyle.Render("✅ All dependencies satisfied!"))
 		s.WriteString("\n\n")
 	} else {
-		s.WriteString(missingStyle.Render(fmt.Sprintf("⚠️  Missing %d dependencies", len(m.missingDeps))))
+		s.WriteString(missingStyle.Render(m.summaryText()))
 		s.WriteString("\n\n")
 	}
 
 	// Dependency list
 	for _, dep := range m.dependencies {
 		switch dep.Status {
+		case "":
+			s.WriteString(fmt.Sprintf("%s%s - checking...", m.spinner.View(), dep.Name)) // Spinner frames include a trailing space
 		case "ok":
 			s.WriteString(okStyle.Render(fmt.Sprintf("✓ %s", dep.Name)))
 		case "missing":
 			s.WriteString(missingStyle.Render(fmt.Sprintf("✗ %s - Not found", dep.Name)))
 		case "error":
 			s.WriteString(missingStyle.Render(fmt.Sprintf("✗ %s - Error", dep.Name)))
+		case "outdated":
+			s.WriteString(outdatedStyle.Render(fmt.Sprintf("⚠ %s - Outdated (%s, needs %s)", dep.Name, dep.Version, dep.MinVersion)))
+		case "timeout":
+			s.WriteString(outdatedStyle.Render(fmt.Sprintf("⏱ %s - Timed out (%s)", dep.Name, dep.Output)))
 		}
 		s.WriteString("\n")
 	}
 
 	s.WriteString("\n")
 
+	if m.checking {
+		s.WriteString(depHelpStyle.Render("Checking dependencies, q to quit"))
+		return s.String()
+	}
+
 	// Warning if missing dependencies
 	if !m.allGood {
 		s.WriteString(lipgloss.NewStyle().
@@ -258,6 +341,51 @@ func (m *dependencyCheckModel) View() string {
 	return s.String()
 }
 
+// progressText reports how many checks have finished
+func (m *dependencyCheckModel) progressText() string {
+	finished := 0
+	for _, dep := range m.dependencies {
+		if dep.Status != "" {
+			finished++
+		}
+	}
+	return fmt.Sprintf("Checking dependencies (%d/%d)", finished, len(m.dependencies))
+}
+
+// summaryText describes what is wrong, distinguishing missing, outdated and
+// timed out tools
+func (m *dependencyCheckModel) summaryText() string {
+	outdated, timedOut := 0, 0
+	for _, dep := range m.missingDeps {
+		switch dep.Status {
+		case "outdated":
+			outdated++
+		case "timeout":
+			timedOut++
+		}
+	}
+
+	missing := len(m.missingDeps) - outdated - timedOut
+	if timedOut > 0 {
+		if missing == 0 && outdated == 0 {
+			return fmt.Sprintf("⚠️  %d dependency checks timed out", timedOut)
+		}
+		return fmt.Sprintf("%s (%d timed out)", m.problemText(missing, outdated), timedOut)
+	}
+	return m.problemText(missing, outdated)
+}
+
+func (m *dependencyCheckModel) problemText(missing, outdated int) string {
+	switch {
+	case outdated == 0:
+		return fmt.Sprintf("⚠️  Missing %d dependencies", missing)
+	case missing == 0:
+		return fmt.Sprintf("⚠️  %d outdated dependencies", outdated)
+	default:
+		return fmt.Sprintf("⚠️  Missing %d and %d outdated dependencies", missing, outdated)
+	}
+}
+
 func (m *dependencyCheckModel) renderButtons() string {
 	buttons := []string{}
 
@@ -316,26 +444,13 @@ func (m *dependencyCheckModel) AllDependenciesSatisfied() bool {
 	return m.allGood
 }
 
-// Handle recheck message
-func (m *dependencyCheckModel) updateDependencies(msg recheckMsg) {
-	m.dependencies = msg.dependencies
-	m.missingDeps = msg.missingDeps
-	m.allGood = msg.allGood
-	m.selectedButton = 0 // Reset selection
-}
-
-// Update method to handle recheckMsg
-func (m *dependencyCheckModel) handleRecheck(msg recheckMsg) (*dependencyCheckModel, tea.Cmd) {
-	m.updateDependencies(msg)
-	return m, nil
-}
-
 // RunDependencyCheck runs the dependency check TUI and returns the result
 func RunDependencyCheck(moduleID, moduleName string) (DependencyCheckResult, error) {
 	model := NewDependencyCheck(moduleID, moduleName)
 
 	p := tea.NewProgram(model, tea.WithAltScreen())
 	finalModel, err := p.Run()
+	model.stopChecks()
 	if err != nil {
 		return CheckAbort, err
 	}
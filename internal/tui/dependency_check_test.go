@@ -0,0 +1,79 @@
+package tui
+
+import (
+	"testing"
+
+	"netlab/internal/tuitest"
+	"netlab/internal/utils"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// scriptedDependencyCheck returns a check screen for the OSI module whose
+// rows are filled by the given results instead of real commands
+func scriptedDependencyCheck(results ...utils.DependencyStatus) (*dependencyCheckModel, []tea.Msg) {
+	m := NewDependencyCheck("01-osi-model", "OSI Model Fundamentals")
+	for _, name := range utils.ModuleDependencyNames("01-osi-model") {
+		m.dependencies = append(m.dependencies, utils.DependencyStatus{Name: name})
+	}
+	m.checking = true
+
+	var msgs []tea.Msg
+	for i, r := range results {
+		msgs = append(msgs, depResultMsg{result: utils.DependencyResult{Index: i, Status: r}})
+	}
+	return m, msgs
+}
+
+var osiResults = []utils.DependencyStatus{
+	{Name: "Docker", Status: "ok", Required: true},
+	{Name: "kubectl", Status: "ok", Required: true},
+	{Name: "kind", Status: "outdated", Version: "0.17.0", MinVersion: ">=0.20.0", Required: true, UpgradeCmd: "brew upgrade kind"},
+	{Name: "tcpdump", Status: "timeout", Output: "no response after 5s", Required: true},
+	{Name: "tshark", Status: "missing", Required: true, InstallCmd: "sudo apt-get install -y tshark"},
+}
+
+func TestDependencyCheckView(t *testing.T) {
+	allOK := make([]utils.DependencyStatus, len(osiResults))
+	for i, r := range osiResults {
+		allOK[i] = utils.DependencyStatus{Name: r.Name, Status: "ok", Required: true}
+	}
+
+	tests := []struct {
+		name          string
+		width, height int
+		results       []utils.DependencyStatus
+		done          bool
+		keys          []string
+	}{
+		{"depcheck_checking_80x30", 80, 30, osiResults[:2], false, nil},
+		{"depcheck_problems_80x30", 80, 30, osiResults, true, nil},
+		{"depcheck_problems_60x20", 60, 20, osiResults, true, nil},
+		{"depcheck_all_ok_80x30", 80, 30, allOK, true, []string{"right"}},
+		{"depcheck_guide_80x30", 80, 30, osiResults, true, []string{"right", "enter"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, msgs := scriptedDependencyCheck(tt.results...)
+			if tt.done {
+				msgs = append(msgs, depChecksDoneMsg{})
+			}
+			msgs = append(msgs, tuitest.Keys(tt.keys...)...)
+			tuitest.AssertView(t, tt.name, m, tt.width, tt.height, msgs...)
+		})
+	}
+}
+
+func TestDependencyCheckIgnoresKeysWhileChecking(t *testing.T) {
+	m, msgs := scriptedDependencyCheck(osiResults[:1]...)
+	tuitest.Send(m, append(msgs, tuitest.Key("enter"))...)
+	if m.done {
+		t.Fatal("enter finished the check before results arrived")
+	}
+
+	tuitest.Send(m, tuitest.Key("q"))
+	if !m.done || m.GetResult() != CheckAbort {
+		t.Errorf("q should abort, got done=%v result=%v", m.done, m.GetResult())
+	}
+}
@@ -0,0 +1,392 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"netlab/internal/packs"
+	"netlab/internal/utils"
+	"netlab/pkg/components"
+	"netlab/pkg/styles"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// packScreen is one of the tabs of a content pack module
+type packScreen int
+
+const (
+	packLessons packScreen = iota
+	packQuiz
+	packLabs
+)
+
+var packScreenNames = map[packScreen]string{
+	packLessons: "Lessons",
+	packQuiz:    "Quiz",
+	packLabs:    "Labs",
+}
+
+// labFinishedMsg reports a lab script that ran in the terminal
+type labFinishedMsg struct {
+	lab       string
+	action    string
+	resources []utils.LabResource // What the lab creates, forgotten after a clean cleanup
+	err       error
+}
+
+// packModuleModel shows a module from a content pack: its lessons as
+// Markdown, its quiz and its labs
+type packModuleModel struct {
+	module    packs.Module
+	screens   []packScreen
+	screen    int
+	lesson    int
+	doc       components.Document
+	quiz      components.Quiz
+	lab       int
+	labStatus string
+	width     int
+	height    int
+	quitting  bool
+	executor  utils.Executor
+}
+
+func newPackModuleModel(module packs.Module) packModuleModel {
+	m := packModuleModel{module: module, screens: []packScreen{packLessons}, executor: utils.ShellExecutor{}}
+	if len(module.Quiz) > 0 {
+		m.screens = append(m.screens, packQuiz)
+	}
+	if len(module.Labs) > 0 {
+		m.screens = append(m.screens, packLabs)
+	}
+	m.doc = components.NewDocument(m.lessonBody())
+	m.quiz = components.NewQuiz(m.quizQuestions())
+	return m
+}
+
+// quizQuestions converts the module's quiz for the quiz component
+func (m packModuleModel) quizQuestions() []components.QuizQuestion {
+	questions := make([]components.QuizQuestion, len(m.module.Quiz))
+	for i, q := range m.module.Quiz {
+		questions[i] = components.QuizQuestion{
+			Question:    q.Question,
+			Options:     q.Options,
+			Answer:      q.Answer,
+			Explanation: q.Explanation,
+			SeeAlso:     m.lessonTitle(q.Lesson),
+		}
+	}
+	return questions
+}
+
+func (m packModuleModel) Init() tea.Cmd {
+	return nil
+}
+
+// lessonBody returns the current lesson's Markdown
+func (m packModuleModel) lessonBody() string {
+	if len(m.module.Lessons) == 0 {
+		return ""
+	}
+	return m.module.Lessons[m.lesson].Body
+}
+
+// contentSize returns the space inside the frame, below its title line
+func (m packModuleModel) contentSize() (width, height int) {
+	// Header and tabs (3), frame border (2), separator and help (2)
+	return m.width - 6, m.height - 7 - 2
+}
+
+func (m *packModuleModel) showLesson(i int) {
+	m.lesson = i
+	m.doc = components.NewDocument(m.lessonBody())
+	m.doc.SetSize(m.contentSize())
+}
+
+func (m packModuleModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.doc.SetSize(m.contentSize())
+		return m, nil
+
+	case labFinishedMsg:
+		if msg.err != nil {
+			m.labStatus = styles.StatusError.Render(fmt.Sprintf("❌ %s of %s failed: %v", msg.action, msg.lab, msg.err))
+			return m, nil
+		}
+		m.labStatus = styles.StatusSuccess.Render(fmt.Sprintf("✅ %s of %s finished", msg.action, msg.lab))
+		if msg.action == "Cleanup" {
+			for _, r := range msg.resources {
+				if err := utils.UntrackLabResource(r); err != nil {
+					m.labStatus += "\n" + styles.StatusWarning.Render(fmt.Sprintf("⚠️  Could not update lab state: %v", err))
+					break
+				}
+			}
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "q", "esc":
+			m.quitting = true
+			return m, tea.Quit
+		case "tab":
+			m.screen = (m.screen + 1) % len(m.screens)
+			return m, nil
+		case "shift+tab":
+			m.screen = (m.screen + len(m.screens) - 1) % len(m.screens)
+			return m, nil
+		}
+
+		switch m.screens[m.screen] {
+		case packLessons:
+			return m.updateLessons(msg)
+		case packQuiz:
+			m.quiz = m.quiz.Update(msg)
+			return m, nil
+		case packLabs:
+			return m.updateLabs(msg)
+		}
+	}
+
+	if m.screens[m.screen] == packLessons {
+		var cmd tea.Cmd
+		m.doc, cmd = m.doc.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+func (m packModuleModel) updateLessons(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "right", "l", "n":
+		if m.lesson < len(m.module.Lessons)-1 {
+			m.showLesson(m.lesson + 1)
+		}
+		return m, nil
+	case "left", "h", "p":
+		if m.lesson > 0 {
+			m.showLesson(m.lesson - 1)
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.doc, cmd = m.doc.Update(msg)
+	return m, cmd
+}
+
+func (m packModuleModel) updateLabs(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	lab := m.module.Labs[m.lab]
+	switch msg.String() {
+	case "up", "k":
+		if m.lab > 0 {
+			m.lab--
+		}
+	case "down", "j":
+		if m.lab < len(m.module.Labs)-1 {
+			m.lab++
+		}
+	case "s":
+		m.labStatus = ""
+		// Record what the lab creates before it exists so an interrupted
+		// setup can still be cleaned up
+		for _, r := range lab.Resources(m.module.ID) {
+			if err := utils.TrackLabResource(r); err != nil {
+				m.labStatus = styles.StatusWarning.Render(fmt.Sprintf("⚠️  Could not record lab state: %v", err))
+				break
+			}
+		}
+		return m, m.runLabScript(lab, "Setup", lab.Setup)
+	case "c":
+		if lab.Cleanup != "" {
+			m.labStatus = ""
+			return m, m.runLabScript(lab, "Cleanup", lab.Cleanup)
+		}
+	}
+	return m, nil
+}
+
+// runLabScript hands the terminal to a lab script so its prompts and
+// progress are shown as the author wrote them
+func (m packModuleModel) runLabScript(lab packs.Lab, action, script string) tea.Cmd {
+	cmd := &labScriptCommand{executor: m.executor, script: script}
+	resources := lab.Resources(m.module.ID)
+	return tea.Exec(cmd, func(err error) tea.Msg {
+		return labFinishedMsg{lab: lab.Title, action: action, resources: resources, err: err}
+	})
+}
+
+// labScriptCommand runs a lab script from its own directory through the
+// executor, while Bubble Tea has released the terminal
+type labScriptCommand struct {
+	executor utils.Executor
+	script   string
+	stdout   io.Writer
+}
+
+func (c *labScriptCommand) Run() error {
+	return c.executor.Shell(c.commandLine(), c.stdout)
+}
+
+// commandLine changes to the script's directory and runs it
+func (c *labScriptCommand) commandLine() string {
+	return "cd " + shellQuote(filepath.Dir(c.script)) + " && exec " + shellQuote(c.script)
+}
+
+func (c *labScriptCommand) SetStdout(w io.Writer) { c.stdout = w }
+
+// The shell executor keeps stdin on the terminal and sends stderr to stdout
+func (c *labScriptCommand) SetStdin(io.Reader)  {}
+func (c *labScriptCommand) SetStderr(io.Writer) {}
+
+// shellQuote quotes s as a single sh word
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func (m packModuleModel) View() string {
+	if m.quitting {
+		return ""
+	}
+	if m.width < 60 || m.height < 20 {
+		return fmt.Sprintf("⚠️  Terminal too small (%dx%d), please resize to at least 60x20", m.width, m.height)
+	}
+
+	width, height := m.contentSize()
+	var title, body string
+	switch m.screens[m.screen] {
+	case packLessons:
+		lesson := m.module.Lessons[m.lesson]
+		title = styles.H3.Render(lesson.Title) + "  " +
+			styles.BodyMuted.Render(fmt.Sprintf("lesson %d/%d · %3.0f%%", m.lesson+1, len(m.module.Lessons), m.doc.ScrollPercent()*100))
+		body = m.doc.View()
+	case packQuiz:
+		title = styles.H3.Render("📝 Quiz")
+		body = m.quiz.View(width)
+	case packLabs:
+		title = styles.H3.Render("🧪 Labs")
+		body = m.labsView(width)
+	}
+
+	containerStyle := lipgloss.NewStyle().
+		Width(m.width-4).
+		Height(height+2).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(styles.Border).
+		Padding(0, 1)
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		m.headerView(),
+		containerStyle.Render(title+"\n\n"+body),
+		styles.BodyDim.Render(strings.Repeat("─", m.width-2)),
+		m.helpView(),
+	)
+}
+
+func (m packModuleModel) headerView() string {
+	title := styles.H2.Copy().
+		Width(m.width-4).
+		Align(lipgloss.Center).
+		Margin(0, 0).
+		Render(m.module.Title)
+
+	breadcrumb := styles.BodyMuted.Copy().
+		Margin(0, 0).
+		Render(fmt.Sprintf("NetLab > %s > %s", m.module.Pack, m.module.Title))
+
+	var tabs []string
+	for i, screen := range m.screens {
+		name := packScreenNames[screen]
+		if i == m.screen {
+			tabs = append(tabs, styles.NavItemActive.Render("["+name+"]"))
+			continue
+		}
+		tabs = append(tabs, styles.NavItem.Render(" "+name+" "))
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, title, breadcrumb, strings.Join(tabs, ""))
+}
+
+func (m packModuleModel) helpView() string {
+	var helpKeys []string
+	switch m.screens[m.screen] {
+	case packLessons:
+		helpKeys = []string{
+			styles.KeyBinding.Render("↑/↓") + " scroll",
+			styles.KeyBinding.Render("←/→") + " lesson",
+		}
+	case packQuiz:
+		helpKeys = []string{
+			styles.KeyBinding.Render("↑/↓") + " choose",
+			styles.KeyBinding.Render("enter") + " answer",
+		}
+		if m.quiz.Finished() {
+			helpKeys = []string{styles.KeyBinding.Render("r") + " retry"}
+		}
+	case packLabs:
+		helpKeys = []string{
+			styles.KeyBinding.Render("↑/↓") + " select",
+			styles.KeyBinding.Render("s") + " setup",
+			styles.KeyBinding.Render("c") + " cleanup",
+		}
+	}
+	if len(m.screens) > 1 {
+		helpKeys = append(helpKeys, styles.KeyBinding.Render("tab")+" next tab")
+	}
+	helpKeys = append(helpKeys, styles.KeyBinding.Render("q")+" quit")
+	return styles.Help.Copy().UnsetMargins().Render(strings.Join(helpKeys, " • "))
+}
+
+// lessonTitle returns the title of the lesson with the given id
+func (m packModuleModel) lessonTitle(id string) string {
+	for _, l := range m.module.Lessons {
+		if l.ID == id {
+			return l.Title
+		}
+	}
+	return ""
+}
+
+func (m packModuleModel) labsView(width int) string {
+	var lines []string
+	for i, lab := range m.module.Labs {
+		line := "  " + lab.Title
+		if i == m.lab {
+			line = styles.NavItemActive.Copy().UnsetPadding().Render("▶ " + lab.Title)
+		}
+		lines = append(lines, line)
+	}
+
+	lab := m.module.Labs[m.lab]
+	lines = append(lines, "")
+	if lab.Description != "" {
+		lines = append(lines, lipgloss.NewStyle().Width(width).Render(lab.Description), "")
+	}
+	lines = append(lines, styles.BodyMuted.Render("Setup:   "+filepath.Base(lab.Setup)))
+	if lab.Cleanup != "" {
+		lines = append(lines, styles.BodyMuted.Render("Cleanup: "+filepath.Base(lab.Cleanup)))
+	}
+	if m.labStatus != "" {
+		lines = append(lines, "", m.labStatus)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// RunPackModule starts the TUI for a module from a content pack
+func RunPackModule(module packs.Module) error {
+	p := tea.NewProgram(
+		newPackModuleModel(module),
+		tea.WithAltScreen(),
+		tea.WithMouseCellMotion(),
+	)
+	_, err := p.Run()
+	return err
+}
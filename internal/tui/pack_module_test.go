@@ -0,0 +1,96 @@
+package tui
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"netlab/internal/packs"
+	"netlab/internal/tuitest"
+	"netlab/internal/utils"
+)
+
+func loadTestPack(t *testing.T) packs.Module {
+	t.Helper()
+	pack, err := packs.Load("../packs/testdata/acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pack.Modules[0]
+}
+
+func TestPackModuleView(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		keys          []string
+	}{
+		{"pack_100x30_lesson", 100, 30, nil},
+		{"pack_100x30_second_lesson", 100, 30, []string{"right"}},
+		{"pack_60x20_lesson", 60, 20, nil},
+		{"pack_100x30_quiz", 100, 30, []string{"tab", "down"}},
+		{"pack_100x30_quiz_wrong", 100, 30, []string{"tab", "3", "enter"}},
+		{"pack_100x30_quiz_score", 100, 30, []string{"tab", "enter", "enter", "2", "enter", "enter"}},
+		{"pack_100x30_labs", 100, 30, []string{"tab", "tab"}},
+		{"pack_59x20_too_small", 59, 20, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tuitest.AssertView(t, tt.name, newPackModuleModel(loadTestPack(t)), tt.width, tt.height, tuitest.Keys(tt.keys...)...)
+		})
+	}
+}
+
+func TestPackModuleLabResult(t *testing.T) {
+	m := tuitest.Send(newPackModuleModel(loadTestPack(t)),
+		tuitest.Resize(100, 30), tuitest.Key("tab"), tuitest.Key("tab"),
+		labFinishedMsg{lab: "Calico on kind", action: "Setup"})
+	tuitest.AssertGolden(t, "pack_100x30_lab_finished", m.View())
+}
+
+func TestLabScriptCommand(t *testing.T) {
+	exec := utils.NewScriptedExecutor(map[string]utils.ScriptedResult{
+		`cd '/packs/it'\''s' && exec '/packs/it'\''s/setup.sh'`: {Stdout: "setting up\n"},
+	})
+	var out bytes.Buffer
+	cmd := &labScriptCommand{executor: exec, script: "/packs/it's/setup.sh"}
+	cmd.SetStdout(&out)
+
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Run: %v (commands %q)", err, exec.Commands())
+	}
+	if out.String() != "setting up\n" {
+		t.Errorf("output = %q", out.String())
+	}
+}
+
+func TestPackModuleTracksLabResources(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	module := loadTestPack(t)
+	tracked := func() []utils.LabResource {
+		t.Helper()
+		state, err := utils.LoadLabState()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return state.ForModule(module.ID)
+	}
+
+	m := newPackModuleModel(module)
+	m.executor = utils.NewScriptedExecutor(nil)
+	model := tuitest.Send(m, tuitest.Resize(100, 30), tuitest.Key("tab"), tuitest.Key("tab"), tuitest.Key("s"))
+	if got := tracked(); len(got) != 2 || got[0].Name != "netlab-acme" || got[1].Name != "calico-system" {
+		t.Fatalf("tracked after setup = %+v", got)
+	}
+
+	resources := module.Labs[0].Resources(module.ID)
+	model = tuitest.Send(model, labFinishedMsg{lab: "Calico on kind", action: "Cleanup", resources: resources, err: errors.New("exit status 1")})
+	if got := tracked(); len(got) != 2 {
+		t.Errorf("a failed cleanup forgot the lab resources: %+v", got)
+	}
+	tuitest.Send(model, labFinishedMsg{lab: "Calico on kind", action: "Cleanup", resources: resources})
+	if got := tracked(); len(got) != 0 {
+		t.Errorf("tracked after cleanup = %+v", got)
+	}
+}
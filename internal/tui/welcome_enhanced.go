@@ -5,6 +5,7 @@ import (
 	"io"
 	"strings"
 
+	"netlab/internal/registry"
 	"netlab/pkg/components"
 	"netlab/pkg/styles"
 
@@ -23,6 +24,7 @@ type enhancedItem struct {
 	description string
 	moduleID    string
 	status      string // "ready", "planned", "wip"
+	pack        string // Content pack the module comes from, if any
 }
 
 func (i enhancedItem) FilterValue() string { return "" }
@@ -54,6 +56,9 @@ func (d enhancedItemDelegate) Render(w io.Writer, m list.Model, index int, listI
 		statusStyle = styles.StatusInfo
 		statusText = "📋 PLANNED"
 	}
+	if i.pack != "" {
+		statusText = "📦 " + i.pack + "  " + statusText
+	}
 
 	// Module number and title
 	moduleNum := fmt.Sprintf("%d.", index+1)
@@ -61,10 +66,11 @@ func (d enhancedItemDelegate) Render(w io.Writer, m list.Model, index int, listI
 
 	var titleStyle lipgloss.Style
 	var itemStyle lipgloss.Style
+	renderedStatus := statusStyle.Render(statusText)
 
 	if index == m.Index() {
-		// Selected item
-		titleStyle = styles.ListItemActive.Copy().Width(m.Width() - 4)
+		// Selected item, its highlight running up to the status column
+		titleStyle = styles.ListItemActive.Copy().Width(m.Width() - 5 - lipgloss.Width(renderedStatus))
 		itemStyle = styles.ListItemActive.Copy().
 			Foreground(styles.Background).
 			Width(m.Width() - 4)
@@ -80,7 +86,6 @@ func (d enhancedItemDelegate) Render(w io.Writer, m list.Model, index int, listI
 	// Render the item
 	renderedTitle := titleStyle.Render(title)
 	renderedDesc := itemStyle.Render(i.description)
-	renderedStatus := statusStyle.Render(statusText)
 
 	// Combine with status
 	line1 := lipgloss.JoinHorizontal(lipgloss.Left,
@@ -142,7 +147,7 @@ func (m enhancedModel) View() string {
 	header := components.RenderWelcomeHeader(max(m.width, minWidth))
 
 	// Instructions
-	instructions := styles.Instruction.
+	instructions := styles.Instruction.Copy().
 		Align(lipgloss.Center).
 		Margin(1, 0).
 		Render("Select a learning module to begin your networking journey")
@@ -156,7 +161,7 @@ func (m enhancedModel) View() string {
 		styles.KeyBinding.Render("Enter") + " select",
 		styles.KeyBinding.Render("q") + " quit",
 	}
-	helpText := styles.Help.
+	helpText := styles.Help.Copy().
 		Align(lipgloss.Center).
 		Margin(1, 0).
 		Render(strings.Join(helpKeys, " • "))
@@ -170,7 +175,7 @@ func (m enhancedModel) View() string {
 		}
 	}
 	progress := fmt.Sprintf("Progress: %d/%d modules ready", readyModules, totalModules)
-	progressText := styles.BodyMuted.
+	progressText := styles.BodyMuted.Copy().
 		Align(lipgloss.Center).
 		Render(progress)
 
@@ -194,51 +199,17 @@ func (m enhancedModel) View() string {
 	)
 }
 
-// StartEnhancedWelcome launches the enhanced welcome screen
-func StartEnhancedWelcome() (string, error) {
-	items := []list.Item{
-		enhancedItem{
-			title:       "OSI Model Fundamentals",
-			description: "Learn the seven layers of network communication",
-			moduleID:    "01-osi-model",
-			status:      "ready",
-		},
-		enhancedItem{
-			title:       "TCP/IP Stack Deep Dive",
-			description: "Explore the Internet Protocol suite in detail",
-			moduleID:    "02-tcp-ip",
-			status:      "planned",
-		},
-		enhancedItem{
-			title:       "Subnetting and CIDR",
-			description: "Master network segmentation and addressing",
-			moduleID:    "03-subnetting",
-			status:      "planned",
-		},
-		enhancedItem{
-			title:       "Routing Protocols",
-			description: "Understand how packets find their destination",
-			moduleID:    "04-routing",
-			status:      "planned",
-		},
-		enhancedItem{
-			title:       "Kubernetes Networking",
-			description: "Container networking in orchestrated environments",
-			moduleID:    "05-k8s-networking",
-			status:      "planned",
-		},
-		enhancedItem{
-			title:       "Container Network Interface",
-			description: "CNI specifications and implementations",
-			moduleID:    "06-cni",
-			status:      "planned",
-		},
-		enhancedItem{
-			title:       "Service Mesh Concepts",
-			description: "Advanced traffic management and observability",
-			moduleID:    "07-service-mesh",
-			status:      "planned",
-		},
+// newEnhancedModel builds the welcome screen with the module catalogue
+func newEnhancedModel() enhancedModel {
+	var items []list.Item
+	for _, m := range registry.Modules() {
+		items = append(items, enhancedItem{
+			title:       m.Name,
+			description: m.Description,
+			moduleID:    m.ID,
+			status:      m.Status,
+			pack:        m.Pack,
+		})
 	}
 
 	l := list.New(items, enhancedItemDelegate{}, minWidth, enhancedListHeight)
@@ -249,15 +220,18 @@ func StartEnhancedWelcome() (string, error) {
 
 	// Custom list styles
 	l.Styles.NoItems = styles.BodyMuted
-	l.Styles.PaginationStyle = styles.BodyDim.Align(lipgloss.Center)
+	l.Styles.PaginationStyle = styles.BodyDim.Copy().Align(lipgloss.Center)
 
-	m := enhancedModel{
+	return enhancedModel{
 		list:   l,
 		width:  minWidth,
 		height: 30,
 	}
+}
 
-	p := tea.NewProgram(m, tea.WithAltScreen())
+// StartEnhancedWelcome launches the enhanced welcome screen
+func StartEnhancedWelcome() (string, error) {
+	p := tea.NewProgram(newEnhancedModel(), tea.WithAltScreen())
 	finalModel, err := p.Run()
 	if err != nil {
 		return "", err
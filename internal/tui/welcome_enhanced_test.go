@@ -0,0 +1,37 @@
+package tui
+
+import (
+	"testing"
+
+	"netlab/internal/tuitest"
+)
+
+func TestEnhancedWelcomeView(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		keys          []string
+	}{
+		{"welcome_80x30", 80, 30, nil},
+		{"welcome_120x40", 120, 40, nil},
+		{"welcome_60x20", 60, 20, nil},
+		{"welcome_80x30_third_module", 80, 30, []string{"down", "down"}},
+		{"welcome_80x30_next_page", 80, 30, []string{"right"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tuitest.AssertView(t, tt.name, newEnhancedModel(), tt.width, tt.height, tuitest.Keys(tt.keys...)...)
+		})
+	}
+}
+
+func TestEnhancedWelcomeSelect(t *testing.T) {
+	m := tuitest.Send(newEnhancedModel(), tuitest.Resize(80, 30), tuitest.Key("down"), tuitest.Key("enter"))
+	if got := m.(enhancedModel).choice; got != "02-tcp-ip" {
+		t.Errorf("choice = %q, want 02-tcp-ip", got)
+	}
+
+	m = tuitest.Send(newEnhancedModel(), tuitest.Key("q"))
+	tuitest.AssertGolden(t, "welcome_quit", m.View())
+}
@@ -0,0 +1,158 @@
+// Package tuitest drives Bubble Tea models without a terminal and compares
+// their views against golden files in the calling package's testdata.
+//
+// Regenerate golden files after an intentional layout change with:
+//
+//	NETLAB_UPDATE_GOLDEN=1 go test ./...
+//
+// or pass -update when testing a single package that has golden files.
+package tuitest
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files with the current output")
+
+// updateEnv rewrites golden files like -update, but can be set for
+// ./... where packages without golden files don't define the flag
+const updateEnv = "NETLAB_UPDATE_GOLDEN"
+
+// maxDiffLines caps how many differing lines a failure prints
+const maxDiffLines = 20
+
+func init() {
+	// Render without colours or terminal queries so snapshots are identical
+	// on every machine and in CI
+	lipgloss.SetColorProfile(termenv.Ascii)
+	lipgloss.SetHasDarkBackground(true)
+}
+
+// keyTypes maps key names, as returned by tea.KeyMsg.String, to key types
+var keyTypes = map[string]tea.KeyType{
+	"enter":     tea.KeyEnter,
+	"esc":       tea.KeyEsc,
+	"tab":       tea.KeyTab,
+	"shift+tab": tea.KeyShiftTab,
+	"backspace": tea.KeyBackspace,
+	"up":        tea.KeyUp,
+	"down":      tea.KeyDown,
+	"left":      tea.KeyLeft,
+	"right":     tea.KeyRight,
+	"pgup":      tea.KeyPgUp,
+	"pgdown":    tea.KeyPgDown,
+	"home":      tea.KeyHome,
+	"end":       tea.KeyEnd,
+	"ctrl+c":    tea.KeyCtrlC,
+	"ctrl+s":    tea.KeyCtrlS,
+}
+
+// Key builds the message for a key name such as "down", "enter" or "q"
+func Key(name string) tea.KeyMsg {
+	if name == " " {
+		// Bubble Tea sends space with its rune, which text inputs insert
+		return tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(name)}
+	}
+	if t, ok := keyTypes[name]; ok {
+		return tea.KeyMsg{Type: t}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(name)}
+}
+
+// Keys builds one message per key name
+func Keys(names ...string) []tea.Msg {
+	msgs := make([]tea.Msg, len(names))
+	for i, name := range names {
+		msgs[i] = Key(name)
+	}
+	return msgs
+}
+
+// Resize builds a window size message
+func Resize(width, height int) tea.WindowSizeMsg {
+	return tea.WindowSizeMsg{Width: width, Height: height}
+}
+
+// Send feeds messages to a model in order and returns the final model.
+// Returned commands are dropped: tests script every message explicitly so
+// timers, spinners and shell commands never run.
+func Send(m tea.Model, msgs ...tea.Msg) tea.Model {
+	for _, msg := range msgs {
+		m, _ = m.Update(msg)
+	}
+	return m
+}
+
+// AssertGolden compares a view with testdata/golden/<name>.golden, or
+// rewrites the file when -update or NETLAB_UPDATE_GOLDEN=1 is set
+func AssertGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", "golden", name+".golden")
+
+	if *update || os.Getenv(updateEnv) == "1" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("missing golden file (run make golden to create it): %v", err)
+	}
+	if string(want) != got {
+		t.Errorf("view differs from %s (run make golden if the change is intended)\n%s", path, diff(string(want), got))
+	}
+}
+
+// AssertView sizes a model, applies msgs and compares the resulting view
+// with the golden file for name
+func AssertView(t *testing.T, name string, m tea.Model, width, height int, msgs ...tea.Msg) tea.Model {
+	t.Helper()
+	m = Send(m, append([]tea.Msg{Resize(width, height)}, msgs...)...)
+	AssertGolden(t, name, m.View())
+	return m
+}
+
+// diff lists the differing lines, marking the wanted and actual versions
+func diff(want, got string) string {
+	wantLines := strings.Split(want, "\n")
+	gotLines := strings.Split(got, "\n")
+
+	var b strings.Builder
+	shown := 0
+	for i := 0; i < len(wantLines) || i < len(gotLines); i++ {
+		var w, g string
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if w == g {
+			continue
+		}
+		if shown == maxDiffLines {
+			b.WriteString("...\n")
+			break
+		}
+		fmt.Fprintf(&b, "line %d:\n- %q\n+ %q\n", i+1, w, g)
+		shown++
+	}
+	if len(wantLines) != len(gotLines) {
+		fmt.Fprintf(&b, "want %d lines, got %d\n", len(wantLines), len(gotLines))
+	}
+	return b.String()
+}
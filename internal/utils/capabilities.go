@@ -0,0 +1,399 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// sysRoot is prepended to /proc and /sys paths so checks can read fixtures
+var sysRoot = "/"
+
+// Linux capability bits from include/uapi/linux/capability.h
+const (
+	capNetAdmin = 12
+	capNetRaw   = 13
+)
+
+// capabilityCheck verifies the environment can actually do something,
+// beyond a binary being on PATH
+type capabilityCheck struct {
+	name        string
+	description string
+	linuxOnly   bool
+	needs       string                                   // Skip the check when this command isn't installed
+	check       func(ctx context.Context) (bool, string) // Returns ok and a one-line detail
+	remediation map[string]string                        // OS -> how to fix a failed check
+	fixCmd      map[string]string                        // OS -> command 'doctor --fix' can run, if the fix is automatable
+}
+
+var capabilityChecks = []capabilityCheck{
+	{
+		name:        "Docker daemon",
+		description: "Docker daemon is running and answering requests",
+		needs:       "docker",
+		check:       checkDockerDaemon,
+		remediation: map[string]string{
+			"darwin": "Start Docker Desktop: open -a Docker",
+			"linux":  "sudo systemctl start docker",
+		},
+		fixCmd: map[string]string{
+			"darwin": "open -a Docker",
+			"linux":  "sudo systemctl start docker",
+		},
+	},
+	{
+		name:        "Docker socket",
+		description: "Docker socket is reachable without sudo",
+		needs:       "docker",
+		check:       checkDockerSocket,
+		remediation: map[string]string{
+			"darwin": "Start Docker Desktop, or set DOCKER_HOST to your daemon's socket",
+			"linux":  "sudo usermod -aG docker $USER, then log out and back in (or run 'newgrp docker')",
+		},
+		fixCmd: map[string]string{
+			"linux": "sudo usermod -aG docker $USER",
+		},
+	},
+	{
+		name:        "Packet capture privileges",
+		description: "CAP_NET_ADMIN and CAP_NET_RAW for capture and netns labs",
+		linuxOnly:   true,
+		check:       checkNetCapabilities,
+		remediation: map[string]string{
+			"linux": "Run the lab with sudo, or grant tcpdump the capabilities: sudo setcap cap_net_raw,cap_net_admin=eip $(command -v tcpdump)",
+		},
+	},
+	{
+		name:        "IP forwarding",
+		description: "net.ipv4.ip_forward is enabled for routing labs",
+		linuxOnly:   true,
+		check:       checkIPForward,
+		remediation: map[string]string{
+			"linux": "sudo sysctl -w net.ipv4.ip_forward=1",
+		},
+		fixCmd: map[string]string{
+			"linux": "sudo sysctl -w net.ipv4.ip_forward=1",
+		},
+	},
+	{
+		name:        "br_netfilter",
+		description: "Bridged traffic is visible to iptables (needed by kube-proxy)",
+		linuxOnly:   true,
+		check:       checkBrNetfilter,
+		remediation: map[string]string{
+			"linux": "sudo modprobe br_netfilter",
+		},
+		fixCmd: map[string]string{
+			"linux": "sudo modprobe br_netfilter",
+		},
+	},
+	{
+		name:        "cgroup v2",
+		description: "Unified cgroup hierarchy used by current kind node images",
+		linuxOnly:   true,
+		check:       checkCgroupV2,
+		remediation: map[string]string{
+			"linux": "Boot with systemd.unified_cgroup_hierarchy=1 on the kernel command line",
+		},
+	},
+}
+
+// ModuleCapabilities lists the runtime capabilities each module relies on.
+// Docker checks are required; the rest only warn because the lab scripts
+// can fall back to sudo.
+var ModuleCapabilities = map[string][]string{
+	"01-osi-model":      {"Docker daemon", "Docker socket", "Packet capture privileges"},
+	"02-tcp-ip":         {"Packet capture privileges"},
+	"04-routing":        {"Packet capture privileges", "IP forwarding"},
+	"05-k8s-networking": {"Docker daemon", "Docker socket", "br_netfilter", "cgroup v2"},
+	"06-cni":            {"Docker daemon", "Docker socket", "br_netfilter", "cgroup v2"},
+	"07-service-mesh":   {"Docker daemon", "Docker socket", "cgroup v2"},
+}
+
+// requiredCapabilities fail a module check; other capabilities only warn
+var requiredCapabilities = map[string]bool{
+	"Docker daemon": true,
+	"Docker socket": true,
+}
+
+// CheckCapabilities runs every runtime capability check that applies to this
+// machine, concurrently. None of them are required outside of a module.
+func CheckCapabilities(ctx context.Context) []DependencyStatus {
+	return runCapabilityChecks(ctx, capabilityChecksFor(""))
+}
+
+// CheckModuleCapabilities runs the capability checks a module relies on and
+// reports whether all of its required ones passed
+func CheckModuleCapabilities(ctx context.Context, moduleID string) ([]DependencyStatus, bool) {
+	results := runCapabilityChecks(ctx, capabilityChecksFor(moduleID))
+	allGood := true
+	for i := range results {
+		results[i].Required = requiredCapabilities[results[i].Name]
+		if results[i].Required && results[i].Status != "ok" {
+			allGood = false
+		}
+	}
+
+	return results, allGood
+}
+
+// capabilityChecksFor returns the checks that apply to this machine, either
+// the ones a module relies on or, without a module, all of them
+func capabilityChecksFor(moduleID string) []capabilityCheck {
+	var checks []capabilityCheck
+	if moduleID == "" {
+		for _, c := range capabilityChecks {
+			if capabilityApplies(c) {
+				checks = append(checks, c)
+			}
+		}
+		return checks
+	}
+
+	for _, name := range ModuleCapabilities[moduleID] {
+		for _, c := range capabilityChecks {
+			if c.name == name && capabilityApplies(c) {
+				checks = append(checks, c)
+			}
+		}
+	}
+	return checks
+}
+
+// runCapabilityChecks runs checks concurrently, keeping their order
+func runCapabilityChecks(ctx context.Context, checks []capabilityCheck) []DependencyStatus {
+	results := make([]DependencyStatus, len(checks))
+	for r := range runChecks(ctx, len(checks), func(ctx context.Context, i int) DependencyStatus {
+		return runCapabilityCheck(ctx, checks[i])
+	}) {
+		results[r.Index] = r.Status
+	}
+	return results
+}
+
+// capabilityApplies reports whether a check is meaningful on this machine
+func capabilityApplies(c capabilityCheck) bool {
+	if c.linuxOnly && runtime.GOOS != "linux" {
+		return false
+	}
+	if c.needs != "" {
+		if _, err := executor.LookPath(c.needs); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// runCapabilityCheck runs one check, attaching remediation when it fails
+func runCapabilityCheck(ctx context.Context, c capabilityCheck) DependencyStatus {
+	ok, detail := c.check(ctx)
+	result := DependencyStatus{
+		Name:   c.name,
+		Status: "ok",
+		Output: detail,
+	}
+	if !ok {
+		result.Status = "error"
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			result.Status = "timeout"
+			result.Output = fmt.Sprintf("no response after %s", checkTimeout)
+		}
+		result.Remediation = lookupCommand(c.remediation, DetectPlatform())
+		result.FixCmd = lookupCommand(c.fixCmd, DetectPlatform())
+	}
+	return result
+}
+
+func checkDockerDaemon(ctx context.Context) (bool, string) {
+	var output bytes.Buffer
+	err := executor.Run(ctx, &output, &output, "docker", "info", "--format", "{{.ServerVersion}}")
+	text := strings.TrimSpace(output.String())
+	if err != nil {
+		if strings.Contains(strings.ToLower(text), "permission denied") {
+			return false, "permission denied talking to the daemon"
+		}
+		return false, "daemon is not answering"
+	}
+	return true, fmt.Sprintf("server version %s", text)
+}
+
+func checkDockerSocket(ctx context.Context) (bool, string) {
+	path := dockerSocketPath()
+	if path == "" {
+		return true, "DOCKER_HOST is not a unix socket, skipped"
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		return false, fmt.Sprintf("socket not found at %s", path)
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "unix", path)
+	if err != nil {
+		if errors.Is(err, syscall.EACCES) || errors.Is(err, os.ErrPermission) {
+			return false, fmt.Sprintf("permission denied on %s", path)
+		}
+		return false, fmt.Sprintf("cannot connect to %s: %v", path, err)
+	}
+	conn.Close()
+	return true, path
+}
+
+// dockerSocketPath returns the unix socket the docker CLI would use, or ""
+// when DOCKER_HOST points somewhere else
+func dockerSocketPath() string {
+	if host := os.Getenv("DOCKER_HOST"); host != "" {
+		if strings.HasPrefix(host, "unix://") {
+			return strings.TrimPrefix(host, "unix://")
+		}
+		return ""
+	}
+
+	if runtime.GOOS == "darwin" {
+		if home, err := os.UserHomeDir(); err == nil {
+			desktop := filepath.Join(home, ".docker", "run", "docker.sock")
+			if _, err := os.Stat(desktop); err == nil {
+				return desktop
+			}
+		}
+	}
+	return "/var/run/docker.sock"
+}
+
+// checkNetCapabilities passes when NetLab itself runs with the capabilities,
+// as under sudo, or when tcpdump was granted them with setcap. Those are the
+// two fixes the remediation offers.
+func checkNetCapabilities(ctx context.Context) (bool, string) {
+	reason := processNetCapabilities()
+	if reason == "" {
+		return true, "CAP_NET_ADMIN, CAP_NET_RAW"
+	}
+
+	ok, detail := tcpdumpNetCapabilities(ctx)
+	if ok {
+		return true, detail
+	}
+	return false, reason + "; " + detail
+}
+
+// processNetCapabilities returns why this process lacks CAP_NET_ADMIN or
+// CAP_NET_RAW, or "" if it has both
+func processNetCapabilities() string {
+	data, err := os.ReadFile(filepath.Join(sysRoot, "proc", "self", "status"))
+	if err != nil {
+		return fmt.Sprintf("cannot read process status: %v", err)
+	}
+
+	var capEff uint64
+	found := false
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, "CapEff:") {
+			capEff, err = strconv.ParseUint(strings.TrimSpace(strings.TrimPrefix(line, "CapEff:")), 16, 64)
+			found = err == nil
+			break
+		}
+	}
+	if !found {
+		return "no CapEff line in process status"
+	}
+
+	var missing []string
+	if capEff&(1<<capNetAdmin) == 0 {
+		missing = append(missing, "CAP_NET_ADMIN")
+	}
+	if capEff&(1<<capNetRaw) == 0 {
+		missing = append(missing, "CAP_NET_RAW")
+	}
+	if len(missing) > 0 {
+		return "missing " + strings.Join(missing, ", ")
+	}
+	return ""
+}
+
+// tcpdumpNetCapabilities reports whether tcpdump's file capabilities grant
+// cap_net_admin and cap_net_raw when it runs
+func tcpdumpNetCapabilities(ctx context.Context) (bool, string) {
+	path, err := executor.LookPath("tcpdump")
+	if err != nil {
+		return false, "tcpdump is not installed"
+	}
+	var output bytes.Buffer
+	if err := executor.Run(ctx, &output, nil, "getcap", path); err != nil {
+		return false, "getcap is not available to check tcpdump"
+	}
+
+	granted := parseGetcap(output.String())
+	if granted["cap_net_admin"] && granted["cap_net_raw"] {
+		return true, "tcpdump has cap_net_admin, cap_net_raw"
+	}
+	return false, "tcpdump has no cap_net_admin and cap_net_raw file capabilities"
+}
+
+// parseGetcap returns the effective capabilities in getcap output. Both the
+// current "path caps=eip" and the older "path = caps+eip" forms are read; a
+// clause without names, like "=ep", grants every capability.
+func parseGetcap(output string) map[string]bool {
+	granted := map[string]bool{}
+	fields := strings.Fields(output)
+	if len(fields) < 2 {
+		return granted
+	}
+	for _, clause := range fields[1:] {
+		i := strings.IndexAny(clause, "=+-")
+		if i < 0 || !strings.Contains(clause[i+1:], "e") || clause[i] == '-' {
+			continue
+		}
+		if i == 0 {
+			granted["cap_net_admin"], granted["cap_net_raw"] = true, true
+			continue
+		}
+		for _, name := range strings.Split(clause[:i], ",") {
+			granted[strings.ToLower(name)] = true
+		}
+	}
+	return granted
+}
+
+func checkIPForward(context.Context) (bool, string) {
+	data, err := os.ReadFile(filepath.Join(sysRoot, "proc", "sys", "net", "ipv4", "ip_forward"))
+	if err != nil {
+		return false, fmt.Sprintf("cannot read ip_forward: %v", err)
+	}
+	if strings.TrimSpace(string(data)) != "1" {
+		return false, "disabled"
+	}
+	return true, "enabled"
+}
+
+func checkBrNetfilter(context.Context) (bool, string) {
+	// The sysctl directory only exists once the module is loaded (or built in)
+	if _, err := os.Stat(filepath.Join(sysRoot, "proc", "sys", "net", "bridge", "bridge-nf-call-iptables")); err == nil {
+		return true, "loaded"
+	}
+
+	data, err := os.ReadFile(filepath.Join(sysRoot, "proc", "modules"))
+	if err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			if strings.HasPrefix(line, "br_netfilter ") {
+				return true, "loaded"
+			}
+		}
+	}
+	return false, "module not loaded"
+}
+
+func checkCgroupV2(context.Context) (bool, string) {
+	if _, err := os.Stat(filepath.Join(sysRoot, "sys", "fs", "cgroup", "cgroup.controllers")); err != nil {
+		return false, "cgroup v1 (or hybrid) hierarchy detected"
+	}
+	return true, "unified hierarchy"
+}
@@ -0,0 +1,133 @@
+package utils
+
+import (
+	"context"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// useSysRoot points the /proc and /sys checks at a fixture tree
+func useSysRoot(t *testing.T, fixture string) {
+	t.Helper()
+	previous := sysRoot
+	sysRoot = filepath.Join("testdata", "sysroot", fixture)
+	t.Cleanup(func() { sysRoot = previous })
+}
+
+func TestCapabilityChecks(t *testing.T) {
+	tests := []struct {
+		fixture string
+		name    string
+		check   func(context.Context) (bool, string)
+		ok      bool
+		detail  string
+	}{
+		{"capable", "capabilities", checkNetCapabilities, true, "CAP_NET_ADMIN, CAP_NET_RAW"},
+		{"restricted", "capabilities", checkNetCapabilities, false, "missing CAP_NET_ADMIN; tcpdump is not installed"},
+		{"module-only", "capabilities", checkNetCapabilities, false, "no CapEff line in process status; tcpdump is not installed"},
+		{"empty", "capabilities", checkNetCapabilities, false, ""},
+
+		{"capable", "ip_forward", checkIPForward, true, "enabled"},
+		{"restricted", "ip_forward", checkIPForward, false, "disabled"},
+		{"empty", "ip_forward", checkIPForward, false, ""},
+
+		{"capable", "br_netfilter", checkBrNetfilter, true, "loaded"},     // The bridge sysctls exist
+		{"module-only", "br_netfilter", checkBrNetfilter, true, "loaded"}, // Listed in /proc/modules
+		{"restricted", "br_netfilter", checkBrNetfilter, false, "module not loaded"},
+		{"empty", "br_netfilter", checkBrNetfilter, false, "module not loaded"},
+
+		{"capable", "cgroup", checkCgroupV2, true, "unified hierarchy"},
+		{"restricted", "cgroup", checkCgroupV2, false, "cgroup v1 (or hybrid) hierarchy detected"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name+"/"+tt.fixture, func(t *testing.T) {
+			useSysRoot(t, tt.fixture)
+			useExecutor(t, NewScriptedExecutor(nil))
+			ok, detail := tt.check(context.Background())
+			if ok != tt.ok {
+				t.Errorf("ok = %v, want %v (%q)", ok, tt.ok, detail)
+			}
+			if tt.detail != "" && detail != tt.detail {
+				t.Errorf("detail = %q, want %q", detail, tt.detail)
+			}
+			if detail == "" {
+				t.Error("no detail")
+			}
+		})
+	}
+}
+
+func TestNetCapabilitiesFromTcpdump(t *testing.T) {
+	tests := []struct {
+		name   string
+		getcap ScriptedResult
+		ok     bool
+		detail string
+	}{
+		{"setcap", ScriptedResult{Stdout: "/usr/bin/tcpdump cap_net_admin,cap_net_raw=eip\n"}, true, "tcpdump has cap_net_admin, cap_net_raw"},
+		{"old getcap", ScriptedResult{Stdout: "/usr/bin/tcpdump = cap_net_raw,cap_net_admin+eip\n"}, true, "tcpdump has cap_net_admin, cap_net_raw"},
+		{"every capability", ScriptedResult{Stdout: "/usr/bin/tcpdump =ep\n"}, true, "tcpdump has cap_net_admin, cap_net_raw"},
+		{"raw only", ScriptedResult{Stdout: "/usr/bin/tcpdump cap_net_raw=eip\n"}, false, "missing CAP_NET_ADMIN; tcpdump has no cap_net_admin and cap_net_raw file capabilities"},
+		{"not effective", ScriptedResult{Stdout: "/usr/bin/tcpdump cap_net_admin,cap_net_raw=p\n"}, false, "missing CAP_NET_ADMIN; tcpdump has no cap_net_admin and cap_net_raw file capabilities"},
+		{"none", ScriptedResult{}, false, "missing CAP_NET_ADMIN; tcpdump has no cap_net_admin and cap_net_raw file capabilities"},
+		{"no getcap", ScriptedResult{NotFound: true}, false, "missing CAP_NET_ADMIN; getcap is not available to check tcpdump"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useSysRoot(t, "restricted")
+			useExecutor(t, NewScriptedExecutor(map[string]ScriptedResult{
+				"tcpdump --version":       {},
+				"getcap /usr/bin/tcpdump": tt.getcap,
+			}))
+			ok, detail := checkNetCapabilities(context.Background())
+			if ok != tt.ok || detail != tt.detail {
+				t.Errorf("checkNetCapabilities() = %v, %q, want %v, %q", ok, detail, tt.ok, tt.detail)
+			}
+		})
+	}
+}
+
+func TestCapabilityApplies(t *testing.T) {
+	useExecutor(t, NewScriptedExecutor(map[string]ScriptedResult{
+		"docker info --format {{.ServerVersion}}": {Stdout: "24.0.7\n"},
+	}))
+	if !capabilityApplies(capabilityCheck{needs: "docker"}) {
+		t.Error("a check needing docker doesn't apply with docker installed")
+	}
+	if capabilityApplies(capabilityCheck{needs: "podman"}) {
+		t.Error("a check needing podman applies without podman installed")
+	}
+	if got := capabilityApplies(capabilityCheck{linuxOnly: true}); got != (runtime.GOOS == "linux") {
+		t.Errorf("a Linux-only check applies = %v on %s", got, runtime.GOOS)
+	}
+}
+
+func TestCheckModuleCapabilitiesRequired(t *testing.T) {
+	shortTimeout(t)
+	useSysRoot(t, "restricted")
+	useExecutor(t, NewScriptedExecutor(map[string]ScriptedResult{
+		"docker info --format {{.ServerVersion}}": {Stderr: "Cannot connect to the Docker daemon", ExitCode: 1},
+	}))
+
+	results, ok := CheckModuleCapabilities(context.Background(), "01-osi-model")
+	if ok {
+		t.Error("module passed with the Docker daemon down")
+	}
+	byName := map[string]DependencyStatus{}
+	for _, r := range results {
+		byName[r.Name] = r
+	}
+	daemon := byName["Docker daemon"]
+	if daemon.Status != "error" || !daemon.Required || daemon.Output != "daemon is not answering" || daemon.Remediation == "" {
+		t.Errorf("Docker daemon = %+v, want a required error with remediation", daemon)
+	}
+	if runtime.GOOS == "linux" {
+		capture := byName["Packet capture privileges"]
+		if capture.Status != "error" || capture.Required {
+			t.Errorf("Packet capture privileges = %+v, want an optional error", capture)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// ConfigDir returns the NetLab configuration directory (~/.config/netlab).
+// XDG_CONFIG_HOME is honoured when set.
+func ConfigDir() (string, error) {
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		return filepath.Join(xdg, "netlab"), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "netlab"), nil
+}
@@ -1,9 +1,16 @@
 package utils
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"os/exec"
+	"io"
+	"regexp"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
 )
@@ -15,13 +22,23 @@ var (
 	infoStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("12")).Bold(true) // Blue
 )
 
+// Tools that Debian, Ubuntu, Fedora and some other distributions don't
+// package are installed from the upstream release binaries instead
+const (
+	kubectlUpstreamInstall = `curl -LO "https://dl.k8s.io/release/$(curl -L -s https://dl.k8s.io/release/stable.txt)/bin/linux/amd64/kubectl" && sudo install -m 0755 kubectl /usr/local/bin/kubectl && rm kubectl`
+	kindUpstreamInstall    = "curl -Lo ./kind https://kind.sigs.k8s.io/dl/v0.20.0/kind-linux-amd64 && chmod +x ./kind && sudo mv ./kind /usr/local/bin/kind"
+)
+
 type diagnostic struct {
-	name        string
-	command     string
-	args        []string
-	required    bool
-	description string
-	installCmd  map[string]string // OS -> install command
+	name           string
+	command        string
+	args           []string
+	required       bool
+	description    string
+	installCmd     map[string]string // Package manager or OS -> install command
+	versionPattern *regexp.Regexp    // First submatch is the version; defaults to versionPattern
+	minVersion     string            // Version constraint, e.g. ">=0.20.0"
+	upgradeCmd     map[string]string // Package manager or OS -> upgrade command for outdated installs
 }
 
 var diagnostics = []diagnostic{
@@ -32,8 +49,26 @@ var diagnostics = []diagnostic{
 		required:    true,
 		description: "Go programming language (required for building NetLab)",
 		installCmd: map[string]string{
-			"darwin": "brew install go",
-			"linux":  "Visit https://golang.org/doc/install",
+			"darwin":  "brew install go",
+			PkgApt:    "sudo apt-get install -y golang-go",
+			PkgDnf:    "sudo dnf install -y golang",
+			PkgPacman: "sudo pacman -S --needed go",
+			PkgApk:    "sudo apk add go",
+			PkgZypper: "sudo zypper install -y go",
+			PkgNix:    "nix-env -iA nixos.go",
+			"linux":   "Visit https://golang.org/doc/install",
+		},
+		versionPattern: regexp.MustCompile(`go(\d+\.\d+(?:\.\d+)?)`),
+		minVersion:     ">=1.21",
+		upgradeCmd: map[string]string{
+			"darwin":  "brew upgrade go",
+			PkgApt:    "Visit https://golang.org/doc/install; the golang-go package lags behind Go releases",
+			PkgDnf:    "sudo dnf upgrade -y golang",
+			PkgPacman: "sudo pacman -S go",
+			PkgApk:    "sudo apk upgrade go",
+			PkgZypper: "sudo zypper update -y go",
+			PkgNix:    "nix-env -uA nixos.go",
+			"linux":   "Visit https://golang.org/doc/install",
 		},
 	},
 	{
@@ -43,8 +78,26 @@ var diagnostics = []diagnostic{
 		required:    false,
 		description: "Docker for containerized network experiments",
 		installCmd: map[string]string{
-			"darwin": "brew install --cask docker",
-			"linux":  "curl -fsSL https://get.docker.com | sh",
+			"darwin":  "brew install --cask docker",
+			PkgApt:    "curl -fsSL https://get.docker.com | sh",
+			PkgDnf:    "curl -fsSL https://get.docker.com | sh",
+			PkgPacman: "sudo pacman -S --needed docker && sudo systemctl enable --now docker",
+			PkgApk:    "sudo apk add docker && sudo rc-update add docker && sudo service docker start",
+			PkgZypper: "sudo zypper install -y docker && sudo systemctl enable --now docker",
+			PkgNix:    "Set virtualisation.docker.enable = true; in configuration.nix, then nixos-rebuild switch",
+			"linux":   "curl -fsSL https://get.docker.com | sh",
+		},
+		versionPattern: regexp.MustCompile(`version (\d+\.\d+\.\d+)`),
+		minVersion:     ">=20.10",
+		upgradeCmd: map[string]string{
+			"darwin":  "brew upgrade --cask docker",
+			PkgApt:    "curl -fsSL https://get.docker.com | sh",
+			PkgDnf:    "curl -fsSL https://get.docker.com | sh",
+			PkgPacman: "sudo pacman -S docker",
+			PkgApk:    "sudo apk upgrade docker",
+			PkgZypper: "sudo zypper update -y docker",
+			PkgNix:    "sudo nixos-rebuild switch --upgrade",
+			"linux":   "curl -fsSL https://get.docker.com | sh",
 		},
 	},
 	{
@@ -54,8 +107,26 @@ var diagnostics = []diagnostic{
 		required:    false,
 		description: "Kubernetes CLI for cluster networking modules",
 		installCmd: map[string]string{
-			"darwin": "brew install kubectl",
-			"linux":  "curl -LO \"https://dl.k8s.io/release/$(curl -L -s https://dl.k8s.io/release/stable.txt)/bin/linux/amd64/kubectl\"",
+			"darwin":  "brew install kubectl",
+			PkgApt:    kubectlUpstreamInstall, // Only in the pkgs.k8s.io repository
+			PkgDnf:    kubectlUpstreamInstall, // Only in the pkgs.k8s.io repository
+			PkgPacman: "sudo pacman -S --needed kubectl",
+			PkgApk:    "sudo apk add kubectl",
+			PkgZypper: "sudo zypper install -y kubernetes-client",
+			PkgNix:    "nix-env -iA nixos.kubectl",
+			"linux":   kubectlUpstreamInstall,
+		},
+		versionPattern: regexp.MustCompile(`Client Version: v?(\d+\.\d+\.\d+)`),
+		minVersion:     ">=1.26",
+		upgradeCmd: map[string]string{
+			"darwin":  "brew upgrade kubectl",
+			PkgApt:    kubectlUpstreamInstall,
+			PkgDnf:    kubectlUpstreamInstall,
+			PkgPacman: "sudo pacman -S kubectl",
+			PkgApk:    "sudo apk upgrade kubectl",
+			PkgZypper: "sudo zypper update -y kubernetes-client",
+			PkgNix:    "nix-env -uA nixos.kubectl",
+			"linux":   kubectlUpstreamInstall,
 		},
 	},
 	{
@@ -65,8 +136,26 @@ var diagnostics = []diagnostic{
 		required:    false,
 		description: "Kubernetes in Docker for local cluster setup",
 		installCmd: map[string]string{
-			"darwin": "brew install kind",
-			"linux":  "curl -Lo ./kind https://kind.sigs.k8s.io/dl/v0.20.0/kind-linux-amd64 && chmod +x ./kind && sudo mv ./kind /usr/local/bin/kind",
+			"darwin":  "brew install kind",
+			PkgApt:    kindUpstreamInstall, // Not packaged by these distributions
+			PkgDnf:    kindUpstreamInstall,
+			PkgPacman: kindUpstreamInstall,
+			PkgApk:    kindUpstreamInstall,
+			PkgZypper: kindUpstreamInstall,
+			PkgNix:    "nix-env -iA nixos.kind",
+			"linux":   kindUpstreamInstall,
+		},
+		versionPattern: regexp.MustCompile(`kind v(\d+\.\d+\.\d+)`),
+		minVersion:     ">=0.12.0", // v0.11 and older lack flags the labs rely on
+		upgradeCmd: map[string]string{
+			"darwin":  "brew upgrade kind",
+			PkgApt:    kindUpstreamInstall,
+			PkgDnf:    kindUpstreamInstall,
+			PkgPacman: kindUpstreamInstall,
+			PkgApk:    kindUpstreamInstall,
+			PkgZypper: kindUpstreamInstall,
+			PkgNix:    "nix-env -uA nixos.kind",
+			"linux":   kindUpstreamInstall,
 		},
 	},
 	{
@@ -76,8 +165,13 @@ var diagnostics = []diagnostic{
 		required:    false,
 		description: "Network packet analyzer for traffic inspection",
 		installCmd: map[string]string{
-			"darwin": "brew install tcpdump",
-			"linux":  "sudo apt-get install tcpdump",
+			"darwin":  "brew install tcpdump",
+			PkgApt:    "sudo apt-get install -y tcpdump",
+			PkgDnf:    "sudo dnf install -y tcpdump",
+			PkgPacman: "sudo pacman -S --needed tcpdump",
+			PkgApk:    "sudo apk add tcpdump",
+			PkgZypper: "sudo zypper install -y tcpdump",
+			PkgNix:    "nix-env -iA nixos.tcpdump",
 		},
 	},
 	{
@@ -87,8 +181,13 @@ var diagnostics = []diagnostic{
 		required:    false,
 		description: "Wireshark command-line packet analyzer for OSI lab",
 		installCmd: map[string]string{
-			"darwin": "brew install wireshark",
-			"linux":  "sudo apt-get install tshark",
+			"darwin":  "brew install wireshark",
+			PkgApt:    "sudo apt-get install -y tshark",
+			PkgDnf:    "sudo dnf install -y wireshark-cli",
+			PkgPacman: "sudo pacman -S --needed wireshark-cli",
+			PkgApk:    "sudo apk add tshark",
+			PkgZypper: "sudo zypper install -y wireshark",
+			PkgNix:    "nix-env -iA nixos.wireshark-cli",
 		},
 	},
 	{
@@ -98,7 +197,12 @@ var diagnostics = []diagnostic{
 		required:    false,
 		description: "Network configuration tool (Linux)",
 		installCmd: map[string]string{
-			"linux": "sudo apt-get install iproute2",
+			PkgApt:    "sudo apt-get install -y iproute2",
+			PkgDnf:    "sudo dnf install -y iproute",
+			PkgPacman: "sudo pacman -S --needed iproute2",
+			PkgApk:    "sudo apk add iproute2",
+			PkgZypper: "sudo zypper install -y iproute2",
+			PkgNix:    "nix-env -iA nixos.iproute2",
 		},
 	},
 	{
@@ -108,11 +212,21 @@ var diagnostics = []diagnostic{
 		required:    false,
 		description: "Firewall administration tool (Linux)",
 		installCmd: map[string]string{
-			"linux": "sudo apt-get install iptables",
+			PkgApt:    "sudo apt-get install -y iptables",
+			PkgDnf:    "sudo dnf install -y iptables",
+			PkgPacman: "sudo pacman -S --needed iptables",
+			PkgApk:    "sudo apk add iptables",
+			PkgZypper: "sudo zypper install -y iptables",
+			PkgNix:    "nix-env -iA nixos.iptables",
 		},
 	},
 }
 
+// ModuleVersionConstraints tightens a tool's minVersion for a single module
+var ModuleVersionConstraints = map[string]map[string]string{
+	"01-osi-model": {"kind": ">=0.20.0"}, // Cluster config in scripts/k8s_lab.sh
+}
+
 // ModuleDependencies defines what each module actually needs
 var ModuleDependencies = map[string][]string{
 	"01-osi-model":      {"Docker", "kubectl", "kind", "tcpdump", "tshark"},
@@ -124,52 +238,260 @@ var ModuleDependencies = map[string][]string{
 	"07-service-mesh":   {"Docker", "kubectl", "kind"},
 }
 
+// Exit codes returned by 'netlab doctor'
+const (
+	ExitOK              = 0
+	ExitMissingRequired = 1
+	ExitInvalidContent  = 2 // A content pack failed validation
+)
+
+// checkTimeout bounds each individual check, so a wedged Docker daemon or an
+// unreachable cluster can't hang the whole run
+var checkTimeout = 5 * time.Second
+
+// versionPattern extracts the first dotted version number from tool output
+var versionPattern = regexp.MustCompile(`v?(\d+\.\d+(?:\.\d+)?)`)
+
 type DependencyStatus struct {
-	Name       string
-	Status     string // "ok", "missing", "error"
-	Output     string
-	Required   bool
-	InstallCmd string
+	Name        string `json:"name"`
+	Status      string `json:"status"` // "ok", "missing", "error", "outdated", "timeout"
+	Output      string `json:"output,omitempty"`
+	Version     string `json:"version,omitempty"`
+	MinVersion  string `json:"min_version,omitempty"`
+	Required    bool   `json:"required"`
+	InstallCmd  string `json:"install_cmd,omitempty"`
+	UpgradeCmd  string `json:"upgrade_cmd,omitempty"`
+	Remediation string `json:"remediation,omitempty"` // How to fix a failed capability check
+	FixCmd      string `json:"fix_cmd,omitempty"`     // Command 'doctor --fix' can run for a failed capability check
+}
+
+// DiagnosticsReport is the result of a doctor run, shared by the text and JSON output
+type DiagnosticsReport struct {
+	OS             string              `json:"os"`
+	Distro         string              `json:"distro,omitempty"`
+	PackageManager string              `json:"package_manager,omitempty"`
+	Module         string              `json:"module,omitempty"`
+	OK             bool                `json:"ok"`
+	Dependencies   []DependencyStatus  `json:"dependencies"`
+	Capabilities   []DependencyStatus  `json:"capabilities,omitempty"`
+	LabResources   []LabResource       `json:"lab_resources,omitempty"`
+	LabStateError  string              `json:"lab_state_error,omitempty"`
+	ContentPacks   []ContentPackStatus `json:"content_packs,omitempty"`
+}
+
+// ExitCode returns the process exit code for the report
+func (r DiagnosticsReport) ExitCode() int {
+	if !r.OK {
+		return ExitMissingRequired
+	}
+	for _, pack := range r.ContentPacks {
+		if len(pack.Errors) > 0 {
+			return ExitInvalidContent
+		}
+	}
+	return ExitOK
+}
+
+// BuildDiagnosticsReport checks every known tool, or only the tools a module
+// needs when moduleID is set
+func BuildDiagnosticsReport(ctx context.Context, moduleID string) (DiagnosticsReport, error) {
+	platform := DetectPlatform()
+	report := DiagnosticsReport{
+		OS:             platform.OS,
+		Distro:         platform.Distro,
+		PackageManager: platform.PackageManager,
+		Module:         moduleID,
+	}
+
+	if moduleID != "" {
+		if _, exists := ModuleDependencies[moduleID]; !exists {
+			return report, fmt.Errorf("unknown module: %s", moduleID)
+		}
+		var capsOK bool
+		report.Dependencies, report.OK = CheckModuleDependencies(ctx, moduleID)
+		report.Capabilities, capsOK = CheckModuleCapabilities(ctx, moduleID)
+		report.OK = report.OK && capsOK
+	} else {
+		report.Dependencies, report.OK = CheckAllDependencies(ctx)
+		report.Capabilities = CheckCapabilities(ctx)
+	}
+
+	orphans, err := FindOrphanedLabResources()
+	if err != nil {
+		report.LabStateError = err.Error()
+	}
+	report.LabResources = orphans
+	report.ContentPacks = ContentPacks()
+
+	return report, nil
+}
+
+// WriteDiagnosticsJSON writes the report as indented JSON
+func WriteDiagnosticsJSON(w io.Writer, report DiagnosticsReport) error {
+	if report.Dependencies == nil {
+		report.Dependencies = []DependencyStatus{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+// RecheckDiagnostics re-runs the named checks of a report and returns the
+// updated copy. Capability checks that need a re-checked tool run too, since
+// installing Docker is what makes the daemon checks apply.
+func RecheckDiagnostics(ctx context.Context, report DiagnosticsReport, names []string) DiagnosticsReport {
+	recheck := map[string]bool{}
+	for _, name := range names {
+		recheck[name] = true
+	}
+
+	var checks []func(ctx context.Context) DependencyStatus
+	rechecked := map[string]bool{} // Commands of the re-checked tools
+	for _, dep := range report.Dependencies {
+		diag, ok := findDiagnostic(dep.Name)
+		if !ok || !recheck[dep.Name] {
+			continue
+		}
+		rechecked[diag.command] = true
+		dep := dep
+		checks = append(checks, func(ctx context.Context) DependencyStatus {
+			result := checkDiagnostic(ctx, diag, dep.MinVersion)
+			result.Required = dep.Required
+			return result
+		})
+	}
+
+	previous := map[string]DependencyStatus{}
+	for _, c := range report.Capabilities {
+		previous[c.Name] = c
+	}
+	var capabilities []capabilityCheck
+	for _, c := range capabilityChecksFor(report.Module) {
+		if _, checked := previous[c.name]; checked || rechecked[c.needs] {
+			capabilities = append(capabilities, c)
+		}
+		if recheck[c.name] || rechecked[c.needs] {
+			c := c
+			checks = append(checks, func(ctx context.Context) DependencyStatus {
+				result := runCapabilityCheck(ctx, c)
+				result.Required = report.Module != "" && requiredCapabilities[c.name]
+				return result
+			})
+		}
+	}
+
+	updated := map[string]DependencyStatus{}
+	for r := range runChecks(ctx, len(checks), func(ctx context.Context, i int) DependencyStatus {
+		return checks[i](ctx)
+	}) {
+		updated[r.Status.Name] = r.Status
+	}
+
+	report.Dependencies = append([]DependencyStatus(nil), report.Dependencies...)
+	for i, dep := range report.Dependencies {
+		if result, ok := updated[dep.Name]; ok {
+			report.Dependencies[i] = result
+		}
+	}
+	report.Capabilities = nil
+	for _, c := range capabilities {
+		if result, ok := updated[c.name]; ok {
+			report.Capabilities = append(report.Capabilities, result)
+		} else {
+			report.Capabilities = append(report.Capabilities, previous[c.name])
+		}
+	}
+
+	report.OK = true
+	for _, statuses := range [][]DependencyStatus{report.Dependencies, report.Capabilities} {
+		for _, s := range statuses {
+			if s.Required && s.Status != "ok" {
+				report.OK = false
+			}
+		}
+	}
+	return report
+}
+
+// RunDiagnostics prints a coloured diagnostics report and returns it so the
+// caller can pick an exit code
+func RunDiagnostics(ctx context.Context, moduleID string) (DiagnosticsReport, error) {
+	report, err := BuildDiagnosticsReport(ctx, moduleID)
+	if err != nil {
+		return report, err
+	}
+	PrintDiagnostics(report)
+	return report, nil
 }
 
-func RunDiagnostics() {
+// PrintDiagnostics prints a coloured diagnostics report
+func PrintDiagnostics(report DiagnosticsReport) {
 	fmt.Println(infoStyle.Render("🔍 NetLab Environment Diagnostics"))
+	if report.Module != "" {
+		fmt.Printf("Module: %s\n", report.Module)
+	}
 	fmt.Println()
 
-	allGood := true
+	allGood := report.OK
 	warnings := []string{}
 
-	for _, diag := range diagnostics {
-		status, output := checkCommand(diag.command, diag.args...)
-
-		switch status {
+	for _, dep := range report.Dependencies {
+		switch dep.Status {
 		case "ok":
 			fmt.Printf("%s %s: %s\n",
 				checkStyle.Render("✓"),
-				diag.name,
-				strings.TrimSpace(output))
+				dep.Name,
+				strings.TrimSpace(dep.Output))
 		case "missing":
-			if diag.required {
+			if dep.Required {
 				fmt.Printf("%s %s: %s\n",
 					errorStyle.Render("✗"),
-					diag.name,
+					dep.Name,
 					"REQUIRED - Not found")
-				allGood = false
 			} else {
 				fmt.Printf("%s %s: %s\n",
 					warnStyle.Render("⚠"),
-					diag.name,
+					dep.Name,
 					"Optional - Not found")
-				warnings = append(warnings, diag.name)
+				warnings = append(warnings, dep.Name)
+			}
+		case "outdated":
+			label := "Optional - Outdated"
+			style := warnStyle
+			if dep.Required {
+				label = "REQUIRED - Outdated"
+				style = errorStyle
+			} else {
+				warnings = append(warnings, dep.Name)
+			}
+			fmt.Printf("%s %s: %s (found %s, need %s)\n",
+				style.Render("⚠"),
+				dep.Name,
+				label,
+				dep.Version,
+				dep.MinVersion)
+			if dep.UpgradeCmd != "" {
+				fmt.Printf("   Upgrade: %s\n", dep.UpgradeCmd)
 			}
 		case "error":
 			fmt.Printf("%s %s: %s\n",
 				errorStyle.Render("✗"),
-				diag.name,
+				dep.Name,
 				"Error running command")
-			if diag.required {
-				allGood = false
+		case "timeout":
+			label := "Optional - Timed out"
+			style := warnStyle
+			if dep.Required {
+				label = "REQUIRED - Timed out"
+				style = errorStyle
+			} else {
+				warnings = append(warnings, dep.Name)
 			}
+			fmt.Printf("%s %s: %s (%s)\n",
+				style.Render("⏱"),
+				dep.Name,
+				label,
+				dep.Output)
 		}
 	}
 
@@ -180,63 +502,262 @@ func RunDiagnostics() {
 	} else if allGood {
 		fmt.Println(checkStyle.Render("✅ Core requirements met!"))
 		if len(warnings) > 0 {
-			fmt.Println(warnStyle.Render(fmt.Sprintf("⚠️  Optional tools missing: %s", strings.Join(warnings, ", "))))
+			fmt.Println(warnStyle.Render(fmt.Sprintf("⚠️  Optional tools missing or outdated: %s", strings.Join(warnings, ", "))))
 			fmt.Println("   Some advanced modules may have limited functionality.")
 		}
 	} else {
-		fmt.Println(errorStyle.Render("❌ Missing required dependencies!"))
-		fmt.Println("   Please install the missing tools and run 'netlab doctor' again.")
+		fmt.Println(errorStyle.Render("❌ Missing or outdated required dependencies!"))
+		fmt.Println("   Please install or upgrade the tools above and run 'netlab doctor' again.")
 	}
 
+	reportCapabilities(report)
+	reportLabResources(report)
+	reportContentPacks(report)
+
 	fmt.Println()
 	fmt.Println("💡 Run 'scripts/setup.sh' for guided installation help.")
 }
 
+// reportCapabilities prints the runtime capability checks with a fix for each failure
+func reportCapabilities(report DiagnosticsReport) {
+	if len(report.Capabilities) == 0 {
+		return
+	}
+
+	fmt.Println()
+	fmt.Println(infoStyle.Render("⚙️  Runtime Capabilities"))
+	for _, c := range report.Capabilities {
+		if c.Status == "ok" {
+			fmt.Printf("%s %s: %s\n", checkStyle.Render("✓"), c.Name, c.Output)
+			continue
+		}
+
+		mark := warnStyle.Render("⚠")
+		if c.Required {
+			mark = errorStyle.Render("✗")
+		}
+		fmt.Printf("%s %s: %s\n", mark, c.Name, c.Output)
+		if c.Remediation != "" {
+			fmt.Printf("   Fix: %s\n", c.Remediation)
+		}
+	}
+}
+
+// reportLabResources warns about lab clusters and namespaces left running
+func reportLabResources(report DiagnosticsReport) {
+	if report.LabStateError != "" {
+		fmt.Println()
+		fmt.Printf("%s Lab state: %s\n", warnStyle.Render("⚠"), report.LabStateError)
+	}
+	orphans := report.LabResources
+	if len(orphans) == 0 {
+		return
+	}
+
+	fmt.Println()
+	fmt.Println(warnStyle.Render(fmt.Sprintf("⚠️  %d lab resource(s) left running:", len(orphans))))
+	for _, r := range orphans {
+		note := ""
+		if !r.Tracked {
+			note = " (not created by this NetLab install)"
+		} else if r.Module != "" {
+			note = fmt.Sprintf(" (%s, since %s)", r.Module, r.CreatedAt.Format("2006-01-02 15:04"))
+		}
+		fmt.Printf("   • %s %s%s\n", r.Type, r.Name, note)
+	}
+	fmt.Println("   Run 'netlab cleanup' to delete them and free up memory.")
+}
+
+// reportContentPacks lists the content packs found and why any failed to load
+func reportContentPacks(report DiagnosticsReport) {
+	if len(report.ContentPacks) == 0 {
+		return
+	}
+
+	fmt.Println()
+	fmt.Println(infoStyle.Render("📦 Content Packs"))
+	for _, pack := range report.ContentPacks {
+		if len(pack.Errors) == 0 {
+			fmt.Printf("%s %s: %d module(s) from %s\n", checkStyle.Render("✓"), pack.Name, len(pack.Modules), pack.Path)
+			continue
+		}
+		fmt.Printf("%s %s: not loaded, %d problem(s) in %s\n", errorStyle.Render("✗"), pack.Name, len(pack.Errors), pack.Path)
+		for _, e := range pack.Errors {
+			fmt.Printf("   • %s\n", e)
+		}
+	}
+}
+
+// CheckAllDependencies checks every known tool concurrently. Only tools
+// marked required affect the returned allGood flag.
+func CheckAllDependencies(ctx context.Context) ([]DependencyStatus, bool) {
+	results := make([]DependencyStatus, len(diagnostics))
+	for r := range runChecks(ctx, len(diagnostics), func(ctx context.Context, i int) DependencyStatus {
+		result := checkDiagnostic(ctx, diagnostics[i], diagnostics[i].minVersion)
+		result.Required = diagnostics[i].required
+		return result
+	}) {
+		results[r.Index] = r.Status
+	}
+
+	allGood := true
+	for _, result := range results {
+		if result.Required && result.Status != "ok" {
+			allGood = false
+		}
+	}
+	return results, allGood
+}
+
 // CheckModuleDependencies checks dependencies specific to a module
-func CheckModuleDependencies(moduleID string) ([]DependencyStatus, bool) {
-	requiredDeps, exists := ModuleDependencies[moduleID]
-	if !exists {
+func CheckModuleDependencies(ctx context.Context, moduleID string) ([]DependencyStatus, bool) {
+	names := ModuleDependencyNames(moduleID)
+	if len(names) == 0 {
 		return nil, true // No specific requirements
 	}
 
-	var results []DependencyStatus
+	results := make([]DependencyStatus, len(names))
 	allGood := true
+	for r := range StreamModuleDependencies(ctx, moduleID) {
+		results[r.Index] = r.Status
+		if r.Status.Status != "ok" {
+			allGood = false
+		}
+	}
 
-	// Create a map for quick lookup
-	diagMap := make(map[string]diagnostic)
+	return results, allGood
+}
+
+// ModuleDependencyNames returns the known tools a module needs, in check order
+func ModuleDependencyNames(moduleID string) []string {
+	var names []string
+	for _, depName := range ModuleDependencies[moduleID] {
+		if _, exists := findDiagnostic(depName); exists {
+			names = append(names, depName)
+		}
+	}
+	return names
+}
+
+// StreamModuleDependencies starts every check for a module at once and
+// delivers each result as soon as it finishes. Index refers to
+// ModuleDependencyNames. The channel is closed when all checks are done.
+func StreamModuleDependencies(ctx context.Context, moduleID string) <-chan DependencyResult {
+	names := ModuleDependencyNames(moduleID)
+
+	return runChecks(ctx, len(names), func(ctx context.Context, i int) DependencyStatus {
+		diag, _ := findDiagnostic(names[i])
+
+		constraint := diag.minVersion
+		if override, ok := ModuleVersionConstraints[moduleID][diag.name]; ok {
+			constraint = override
+		}
+
+		result := checkDiagnostic(ctx, diag, constraint)
+		result.Required = true // All module deps are required for that module
+		return result
+	})
+}
+
+// DependencyResult is one finished check and its position in the check list
+type DependencyResult struct {
+	Index  int
+	Status DependencyStatus
+}
+
+// runChecks runs n checks concurrently, each bounded by checkTimeout, and
+// streams their results. The channel is buffered so abandoned checks never block.
+func runChecks(ctx context.Context, n int, check func(ctx context.Context, i int) DependencyStatus) <-chan DependencyResult {
+	results := make(chan DependencyResult, n)
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, checkTimeout)
+			defer cancel()
+			results <- DependencyResult{Index: i, Status: check(checkCtx, i)}
+		}(i)
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	return results
+}
+
+// findDiagnostic looks up a diagnostic by name
+func findDiagnostic(name string) (diagnostic, bool) {
 	for _, diag := range diagnostics {
-		diagMap[diag.name] = diag
+		if diag.name == name {
+			return diag, true
+		}
 	}
+	return diagnostic{}, false
+}
 
-	for _, depName := range requiredDeps {
-		if diag, exists := diagMap[depName]; exists {
-			status, output := checkCommand(diag.command, diag.args...)
+// checkDiagnostic runs a single diagnostic, parses its version and checks it
+// against constraint. Missing tools get an install command for this OS and
+// outdated ones an upgrade command.
+func checkDiagnostic(ctx context.Context, diag diagnostic, constraint string) DependencyStatus {
+	status, output := checkCommand(ctx, diag.command, diag.args...)
 
-			installCmd := ""
-			if status == "missing" {
-				// Determine OS and get install command
-				os := getOS()
-				if cmd, hasCmd := diag.installCmd[os]; hasCmd {
-					installCmd = cmd
-				}
-			}
+	result := DependencyStatus{
+		Name:       diag.name,
+		Status:     status,
+		Output:     output,
+		MinVersion: constraint,
+	}
 
-			result := DependencyStatus{
-				Name:       diag.name,
-				Status:     status,
-				Output:     output,
-				Required:   true, // All module deps are required for that module
-				InstallCmd: installCmd,
-			}
-			results = append(results, result)
+	if status == "ok" {
+		result.Version = parseVersion(diag, output)
 
-			if status != "ok" {
-				allGood = false
+		// An unparseable version can't be judged, so it's left as ok
+		if constraint != "" && result.Version != "" {
+			if ok, err := versionSatisfies(result.Version, constraint); err == nil && !ok {
+				result.Status = "outdated"
 			}
 		}
 	}
 
-	return results, allGood
+	platform := DetectPlatform()
+	switch result.Status {
+	case "missing":
+		result.InstallCmd = installCommandFor(diag, platform)
+	case "outdated":
+		result.UpgradeCmd = upgradeCommandFor(diag, platform)
+	}
+
+	return result
+}
+
+// installCommandFor returns how to install a tool on the given platform
+func installCommandFor(diag diagnostic, p Platform) string {
+	return lookupCommand(diag.installCmd, p)
+}
+
+// upgradeCommandFor returns how to upgrade a tool, falling back to reinstalling it
+func upgradeCommandFor(diag diagnostic, p Platform) string {
+	if cmd := lookupCommand(diag.upgradeCmd, p); cmd != "" {
+		return cmd
+	}
+	return installCommandFor(diag, p)
+}
+
+// parseVersion extracts the tool's version from its output, using the
+// diagnostic's own pattern when it has one
+func parseVersion(diag diagnostic, output string) string {
+	pattern := versionPattern
+	if diag.versionPattern != nil {
+		pattern = diag.versionPattern
+	}
+	if match := pattern.FindStringSubmatch(output); match != nil {
+		return match[1]
+	}
+	return ""
 }
 
 // GetInstallationGuide returns formatted installation instructions for missing dependencies
@@ -249,8 +770,7 @@ func GetInstallationGuide(missingDeps []DependencyStatus) string {
 	guide.WriteString(infoStyle.Render("📦 Installation Guide\n"))
 	guide.WriteString("\n")
 
-	os := getOS()
-	guide.WriteString(fmt.Sprintf("Detected OS: %s\n\n", os))
+	guide.WriteString(fmt.Sprintf("Detected OS: %s\n\n", DetectPlatform()))
 
 	for _, dep := range missingDeps {
 		if dep.Status == "missing" && dep.InstallCmd != "" {
@@ -258,17 +778,31 @@ func GetInstallationGuide(missingDeps []DependencyStatus) string {
 				warnStyle.Render("•"), dep.Name))
 			guide.WriteString(fmt.Sprintf("  %s\n\n", dep.InstallCmd))
 		}
+		if dep.Status == "outdated" && dep.UpgradeCmd != "" {
+			guide.WriteString(fmt.Sprintf("%s %s (upgrade %s → %s):\n",
+				warnStyle.Render("•"), dep.Name, dep.Version, dep.MinVersion))
+			guide.WriteString(fmt.Sprintf("  %s\n\n", dep.UpgradeCmd))
+		}
+		if dep.Status == "timeout" {
+			guide.WriteString(fmt.Sprintf("%s %s (%s):\n",
+				warnStyle.Render("•"), dep.Name, dep.Output))
+			guide.WriteString("  The tool is installed but hung. Check that its daemon or cluster is reachable.\n\n")
+		}
 	}
 
 	guide.WriteString("After installation, run the module again or use 'netlab doctor' to verify.\n")
 	return guide.String()
 }
 
-func checkCommand(command string, args ...string) (string, string) {
-	cmd := exec.Command(command, args...)
-	output, err := cmd.Output()
+func checkCommand(ctx context.Context, command string, args ...string) (string, string) {
+	var stdout bytes.Buffer
+	err := executor.Run(ctx, &stdout, nil, command, args...)
+	output := stdout.Bytes()
 
 	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return "timeout", fmt.Sprintf("no response after %s", checkTimeout)
+		}
 		// Check if it's a "command not found" error
 		if strings.Contains(err.Error(), "executable file not found") ||
 			strings.Contains(err.Error(), "command not found") {
@@ -279,19 +813,3 @@ func checkCommand(command string, args ...string) (string, string) {
 
 	return "ok", string(output)
 }
-
-func getOS() string {
-	// Simple OS detection - can be enhanced
-	if cmd := exec.Command("uname", "-s"); cmd.Run() == nil {
-		if output, err := cmd.Output(); err == nil {
-			os := strings.ToLower(strings.TrimSpace(string(output)))
-			if os == "darwin" {
-				return "darwin"
-			}
-			if os == "linux" {
-				return "linux"
-			}
-		}
-	}
-	return "unknown"
-}
@@ -0,0 +1,190 @@
+package utils
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// useExecutor swaps the package executor for the duration of a test
+func useExecutor(t *testing.T, e Executor) {
+	t.Helper()
+	previous := executor
+	executor = e
+	t.Cleanup(func() { executor = previous })
+}
+
+// shortTimeout keeps Hang results from slowing the suite down
+func shortTimeout(t *testing.T) {
+	t.Helper()
+	previous := checkTimeout
+	checkTimeout = 20 * time.Millisecond
+	t.Cleanup(func() { checkTimeout = previous })
+}
+
+func TestCheckDiagnosticStatuses(t *testing.T) {
+	shortTimeout(t)
+	kind, _ := findDiagnostic("kind")
+
+	tests := []struct {
+		name    string
+		result  ScriptedResult
+		status  string
+		version string
+	}{
+		{"ok", ScriptedResult{Stdout: "kind v0.20.0 go1.20.4 linux/amd64\n"}, "ok", "0.20.0"},
+		{"outdated", ScriptedResult{Stdout: "kind v0.11.1 go1.16.4 linux/amd64\n"}, "outdated", "0.11.1"},
+		{"unparseable version", ScriptedResult{Stdout: "kind (devel)\n"}, "ok", ""},
+		{"missing", ScriptedResult{NotFound: true}, "missing", ""},
+		{"error", ScriptedResult{Stderr: "segmentation fault", ExitCode: 139}, "error", ""},
+		{"timeout", ScriptedResult{Hang: true}, "timeout", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useExecutor(t, NewScriptedExecutor(map[string]ScriptedResult{"kind version": tt.result}))
+
+			ctx, cancel := context.WithTimeout(context.Background(), checkTimeout)
+			defer cancel()
+
+			got := checkDiagnostic(ctx, kind, ">=0.12.0")
+			if got.Status != tt.status {
+				t.Fatalf("Status = %q, want %q (output %q)", got.Status, tt.status, got.Output)
+			}
+			if got.Version != tt.version {
+				t.Errorf("Version = %q, want %q", got.Version, tt.version)
+			}
+
+			switch tt.status {
+			case "missing":
+				if got.InstallCmd == "" {
+					t.Error("missing tool has no install command")
+				}
+			case "outdated":
+				if got.UpgradeCmd == "" {
+					t.Error("outdated tool has no upgrade command")
+				}
+			case "error":
+				if got.Output != "exit status 139" {
+					t.Errorf("Output = %q", got.Output)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckModuleDependencies(t *testing.T) {
+	shortTimeout(t)
+	exec := NewScriptedExecutor(map[string]ScriptedResult{
+		"docker --version":         {Stdout: "Docker version 24.0.7, build afdd53b\n"},
+		"kubectl version --client": {Stdout: "Client Version: v1.29.0\n"},
+		// Fine globally, but the OSI module needs a newer kind
+		"kind version":      {Stdout: "kind v0.17.0 go1.19.2 linux/amd64\n"},
+		"tcpdump --version": {Hang: true},
+	})
+	useExecutor(t, exec)
+
+	deps, allGood := CheckModuleDependencies(context.Background(), "01-osi-model")
+	if allGood {
+		t.Fatal("allGood = true with failing dependencies")
+	}
+
+	want := map[string]string{
+		"Docker":  "ok",
+		"kubectl": "ok",
+		"kind":    "outdated",
+		"tcpdump": "timeout",
+		"tshark":  "missing",
+	}
+	if len(deps) != len(want) {
+		t.Fatalf("got %d results, want %d", len(deps), len(want))
+	}
+	for i, dep := range deps {
+		if dep.Name != ModuleDependencyNames("01-osi-model")[i] {
+			t.Errorf("result %d is %s, out of order", i, dep.Name)
+		}
+		if dep.Status != want[dep.Name] {
+			t.Errorf("%s: Status = %q, want %q", dep.Name, dep.Status, want[dep.Name])
+		}
+		if !dep.Required {
+			t.Errorf("%s should be required for the module", dep.Name)
+		}
+	}
+	if deps[2].MinVersion != ">=0.20.0" {
+		t.Errorf("kind MinVersion = %q, want the module override", deps[2].MinVersion)
+	}
+}
+
+func TestCheckDockerDaemon(t *testing.T) {
+	shortTimeout(t)
+	daemon := capabilityChecks[0]
+	if daemon.name != "Docker daemon" {
+		t.Fatalf("first capability check is %q", daemon.name)
+	}
+
+	tests := []struct {
+		name   string
+		result ScriptedResult
+		status string
+		output string
+	}{
+		{"running", ScriptedResult{Stdout: "24.0.7\n"}, "ok", "server version 24.0.7"},
+		{"permission denied", ScriptedResult{
+			Stderr:   "permission denied while trying to connect to the Docker daemon socket",
+			ExitCode: 1,
+		}, "error", "permission denied talking to the daemon"},
+		{"stopped", ScriptedResult{
+			Stderr:   "Cannot connect to the Docker daemon at unix:///var/run/docker.sock",
+			ExitCode: 1,
+		}, "error", "daemon is not answering"},
+		{"hung", ScriptedResult{Hang: true}, "timeout", "no response after 20ms"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useExecutor(t, NewScriptedExecutor(map[string]ScriptedResult{
+				"docker info --format {{.ServerVersion}}": tt.result,
+			}))
+
+			ctx, cancel := context.WithTimeout(context.Background(), checkTimeout)
+			defer cancel()
+
+			got := runCapabilityCheck(ctx, daemon)
+			if got.Status != tt.status || got.Output != tt.output {
+				t.Errorf("got %q (%q), want %q (%q)", got.Status, got.Output, tt.status, tt.output)
+			}
+			if tt.status != "ok" && got.Remediation == "" {
+				t.Error("failed check has no remediation")
+			}
+		})
+	}
+}
+
+func TestDiagnosticsReportExitCode(t *testing.T) {
+	if code := (DiagnosticsReport{OK: true}).ExitCode(); code != ExitOK {
+		t.Errorf("OK report exits %d", code)
+	}
+	if code := (DiagnosticsReport{OK: false}).ExitCode(); code != ExitMissingRequired {
+		t.Errorf("failing report exits %d", code)
+	}
+}
+
+func TestRunChecksKeepsEveryResult(t *testing.T) {
+	const n = 8
+	seen := make([]bool, n)
+	for r := range runChecks(context.Background(), n, func(ctx context.Context, i int) DependencyStatus {
+		// Finish in reverse order to show results stream as they complete
+		time.Sleep(time.Duration(n-i) * time.Millisecond)
+		return DependencyStatus{Status: "ok"}
+	}) {
+		if seen[r.Index] {
+			t.Fatalf("index %d reported twice", r.Index)
+		}
+		seen[r.Index] = true
+	}
+	for i, ok := range seen {
+		if !ok {
+			t.Errorf("index %d never reported", i)
+		}
+	}
+}
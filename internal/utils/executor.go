@@ -0,0 +1,143 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// Executor runs external commands on NetLab's behalf. Everything that shells
+// out goes through it so it can be dry-run or tested without side effects.
+type Executor interface {
+	// Shell runs a command line through sh, streaming stdout and stderr to w
+	Shell(command string, w io.Writer) error
+	// Run runs a command to completion, writing its output to stdout and
+	// stderr (which may be the same writer to keep them interleaved)
+	Run(ctx context.Context, stdout, stderr io.Writer, name string, args ...string) error
+	// LookPath finds a command on PATH, as exec.LookPath does
+	LookPath(name string) (string, error)
+}
+
+// executor is used by the diagnostics and lab state helpers. Tests swap in a
+// ScriptedExecutor.
+var executor Executor = ShellExecutor{}
+
+// ShellExecutor runs commands for real. Stdin stays attached to the terminal
+// so sudo and package managers can prompt.
+type ShellExecutor struct{}
+
+func (ShellExecutor) Shell(command string, w io.Writer) error {
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = w
+	cmd.Stderr = w
+	return cmd.Run()
+}
+
+func (ShellExecutor) Run(ctx context.Context, stdout, stderr io.Writer, name string, args ...string) error {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	return cmd.Run()
+}
+
+func (ShellExecutor) LookPath(name string) (string, error) {
+	return exec.LookPath(name)
+}
+
+// ScriptedResult is the recorded outcome of one command
+type ScriptedResult struct {
+	Stdout   string
+	Stderr   string
+	ExitCode int  // Non-zero fails the command with "exit status N"
+	NotFound bool // Fail as if the binary isn't on PATH
+	Hang     bool // Block until the context is cancelled
+}
+
+// ScriptedExecutor replays recorded results instead of running anything.
+// Results are keyed by the full command line, e.g. "kind version".
+// Commands without a result fail as not found.
+type ScriptedExecutor struct {
+	Results map[string]ScriptedResult
+
+	mu       sync.Mutex
+	commands []string
+}
+
+// NewScriptedExecutor returns an executor that replays results
+func NewScriptedExecutor(results map[string]ScriptedResult) *ScriptedExecutor {
+	return &ScriptedExecutor{Results: results}
+}
+
+// Commands returns every command line run so far, in order
+func (e *ScriptedExecutor) Commands() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]string(nil), e.commands...)
+}
+
+func (e *ScriptedExecutor) Shell(command string, w io.Writer) error {
+	return e.replay(context.Background(), command, "sh", w, w)
+}
+
+func (e *ScriptedExecutor) Run(ctx context.Context, stdout, stderr io.Writer, name string, args ...string) error {
+	line := strings.Join(append([]string{name}, args...), " ")
+	return e.replay(ctx, line, name, stdout, stderr)
+}
+
+// LookPath finds a command when any result is recorded for it, unless that
+// result is NotFound
+func (e *ScriptedExecutor) LookPath(name string) (string, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for line, result := range e.Results {
+		if fields := strings.Fields(line); len(fields) > 0 && fields[0] == name && !result.NotFound {
+			return "/usr/bin/" + name, nil
+		}
+	}
+	return "", &exec.Error{Name: name, Err: exec.ErrNotFound}
+}
+
+func (e *ScriptedExecutor) replay(ctx context.Context, line, name string, stdout, stderr io.Writer) error {
+	e.mu.Lock()
+	e.commands = append(e.commands, line)
+	result, ok := e.Results[line]
+	e.mu.Unlock()
+
+	if !ok || result.NotFound {
+		return &exec.Error{Name: name, Err: exec.ErrNotFound}
+	}
+	if result.Hang {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	if stdout != nil {
+		io.WriteString(stdout, result.Stdout)
+	}
+	if stderr != nil {
+		io.WriteString(stderr, result.Stderr)
+	}
+	if result.ExitCode != 0 {
+		return &ScriptedExitError{Code: result.ExitCode}
+	}
+	return nil
+}
+
+// ScriptedExitError mirrors *exec.ExitError for a replayed failure
+type ScriptedExitError struct {
+	Code int
+}
+
+func (e *ScriptedExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+// ExitCode returns the recorded exit code
+func (e *ScriptedExitError) ExitCode() int {
+	return e.Code
+}
@@ -0,0 +1,188 @@
+package utils
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// manualStepPrefixes mark install hints that are instructions, not commands
+var manualStepPrefixes = []string{"Visit ", "Set ", "Start ", "Boot "}
+
+// FixAction is a command that should resolve one failed check
+type FixAction struct {
+	Name    string
+	Reason  string // e.g. "missing", "outdated (1.19 → >=1.21)"
+	Command string
+}
+
+// FixOptions configures a 'doctor --fix' run
+type FixOptions struct {
+	Context  context.Context
+	ModuleID string
+	Report   *DiagnosticsReport // Results already checked; built from scratch when nil
+	DryRun   bool               // Show the commands without running anything
+	Yes      bool               // Run every command without asking
+	In       io.Reader
+	Out      io.Writer
+	Exec     Executor
+}
+
+// FixResult records what happened to one action
+type FixResult struct {
+	Action  FixAction
+	Ran     bool
+	Skipped bool
+	Err     error
+}
+
+// RunFix offers to run the install or remediation command for each failed
+// check, then re-checks the ones it ran a command for. Fixes can uncover new
+// failures (installing Docker reveals a stopped daemon), so it keeps going
+// until nothing new is fixable. Each action is offered at most once.
+func RunFix(opts FixOptions) (DiagnosticsReport, []FixResult, error) {
+	if opts.Context == nil {
+		opts.Context = context.Background()
+	}
+	if opts.Exec == nil {
+		opts.Exec = ShellExecutor{}
+	}
+
+	in := bufio.NewReader(opts.In)
+	attempted := map[string]bool{}
+	var results []FixResult
+
+	var report DiagnosticsReport
+	if opts.Report != nil {
+		report = *opts.Report
+	} else {
+		var err error
+		if report, err = BuildDiagnosticsReport(opts.Context, opts.ModuleID); err != nil {
+			return report, results, err
+		}
+	}
+
+	for {
+		var pending []FixAction
+		for _, action := range PlanFixes(report) {
+			if !attempted[action.Name+"\x00"+action.Command] {
+				attempted[action.Name+"\x00"+action.Command] = true
+				pending = append(pending, action)
+			}
+		}
+		if len(pending) == 0 {
+			if len(results) == 0 {
+				fmt.Fprintln(opts.Out, checkStyle.Render("✅ Nothing to fix automatically."))
+			}
+			return report, results, nil
+		}
+
+		round := applyFixes(pending, opts, in)
+		results = append(results, round...)
+
+		if opts.DryRun {
+			return report, results, nil
+		}
+
+		var ran []string
+		for _, r := range round {
+			if r.Ran {
+				ran = append(ran, r.Action.Name)
+			}
+		}
+		if len(ran) == 0 {
+			return report, results, nil
+		}
+
+		fmt.Fprintln(opts.Out)
+		fmt.Fprintln(opts.Out, infoStyle.Render("🔁 Re-checking..."))
+		report = RecheckDiagnostics(opts.Context, report, ran)
+	}
+}
+
+// PlanFixes lists the commands that could resolve the report's failures.
+// Manual steps such as "Visit https://..." are left out.
+func PlanFixes(report DiagnosticsReport) []FixAction {
+	var actions []FixAction
+
+	for _, dep := range report.Dependencies {
+		switch dep.Status {
+		case "missing":
+			if isRunnable(dep.InstallCmd) {
+				actions = append(actions, FixAction{Name: dep.Name, Reason: "missing", Command: dep.InstallCmd})
+			}
+		case "outdated":
+			if isRunnable(dep.UpgradeCmd) {
+				reason := fmt.Sprintf("outdated (%s, needs %s)", dep.Version, dep.MinVersion)
+				actions = append(actions, FixAction{Name: dep.Name, Reason: reason, Command: dep.UpgradeCmd})
+			}
+		}
+	}
+
+	for _, c := range report.Capabilities {
+		if c.Status != "ok" && isRunnable(c.FixCmd) {
+			actions = append(actions, FixAction{Name: c.Name, Reason: c.Output, Command: c.FixCmd})
+		}
+	}
+
+	return actions
+}
+
+// applyFixes shows, confirms and runs each action in turn
+func applyFixes(actions []FixAction, opts FixOptions, in *bufio.Reader) []FixResult {
+	var results []FixResult
+
+	for _, action := range actions {
+		fmt.Fprintln(opts.Out)
+		fmt.Fprintf(opts.Out, "%s %s: %s\n", warnStyle.Render("🔧"), action.Name, action.Reason)
+		fmt.Fprintf(opts.Out, "   $ %s\n", action.Command)
+
+		result := FixResult{Action: action}
+
+		if opts.DryRun {
+			fmt.Fprintln(opts.Out, "   (dry run, not executed)")
+			result.Skipped = true
+			results = append(results, result)
+			continue
+		}
+
+		if !opts.Yes && !confirm(in, opts.Out, "   Run this command? [y/N]: ") {
+			fmt.Fprintln(opts.Out, "   Skipped")
+			result.Skipped = true
+			results = append(results, result)
+			continue
+		}
+
+		result.Ran = true
+		result.Err = opts.Exec.Shell(action.Command, opts.Out)
+		if result.Err != nil {
+			fmt.Fprintf(opts.Out, "%s %s: %v\n", errorStyle.Render("✗"), action.Name, result.Err)
+		} else {
+			fmt.Fprintf(opts.Out, "%s %s: command finished\n", checkStyle.Render("✓"), action.Name)
+		}
+		results = append(results, result)
+	}
+
+	return results
+}
+
+func confirm(in *bufio.Reader, out io.Writer, prompt string) bool {
+	fmt.Fprint(out, prompt)
+	answer, _ := in.ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+func isRunnable(command string) bool {
+	if command == "" {
+		return false
+	}
+	for _, prefix := range manualStepPrefixes {
+		if strings.HasPrefix(command, prefix) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,162 @@
+package utils
+
+import (
+	"bufio"
+	"io"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// succeeding scripts every command to exit cleanly
+func succeeding(commands ...string) map[string]ScriptedResult {
+	results := map[string]ScriptedResult{}
+	for _, c := range commands {
+		results[c] = ScriptedResult{}
+	}
+	return results
+}
+
+func TestPlanFixes(t *testing.T) {
+	report := DiagnosticsReport{
+		Dependencies: []DependencyStatus{
+			{Name: "Go", Status: "outdated", Version: "1.19", MinVersion: ">=1.21", UpgradeCmd: "Visit https://golang.org/doc/install"},
+			{Name: "tcpdump", Status: "missing", InstallCmd: "sudo apt-get install -y tcpdump"},
+			{Name: "kind", Status: "outdated", Version: "0.11.1", MinVersion: ">=0.20.0", UpgradeCmd: "brew upgrade kind"},
+			{Name: "kubectl", Status: "ok"},
+			{Name: "ip", Status: "missing"}, // No install command known
+		},
+		Capabilities: []DependencyStatus{
+			{Name: "IP forwarding", Status: "error", Output: "disabled", FixCmd: "sudo sysctl -w net.ipv4.ip_forward=1"},
+			{Name: "cgroup v2", Status: "error", Remediation: "Boot with systemd.unified_cgroup_hierarchy=1"},
+		},
+	}
+
+	actions := PlanFixes(report)
+	var got []string
+	for _, a := range actions {
+		got = append(got, a.Name)
+	}
+	want := []string{"tcpdump", "kind", "IP forwarding"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("PlanFixes = %v, want %v", got, want)
+	}
+	if actions[1].Reason != "outdated (0.11.1, needs >=0.20.0)" {
+		t.Errorf("kind reason = %q", actions[1].Reason)
+	}
+}
+
+func TestApplyFixes(t *testing.T) {
+	actions := []FixAction{
+		{Name: "tcpdump", Reason: "missing", Command: "sudo apt-get install -y tcpdump"},
+		{Name: "tshark", Reason: "missing", Command: "sudo apt-get install -y tshark"},
+		{Name: "br_netfilter", Reason: "module not loaded", Command: "sudo modprobe br_netfilter"},
+	}
+
+	t.Run("confirm each", func(t *testing.T) {
+		exec := NewScriptedExecutor(succeeding(actions[0].Command))
+		exec.Results[actions[2].Command] = ScriptedResult{Stderr: "modprobe: FATAL: Module br_netfilter not found", ExitCode: 1}
+		var out strings.Builder
+		opts := FixOptions{Out: &out, Exec: exec}
+
+		results := applyFixes(actions, opts, bufio.NewReader(strings.NewReader("y\nn\nyes\n")))
+
+		ran := exec.Commands()
+		if len(ran) != 2 || ran[0] != actions[0].Command || ran[1] != actions[2].Command {
+			t.Fatalf("ran %v", ran)
+		}
+		if !results[0].Ran || results[0].Err != nil {
+			t.Errorf("tcpdump result = %+v", results[0])
+		}
+		if !results[1].Skipped {
+			t.Errorf("tshark should have been skipped: %+v", results[1])
+		}
+		if results[2].Err == nil {
+			t.Errorf("br_netfilter should report the failure")
+		}
+		if !strings.Contains(out.String(), "$ sudo apt-get install -y tshark") {
+			t.Errorf("command not shown before confirmation:\n%s", out.String())
+		}
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		exec := NewScriptedExecutor(nil)
+		var out strings.Builder
+		opts := FixOptions{DryRun: true, Out: &out, Exec: exec}
+
+		results := applyFixes(actions, opts, bufio.NewReader(strings.NewReader("")))
+
+		if ran := exec.Commands(); len(ran) != 0 {
+			t.Fatalf("dry run executed %v", ran)
+		}
+		for _, r := range results {
+			if r.Ran || !r.Skipped {
+				t.Errorf("%s: %+v", r.Action.Name, r)
+			}
+		}
+	})
+
+	t.Run("yes", func(t *testing.T) {
+		exec := NewScriptedExecutor(succeeding(actions[0].Command, actions[1].Command, actions[2].Command))
+		opts := FixOptions{Yes: true, Out: io.Discard, Exec: exec}
+
+		results := applyFixes(actions, opts, bufio.NewReader(strings.NewReader("")))
+
+		if ran := exec.Commands(); len(ran) != len(actions) {
+			t.Fatalf("ran %v", ran)
+		}
+		for _, r := range results {
+			if r.Err != nil {
+				t.Errorf("%s: %v", r.Action.Name, r.Err)
+			}
+		}
+	})
+}
+
+func TestRunFixRechecksOnlyWhatRan(t *testing.T) {
+	shortTimeout(t)
+	t.Setenv("DOCKER_HOST", "tcp://127.0.0.1:2375") // Skips the socket check
+	install := "curl -fsSL https://get.docker.com | sh"
+	exec := NewScriptedExecutor(map[string]ScriptedResult{
+		install:            {},
+		"docker --version": {Stdout: "Docker version 24.0.7, build afdd53b\n"},
+		"docker info --format {{.ServerVersion}}": {Stdout: "24.0.7\n"},
+	})
+	useExecutor(t, exec)
+
+	report := DiagnosticsReport{
+		OK: true,
+		Dependencies: []DependencyStatus{
+			{Name: "Go", Status: "ok", Version: "1.21.5", Required: true},
+			{Name: "Docker", Status: "missing", InstallCmd: install},
+			{Name: "tcpdump", Status: "missing"}, // No install command, so not re-checked
+		},
+	}
+	fixed, results, err := RunFix(FixOptions{Report: &report, Yes: true, Out: io.Discard, Exec: exec})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || !results[0].Ran {
+		t.Fatalf("results = %+v", results)
+	}
+
+	ran := exec.Commands()
+	sort.Strings(ran[1:]) // The re-checks run concurrently
+	want := []string{install, "docker --version", "docker info --format {{.ServerVersion}}"}
+	if strings.Join(ran, "\n") != strings.Join(want, "\n") {
+		t.Errorf("ran %q, want %q", ran, want)
+	}
+
+	statuses := map[string]string{}
+	for _, s := range append(fixed.Dependencies, fixed.Capabilities...) {
+		statuses[s.Name] = s.Status
+	}
+	for name, status := range map[string]string{"Go": "ok", "Docker": "ok", "tcpdump": "missing", "Docker daemon": "ok", "Docker socket": "ok"} {
+		if statuses[name] != status {
+			t.Errorf("%s = %q, want %q", name, statuses[name], status)
+		}
+	}
+	if report.Dependencies[1].Status != "missing" {
+		t.Error("RunFix changed the caller's report")
+	}
+}
@@ -0,0 +1,342 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Lab resource types tracked in the state file
+const (
+	ResourceKindCluster = "kind-cluster"
+	ResourceNamespace   = "namespace"
+)
+
+// LabClusterPrefix is shared by every kind cluster a NetLab lab creates
+const LabClusterPrefix = "netlab-"
+
+// LabResource is something a lab created that has to be torn down again
+type LabResource struct {
+	Type      string    `json:"type"`
+	Name      string    `json:"name"`
+	Context   string    `json:"context,omitempty"` // kubectl context for namespaces
+	Module    string    `json:"module"`
+	CreatedAt time.Time `json:"created_at"`
+	Tracked   bool      `json:"-"` // false for resources discovered but never recorded
+}
+
+// UnknownLabStateError reports tracked kind clusters whose state couldn't be
+// checked because kind didn't answer. They stay in the state file and aren't
+// reported as left running.
+type UnknownLabStateError struct {
+	Clusters []LabResource
+	Err      error
+}
+
+func (e *UnknownLabStateError) Error() string {
+	names := make([]string, len(e.Clusters))
+	for i, r := range e.Clusters {
+		names[i] = r.Name
+	}
+	msg := fmt.Sprintf("could not query kind: %v", e.Err)
+	if len(names) > 0 {
+		msg += fmt.Sprintf(" (state of %s unknown)", strings.Join(names, ", "))
+	}
+	return msg
+}
+
+func (e *UnknownLabStateError) Unwrap() error {
+	return e.Err
+}
+
+// LabState is the on-disk record of lab resources created by NetLab
+type LabState struct {
+	Resources []LabResource `json:"resources"`
+}
+
+// LabStatePath returns the location of the lab state file
+func LabStatePath() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "lab-state.json"), nil
+}
+
+// LoadLabState reads the lab state file, returning an empty state if none exists
+func LoadLabState() (*LabState, error) {
+	path, err := LabStatePath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &LabState{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lab state: %w", err)
+	}
+
+	var state LabState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse lab state %s: %w", path, err)
+	}
+	for i := range state.Resources {
+		state.Resources[i].Tracked = true
+	}
+	return &state, nil
+}
+
+// Save writes the lab state file
+func (s *LabState) Save() error {
+	path, err := LabStatePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// ForModule returns the tracked resources created by a module
+func (s *LabState) ForModule(moduleID string) []LabResource {
+	var resources []LabResource
+	for _, r := range s.Resources {
+		if r.Module == moduleID {
+			resources = append(resources, r)
+		}
+	}
+	return resources
+}
+
+// TrackLabResource records a resource before a lab starts creating it, so it
+// can still be found if NetLab crashes or is quit mid-setup
+func TrackLabResource(resource LabResource) error {
+	state, err := LoadLabState()
+	if err != nil {
+		return err
+	}
+
+	for _, r := range state.Resources {
+		if r.Type == resource.Type && r.Name == resource.Name && r.Context == resource.Context {
+			return nil // Already tracked
+		}
+	}
+
+	if resource.CreatedAt.IsZero() {
+		resource.CreatedAt = time.Now()
+	}
+	state.Resources = append(state.Resources, resource)
+	return state.Save()
+}
+
+// UntrackLabResource forgets one recorded resource, after the lab that
+// created it has deleted it
+func UntrackLabResource(resource LabResource) error {
+	state, err := LoadLabState()
+	if err != nil {
+		return err
+	}
+
+	kept := state.Resources[:0]
+	for _, r := range state.Resources {
+		if resourceKey(r) != resourceKey(resource) {
+			kept = append(kept, r)
+		}
+	}
+	if len(kept) == len(state.Resources) {
+		return nil
+	}
+	state.Resources = kept
+	return state.Save()
+}
+
+// UntrackLabResources forgets every resource recorded for a module
+func UntrackLabResources(moduleID string) error {
+	state, err := LoadLabState()
+	if err != nil {
+		return err
+	}
+
+	kept := state.Resources[:0]
+	for _, r := range state.Resources {
+		if r.Module != moduleID {
+			kept = append(kept, r)
+		}
+	}
+	state.Resources = kept
+	return state.Save()
+}
+
+// CleanupLabResources deletes the given resources without prompting and
+// removes the ones that are gone from the state file. It returns one line of
+// output per resource.
+func CleanupLabResources(resources []LabResource) ([]string, error) {
+	var output []string
+	var failed []string
+	removed := map[string]bool{}
+
+	// Namespaces first: they live inside clusters we may be about to delete
+	ordered := make([]LabResource, 0, len(resources))
+	for _, r := range resources {
+		if r.Type == ResourceNamespace {
+			ordered = append(ordered, r)
+		}
+	}
+	for _, r := range resources {
+		if r.Type != ResourceNamespace {
+			ordered = append(ordered, r)
+		}
+	}
+
+	for _, r := range ordered {
+		err := deleteLabResource(r)
+		if err != nil {
+			output = append(output, fmt.Sprintf("✗ %s %s: %v", r.Type, r.Name, err))
+			failed = append(failed, r.Name)
+			continue
+		}
+		output = append(output, fmt.Sprintf("✓ %s %s deleted", r.Type, r.Name))
+		removed[resourceKey(r)] = true
+	}
+
+	state, err := LoadLabState()
+	if err != nil {
+		return output, err
+	}
+	kept := state.Resources[:0]
+	for _, r := range state.Resources {
+		if !removed[resourceKey(r)] {
+			kept = append(kept, r)
+		}
+	}
+	state.Resources = kept
+	if err := state.Save(); err != nil {
+		return output, err
+	}
+
+	if len(failed) > 0 {
+		return output, fmt.Errorf("failed to delete: %s", strings.Join(failed, ", "))
+	}
+	return output, nil
+}
+
+// FindOrphanedLabResources reports lab resources that still exist. Tracked
+// resources that no longer exist are pruned from the state file, and kind
+// clusters named netlab-* that were never recorded are reported as untracked.
+// If kind can't be queried, the tracked clusters are kept but not reported,
+// and the error is an *UnknownLabStateError alongside the other orphans.
+func FindOrphanedLabResources() ([]LabResource, error) {
+	state, err := LoadLabState()
+	if err != nil {
+		return nil, err
+	}
+
+	clusters, kindErr := listKindClusters()
+
+	var orphans, unknown []LabResource
+	kept := state.Resources[:0]
+	seen := map[string]bool{}
+	for _, r := range state.Resources {
+		exists := false
+		switch r.Type {
+		case ResourceKindCluster:
+			if kindErr != nil {
+				// Keep the record if kind cannot tell us either way
+				kept = append(kept, r)
+				unknown = append(unknown, r)
+				continue
+			}
+			exists = clusters[r.Name]
+		case ResourceNamespace:
+			exists = namespaceExists(r.Name, r.Context)
+		}
+		if exists {
+			kept = append(kept, r)
+			orphans = append(orphans, r)
+			seen[r.Name] = true
+		}
+	}
+
+	if len(kept) != len(state.Resources) {
+		state.Resources = kept
+		if err := state.Save(); err != nil {
+			return orphans, err
+		}
+	}
+
+	for name := range clusters {
+		if strings.HasPrefix(name, LabClusterPrefix) && !seen[name] {
+			orphans = append(orphans, LabResource{
+				Type: ResourceKindCluster,
+				Name: name,
+			})
+		}
+	}
+
+	if kindErr != nil {
+		return orphans, &UnknownLabStateError{Clusters: unknown, Err: kindErr}
+	}
+	return orphans, nil
+}
+
+func deleteLabResource(r LabResource) error {
+	var name string
+	var args []string
+	switch r.Type {
+	case ResourceKindCluster:
+		name, args = "kind", []string{"delete", "cluster", "--name", r.Name}
+	case ResourceNamespace:
+		name, args = "kubectl", []string{"delete", "namespace", r.Name, "--ignore-not-found", "--wait=false"}
+		if r.Context != "" {
+			args = append(args, "--context", r.Context)
+		}
+	default:
+		return fmt.Errorf("unknown resource type %q", r.Type)
+	}
+
+	var output bytes.Buffer
+	if err := executor.Run(context.Background(), &output, &output, name, args...); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(output.String()))
+	}
+	return nil
+}
+
+func listKindClusters() (map[string]bool, error) {
+	var output bytes.Buffer
+	if err := executor.Run(context.Background(), &output, nil, "kind", "get", "clusters"); err != nil {
+		return nil, err
+	}
+
+	clusters := map[string]bool{}
+	for _, line := range strings.Split(output.String(), "\n") {
+		if name := strings.TrimSpace(line); name != "" {
+			clusters[name] = true
+		}
+	}
+	return clusters, nil
+}
+
+func namespaceExists(name, kubeContext string) bool {
+	args := []string{"get", "namespace", name, "--request-timeout=5s"}
+	if kubeContext != "" {
+		args = append(args, "--context", kubeContext)
+	}
+	return executor.Run(context.Background(), nil, nil, "kubectl", args...) == nil
+}
+
+func resourceKey(r LabResource) string {
+	return r.Type + "/" + r.Context + "/" + r.Name
+}
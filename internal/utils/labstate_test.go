@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFindOrphanedLabResources(t *testing.T) {
+	tracked := []LabResource{
+		{Type: ResourceKindCluster, Name: "netlab-osi", Module: "01-osi-model", CreatedAt: time.Now()},
+		{Type: ResourceKindCluster, Name: "netlab-gone", Module: "04-routing", CreatedAt: time.Now()},
+	}
+
+	tests := []struct {
+		name        string
+		results     map[string]ScriptedResult
+		wantOrphans []string
+		wantKept    int
+		wantUnknown []string
+	}{
+		{
+			name:        "running and untracked clusters",
+			results:     map[string]ScriptedResult{"kind get clusters": {Stdout: "netlab-osi\nnetlab-stray\nother\n"}},
+			wantOrphans: []string{"netlab-osi", "netlab-stray"},
+			wantKept:    1,
+		},
+		{
+			name:        "kind fails",
+			results:     map[string]ScriptedResult{"kind get clusters": {ExitCode: 1, Stderr: "cannot connect to docker"}},
+			wantKept:    2,
+			wantUnknown: []string{"netlab-osi", "netlab-gone"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+			useExecutor(t, NewScriptedExecutor(tt.results))
+			state := &LabState{Resources: append([]LabResource(nil), tracked...)}
+			if err := state.Save(); err != nil {
+				t.Fatal(err)
+			}
+
+			orphans, err := FindOrphanedLabResources()
+			var unknown *UnknownLabStateError
+			if errors.As(err, &unknown) {
+				var names []string
+				for _, r := range unknown.Clusters {
+					names = append(names, r.Name)
+				}
+				if strings.Join(names, ",") != strings.Join(tt.wantUnknown, ",") {
+					t.Errorf("unknown clusters = %v, want %v", names, tt.wantUnknown)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			} else if tt.wantUnknown != nil {
+				t.Fatalf("err = nil, want an UnknownLabStateError")
+			}
+
+			var names []string
+			for _, r := range orphans {
+				names = append(names, r.Name)
+				if r.Tracked != (r.Name != "netlab-stray") {
+					t.Errorf("%s: Tracked = %v", r.Name, r.Tracked)
+				}
+			}
+			if strings.Join(names, ",") != strings.Join(tt.wantOrphans, ",") {
+				t.Errorf("orphans = %v, want %v", names, tt.wantOrphans)
+			}
+
+			saved, err := LoadLabState()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(saved.Resources) != tt.wantKept {
+				t.Errorf("state keeps %d resources, want %d", len(saved.Resources), tt.wantKept)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ToolSpec declares a tool that a module from a content pack depends on.
+// Tools NetLab already checks only need a Name; new tools also need a Command.
+type ToolSpec struct {
+	Name        string
+	Command     string
+	Args        []string
+	MinVersion  string
+	Install     string // Shown when the tool is missing, on every OS
+	Description string
+}
+
+// ContentPackStatus is the outcome of loading one content pack
+type ContentPackStatus struct {
+	Name    string   `json:"name"`
+	Path    string   `json:"path"`
+	Modules []string `json:"modules,omitempty"`
+	Errors  []string `json:"errors,omitempty"`
+}
+
+var (
+	contentPacksMu sync.Mutex
+	contentPacks   []ContentPackStatus
+)
+
+// SetContentPacks records the content packs found at startup so doctor can
+// report them
+func SetContentPacks(statuses []ContentPackStatus) {
+	contentPacksMu.Lock()
+	defer contentPacksMu.Unlock()
+	contentPacks = append([]ContentPackStatus(nil), statuses...)
+}
+
+// ContentPacks returns the content packs recorded by SetContentPacks
+func ContentPacks() []ContentPackStatus {
+	contentPacksMu.Lock()
+	defer contentPacksMu.Unlock()
+	return append([]ContentPackStatus(nil), contentPacks...)
+}
+
+// KnownTool reports whether NetLab has a built-in check for a tool
+func KnownTool(name string) bool {
+	_, ok := findDiagnostic(name)
+	return ok
+}
+
+// RegisterModuleDependencies adds the dependencies of a module that isn't
+// built in, so the dependency check screen and 'doctor --module' cover it.
+// It must be called before any checks run.
+func RegisterModuleDependencies(moduleID string, tools []ToolSpec) error {
+	added, names, constraints, err := planModuleDependencies(moduleID, tools)
+	if err != nil {
+		return err
+	}
+
+	// Packs may share a new tool; the first definition wins
+	for _, diag := range added {
+		if _, exists := findDiagnostic(diag.name); !exists {
+			diagnostics = append(diagnostics, diag)
+		}
+	}
+	ModuleDependencies[moduleID] = names
+	if len(constraints) > 0 {
+		ModuleVersionConstraints[moduleID] = constraints
+	}
+	return nil
+}
+
+// ValidateModuleDependencies returns the error RegisterModuleDependencies
+// would, without registering anything
+func ValidateModuleDependencies(moduleID string, tools []ToolSpec) error {
+	_, _, _, err := planModuleDependencies(moduleID, tools)
+	return err
+}
+
+// planModuleDependencies works out the new tools, dependency names and
+// version constraints of a module
+func planModuleDependencies(moduleID string, tools []ToolSpec) ([]diagnostic, []string, map[string]string, error) {
+	if _, exists := ModuleDependencies[moduleID]; exists {
+		return nil, nil, nil, fmt.Errorf("module %s already has dependencies registered", moduleID)
+	}
+
+	var added []diagnostic
+	names := []string{}
+	constraints := map[string]string{}
+	for _, tool := range tools {
+		builtin, known := findDiagnostic(tool.Name)
+		switch {
+		case known && tool.Command != "" && tool.Command != builtin.command:
+			return nil, nil, nil, fmt.Errorf("%s is a built-in tool and can't be redefined", tool.Name)
+		case !known && tool.Command == "":
+			return nil, nil, nil, fmt.Errorf("%s is not a known tool, so it needs a command", tool.Name)
+		case !known:
+			diag := diagnostic{
+				name:        tool.Name,
+				command:     tool.Command,
+				args:        tool.Args,
+				description: tool.Description,
+			}
+			if tool.Install != "" {
+				diag.installCmd = map[string]string{"darwin": tool.Install, "linux": tool.Install}
+			}
+			added = append(added, diag)
+		}
+
+		if tool.MinVersion != "" {
+			if err := ValidVersionConstraint(tool.MinVersion); err != nil {
+				return nil, nil, nil, fmt.Errorf("%s: %w", tool.Name, err)
+			}
+			constraints[tool.Name] = tool.MinVersion
+		}
+		names = append(names, tool.Name)
+	}
+	return added, names, constraints, nil
+}
@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// restoreModuleRegistry undoes RegisterModuleDependencies after a test
+func restoreModuleRegistry(t *testing.T) {
+	t.Helper()
+	diags := append([]diagnostic(nil), diagnostics...)
+	deps := map[string][]string{}
+	for k, v := range ModuleDependencies {
+		deps[k] = v
+	}
+	constraints := map[string]map[string]string{}
+	for k, v := range ModuleVersionConstraints {
+		constraints[k] = v
+	}
+	t.Cleanup(func() {
+		diagnostics = diags
+		ModuleDependencies = deps
+		ModuleVersionConstraints = constraints
+	})
+}
+
+func TestRegisterModuleDependencies(t *testing.T) {
+	restoreModuleRegistry(t)
+	shortTimeout(t)
+	useExecutor(t, NewScriptedExecutor(map[string]ScriptedResult{
+		"kubectl version --client": {Stdout: "Client Version: v1.27.3\n"},
+		"calicoctl version":        {Stdout: "Client Version:    v3.26.1\n"},
+	}))
+
+	err := RegisterModuleDependencies("acme-cni", []ToolSpec{
+		{Name: "kubectl", MinVersion: ">=1.28"},
+		{Name: "calicoctl", Command: "calicoctl", Args: []string{"version"}, MinVersion: ">=3.25"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deps, allGood := CheckModuleDependencies(context.Background(), "acme-cni")
+	if allGood || len(deps) != 2 {
+		t.Fatalf("allGood = %v with %d results", allGood, len(deps))
+	}
+	if deps[0].Status != "outdated" || deps[0].MinVersion != ">=1.28" {
+		t.Errorf("kubectl = %+v, want outdated against the pack's constraint", deps[0])
+	}
+	if deps[1].Status != "ok" || deps[1].Version != "3.26.1" {
+		t.Errorf("calicoctl = %+v", deps[1])
+	}
+	if !KnownTool("calicoctl") {
+		t.Error("calicoctl not added to the known tools")
+	}
+}
+
+func TestRegisterModuleDependenciesErrors(t *testing.T) {
+	restoreModuleRegistry(t)
+
+	tests := []struct {
+		name  string
+		id    string
+		tools []ToolSpec
+		want  string
+	}{
+		{"built-in module", "01-osi-model", nil, "already has dependencies"},
+		{"unknown tool", "x", []ToolSpec{{Name: "mystery"}}, "needs a command"},
+		{"redefined tool", "x", []ToolSpec{{Name: "kind", Command: "kind-wrapper"}}, "can't be redefined"},
+		{"bad constraint", "x", []ToolSpec{{Name: "kind", MinVersion: "latest"}}, "invalid version constraint"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := RegisterModuleDependencies(tt.id, tt.tools)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+	if _, exists := ModuleDependencies["x"]; exists {
+		t.Error("a failed registration left the module registered")
+	}
+}
+
+func TestDiagnosticsReportInvalidPack(t *testing.T) {
+	report := DiagnosticsReport{OK: true, ContentPacks: []ContentPackStatus{
+		{Name: "acme", Modules: []string{"acme-cni"}},
+		{Name: "broken", Errors: []string{"title is required"}},
+	}}
+	if code := report.ExitCode(); code != ExitInvalidContent {
+		t.Errorf("exit code = %d, want %d", code, ExitInvalidContent)
+	}
+
+	// Missing required tools take precedence
+	report.OK = false
+	if code := report.ExitCode(); code != ExitMissingRequired {
+		t.Errorf("exit code = %d, want %d", code, ExitMissingRequired)
+	}
+}
@@ -0,0 +1,170 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Package managers used as install map keys. "darwin" and "linux" remain as
+// OS-wide fallbacks when a tool has no entry for the detected manager.
+const (
+	PkgBrew   = "brew"
+	PkgApt    = "apt"
+	PkgDnf    = "dnf"
+	PkgPacman = "pacman"
+	PkgApk    = "apk"
+	PkgZypper = "zypper"
+	PkgNix    = "nix"
+)
+
+// osReleasePaths are tried in order, as described in os-release(5)
+var osReleasePaths = []string{"etc/os-release", "usr/lib/os-release"}
+
+// distroPackageManagers maps os-release IDs (and ID_LIKE entries) to a package manager
+var distroPackageManagers = map[string]string{
+	"debian":              PkgApt,
+	"ubuntu":              PkgApt,
+	"linuxmint":           PkgApt,
+	"pop":                 PkgApt,
+	"fedora":              PkgDnf,
+	"rhel":                PkgDnf,
+	"centos":              PkgDnf,
+	"rocky":               PkgDnf,
+	"almalinux":           PkgDnf,
+	"arch":                PkgPacman,
+	"manjaro":             PkgPacman,
+	"endeavouros":         PkgPacman,
+	"alpine":              PkgApk,
+	"opensuse":            PkgZypper,
+	"opensuse-leap":       PkgZypper,
+	"opensuse-tumbleweed": PkgZypper,
+	"suse":                PkgZypper,
+	"sles":                PkgZypper,
+	"nixos":               PkgNix,
+}
+
+// Platform describes the machine NetLab is running on
+type Platform struct {
+	OS             string // "darwin", "linux" or "unknown"
+	Distro         string // os-release ID, e.g. "ubuntu"
+	DistroName     string // os-release PRETTY_NAME, e.g. "Ubuntu 22.04.3 LTS"
+	PackageManager string // One of the Pkg* constants, or "" if unknown
+}
+
+// String returns a human-readable description such as "Ubuntu 22.04 LTS (apt)"
+func (p Platform) String() string {
+	name := p.OS
+	if p.DistroName != "" {
+		name = p.DistroName
+	} else if p.Distro != "" {
+		name = p.Distro
+	}
+	if p.PackageManager != "" {
+		return fmt.Sprintf("%s (%s)", name, p.PackageManager)
+	}
+	return name
+}
+
+var (
+	platformOnce sync.Once
+	platform     Platform
+)
+
+// DetectPlatform returns the current platform, reading os-release once
+func DetectPlatform() Platform {
+	platformOnce.Do(func() {
+		platform = detectPlatform(runtime.GOOS)
+	})
+	return platform
+}
+
+func detectPlatform(goos string) Platform {
+	switch goos {
+	case "darwin":
+		return Platform{OS: "darwin", DistroName: "macOS", PackageManager: PkgBrew}
+	case "linux":
+		for _, path := range osReleasePaths {
+			if p, err := ReadOSRelease(filepath.Join(sysRoot, path)); err == nil {
+				return p
+			}
+		}
+		return Platform{OS: "linux"}
+	default:
+		return Platform{OS: "unknown"}
+	}
+}
+
+// ReadOSRelease parses an os-release file into a Linux Platform
+func ReadOSRelease(path string) (Platform, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Platform{}, err
+	}
+	defer f.Close()
+
+	fields, err := parseOSRelease(f)
+	if err != nil {
+		return Platform{}, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return platformFromOSRelease(fields), nil
+}
+
+// parseOSRelease reads KEY=value lines, unquoting values
+func parseOSRelease(r io.Reader) (map[string]string, error) {
+	fields := map[string]string{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			value = unquoted
+		} else {
+			value = strings.Trim(value, `'"`)
+		}
+		fields[key] = value
+	}
+	return fields, scanner.Err()
+}
+
+func platformFromOSRelease(fields map[string]string) Platform {
+	p := Platform{
+		OS:         "linux",
+		Distro:     strings.ToLower(fields["ID"]),
+		DistroName: fields["PRETTY_NAME"],
+	}
+
+	// ID first, then ID_LIKE in order of preference
+	candidates := append([]string{p.Distro}, strings.Fields(strings.ToLower(fields["ID_LIKE"]))...)
+	for _, id := range candidates {
+		if pm, ok := distroPackageManagers[id]; ok {
+			p.PackageManager = pm
+			break
+		}
+	}
+	return p
+}
+
+// lookupCommand picks the command for the platform's package manager,
+// falling back to the OS-wide entry
+func lookupCommand(commands map[string]string, p Platform) string {
+	if p.PackageManager != "" {
+		if cmd, ok := commands[p.PackageManager]; ok {
+			return cmd
+		}
+	}
+	return commands[p.OS]
+}
@@ -0,0 +1,164 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadOSRelease(t *testing.T) {
+	tests := []struct {
+		fixture        string
+		distro         string
+		distroName     string
+		packageManager string
+	}{
+		{"ubuntu", "ubuntu", "Ubuntu 22.04.3 LTS", PkgApt},
+		{"debian", "debian", "Debian GNU/Linux 12 (bookworm)", PkgApt},
+		{"fedora", "fedora", "Fedora Linux 39 (Workstation Edition)", PkgDnf},
+		{"rhel", "rhel", "Red Hat Enterprise Linux 9.3 (Plow)", PkgDnf},
+		{"arch", "arch", "Arch Linux", PkgPacman},
+		{"alpine", "alpine", "Alpine Linux v3.19", PkgApk},
+		{"opensuse-tumbleweed", "opensuse-tumbleweed", "openSUSE Tumbleweed", PkgZypper},
+		{"nixos", "nixos", "NixOS 23.11 (Tapir)", PkgNix},
+		{"zorin", "zorin", "Zorin OS 17", PkgApt}, // Only known through ID_LIKE
+		{"gentoo", "gentoo", "Gentoo Linux", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			p, err := ReadOSRelease(filepath.Join("testdata", "os-release", tt.fixture))
+			if err != nil {
+				t.Fatalf("ReadOSRelease: %v", err)
+			}
+			if p.OS != "linux" {
+				t.Errorf("OS = %q, want linux", p.OS)
+			}
+			if p.Distro != tt.distro {
+				t.Errorf("Distro = %q, want %q", p.Distro, tt.distro)
+			}
+			if p.DistroName != tt.distroName {
+				t.Errorf("DistroName = %q, want %q", p.DistroName, tt.distroName)
+			}
+			if p.PackageManager != tt.packageManager {
+				t.Errorf("PackageManager = %q, want %q", p.PackageManager, tt.packageManager)
+			}
+		})
+	}
+}
+
+func TestInstallCommandForDistro(t *testing.T) {
+	tests := []struct {
+		fixture string
+		tool    string
+		want    string
+	}{
+		{"ubuntu", "tshark", "sudo apt-get install -y tshark"},
+		{"fedora", "tshark", "sudo dnf install -y wireshark-cli"},
+		{"arch", "tcpdump", "sudo pacman -S --needed tcpdump"},
+		{"alpine", "iptables", "sudo apk add iptables"},
+		{"opensuse-tumbleweed", "kubectl", "sudo zypper install -y kubernetes-client"},
+		{"nixos", "kind", "nix-env -iA nixos.kind"},
+		{"ubuntu", "kind", kindUpstreamInstall}, // Not packaged, so the upstream binary
+		// No package manager entry, so the generic Linux command is used
+		{"gentoo", "kind", kindUpstreamInstall},
+		{"gentoo", "Go", "Visit https://golang.org/doc/install"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture+"/"+tt.tool, func(t *testing.T) {
+			p, err := ReadOSRelease(filepath.Join("testdata", "os-release", tt.fixture))
+			if err != nil {
+				t.Fatalf("ReadOSRelease: %v", err)
+			}
+
+			diag, ok := findDiagnostic(tt.tool)
+			if !ok {
+				t.Fatalf("no diagnostic named %q", tt.tool)
+			}
+			if got := installCommandFor(diag, p); got != tt.want {
+				t.Errorf("installCommandFor = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestEveryDiagnosticHasCommands checks that every tool names its own
+// install command for each package manager, and its own upgrade command when
+// it has a minimum version, rather than falling back to the generic one
+func TestEveryDiagnosticHasCommands(t *testing.T) {
+	managers := []string{PkgApt, PkgDnf, PkgPacman, PkgApk, PkgZypper, PkgNix}
+	for _, diag := range diagnostics {
+		for _, pm := range managers {
+			t.Run(diag.name+"/"+pm, func(t *testing.T) {
+				commands := map[string]map[string]string{"install": diag.installCmd}
+				if diag.minVersion != "" {
+					commands["upgrade"] = diag.upgradeCmd
+				}
+				for kind, byManager := range commands {
+					cmd, ok := byManager[pm]
+					if !ok {
+						t.Errorf("no %s command for %s", kind, pm)
+						continue
+					}
+					// Pointing at the upstream installer has to say where it is
+					if strings.HasPrefix(cmd, "Visit ") && !strings.Contains(cmd, "https://") {
+						t.Errorf("%s step %q has no URL", kind, cmd)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestDetectPlatform(t *testing.T) {
+	previous := sysRoot
+	t.Cleanup(func() { sysRoot = previous })
+
+	t.Run("etc/os-release", func(t *testing.T) {
+		sysRoot = t.TempDir()
+		writeOSRelease(t, filepath.Join(sysRoot, "etc", "os-release"), "ID=fedora\nPRETTY_NAME=\"Fedora Linux 39\"\n")
+		writeOSRelease(t, filepath.Join(sysRoot, "usr", "lib", "os-release"), "ID=arch\n")
+
+		if p := detectPlatform("linux"); p.Distro != "fedora" || p.PackageManager != PkgDnf {
+			t.Errorf("detectPlatform = %+v, want fedora/dnf", p)
+		}
+	})
+
+	t.Run("usr/lib fallback", func(t *testing.T) {
+		sysRoot = t.TempDir()
+		writeOSRelease(t, filepath.Join(sysRoot, "usr", "lib", "os-release"), "ID=arch\n")
+
+		if p := detectPlatform("linux"); p.Distro != "arch" || p.PackageManager != PkgPacman {
+			t.Errorf("detectPlatform = %+v, want arch/pacman", p)
+		}
+	})
+
+	t.Run("no os-release", func(t *testing.T) {
+		sysRoot = t.TempDir()
+
+		if p := detectPlatform("linux"); p != (Platform{OS: "linux"}) {
+			t.Errorf("detectPlatform = %+v, want bare linux", p)
+		}
+	})
+
+	for goos, want := range map[string]Platform{
+		"darwin":  {OS: "darwin", DistroName: "macOS", PackageManager: PkgBrew},
+		"windows": {OS: "unknown"},
+	} {
+		if p := detectPlatform(goos); p != want {
+			t.Errorf("detectPlatform(%q) = %+v, want %+v", goos, p, want)
+		}
+	}
+}
+
+func writeOSRelease(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
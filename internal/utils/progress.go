@@ -0,0 +1,178 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// progressMu serializes writes to the progress file, so drills answered in
+// quick succession don't overwrite each other's updates
+var progressMu sync.Mutex
+
+// Progress is the on-disk record of what the learner has done in each module
+type Progress struct {
+	Modules map[string]*ModuleProgress `json:"modules"`
+}
+
+// ModuleProgress is the learner's record for one module
+type ModuleProgress struct {
+	Drills     map[string]*DrillStats `json:"drills,omitempty"` // By drill kind
+	LastActive time.Time              `json:"last_active"`
+}
+
+// DrillStats sums up the answers to one kind of practice problem
+type DrillStats struct {
+	Attempts  int   `json:"attempts"`
+	Correct   int   `json:"correct"`
+	Streak    int   `json:"streak"` // Correct answers in a row, up to the latest
+	TotalMS   int64 `json:"total_ms"`
+	FastestMS int64 `json:"fastest_ms,omitempty"` // Fastest correct answer
+}
+
+// Totals adds up the stats for every drill kind in the module
+func (m *ModuleProgress) Totals() DrillStats {
+	var total DrillStats
+	for _, s := range m.Drills {
+		total.Attempts += s.Attempts
+		total.Correct += s.Correct
+		total.TotalMS += s.TotalMS
+		if s.FastestMS > 0 && (total.FastestMS == 0 || s.FastestMS < total.FastestMS) {
+			total.FastestMS = s.FastestMS
+		}
+	}
+	return total
+}
+
+// ProgressPath returns the location of the progress file
+func ProgressPath() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "progress.json"), nil
+}
+
+// LoadProgress reads the progress file, returning an empty record if none exists
+func LoadProgress() (*Progress, error) {
+	path, err := ProgressPath()
+	if err != nil {
+		return nil, err
+	}
+
+	progress := &Progress{Modules: map[string]*ModuleProgress{}}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return progress, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read progress: %w", err)
+	}
+	if err := json.Unmarshal(data, progress); err != nil {
+		return nil, fmt.Errorf("failed to parse progress %s: %w", path, err)
+	}
+	if progress.Modules == nil {
+		progress.Modules = map[string]*ModuleProgress{}
+	}
+	return progress, nil
+}
+
+// Save writes the progress file
+func (p *Progress) Save() error {
+	progressMu.Lock()
+	defer progressMu.Unlock()
+	return p.save()
+}
+
+// save writes the progress file to a temporary file and renames it into
+// place, so a crash mid-write can't leave it truncated. The caller holds
+// progressMu.
+func (p *Progress) save() error {
+	path, err := ProgressPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".progress-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to write progress: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // Fails harmlessly once renamed
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write progress: %w", err)
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write progress: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write progress: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write progress: %w", err)
+	}
+	return nil
+}
+
+// Module returns the record for a module, creating it if needed
+func (p *Progress) Module(moduleID string) *ModuleProgress {
+	m, ok := p.Modules[moduleID]
+	if !ok {
+		m = &ModuleProgress{}
+		p.Modules[moduleID] = m
+	}
+	if m.Drills == nil {
+		m.Drills = map[string]*DrillStats{}
+	}
+	return m
+}
+
+// RecordDrill adds an answered practice problem to the progress file and
+// returns the module's updated record
+func RecordDrill(moduleID, kind string, correct bool, took time.Duration) (ModuleProgress, error) {
+	progressMu.Lock()
+	defer progressMu.Unlock()
+
+	progress, err := LoadProgress()
+	if err != nil {
+		return ModuleProgress{}, err
+	}
+
+	m := progress.Module(moduleID)
+	stats, ok := m.Drills[kind]
+	if !ok {
+		stats = &DrillStats{}
+		m.Drills[kind] = stats
+	}
+
+	ms := took.Milliseconds()
+	stats.Attempts++
+	stats.TotalMS += ms
+	if correct {
+		stats.Correct++
+		stats.Streak++
+		if stats.FastestMS == 0 || ms < stats.FastestMS {
+			stats.FastestMS = ms
+		}
+	} else {
+		stats.Streak = 0
+	}
+	m.LastActive = time.Now()
+
+	if err := progress.save(); err != nil {
+		return ModuleProgress{}, err
+	}
+	return *m, nil
+}
@@ -0,0 +1,104 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRecordDrill(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	answers := []struct {
+		kind    string
+		correct bool
+		took    time.Duration
+	}{
+		{"split", true, 9 * time.Second},
+		{"split", true, 4 * time.Second},
+		{"split", false, 20 * time.Second},
+		{"hosts", true, 6 * time.Second},
+	}
+	var m ModuleProgress
+	for _, a := range answers {
+		var err error
+		if m, err = RecordDrill("03-subnetting", a.kind, a.correct, a.took); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	split := *m.Drills["split"]
+	if want := (DrillStats{Attempts: 3, Correct: 2, Streak: 0, TotalMS: 33000, FastestMS: 4000}); split != want {
+		t.Errorf("split stats = %+v, want %+v", split, want)
+	}
+	if want := (DrillStats{Attempts: 4, Correct: 3, TotalMS: 39000, FastestMS: 4000}); m.Totals() != want {
+		t.Errorf("totals = %+v, want %+v", m.Totals(), want)
+	}
+
+	// The record survives a reload
+	progress, err := LoadProgress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := progress.Module("03-subnetting").Drills["hosts"]; got == nil || got.Streak != 1 {
+		t.Errorf("hosts stats after reload = %+v", got)
+	}
+}
+
+func TestRecordDrillConcurrently(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	const n = 20
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := RecordDrill("03-subnetting", "split", true, time.Second); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	progress, err := LoadProgress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := progress.Module("03-subnetting").Drills["split"].Attempts; got != n {
+		t.Errorf("attempts = %d, want %d; concurrent answers were lost", got, n)
+	}
+
+	// Only the progress file is left, no temporary files
+	path, _ := ProgressPath()
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "progress.json" {
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		t.Errorf("config directory holds %v", names)
+	}
+}
+
+func TestLoadProgressRejectsCorruptFile(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	path, err := ProgressPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("{not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RecordDrill("03-subnetting", "split", true, time.Second); err == nil {
+		t.Error("corrupt progress file was overwritten")
+	}
+}
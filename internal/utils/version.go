@@ -0,0 +1,112 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// versionSatisfies reports whether version meets a constraint. A constraint is
+// a comma-separated list of comparisons that must all hold, e.g. ">=0.20.0" or
+// ">=1.27, <2". A bare version means ">=".
+func versionSatisfies(version, constraint string) (bool, error) {
+	for _, part := range strings.Split(constraint, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		op := ">="
+		for _, candidate := range []string{">=", "<=", "!=", ">", "<", "="} {
+			if strings.HasPrefix(part, candidate) {
+				op = candidate
+				part = strings.TrimSpace(strings.TrimPrefix(part, candidate))
+				break
+			}
+		}
+
+		cmp, err := compareVersions(version, part)
+		if err != nil {
+			return false, err
+		}
+
+		var ok bool
+		switch op {
+		case ">=":
+			ok = cmp >= 0
+		case "<=":
+			ok = cmp <= 0
+		case ">":
+			ok = cmp > 0
+		case "<":
+			ok = cmp < 0
+		case "=":
+			ok = cmp == 0
+		case "!=":
+			ok = cmp != 0
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// compareVersions compares two dotted versions numerically, returning -1, 0
+// or 1. Missing components count as zero, so "1.21" equals "1.21.0".
+func compareVersions(a, b string) (int, error) {
+	pa, err := splitVersion(a)
+	if err != nil {
+		return 0, err
+	}
+	pb, err := splitVersion(b)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var x, y int
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		if x < y {
+			return -1, nil
+		}
+		if x > y {
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+func splitVersion(v string) ([]int, error) {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	// Drop pre-release and build metadata ("1.2.3-rc1+abc")
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v = v[:i]
+	}
+
+	var parts []int
+	for _, field := range strings.Split(v, ".") {
+		n, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version %q", v)
+		}
+		parts = append(parts, n)
+	}
+	return parts, nil
+}
+
+// ValidVersionConstraint reports why a version constraint can't be parsed
+func ValidVersionConstraint(constraint string) error {
+	if strings.TrimSpace(constraint) == "" {
+		return fmt.Errorf("empty version constraint")
+	}
+	if _, err := versionSatisfies("0", constraint); err != nil {
+		return fmt.Errorf("invalid version constraint %q: %w", constraint, err)
+	}
+	return nil
+}
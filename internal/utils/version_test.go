@@ -0,0 +1,86 @@
+package utils
+
+import "testing"
+
+func TestVersionSatisfies(t *testing.T) {
+	tests := []struct {
+		version    string
+		constraint string
+		want       bool
+		wantErr    bool
+	}{
+		{"0.20.0", ">=0.20.0", true, false},
+		{"0.19.9", ">=0.20.0", false, false},
+		{"1.21", "1.21.0", true, false}, // A bare version means >=, missing parts are zero
+		{"1.20.9", "1.21", false, false},
+		{"1.10.0", ">1.9", true, false}, // Compared numerically, not as strings
+		{"1.9.0", ">1.9", false, false},
+		{"1.28.3", "<=1.28.3", true, false},
+		{"1.28.4", "<=1.28.3", false, false},
+		{"1.27.0", ">=1.27, <2", true, false},
+		{"2.0.0", ">=1.27, <2", false, false},
+		{"1.26.9", ">=1.27, <2", false, false},
+		{"4.2", "=4.2.0", true, false},
+		{"4.2.1", "=4.2", false, false},
+		{"4.2.1", "!=4.2.0", true, false},
+		{"4.2.0", "!=4.2", false, false},
+		{"v1.29.0", ">= 1.29", true, false}, // A leading v and spaces are ignored
+		{"1.2.3-rc1+abc", "1.2.3", true, false},
+		{"1.2.3-rc1", ">1.2.3", false, false}, // Pre-releases compare as their release
+		{"3.0", ">=1,,", true, false},         // Empty parts are skipped
+		{"3.0", "", true, false},
+		{"unknown", ">=1.0", false, true},
+		{"1.0", ">=one", false, true},
+		{"1..0", ">=1.0", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version+" "+tt.constraint, func(t *testing.T) {
+			got, err := versionSatisfies(tt.version, tt.constraint)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("versionSatisfies(%q, %q) error = %v, want error %v", tt.version, tt.constraint, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("versionSatisfies(%q, %q) = %v, want %v", tt.version, tt.constraint, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"1.2", "1.2.0", 0},
+		{"v1.2.3", "1.2.3", 0},
+		{"1.2.3", "1.2.10", -1},
+		{"2", "1.99.99", 1},
+		{"0.20.0-beta.1", "0.20.0", 0},
+	}
+
+	for _, tt := range tests {
+		got, err := compareVersions(tt.a, tt.b)
+		if err != nil {
+			t.Errorf("compareVersions(%q, %q): %v", tt.a, tt.b, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestValidVersionConstraint(t *testing.T) {
+	for _, constraint := range []string{">=1.27", ">=1.27, <2", "0.20.0", "!=3"} {
+		if err := ValidVersionConstraint(constraint); err != nil {
+			t.Errorf("ValidVersionConstraint(%q) = %v, want nil", constraint, err)
+		}
+	}
+	for _, constraint := range []string{"", "  ", ">=latest", ">=1.x"} {
+		if err := ValidVersionConstraint(constraint); err == nil {
+			t.Errorf("ValidVersionConstraint(%q) = nil, want an error", constraint)
+		}
+	}
+}
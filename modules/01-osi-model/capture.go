@@ -0,0 +1,213 @@
+package osimodel
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"netlab/internal/utils"
+)
+
+// captureTimeout bounds how long tshark may take to read the lab capture
+const captureTimeout = 10 * time.Second
+
+// captureFields are the tshark fields read from the first HTTP request in
+// the capture. The lab records with Linux cooked capture, which has the
+// sll.* fields instead of eth.*; both are asked for so either works.
+var captureFields = []string{
+	"frame.len",
+	"eth.src", "eth.dst", "eth.type",
+	"sll.src.eth", "sll.etype",
+	"ip.version", "ip.hdr_len", "ip.len", "ip.ttl", "ip.proto", "ip.src", "ip.dst",
+	"tcp.srcport", "tcp.dstport", "tcp.seq_raw", "tcp.ack_raw", "tcp.flags", "tcp.window_size_value", "tcp.checksum",
+	"http.request.method", "http.request.uri", "http.request.version", "http.host", "http.user_agent", "http.accept", "http.connection",
+}
+
+// tcpFlagNames lists TCP flags in header bit order
+var tcpFlagNames = []struct {
+	bit  uint64
+	name string
+}{
+	{0x01, "FIN"}, {0x02, "SYN"}, {0x04, "RST"}, {0x08, "PSH"}, {0x10, "ACK"}, {0x20, "URG"},
+}
+
+// LoadPacketData parses the lab capture into packet layers with tshark. It
+// fails when there's no capture, tshark is missing or the capture holds no
+// HTTP request, and callers then keep the sample packet.
+func LoadPacketData(e utils.Executor) ([]PacketLayer, error) {
+	if _, err := os.Stat(labCapturePath); err != nil {
+		return nil, fmt.Errorf("reading lab capture: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), captureTimeout)
+	defer cancel()
+	return parseCapture(ctx, e, labCapturePath)
+}
+
+// captureArgs returns the tshark arguments that print the capture's HTTP
+// requests, one tab-separated line of captureFields each
+func captureArgs(path string) []string {
+	args := []string{"-r", path, "-Y", "http.request", "-T", "fields", "-E", "separator=/t", "-E", "occurrence=f"}
+	for _, field := range captureFields {
+		args = append(args, "-e", field)
+	}
+	return args
+}
+
+// parseCapture reads the first HTTP request in a capture. A capture holds
+// frames, not signals, so the physical layer stays the sample's.
+func parseCapture(ctx context.Context, e utils.Executor, path string) ([]PacketLayer, error) {
+	var stdout, stderr bytes.Buffer
+	if err := e.Run(ctx, &stdout, &stderr, "tshark", captureArgs(path)...); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("tshark: %w: %s", err, msg)
+		}
+		return nil, fmt.Errorf("tshark: %w", err)
+	}
+
+	line, _, _ := strings.Cut(strings.TrimLeft(stdout.String(), "\n"), "\n")
+	if strings.TrimSpace(line) == "" {
+		return nil, fmt.Errorf("%s: no HTTP request in the capture", path)
+	}
+	values := strings.Split(line, "\t")
+	field := func(name string) string {
+		for i, f := range captureFields {
+			if f == name && i < len(values) {
+				return strings.TrimSpace(values[i])
+			}
+		}
+		return ""
+	}
+	if field("ip.src") == "" || field("tcp.srcport") == "" {
+		return nil, fmt.Errorf("%s: the HTTP request has no IPv4 or TCP header", path)
+	}
+
+	var layers []PacketLayer
+	for _, l := range getSamplePacketLayers() {
+		if l.OSILayer == 1 {
+			layers = append(layers, l)
+		}
+	}
+	return append(layers,
+		captureLinkLayer(field),
+		captureNetworkLayer(field),
+		captureTransportLayer(field),
+		captureApplicationLayer(field),
+	), nil
+}
+
+// captureLinkLayer builds the data link layer. Cooked captures record the
+// sender's address but not the destination's.
+func captureLinkLayer(field func(string) string) PacketLayer {
+	src, dst, etherType := field("eth.src"), field("eth.dst"), field("eth.type")
+	explanation := fmt.Sprintf("The Ethernet frame carries the IP packet from %s to %s on the local network segment.", src, dst)
+	if src == "" {
+		src, dst, etherType = field("sll.src.eth"), "not recorded", field("sll.etype")
+		explanation = fmt.Sprintf("The frame left the interface with MAC address %s. The lab captures in Linux cooked mode, which records the sender's address and EtherType but not the destination MAC.", src)
+	}
+	if etherType == "0x0800" || etherType == "0x00000800" {
+		etherType = "0x0800 (IPv4)"
+	}
+
+	layer := PacketLayer{
+		OSILayer: 2,
+		Name:     "Data Link Layer (Ethernet)",
+		Headers: map[string]string{
+			"Source MAC":      src,
+			"Destination MAC": dst,
+			"EtherType":       etherType,
+		},
+		Explanation: explanation,
+	}
+	if n := field("frame.len"); n != "" {
+		layer.Headers["Frame Length"] = n + " bytes"
+	}
+	return layer
+}
+
+// captureNetworkLayer builds the IP layer
+func captureNetworkLayer(field func(string) string) PacketLayer {
+	protocol := field("ip.proto")
+	if protocol == "6" {
+		protocol = "6 (TCP)"
+	}
+	return PacketLayer{
+		OSILayer: 3,
+		Name:     "Network Layer (IP)",
+		Headers: map[string]string{
+			"Version":        field("ip.version") + " (IPv" + field("ip.version") + ")",
+			"Header Length":  field("ip.hdr_len") + " bytes",
+			"Packet Length":  field("ip.len") + " bytes",
+			"TTL":            field("ip.ttl"),
+			"Protocol":       protocol,
+			"Source IP":      field("ip.src"),
+			"Destination IP": field("ip.dst"),
+		},
+		Explanation: fmt.Sprintf("The IP header routes the packet from %s to %s across the cluster network. Its TTL of %s drops by one at every router on the way.",
+			field("ip.src"), field("ip.dst"), field("ip.ttl")),
+	}
+}
+
+// captureTransportLayer builds the TCP layer
+func captureTransportLayer(field func(string) string) PacketLayer {
+	flags := field("tcp.flags")
+	if bits, err := strconv.ParseUint(strings.TrimPrefix(flags, "0x"), 16, 16); err == nil {
+		var names []string
+		for _, f := range tcpFlagNames {
+			if bits&f.bit != 0 {
+				names = append(names, f.name)
+			}
+		}
+		flags = strings.Join(names, ", ")
+	}
+	return PacketLayer{
+		OSILayer: 4,
+		Name:     "Transport Layer (TCP)",
+		Headers: map[string]string{
+			"Source Port":      field("tcp.srcport"),
+			"Destination Port": field("tcp.dstport"),
+			"Sequence Number":  field("tcp.seq_raw"),
+			"Ack Number":       field("tcp.ack_raw"),
+			"Flags":            flags,
+			"Window Size":      field("tcp.window_size_value"),
+			"Checksum":         field("tcp.checksum"),
+		},
+		Explanation: fmt.Sprintf("TCP delivers the request from port %s to port %s over the connection the three-way handshake opened earlier in the capture. The segment carrying it is flagged %s.",
+			field("tcp.srcport"), field("tcp.dstport"), flags),
+	}
+}
+
+// captureApplicationLayer builds the HTTP layer and rebuilds the request
+// line and headers as raw data
+func captureApplicationLayer(field func(string) string) PacketLayer {
+	version := strings.TrimPrefix(field("http.request.version"), "HTTP/")
+	headers := map[string]string{
+		"Method":       field("http.request.method"),
+		"URI":          field("http.request.uri"),
+		"HTTP Version": version,
+		"Host":         field("http.host"),
+	}
+	raw := fmt.Sprintf("%s %s HTTP/%s\\r\\nHost: %s\\r\\n", headers["Method"], headers["URI"], version, headers["Host"])
+	for _, h := range []struct{ name, field string }{
+		{"User-Agent", "http.user_agent"},
+		{"Accept", "http.accept"},
+		{"Connection", "http.connection"},
+	} {
+		if value := field(h.field); value != "" {
+			headers[h.name] = value
+			raw += h.name + ": " + value + "\\r\\n"
+		}
+	}
+
+	return PacketLayer{
+		OSILayer: 7,
+		Name:     "Application Layer (HTTP)",
+		Headers:  headers,
+		RawData:  raw + "\\r\\n",
+		Explanation: fmt.Sprintf("The HTTP %s request for %s on %s, exactly as the lab's client sent it. This is the application-layer data that users actually care about.",
+			headers["Method"], headers["URI"], headers["Host"]),
+	}
+}
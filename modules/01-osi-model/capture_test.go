@@ -0,0 +1,167 @@
+package osimodel
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"netlab/internal/utils"
+)
+
+// tsharkLine returns a line of tshark field output with the given values
+// and every other field empty
+func tsharkLine(values map[string]string) string {
+	line := make([]string, len(captureFields))
+	for i, field := range captureFields {
+		line[i] = values[field]
+	}
+	return strings.Join(line, "\t") + "\n"
+}
+
+// scriptedCapture returns an executor that answers the tshark command for
+// path with result
+func scriptedCapture(path string, result utils.ScriptedResult) *utils.ScriptedExecutor {
+	line := strings.Join(append([]string{"tshark"}, captureArgs(path)...), " ")
+	return utils.NewScriptedExecutor(map[string]utils.ScriptedResult{line: result})
+}
+
+// labRequest is the HTTP request in assets/https-nginx.pcap, frame 6
+var labRequest = map[string]string{
+	"frame.len":             "140",
+	"sll.src.eth":           "66:52:65:e4:61:86",
+	"sll.etype":             "0x0800",
+	"ip.version":            "4",
+	"ip.hdr_len":            "20",
+	"ip.len":                "120",
+	"ip.ttl":                "63",
+	"ip.proto":              "6",
+	"ip.src":                "10.244.0.6",
+	"ip.dst":                "10.244.0.5",
+	"tcp.srcport":           "46538",
+	"tcp.dstport":           "80",
+	"tcp.seq_raw":           "2210956247",
+	"tcp.ack_raw":           "1152605157",
+	"tcp.flags":             "0x0018",
+	"tcp.window_size_value": "512",
+	"tcp.checksum":          "0x165d",
+	"http.request.method":   "GET",
+	"http.request.uri":      "/",
+	"http.request.version":  "HTTP/1.1",
+	"http.host":             "nginx",
+	"http.user_agent":       "Wget",
+	"http.connection":       "close",
+}
+
+func TestParseCapture(t *testing.T) {
+	second := map[string]string{}
+	for k, v := range labRequest {
+		second[k] = v
+	}
+	second["tcp.srcport"] = "46550"
+
+	e := scriptedCapture("lab.pcap", utils.ScriptedResult{Stdout: tsharkLine(labRequest) + tsharkLine(second)})
+	layers, err := parseCapture(context.Background(), e, "lab.pcap")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []int
+	for _, l := range layers {
+		got = append(got, l.OSILayer)
+	}
+	if len(got) != 5 || got[0] != 1 || got[1] != 2 || got[2] != 3 || got[3] != 4 || got[4] != 7 {
+		t.Fatalf("layers = %v, want 1 2 3 4 7", got)
+	}
+	if layers[0].Name != getSamplePacketLayers()[0].Name {
+		t.Errorf("physical layer = %q, want the sample's", layers[0].Name)
+	}
+
+	tests := []struct {
+		layer int
+		key   string
+		want  string
+	}{
+		{2, "Source MAC", "66:52:65:e4:61:86"},
+		{2, "Destination MAC", "not recorded"}, // Cooked captures don't keep it
+		{2, "EtherType", "0x0800 (IPv4)"},
+		{2, "Frame Length", "140 bytes"},
+		{3, "Version", "4 (IPv4)"},
+		{3, "Protocol", "6 (TCP)"},
+		{3, "Source IP", "10.244.0.6"},
+		{3, "TTL", "63"},
+		{4, "Source Port", "46538"}, // The first request only
+		{4, "Flags", "PSH, ACK"},
+		{4, "Sequence Number", "2210956247"},
+		{7, "Method", "GET"},
+		{7, "HTTP Version", "1.1"},
+		{7, "User-Agent", "Wget"},
+		{7, "Connection", "close"},
+		{7, "", `GET / HTTP/1.1\r\nHost: nginx\r\nUser-Agent: Wget\r\nConnection: close\r\n\r\n`},
+	}
+	for _, tt := range tests {
+		if got := packetField(layers, tt.layer, tt.key, "missing"); got != tt.want {
+			t.Errorf("layer %d %q = %q, want %q", tt.layer, tt.key, got, tt.want)
+		}
+	}
+
+	e2 := newEncapsulation(layers, true)
+	if ips := e2.headers[3].fields[0]; ips != "10.244.0.6 → 10.244.0.5" {
+		t.Errorf("encapsulation IPs = %q", ips)
+	}
+}
+
+func TestParseCaptureEthernet(t *testing.T) {
+	e := scriptedCapture("eth.pcap", utils.ScriptedResult{Stdout: tsharkLine(map[string]string{
+		"eth.src":     "02:42:ac:12:00:01",
+		"eth.dst":     "02:42:ac:12:00:02",
+		"eth.type":    "0x0800",
+		"ip.src":      "10.244.0.5",
+		"ip.dst":      "10.244.0.10",
+		"tcp.srcport": "38472",
+		"tcp.flags":   "0x0002",
+	})})
+	layers, err := parseCapture(context.Background(), e, "eth.pcap")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := packetField(layers, 2, "Destination MAC", ""); got != "02:42:ac:12:00:02" {
+		t.Errorf("Destination MAC = %q", got)
+	}
+	if got := packetField(layers, 4, "Flags", ""); got != "SYN" {
+		t.Errorf("Flags = %q", got)
+	}
+}
+
+func TestParseCaptureErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		result utils.ScriptedResult
+		want   string
+	}{
+		{"tshark missing", utils.ScriptedResult{NotFound: true}, "executable file not found"},
+		{"unreadable", utils.ScriptedResult{Stderr: "tshark: The file \"lab.pcap\" doesn't exist.\n", ExitCode: 2}, "exit status 2: tshark: The file"},
+		{"no request", utils.ScriptedResult{Stdout: "\n"}, "no HTTP request"},
+		{"not IP", utils.ScriptedResult{Stdout: tsharkLine(map[string]string{"http.request.method": "GET"})}, "no IPv4 or TCP header"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseCapture(context.Background(), scriptedCapture("lab.pcap", tt.result), "lab.pcap")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadEncapsulationLayersWithoutCapture(t *testing.T) {
+	// Tests run in the package directory, where the lab capture path doesn't
+	// resolve, so the sample packet is used and not labelled as captured
+	layers, fromCapture := loadEncapsulationLayers(utils.NewScriptedExecutor(nil))
+	if fromCapture {
+		t.Error("reported a capture that wasn't parsed")
+	}
+	if len(layers) != len(getSamplePacketLayers()) {
+		t.Errorf("got %d layers, want the sample packet", len(layers))
+	}
+}
@@ -0,0 +1,251 @@
+package osimodel
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ContentDirEnv names a directory that overrides the embedded content. Files
+// in <dir>/01-osi-model/ replace their embedded counterparts one by one, so
+// authors can edit a copy of content/osi.yaml without rebuilding.
+const ContentDirEnv = "NETLAB_CONTENT_DIR"
+
+// contentVersion is the content schema version this build reads
+const contentVersion = 1
+
+const (
+	layersFile = "content/osi.yaml"
+	packetFile = "content/sample-packet.json"
+	readmeFile = "README.md"
+)
+
+//go:embed README.md content
+var embeddedContent embed.FS
+
+// Content is the text the module shows, loaded from its content files
+type Content struct {
+	Layers            []OSILayer
+	Mnemonics         []string
+	RealWorldExample  map[string]string
+	KubernetesContext map[int]string
+	SamplePacket      []PacketLayer
+	README            string
+}
+
+// layersDoc is the schema of content/osi.yaml
+type layersDoc struct {
+	Version          int               `yaml:"version"`
+	Layers           []layerDoc        `yaml:"layers"`
+	Mnemonics        []string          `yaml:"mnemonics"`
+	RealWorldExample map[string]string `yaml:"real_world_example"`
+}
+
+type layerDoc struct {
+	OSILayer   `yaml:",inline"`
+	Kubernetes string `yaml:"kubernetes"`
+}
+
+// packetDoc is the schema of content/sample-packet.json
+type packetDoc struct {
+	Version int           `json:"version"`
+	Layers  []PacketLayer `json:"layers"`
+}
+
+var (
+	contentOnce   sync.Once
+	loadedContent Content
+	contentErr    error
+)
+
+// moduleContent returns the module content, loading it on first use. An
+// invalid override falls back to the embedded content and is reported by
+// ContentError.
+func moduleContent() Content {
+	contentOnce.Do(func() {
+		loadedContent, contentErr = LoadContent(contentFS())
+		if contentErr != nil {
+			var err error
+			if loadedContent, err = LoadContent(embeddedContent); err != nil {
+				panic(fmt.Sprintf("embedded OSI content is invalid: %v", err))
+			}
+		}
+	})
+	return loadedContent
+}
+
+// ContentError returns why the content override could not be used, if it
+// could not
+func ContentError() error {
+	moduleContent()
+	return contentErr
+}
+
+// contentFS returns the embedded content, overlaid with the directory in
+// ContentDirEnv when it is set
+func contentFS() fs.FS {
+	dir := os.Getenv(ContentDirEnv)
+	if dir == "" {
+		return embeddedContent
+	}
+	return overlayFS{upper: os.DirFS(filepath.Join(dir, labModuleID)), lower: embeddedContent}
+}
+
+// overlayFS opens files from upper, falling back to lower for files upper
+// does not have
+type overlayFS struct {
+	upper, lower fs.FS
+}
+
+func (o overlayFS) Open(name string) (fs.File, error) {
+	f, err := o.upper.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return o.lower.Open(name)
+	}
+	return f, err
+}
+
+// LoadContent reads and validates the module content files from fsys
+func LoadContent(fsys fs.FS) (Content, error) {
+	var c Content
+
+	data, err := fs.ReadFile(fsys, layersFile)
+	if err != nil {
+		return c, err
+	}
+	var layers layersDoc
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&layers); err != nil && !errors.Is(err, io.EOF) {
+		return c, fmt.Errorf("%s: %w", layersFile, err)
+	}
+	if err := layers.validate(); err != nil {
+		return c, fmt.Errorf("%s: %w", layersFile, err)
+	}
+
+	data, err = fs.ReadFile(fsys, packetFile)
+	if err != nil {
+		return c, err
+	}
+	var packet packetDoc
+	jdec := json.NewDecoder(bytes.NewReader(data))
+	jdec.DisallowUnknownFields()
+	if err := jdec.Decode(&packet); err != nil {
+		return c, fmt.Errorf("%s: %w", packetFile, err)
+	}
+	if err := packet.validate(); err != nil {
+		return c, fmt.Errorf("%s: %w", packetFile, err)
+	}
+
+	readme, err := fs.ReadFile(fsys, readmeFile)
+	if err != nil {
+		return c, err
+	}
+
+	c.KubernetesContext = make(map[int]string, len(layers.Layers))
+	for _, l := range layers.Layers {
+		c.Layers = append(c.Layers, l.OSILayer)
+		if l.Kubernetes != "" {
+			c.KubernetesContext[l.Number] = l.Kubernetes
+		}
+	}
+	c.Mnemonics = layers.Mnemonics
+	c.RealWorldExample = layers.RealWorldExample
+	c.SamplePacket = packet.Layers
+	c.README = string(readme)
+	return c, nil
+}
+
+// validate checks everything the YAML decoder can't: versions, required
+// fields, layer numbers and URLs. All problems are reported together.
+func (d layersDoc) validate() error {
+	var errs []error
+	if d.Version != contentVersion {
+		errs = append(errs, fmt.Errorf("version %d is not supported, this build reads version %d", d.Version, contentVersion))
+	}
+
+	seen := map[int]bool{}
+	for i, l := range d.Layers {
+		where := fmt.Sprintf("layers[%d]", i)
+		if l.Number < 1 || l.Number > 7 {
+			errs = append(errs, fmt.Errorf("%s: number %d is not an OSI layer (1-7)", where, l.Number))
+		} else if seen[l.Number] {
+			errs = append(errs, fmt.Errorf("%s: layer %d is listed twice", where, l.Number))
+		}
+		seen[l.Number] = true
+
+		for field, value := range map[string]string{
+			"name":        l.Name,
+			"short_name":  l.ShortName,
+			"description": l.Description,
+			"function":    l.Function,
+		} {
+			if value == "" {
+				errs = append(errs, fmt.Errorf("%s: %s is required", where, field))
+			}
+		}
+		if l.ExternalDoc != "" {
+			if u, err := url.Parse(l.ExternalDoc); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				errs = append(errs, fmt.Errorf("%s: external_doc %q is not an http(s) URL", where, l.ExternalDoc))
+			}
+		}
+	}
+	for n := 1; n <= 7; n++ {
+		if !seen[n] {
+			errs = append(errs, fmt.Errorf("layer %d is missing", n))
+		}
+	}
+
+	if len(d.Mnemonics) == 0 {
+		errs = append(errs, errors.New("mnemonics: at least one is required"))
+	}
+
+	allowed := map[string]bool{"title": true, "description": true}
+	for n := 1; n <= 7; n++ {
+		allowed[fmt.Sprintf("layer%d", n)] = true
+	}
+	for key := range d.RealWorldExample {
+		if !allowed[key] {
+			errs = append(errs, fmt.Errorf("real_world_example: unknown key %q", key))
+		}
+	}
+
+	return sortedJoin(errs)
+}
+
+func (d packetDoc) validate() error {
+	var errs []error
+	if d.Version != contentVersion {
+		errs = append(errs, fmt.Errorf("version %d is not supported, this build reads version %d", d.Version, contentVersion))
+	}
+	if len(d.Layers) == 0 {
+		errs = append(errs, errors.New("layers: at least one is required"))
+	}
+	for i, l := range d.Layers {
+		if l.OSILayer < 1 || l.OSILayer > 7 {
+			errs = append(errs, fmt.Errorf("layers[%d]: osi_layer %d is not an OSI layer (1-7)", i, l.OSILayer))
+		}
+		if l.Name == "" {
+			errs = append(errs, fmt.Errorf("layers[%d]: name is required", i))
+		}
+	}
+	return sortedJoin(errs)
+}
+
+// sortedJoin joins errors in a stable order, since some come from map
+// iteration
+func sortedJoin(errs []error) error {
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+	return errors.Join(errs...)
+}
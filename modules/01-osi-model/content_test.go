@@ -0,0 +1,121 @@
+package osimodel
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestEmbeddedContent(t *testing.T) {
+	c, err := LoadContent(embeddedContent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Layers) != 7 || c.Layers[0].Number != 7 || c.Layers[6].Number != 1 {
+		t.Errorf("layers are not 7 down to 1: %d layers", len(c.Layers))
+	}
+	if len(c.KubernetesContext) != 7 {
+		t.Errorf("Kubernetes context covers %d layers", len(c.KubernetesContext))
+	}
+	if len(c.SamplePacket) == 0 || c.README == "" {
+		t.Error("sample packet or README missing")
+	}
+}
+
+// overrideLayers returns an overlay whose osi.yaml is the embedded one with
+// old replaced by new
+func overrideLayers(t *testing.T, old, new string) fs.FS {
+	t.Helper()
+	data, err := fs.ReadFile(embeddedContent, layersFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), old) {
+		t.Fatalf("%q not found in %s", old, layersFile)
+	}
+	edited := strings.Replace(string(data), old, new, 1)
+	return overlayFS{
+		upper: fstest.MapFS{layersFile: {Data: []byte(edited)}},
+		lower: embeddedContent,
+	}
+}
+
+func TestContentOverride(t *testing.T) {
+	c, err := LoadContent(overrideLayers(t, "name: Application Layer", "name: Application Layer (edited)"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Layers[0].Name != "Application Layer (edited)" {
+		t.Errorf("override not applied: %q", c.Layers[0].Name)
+	}
+	// Files missing from the override come from the embedded copy
+	if len(c.SamplePacket) == 0 {
+		t.Error("sample packet not loaded from the embedded content")
+	}
+}
+
+func TestContentValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		want     string
+	}{
+		{"unknown field", "short_name: Application", "shortname: Application", "field shortname not found"},
+		{"version", "version: 1", "version: 2", "version 2 is not supported"},
+		{"duplicate layer", "number: 6", "number: 7", "layer 7 is listed twice"},
+		{"missing layer", "number: 6", "number: 7", "layer 6 is missing"},
+		{"out of range", "number: 1", "number: 8", "number 8 is not an OSI layer"},
+		{"required field", "function: Routing and logical addressing", "function: \"\"", "layers[4]: function is required"},
+		{"bad url", "external_doc: https://tools.ietf.org/html/rfc791", "external_doc: tools.ietf.org/rfc791", "is not an http(s) URL"},
+		{"real world key", "  layer7:", "  layer8:", "unknown key \"layer8\""},
+		{"syntax", "mnemonics:", "mnemonics: [", "content/osi.yaml: yaml:"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadContent(overrideLayers(t, tt.old, tt.new))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSamplePacketValidation(t *testing.T) {
+	fsys := overlayFS{
+		upper: fstest.MapFS{packetFile: {Data: []byte(`{"version": 1, "layers": [{"osi_layer": 9}], "extra": true}`)}},
+		lower: embeddedContent,
+	}
+	if _, err := LoadContent(fsys); err == nil || !strings.Contains(err.Error(), `unknown field "extra"`) {
+		t.Errorf("error = %v", err)
+	}
+
+	fsys.upper = fstest.MapFS{packetFile: {Data: []byte(`{"version": 1, "layers": [{"osi_layer": 9}]}`)}}
+	_, err := LoadContent(fsys)
+	if err == nil || !strings.Contains(err.Error(), "osi_layer 9 is not an OSI layer") || !strings.Contains(err.Error(), "name is required") {
+		t.Errorf("error = %v", err)
+	}
+}
+
+func TestContentDirEnv(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, labModuleID, readmeFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("# Draft README\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(ContentDirEnv, dir)
+
+	c, err := LoadContent(contentFS())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.README != "# Draft README\n" {
+		t.Errorf("README = %q", c.README)
+	}
+}
@@ -0,0 +1,409 @@
+package osimodel
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"netlab/pkg/styles"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// pduNames maps each layer to the name of its protocol data unit
+var pduNames = map[int]string{
+	7: "data",
+	6: "data",
+	5: "data",
+	4: "segment",
+	3: "packet",
+	2: "frame",
+	1: "bits",
+}
+
+// encapSpeeds are the delays between animation steps, slowest first
+var encapSpeeds = []time.Duration{
+	3 * time.Second,
+	2 * time.Second,
+	1200 * time.Millisecond,
+	700 * time.Millisecond,
+	350 * time.Millisecond,
+}
+
+const defaultEncapSpeed = 2
+
+// encapHeader is one header wrapped around the payload on the way down
+type encapHeader struct {
+	layer   int
+	label   string   // Shown inside the frame, e.g. "TCP"
+	name    string   // Protocol name for the legend, e.g. "Transport Control Protocol"
+	fields  []string // Field summaries for the legend
+	trailer string   // Label of a trailer after the payload, e.g. "FCS"
+}
+
+// encapTickMsg advances the animation. Ticks from an older play/pause cycle
+// carry a stale id and are ignored.
+type encapTickMsg struct {
+	id int
+}
+
+// encapsulation animates an HTTP request being wrapped on the way down the
+// sender's stack, sent as bits, then unwrapped up the receiver's stack
+type encapsulation struct {
+	headers     []encapHeader // Innermost (HTTP) first
+	bits        string
+	fromCapture bool
+	step        int
+	playing     bool
+	speed       int
+	tickID      int
+}
+
+func newEncapsulation(layers []PacketLayer, fromCapture bool) encapsulation {
+	return encapsulation{
+		headers:     buildEncapHeaders(layers),
+		bits:        packetField(layers, 1, "", "10101010 10101010 10101010 10101011 ..."),
+		fromCapture: fromCapture,
+		speed:       defaultEncapSpeed,
+	}
+}
+
+// buildEncapHeaders fills each header with values from the packet layers,
+// falling back to a typical pod-to-pod request
+func buildEncapHeaders(layers []PacketLayer) []encapHeader {
+	f := func(layer int, key, fallback string) string {
+		return packetField(layers, layer, key, fallback)
+	}
+
+	return []encapHeader{
+		{
+			layer: 7,
+			label: "HTTP " + f(7, "Method", "GET") + " " + f(7, "URI", "/"),
+			name:  "HTTP request",
+			fields: []string{
+				"Host: " + f(7, "Host", "nginx"),
+				"User-Agent: " + f(7, "User-Agent", "curl/7.64.0"),
+			},
+		},
+		{
+			layer:  6,
+			label:  "TLS",
+			name:   "TLS record",
+			fields: []string{"version " + f(6, "Version", "1.3"), "type " + f(6, "Content Type", "application_data (23)")},
+		},
+		{
+			layer: 4,
+			label: "TCP",
+			name:  "TCP header",
+			fields: []string{
+				f(4, "Source Port", "38472") + " → " + f(4, "Destination Port", "443"),
+				"flags " + f(4, "Flags", "PSH, ACK"),
+				"seq " + f(4, "Sequence Number", "1234567890"),
+			},
+		},
+		{
+			layer: 3,
+			label: "IPv4",
+			name:  "IP header",
+			fields: []string{
+				f(3, "Source IP", "10.244.0.5") + " → " + f(3, "Destination IP", "10.244.0.10"),
+				"TTL " + f(3, "TTL", "64"),
+			},
+		},
+		{
+			layer: 2,
+			label: "ETH",
+			name:  "Ethernet header",
+			fields: []string{
+				f(2, "Source MAC", "02:42:ac:12:00:01") + " → " + f(2, "Destination MAC", "02:42:ac:12:00:02"),
+				"type " + f(2, "EtherType", "0x0800 (IPv4)"),
+			},
+			trailer: "FCS",
+		},
+	}
+}
+
+// packetField returns a header value from the packet layers. An empty key
+// returns the layer's raw data.
+func packetField(layers []PacketLayer, layer int, key, fallback string) string {
+	for _, l := range layers {
+		if l.OSILayer != layer {
+			continue
+		}
+		if key == "" && l.RawData != "" {
+			return l.RawData
+		}
+		if value, ok := l.Headers[key]; ok && value != "" {
+			return value
+		}
+	}
+	return fallback
+}
+
+// steps is the number of animation frames: one per header on the way down,
+// one on the wire and one per header on the way up
+func (e encapsulation) steps() int {
+	return 2*len(e.headers) + 1
+}
+
+// depth returns how many headers wrap the payload at the current step, and
+// whether the frame is on the wire or at the receiver
+func (e encapsulation) depth() (depth int, onWire, receiving bool) {
+	n := len(e.headers)
+	switch {
+	case e.step < n:
+		return e.step + 1, false, false
+	case e.step == n:
+		return n, true, false
+	default:
+		return e.steps() - e.step, false, true
+	}
+}
+
+// layer returns the OSI layer the PDU is at for the current step
+func (e encapsulation) layer() int {
+	depth, onWire, _ := e.depth()
+	if onWire {
+		return 1
+	}
+	return e.headers[depth-1].layer
+}
+
+func (e *encapsulation) play() tea.Cmd {
+	if e.step == e.steps()-1 {
+		e.step = 0 // Replay from the start
+	}
+	e.playing = true
+	e.tickID++
+	return e.tick()
+}
+
+func (e *encapsulation) pause() {
+	e.playing = false
+	e.tickID++
+}
+
+func (e encapsulation) tick() tea.Cmd {
+	id := e.tickID
+	return tea.Tick(encapSpeeds[e.speed], func(time.Time) tea.Msg {
+		return encapTickMsg{id: id}
+	})
+}
+
+func (e encapsulation) update(msg tea.Msg) (encapsulation, tea.Cmd) {
+	switch msg := msg.(type) {
+	case encapTickMsg:
+		if msg.id != e.tickID || !e.playing {
+			return e, nil
+		}
+		e.step++
+		if e.step >= e.steps()-1 {
+			e.step = e.steps() - 1
+			e.pause()
+			return e, nil
+		}
+		return e, e.tick()
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case " ", "enter":
+			if e.playing {
+				e.pause()
+				return e, nil
+			}
+			return e, e.play()
+
+		case "right", "l", "n":
+			e.pause()
+			if e.step < e.steps()-1 {
+				e.step++
+			}
+
+		case "left", "h", "p":
+			e.pause()
+			if e.step > 0 {
+				e.step--
+			}
+
+		case "+", "=", "f":
+			if e.speed < len(encapSpeeds)-1 {
+				e.speed++
+			}
+			if e.playing {
+				e.tickID++
+				return e, e.tick()
+			}
+
+		case "-", "_", "s":
+			if e.speed > 0 {
+				e.speed--
+			}
+			if e.playing {
+				e.tickID++
+				return e, e.tick()
+			}
+
+		case "r", "home":
+			e.pause()
+			e.step = 0
+		}
+	}
+
+	return e, nil
+}
+
+// view renders the animation to fit within width and height
+func (e encapsulation) view(width, height int) string {
+	depth, onWire, receiving := e.depth()
+	layer := e.layer()
+	layerInfo := layerByNumber(layer)
+
+	var lines []string
+
+	side := "Sender ▼ encapsulating"
+	if onWire {
+		side = "On the wire"
+	} else if receiving {
+		side = "Receiver ▲ decapsulating"
+	}
+	lines = append(lines, styles.H3.Render("📦 Encapsulation")+"  "+styles.BodyMuted.Render(side))
+
+	lines = append(lines, fmt.Sprintf("%s %s",
+		styles.LayerBlock(layer).Render(fmt.Sprintf("L%d %s", layer, layerInfo.ShortName)),
+		styles.LayerText(layer).Render("PDU: "+pduNames[layer])))
+	lines = append(lines, styles.Body.Copy().UnsetMargins().Render(e.action()))
+	lines = append(lines, "")
+
+	if onWire {
+		lines = append(lines, styles.LayerText(1).Render(truncate(e.bits, width)))
+	} else {
+		lines = append(lines, e.frameView(depth, width))
+	}
+	lines = append(lines, "")
+
+	// Legend, outermost header first
+	for i := depth - 1; i >= 0; i-- {
+		h := e.headers[i]
+		line := fmt.Sprintf("%s %s: %s",
+			styles.LayerText(h.layer).Render(fmt.Sprintf("L%d", h.layer)),
+			h.name,
+			strings.Join(h.fields, " · "))
+		lines = append(lines, truncate(line, width))
+	}
+
+	// The stack diagram only fits on taller terminals
+	ladder := e.ladderView(layer, onWire, receiving)
+	if len(lines)+lipgloss.Height(ladder)+3 <= height {
+		lines = append(lines, "", ladder)
+	}
+
+	lines = append(lines, "", e.statusView())
+
+	return strings.Join(lines, "\n")
+}
+
+// action describes what happens at the current step
+func (e encapsulation) action() string {
+	depth, onWire, receiving := e.depth()
+	h := e.headers[depth-1]
+
+	switch {
+	case onWire:
+		return "The frame leaves the NIC as a stream of bits"
+	case !receiving && depth == 1:
+		return "The application writes an " + h.name
+	case !receiving:
+		return fmt.Sprintf("%s added in front of the layer %d %s", h.name, e.headers[depth-2].layer, pduNames[e.headers[depth-2].layer])
+	case depth == 1:
+		return "The " + h.name + " is delivered to the application"
+	default:
+		return fmt.Sprintf("Layer %d reads and removes the %s", h.layer, h.name)
+	}
+}
+
+// frameView renders the headers as coloured blocks, outermost on the left
+func (e encapsulation) frameView(depth, width int) string {
+	var blocks []string
+	for i := depth - 1; i >= 0; i-- {
+		h := e.headers[i]
+		blocks = append(blocks, styles.LayerBlock(h.layer).Render(h.label))
+	}
+	for i := 0; i < depth; i++ {
+		if h := e.headers[i]; h.trailer != "" {
+			blocks = append(blocks, styles.LayerBlock(h.layer).Render(h.trailer))
+		}
+	}
+
+	frame := strings.Join(blocks, "")
+	if lipgloss.Width(frame) > width {
+		// Too narrow for the full labels, show one letter per header
+		blocks = blocks[:0]
+		for i := depth - 1; i >= 0; i-- {
+			blocks = append(blocks, styles.LayerBlock(e.headers[i].layer).Render(e.headers[i].label[:1]))
+		}
+		frame = strings.Join(blocks, "")
+	}
+	return frame
+}
+
+// ladderView shows both stacks with the current layer marked
+func (e encapsulation) ladderView(layer int, onWire, receiving bool) string {
+	var rows []string
+	rows = append(rows, styles.BodyMuted.Render(fmt.Sprintf("  %-24s  %s", "Sender", "Receiver")))
+
+	for n := 7; n >= 1; n-- {
+		info := layerByNumber(n)
+		cell := fmt.Sprintf("%d %-13s %-7s", n, info.ShortName, pduNames[n])
+
+		sender, receiver := "  "+cell, "  "+cell
+		active := n == layer
+		if active && (onWire || !receiving) {
+			sender = styles.LayerText(n).Render("▶ " + cell)
+		}
+		if active && (onWire || receiving) {
+			receiver = styles.LayerText(n).Render("▶ " + cell)
+		}
+		if !active {
+			sender = styles.BodyDim.Render(sender)
+			receiver = styles.BodyDim.Render(receiver)
+		}
+		rows = append(rows, sender+"  "+receiver)
+	}
+	return strings.Join(rows, "\n")
+}
+
+func (e encapsulation) statusView() string {
+	state := "⏸ paused"
+	if e.playing {
+		state = "▶ playing"
+	}
+	source := "sample packet"
+	if e.fromCapture {
+		source = "captured packet"
+	}
+	return styles.BodyMuted.Render(fmt.Sprintf("Step %d/%d · %s · %s per step · %s",
+		e.step+1, e.steps(), state, encapSpeeds[e.speed], source))
+}
+
+// layerByNumber returns the OSI layer with the given number
+func layerByNumber(n int) OSILayer {
+	for _, l := range GetOSILayers() {
+		if l.Number == n {
+			return l
+		}
+	}
+	return OSILayer{Number: n}
+}
+
+// truncate shortens s to fit width cells
+func truncate(s string, width int) string {
+	if width <= 1 || lipgloss.Width(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && lipgloss.Width(string(runes))+1 > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
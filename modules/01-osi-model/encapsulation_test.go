@@ -0,0 +1,96 @@
+package osimodel
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"netlab/internal/tuitest"
+)
+
+func TestEncapsulationSteps(t *testing.T) {
+	e := newEncapsulation(getSamplePacketLayers(), false)
+
+	var got []string
+	for e.step = 0; e.step < e.steps(); e.step++ {
+		got = append(got, fmt.Sprintf("L%d %s", e.layer(), pduNames[e.layer()]))
+	}
+
+	want := []string{
+		"L7 data", "L6 data", "L4 segment", "L3 packet", "L2 frame", // Down the sender
+		"L1 bits",
+		"L2 frame", "L3 packet", "L4 segment", "L6 data", "L7 data", // Up the receiver
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("steps = %v\nwant %v", got, want)
+	}
+}
+
+func TestEncapsulationUsesPacketFields(t *testing.T) {
+	layers := getSamplePacketLayers()
+	layers[3].Headers["Destination Port"] = "8080"
+
+	e := newEncapsulation(layers, true)
+	tcp := e.headers[2]
+	if tcp.fields[0] != "38472 → 8080" {
+		t.Errorf("TCP ports = %q", tcp.fields[0])
+	}
+	// TLS isn't in the capture, so it keeps the typical values
+	if e.headers[1].fields[0] != "version 1.3" {
+		t.Errorf("TLS version = %q", e.headers[1].fields[0])
+	}
+}
+
+func TestEncapsulationKeys(t *testing.T) {
+	e := newEncapsulation(getSamplePacketLayers(), false)
+	e.play()
+	id := e.tickID
+
+	e, _ = e.update(tuitest.Key("right"))
+	if e.playing || e.step != 1 {
+		t.Fatalf("stepping should pause and advance: playing=%v step=%d", e.playing, e.step)
+	}
+	if e, _ = e.update(encapTickMsg{id: id}); e.step != 1 {
+		t.Errorf("stale tick advanced the animation to step %d", e.step)
+	}
+
+	e, _ = e.update(tuitest.Key("+"))
+	if e.speed != defaultEncapSpeed+1 {
+		t.Errorf("speed = %d", e.speed)
+	}
+
+	e, cmd := e.update(tuitest.Key(" "))
+	if !e.playing || cmd == nil {
+		t.Fatal("space should resume playing")
+	}
+	for i := 0; i < 20; i++ {
+		e, _ = e.update(encapTickMsg{id: e.tickID})
+	}
+	if e.playing || e.step != e.steps()-1 {
+		t.Errorf("playback should stop on the last step: playing=%v step=%d", e.playing, e.step)
+	}
+}
+
+func TestEncapsulationView(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		steps         int
+	}{
+		{"encap_100x30_http", 100, 30, 0},
+		{"encap_100x30_frame", 100, 30, 4},
+		{"encap_100x30_wire", 100, 30, 5},
+		{"encap_100x30_receiver_ip", 100, 30, 7},
+		{"encap_60x20_frame", 60, 20, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys := []string{"a", " "} // Open, then pause so nothing advances on its own
+			for i := 0; i < tt.steps; i++ {
+				keys = append(keys, "right")
+			}
+			tuitest.AssertView(t, tt.name, NewModel(), tt.width, tt.height, tuitest.Keys(keys...)...)
+		})
+	}
+}
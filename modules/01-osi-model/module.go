@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"strings"
 
+	"netlab/internal/utils"
+	"netlab/pkg/components"
 	"netlab/pkg/styles"
 
 	"github.com/charmbracelet/bubbles/list"
@@ -27,6 +29,10 @@ type Model struct {
 	layers         []OSILayer
 	selectedLayer  *OSILayer
 	showMnemonic   bool
+	showEncap      bool
+	encap          encapsulation
+	showAbout      bool
+	about          components.Document
 	ready          bool
 	width          int
 	height         int
@@ -37,6 +43,7 @@ type Model struct {
 	viewport       viewport.Model
 	quitting       bool
 	advanceToLab   bool
+	executor       utils.Executor
 }
 
 // NewModel creates a new OSI module model
@@ -68,8 +75,10 @@ func NewModel() Model {
 		list:          l,
 		layers:        layers,
 		selectedLayer: selectedLayer,
+		about:         components.NewDocument(moduleContent().README),
 		showMnemonic:  false,
 		ready:         false,
+		executor:      utils.ShellExecutor{},
 	}
 }
 
@@ -106,6 +115,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		// Update list size
 		m.list.SetSize(m.listWidth, m.listHeight)
+		m.about.SetSize(m.aboutSize())
 
 		// Initialize or update viewport
 		if !m.ready {
@@ -119,14 +129,32 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 	case tea.MouseMsg:
-		// Forward mouse events to viewport for text selection
 		var cmd tea.Cmd
+		if m.showAbout {
+			m.about, cmd = m.about.Update(msg)
+			return m, cmd
+		}
+		// Forward mouse events to viewport for text selection
 		m.viewport, cmd = m.viewport.Update(msg)
 		return m, cmd
 
+	case encapTickMsg:
+		var cmd tea.Cmd
+		m.encap, cmd = m.encap.update(msg)
+		return m, cmd
+
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "ctrl+c", "q", "esc":
+			if m.showEncap {
+				m.showEncap = false
+				m.encap.pause()
+				return m, nil
+			}
+			if m.showAbout {
+				m.showAbout = false
+				return m, nil
+			}
 			if m.showMnemonic {
 				m.showMnemonic = false
 				return m, nil
@@ -134,8 +162,43 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.quitting = true
 			return m, tea.Quit
 
+		case "a":
+			if m.showEncap {
+				m.showEncap = false
+				m.encap.pause()
+				return m, nil
+			}
+			m.showEncap = true
+			m.showMnemonic = false
+			m.showAbout = false
+			m.encap = newEncapsulation(loadEncapsulationLayers(m.executor))
+			return m, m.encap.play()
+
+		case "i":
+			if m.showEncap {
+				break
+			}
+			m.showAbout = !m.showAbout
+			m.showMnemonic = false
+			return m, nil
+		}
+
+		if m.showAbout {
+			var cmd tea.Cmd
+			m.about, cmd = m.about.Update(msg)
+			return m, cmd
+		}
+
+		if m.showEncap {
+			var cmd tea.Cmd
+			m.encap, cmd = m.encap.update(msg)
+			return m, cmd
+		}
+
+		switch msg.String() {
 		case "m":
 			m.showMnemonic = !m.showMnemonic
+			m.showAbout = false
 			return m, nil
 
 		case "v":
@@ -270,6 +333,14 @@ func (m Model) View() string {
 		return m.mnemonicView()
 	}
 
+	if m.showEncap {
+		return m.encapsulationView()
+	}
+
+	if m.showAbout {
+		return m.aboutView()
+	}
+
 	header := m.headerView()
 	footer := m.footerView()
 
@@ -301,13 +372,13 @@ func (m Model) View() string {
 
 func (m Model) headerView() string {
 	// Minimal header to prevent cutoff
-	title := styles.H2.
+	title := styles.H2.Copy().
 		Width(m.width-4).
 		Align(lipgloss.Center).
 		Margin(0, 0).
 		Render("NetLab OSI Model - Interactive Layer Explorer")
 
-	breadcrumb := styles.BodyMuted.
+	breadcrumb := styles.BodyMuted.Copy().
 		Margin(0, 0).
 		Render("NetLab > Fundamentals > OSI Model")
 
@@ -318,10 +389,19 @@ func (m Model) footerView() string {
 	helpKeys := []string{
 		styles.KeyBinding.Render("↑/↓") + " navigate",
 		styles.KeyBinding.Render("m") + " mnemonic",
+		styles.KeyBinding.Render("a") + " animate",
+		styles.KeyBinding.Render("i") + " about",
 		styles.KeyBinding.Render("v") + " packet lab",
 		styles.KeyBinding.Render("q") + " quit",
 	}
 	helpText := styles.Help.Render(strings.Join(helpKeys, " • "))
+	if lipgloss.Width(helpText) > m.width-2 {
+		helpText = styles.Help.Render(strings.Join(helpKeys, "  "))
+	}
+	if lipgloss.Width(helpText) > m.width-2 {
+		// The arrow keys are the least surprising binding, drop them first
+		helpText = styles.Help.Render(strings.Join(helpKeys[1:], "  "))
+	}
 
 	// Create separator line
 	line := strings.Repeat("─", m.width-2)
@@ -402,8 +482,105 @@ A - Application   (Layer 7)`
 	)
 }
 
+// encapsulationView frames the encapsulation animation like the mnemonic view
+func (m Model) encapsulationView() string {
+	headerHeight := 3
+	footerHeight := 2
+	availableHeight := m.height - headerHeight - footerHeight
+	availableWidth := m.width - 4
+
+	containerStyle := lipgloss.NewStyle().
+		Width(availableWidth).
+		Height(availableHeight).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(styles.Border).
+		Padding(0, 1)
+
+	helpKeys := []string{
+		styles.KeyBinding.Render("space") + " play/pause",
+		styles.KeyBinding.Render("←/→") + " step",
+		styles.KeyBinding.Render("+/-") + " speed",
+		styles.KeyBinding.Render("r") + " restart",
+		styles.KeyBinding.Render("a") + " back",
+	}
+	helpText := styles.Help.Copy().UnsetMargins().Render(strings.Join(helpKeys, " • "))
+	if lipgloss.Width(helpText) > m.width-2 {
+		helpText = styles.Help.Copy().UnsetMargins().Render(strings.Join(helpKeys, "  "))
+	}
+
+	separator := styles.BodyDim.Render(strings.Repeat("─", m.width-2))
+
+	// Inside the border and padding
+	content := m.encap.view(availableWidth-2, availableHeight)
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		m.headerView(),
+		containerStyle.Render(content),
+		separator,
+		helpText,
+	)
+}
+
+// aboutSize returns the space for the README inside the About frame, below
+// its title line
+func (m Model) aboutSize() (width, height int) {
+	headerHeight := 3
+	footerHeight := 2
+	return m.width - 6, m.height - headerHeight - footerHeight - 2
+}
+
+// aboutView shows the module README rendered from Markdown
+func (m Model) aboutView() string {
+	headerHeight := 3
+	footerHeight := 2
+	availableHeight := m.height - headerHeight - footerHeight
+	availableWidth := m.width - 4
+
+	containerStyle := lipgloss.NewStyle().
+		Width(availableWidth).
+		Height(availableHeight).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(styles.Border).
+		Padding(0, 1)
+
+	title := styles.H3.Render("📖 About this module") + "  " +
+		styles.BodyMuted.Render(fmt.Sprintf("%3.0f%%", m.about.ScrollPercent()*100))
+
+	helpKeys := []string{
+		styles.KeyBinding.Render("↑/↓") + " scroll",
+		styles.KeyBinding.Render("pgup/pgdn") + " page",
+		styles.KeyBinding.Render("i") + " back",
+		styles.KeyBinding.Render("q") + " close",
+	}
+	helpText := styles.Help.Copy().UnsetMargins().Render(strings.Join(helpKeys, " • "))
+	separator := styles.BodyDim.Render(strings.Repeat("─", m.width-2))
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		m.headerView(),
+		containerStyle.Render(title+"\n\n"+m.about.View()),
+		separator,
+		helpText,
+	)
+}
+
+// loadEncapsulationLayers returns packet layers for the animation and
+// whether they came from a lab capture
+func loadEncapsulationLayers(e utils.Executor) ([]PacketLayer, bool) {
+	layers, err := LoadPacketData(e)
+	if err != nil {
+		return getSamplePacketLayers(), false
+	}
+	return layers, true
+}
+
 // Run starts the interactive OSI model TUI
 func Run() error {
+	if err := ContentError(); err != nil {
+		return fmt.Errorf("loading module content: %w", err)
+	}
+
 	m := NewModel()
 
 	p := tea.NewProgram(
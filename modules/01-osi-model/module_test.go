@@ -0,0 +1,61 @@
+package osimodel
+
+import (
+	"testing"
+
+	"netlab/internal/tuitest"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestModelView(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		keys          []string
+	}{
+		{"osi_100x30", 100, 30, nil},
+		{"osi_160x45", 160, 45, nil},
+		{"osi_60x20", 60, 20, nil},
+		{"osi_59x20_too_small", 59, 20, nil},
+		{"osi_60x19_too_small", 60, 19, nil},
+		{"osi_100x30_transport", 100, 30, []string{"down", "down", "down"}},
+		{"osi_100x30_mnemonic", 100, 30, []string{"m"}},
+		{"osi_100x30_about", 100, 30, []string{"i"}},
+		{"osi_100x30_about_scrolled", 100, 30, []string{"i", "pgdown"}},
+		{"osi_60x20_about", 60, 20, []string{"i"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tuitest.AssertView(t, tt.name, NewModel(), tt.width, tt.height, tuitest.Keys(tt.keys...)...)
+		})
+	}
+}
+
+func TestModelShrinksBelowMinimum(t *testing.T) {
+	m := tuitest.Send(NewModel(), tuitest.Resize(100, 30), tuitest.Resize(40, 12))
+	tuitest.AssertGolden(t, "osi_resized_40x12", m.View())
+}
+
+func TestWalkthroughView(t *testing.T) {
+	labReady := labStatusMsg{ready: true}
+
+	tests := []struct {
+		name          string
+		width, height int
+		msgs          []tea.Msg
+	}{
+		{"walkthrough_100x30_no_lab", 100, 30, nil},
+		{"walkthrough_100x30_layer1", 100, 30, []tea.Msg{labReady}},
+		{"walkthrough_100x30_layer3", 100, 30, []tea.Msg{labReady, tuitest.Key("n"), tuitest.Key("n")}},
+		{"walkthrough_60x20", 60, 20, []tea.Msg{labReady}},
+		{"walkthrough_59x20_too_small", 59, 20, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tuitest.AssertView(t, tt.name, NewWalkthroughModel(), tt.width, tt.height, tt.msgs...)
+		})
+	}
+}
@@ -1,13 +1,17 @@
 package osimodel
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"os"
-	"os/exec"
-	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
+	"netlab/internal/utils"
 	"netlab/pkg/styles"
 
 	"github.com/charmbracelet/bubbles/viewport"
@@ -15,13 +19,20 @@ import (
 	"github.com/charmbracelet/lipgloss"
 )
 
+const (
+	labModuleID    = "01-osi-model"
+	labClusterName = "netlab-osi" // Must match CLUSTER_NAME in scripts/k8s_lab.sh
+	labScriptPath  = "./scripts/k8s_lab.sh"
+	labCapturePath = "modules/01-osi-model/assets/https-nginx.pcap"
+)
+
 // PacketLayer represents a parsed layer from a network packet
 type PacketLayer struct {
-	OSILayer    int
-	Name        string
-	Headers     map[string]string
-	RawData     string
-	Explanation string
+	OSILayer    int               `json:"osi_layer"`
+	Name        string            `json:"name"`
+	Headers     map[string]string `json:"headers"`
+	RawData     string            `json:"raw_data"`
+	Explanation string            `json:"explanation"`
 }
 
 // WalkthroughModel represents the packet walkthrough TUI
@@ -39,6 +50,10 @@ type WalkthroughModel struct {
 	labOutput      []string
 	outputViewport viewport.Model
 	showLabSetup   bool
+	labCtx         context.Context
+	labCancel      context.CancelFunc
+	labScript      string
+	executor       utils.Executor
 }
 
 func NewWalkthroughModel() WalkthroughModel {
@@ -50,6 +65,8 @@ func NewWalkthroughModel() WalkthroughModel {
 		currentIdx: 0,
 		ready:      false,
 		labReady:   false,
+		labScript:  labScriptPath,
+		executor:   utils.ShellExecutor{},
 	}
 }
 
@@ -113,6 +130,10 @@ func (m WalkthroughModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "ctrl+c", "q", "esc":
+			// Stop a setup in progress so it doesn't keep creating resources
+			if m.labRunning && m.labCancel != nil {
+				m.labCancel()
+			}
 			return m, tea.Quit
 
 		case "n", "right", "space":
@@ -137,6 +158,7 @@ func (m WalkthroughModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.labProgress = 0
 				m.labOutput = []string{}
 				m.labReady = false // Reset lab ready state
+				m.labCtx, m.labCancel = context.WithCancel(context.Background())
 				return m, m.runLabSetup()
 			}
 
@@ -166,7 +188,7 @@ func (m WalkthroughModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		if m.labReady {
 			// Load actual packet data and update layers
-			if layers, err := LoadPacketData(); err == nil {
+			if layers, err := LoadPacketData(m.executor); err == nil {
 				m.layers = layers
 			}
 			// Update viewport content
@@ -180,16 +202,26 @@ func (m WalkthroughModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.labProgress = 0
 		m.labOutput = append(m.labOutput, "🚀 Starting lab setup...")
 
+		// Record the cluster before it exists so an interrupted setup can be cleaned up
+		if err := utils.TrackLabResource(utils.LabResource{
+			Type:    utils.ResourceKindCluster,
+			Name:    labClusterName,
+			Context: "kind-" + labClusterName,
+			Module:  labModuleID,
+		}); err != nil {
+			m.labOutput = append(m.labOutput, fmt.Sprintf("⚠️  Could not record lab state: %s", err))
+		}
+
 		// Add debugging info
 		if wd, err := os.Getwd(); err == nil {
 			m.labOutput = append(m.labOutput, fmt.Sprintf("📁 Working directory: %s", wd))
 		}
 
 		// Check if script exists
-		if _, err := os.Stat("./scripts/k8s_lab.sh"); err == nil {
-			m.labOutput = append(m.labOutput, "✅ Found lab script: ./scripts/k8s_lab.sh")
+		if _, err := os.Stat(m.labScript); err == nil {
+			m.labOutput = append(m.labOutput, "✅ Found lab script: "+m.labScript)
 		} else {
-			m.labOutput = append(m.labOutput, "❌ Lab script not found: ./scripts/k8s_lab.sh")
+			m.labOutput = append(m.labOutput, "❌ Lab script not found: "+m.labScript)
 		}
 
 		m.updateOutputViewport()
@@ -205,10 +237,10 @@ func (m WalkthroughModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 		// Check if script exists
-		if _, err := os.Stat("./scripts/k8s_lab.sh"); err == nil {
-			m.labOutput = append(m.labOutput, "✅ Found lab script: ./scripts/k8s_lab.sh")
+		if _, err := os.Stat(m.labScript); err == nil {
+			m.labOutput = append(m.labOutput, "✅ Found lab script: "+m.labScript)
 		} else {
-			m.labOutput = append(m.labOutput, "❌ Lab script not found: ./scripts/k8s_lab.sh")
+			m.labOutput = append(m.labOutput, "❌ Lab script not found: "+m.labScript)
 		}
 
 		m.updateOutputViewport()
@@ -224,7 +256,7 @@ func (m WalkthroughModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.labOutput = append(m.labOutput, "✅ Lab setup completed successfully!")
 				m.showLabSetup = false // Only hide on success
 				// Load actual packet data and update layers
-				if layers, err := LoadPacketData(); err == nil {
+				if layers, err := LoadPacketData(m.executor); err == nil {
 					m.layers = layers
 				}
 				if m.ready {
@@ -308,13 +340,13 @@ func (m WalkthroughModel) View() string {
 
 func (m WalkthroughModel) headerView() string {
 	// Minimal header to prevent cutoff
-	title := styles.H2.
+	title := styles.H2.Copy().
 		Width(m.width-4).
 		Align(lipgloss.Center).
 		Margin(0, 0).
 		Render("NetLab OSI Model - Packet Analysis Lab")
 
-	breadcrumb := styles.BodyMuted.
+	breadcrumb := styles.BodyMuted.Copy().
 		Margin(0, 0).
 		Render("NetLab > OSI Model > Packet Walkthrough")
 
@@ -322,7 +354,7 @@ func (m WalkthroughModel) headerView() string {
 	current := m.currentIdx + 1
 	total := len(m.layers)
 	progress := fmt.Sprintf("Layer %d of %d", current, total)
-	progressText := styles.BodyMuted.
+	progressText := styles.BodyMuted.Copy().
 		Margin(0, 0).
 		Render(progress)
 
@@ -407,9 +439,16 @@ func (m WalkthroughModel) getLayerContent() string {
 		content.WriteString(styles.H2.Render("📋 Headers & Fields"))
 		content.WriteString("\n")
 
+		// Sorted so the fields don't shuffle between redraws
+		fields := make([]string, 0, len(layer.Headers))
+		for field := range layer.Headers {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+
 		var headerContent strings.Builder
-		for field, value := range layer.Headers {
-			headerContent.WriteString(fmt.Sprintf("%-20s: %s\n", field, value))
+		for _, field := range fields {
+			headerContent.WriteString(fmt.Sprintf("%-20s: %s\n", field, layer.Headers[field]))
 		}
 
 		content.WriteString(styles.ModuleExample.Render(headerContent.String()))
@@ -528,7 +567,7 @@ func (m WalkthroughModel) getLabSetupContent() string {
 func (m WalkthroughModel) checkLabStatus() tea.Cmd {
 	return func() tea.Msg {
 		// Check if packet capture file exists
-		_, err := os.Stat("modules/01-osi-model/assets/https-nginx.pcap")
+		_, err := os.Stat(labCapturePath)
 		return labStatusMsg{
 			ready: err == nil,
 			error: "",
@@ -553,7 +592,7 @@ func (m WalkthroughModel) runLabCleanup() tea.Cmd {
 func (m WalkthroughModel) streamLabOutput() tea.Cmd {
 	return func() tea.Msg {
 		// First check if script exists
-		if _, err := os.Stat("./scripts/k8s_lab.sh"); os.IsNotExist(err) {
+		if _, err := os.Stat(m.labScript); os.IsNotExist(err) {
 			return labOutputMsg{
 				output:   "📁 Lab script not found\n\nThe setup script './scripts/k8s_lab.sh' was not found.\n\nThis usually means:\n• You're not running from the NetLab project root directory\n• The script file is missing or moved\n\nTroubleshooting:\n• Make sure you're in the k8s-network-learning directory\n• Check if the file exists: 'ls -la scripts/'\n• If missing, clone the repository again or check if it was accidentally deleted",
 				progress: 0,
@@ -564,11 +603,14 @@ func (m WalkthroughModel) streamLabOutput() tea.Cmd {
 		}
 
 		// Run the k8s lab setup script
-		cmd := exec.Command("./scripts/k8s_lab.sh", "setup")
-		cmd.Dir = "." // Ensure we're in the right directory
-
-		// Execute and capture output
-		output, err := cmd.CombinedOutput()
+		ctx := m.labCtx
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		// Execute and capture output, keeping stdout and stderr interleaved
+		var buf bytes.Buffer
+		err := m.executor.Run(ctx, &buf, &buf, m.labScript, "setup")
+		output := buf.Bytes()
 
 		if err != nil {
 			// Parse the error and provide user-friendly explanation
@@ -584,7 +626,7 @@ func (m WalkthroughModel) streamLabOutput() tea.Cmd {
 		}
 
 		// Check final status
-		_, fileErr := os.Stat("modules/01-osi-model/assets/https-nginx.pcap")
+		_, fileErr := os.Stat(labCapturePath)
 		success := fileErr == nil
 
 		return labOutputMsg{
@@ -599,26 +641,43 @@ func (m WalkthroughModel) streamLabOutput() tea.Cmd {
 
 func (m WalkthroughModel) streamLabCleanup() tea.Cmd {
 	return func() tea.Msg {
-		// First check if script exists
-		if _, err := os.Stat("./scripts/k8s_lab.sh"); os.IsNotExist(err) {
+		// Without the script, delete the lab resources directly
+		if _, err := os.Stat(m.labScript); os.IsNotExist(err) {
+			resources := []utils.LabResource{{
+				Type:   utils.ResourceKindCluster,
+				Name:   labClusterName,
+				Module: labModuleID,
+			}}
+			if state, err := utils.LoadLabState(); err == nil && len(state.ForModule(labModuleID)) > 0 {
+				resources = state.ForModule(labModuleID)
+			}
+
+			lines, err := utils.CleanupLabResources(resources)
+			if err != nil {
+				return labOutputMsg{
+					output:   fmt.Sprintf("⚠️ Cleanup failed:\n\n%s\n\nYou can manually run: 'kind delete cluster --name %s'", strings.Join(lines, "\n"), labClusterName),
+					progress: 0,
+					finished: true,
+					error:    err.Error(),
+					success:  false,
+				}
+			}
 			return labOutputMsg{
-				output:   "📁 Lab script not found\n\nThe cleanup script './scripts/k8s_lab.sh' was not found.\n\nThis usually means:\n• You're not running from the NetLab project root directory\n• The script file is missing or moved\n\nTroubleshooting:\n• Make sure you're in the k8s-network-learning directory\n• You can manually run: 'kind delete cluster --name netlab-osi'",
-				progress: 0,
+				output:   strings.Join(lines, "\n"),
+				progress: 100,
 				finished: true,
-				error:    "📁 Lab script not found in expected location",
-				success:  false,
+				success:  true,
 			}
 		}
 
-		// Run the k8s lab cleanup script
-		cmd := exec.Command("./scripts/k8s_lab.sh", "cleanup")
-		cmd.Dir = "." // Ensure we're in the right directory
-
-		// Execute and capture output
-		output, err := cmd.CombinedOutput()
+		// Run the k8s lab cleanup script without prompts, there is no terminal to answer them
+		var buf bytes.Buffer
+		err := m.executor.Run(context.Background(), &buf, &buf, m.labScript, "cleanup", "--non-interactive")
+		output := buf.Bytes()
 
 		if err != nil {
-			// For cleanup, most errors are non-critical
+			// For cleanup, most errors are non-critical. The lab state is kept
+			// so 'netlab doctor' can still report anything left behind.
 			return labOutputMsg{
 				output:   fmt.Sprintf("⚠️ Cleanup completed with warnings:\n\n%s", string(output)),
 				progress: 100,
@@ -628,6 +687,10 @@ func (m WalkthroughModel) streamLabCleanup() tea.Cmd {
 			}
 		}
 
+		if err := utils.UntrackLabResources(labModuleID); err != nil {
+			output = append(output, []byte(fmt.Sprintf("\n⚠️  Could not update lab state: %s\n", err))...)
+		}
+
 		return labOutputMsg{
 			output:   string(output),
 			progress: 100,
@@ -784,82 +847,6 @@ type logExportMsg struct {
 	error   string
 }
 
-// getSamplePacketLayers returns sample packet data for demonstration
-func getSamplePacketLayers() []PacketLayer {
-	return []PacketLayer{
-		{
-			OSILayer: 1,
-			Name:     "Physical Layer",
-			Headers: map[string]string{
-				"Medium":       "Ethernet over copper wire",
-				"Encoding":     "Manchester encoding",
-				"Signal Level": "-2.5V to +2.5V",
-				"Bit Rate":     "1000 Mbps",
-			},
-			RawData:     "10101010 10101010 10101010 10101010 10111011 ...",
-			Explanation: "At the physical layer, data is transmitted as electrical signals over the network cable. This represents the raw bits being sent as voltage levels on the wire.",
-		},
-		{
-			OSILayer: 2,
-			Name:     "Data Link Layer (Ethernet)",
-			Headers: map[string]string{
-				"Destination MAC": "02:42:ac:12:00:02",
-				"Source MAC":      "02:42:ac:12:00:01",
-				"EtherType":       "0x0800 (IPv4)",
-				"Frame Length":    "74 bytes",
-				"FCS":             "0x12345678",
-			},
-			RawData:     "02:42:ac:12:00:02 02:42:ac:12:00:01 08:00 45:00...",
-			Explanation: "The Ethernet frame wraps the IP packet with MAC addresses for local network delivery. The source MAC is the sending container's interface, and the destination MAC is the nginx container's interface.",
-		},
-		{
-			OSILayer: 3,
-			Name:     "Network Layer (IP)",
-			Headers: map[string]string{
-				"Version":        "4 (IPv4)",
-				"Header Length":  "20 bytes",
-				"Source IP":      "10.244.0.5",
-				"Destination IP": "10.244.0.10",
-				"Protocol":       "6 (TCP)",
-				"TTL":            "64",
-				"Packet Length":  "60 bytes",
-			},
-			RawData:     "45:00:00:3c:00:00:40:00:40:06:b7:c8:0a:f4:00:05:0a:f4:00:0a",
-			Explanation: "The IP header contains routing information to deliver the packet from the busybox Pod IP to the nginx Pod IP within the Kubernetes cluster network.",
-		},
-		{
-			OSILayer: 4,
-			Name:     "Transport Layer (TCP)",
-			Headers: map[string]string{
-				"Source Port":      "38472",
-				"Destination Port": "80",
-				"Sequence Number":  "1234567890",
-				"Ack Number":       "0",
-				"Flags":            "SYN",
-				"Window Size":      "65535",
-				"Checksum":         "0x1234",
-			},
-			RawData:     "96:38:00:50:49:96:02:d2:00:00:00:00:a0:02:ff:ff:12:34:00:00",
-			Explanation: "The TCP header establishes a reliable connection to nginx on port 80. This is the SYN packet that starts the TCP three-way handshake for the HTTP connection.",
-		},
-		{
-			OSILayer: 7,
-			Name:     "Application Layer (HTTP)",
-			Headers: map[string]string{
-				"Method":       "GET",
-				"URI":          "/",
-				"HTTP Version": "1.1",
-				"Host":         "nginx",
-				"User-Agent":   "curl/7.64.0",
-				"Accept":       "*/*",
-				"Connection":   "keep-alive",
-			},
-			RawData:     "GET / HTTP/1.1\\r\\nHost: nginx\\r\\nUser-Agent: curl/7.64.0\\r\\nAccept: */*\\r\\n\\r\\n",
-			Explanation: "The HTTP GET request from the busybox Pod to fetch the nginx welcome page. This is the application-layer data that users actually care about - a web request.",
-		},
-	}
-}
-
 // RunWalkthrough starts the packet analysis walkthrough
 func RunWalkthrough() error {
 	m := NewWalkthroughModel()
@@ -870,22 +857,54 @@ func RunWalkthrough() error {
 		tea.WithMouseCellMotion(),
 	)
 
-	_, err := p.Run()
-	return err
+	if _, err := p.Run(); err != nil {
+		return err
+	}
+
+	return offerLabCleanup()
 }
 
-// LoadPacketData loads parsed packet data from the lab files
-func LoadPacketData() ([]PacketLayer, error) {
-	// Check if the packet capture file exists
-	pcapPath := filepath.Join("modules", "01-osi-model", "assets", "https-nginx.pcap")
-	if _, err := os.Stat(pcapPath); os.IsNotExist(err) {
-		// Return sample data if no lab data is available
-		return getSamplePacketLayers(), nil
+// offerLabCleanup asks whether to tear down the lab if it is still running
+// once the TUI has exited
+func offerLabCleanup() error {
+	orphans, err := utils.FindOrphanedLabResources()
+	var unknown *utils.UnknownLabStateError
+	if errors.As(err, &unknown) {
+		// The cluster may still be up; say so rather than fail the exit
+		fmt.Println(styles.StatusWarning.Render("⚠️  " + err.Error()))
+		fmt.Println(styles.BodyMuted.Render("Run 'netlab cleanup' once kind works again if the lab is still running."))
+	} else if err != nil {
+		return err
+	}
+
+	var resources []utils.LabResource
+	for _, r := range orphans {
+		if r.Module == labModuleID {
+			resources = append(resources, r)
+		}
+	}
+	if len(resources) == 0 {
+		return nil
+	}
+
+	fmt.Println(styles.StatusWarning.Render("⚠️  The packet lab is still running:"))
+	for _, r := range resources {
+		fmt.Printf("   • %s %s\n", r.Type, r.Name)
 	}
+	fmt.Print("Delete it now to free up memory? [y/N]: ")
 
-	// TODO: Parse actual packet data from pcap file
-	// For now, return sample data
-	return getSamplePacketLayers(), nil
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	if answer != "y" && answer != "yes" {
+		fmt.Println(styles.BodyMuted.Render("Lab left running. Run 'netlab cleanup' when you're done."))
+		return nil
+	}
+
+	lines, err := utils.CleanupLabResources(resources)
+	for _, line := range lines {
+		fmt.Println(line)
+	}
+	return err
 }
 
 // updateOutputViewport updates the output viewport with current lab output
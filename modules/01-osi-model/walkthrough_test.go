@@ -0,0 +1,167 @@
+package osimodel
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"netlab/internal/utils"
+)
+
+var errExit1 = errors.New("exit status 1")
+
+func TestParseLabError(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		err    error
+		want   string
+	}{
+		{"docker missing", "./scripts/k8s_lab.sh: line 40: docker: command not found", errExit1, "🐳 Docker is not installed"},
+		{"docker permission", "permission denied while trying to connect to the Docker daemon socket", errExit1, "🐳 Docker connection issue"},
+		{"docker cannot connect", "Cannot connect to the Docker daemon at unix:///var/run/docker.sock", errExit1, "🐳 Docker connection issue"},
+		{"docker starting", "Docker is not running. Attempting to start Docker...", errExit1, "🐳 Starting Docker"},
+		{"docker waiting", "Waiting for Docker to be ready (30s)...", errExit1, "🐳 Docker is starting"},
+		{"docker failed", "Docker failed to start after 60 seconds", errExit1, "🐳 Docker startup failed"},
+		{"kind missing", "kind: not found", errExit1, "☸️  kind is not installed"},
+		{"kubectl missing", "kubectl: not found", errExit1, "☸️  kubectl is not installed"},
+		{"tcpdump missing", "sh: tcpdump: not found", errExit1, "📡 tcpdump is not installed"},
+		{"tshark missing", "tshark: not found", errExit1, "📡 tshark is not installed"},
+		{"cluster creation", "Creating cluster \"netlab-osi\" ...\nERROR: failed to create cluster", errExit1, "☸️  Failed to create kind cluster"},
+		{"network unreachable", "dial tcp: network is unreachable", errExit1, "🌐 Network connectivity issue"},
+		{"network timeout", "network timeout pulling image", errExit1, "🌐 Network connectivity issue"},
+		{"permission denied", "./scripts/k8s_lab.sh: Permission denied", errExit1, "🔒 Permission denied"},
+		{"no space", "write /var/lib/containerd: no space left on device", errExit1, "💾 Insufficient disk space"},
+		{"disk full", "disk full", errExit1, "💾 Insufficient disk space"},
+		{"generic exit status", "something unexpected happened", errExit1, "❌ Lab setup script failed"},
+		{"last resort", "", errors.New("signal: killed"), "❌ Lab setup failed: signal: killed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userError, troubleshooting := parseLabError(tt.output, tt.err)
+			if userError != tt.want {
+				t.Errorf("userError = %q, want %q", userError, tt.want)
+			}
+			if troubleshooting == "" {
+				t.Error("no troubleshooting advice")
+			}
+		})
+	}
+}
+
+// scriptedWalkthrough returns a model whose lab script exists but is never
+// run for real
+func scriptedWalkthrough(t *testing.T, results map[string]utils.ScriptedResult) WalkthroughModel {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	script := filepath.Join(t.TempDir(), "k8s_lab.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\nexit 99\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	scripted := map[string]utils.ScriptedResult{}
+	for args, result := range results {
+		scripted[script+" "+args] = result
+	}
+
+	m := NewWalkthroughModel()
+	m.labScript = script
+	m.executor = utils.NewScriptedExecutor(scripted)
+	return m
+}
+
+func TestStreamLabOutput(t *testing.T) {
+	t.Run("setup fails", func(t *testing.T) {
+		m := scriptedWalkthrough(t, map[string]utils.ScriptedResult{
+			"setup": {
+				Stdout:   "🔍 Checking prerequisites...\n",
+				Stderr:   "kind: not found\n",
+				ExitCode: 1,
+			},
+		})
+
+		msg := m.streamLabOutput()().(labOutputMsg)
+		if msg.success || !msg.finished {
+			t.Fatalf("got %+v, want a finished failure", msg)
+		}
+		if msg.error != "☸️  kind is not installed" {
+			t.Errorf("error = %q", msg.error)
+		}
+		if !strings.Contains(msg.output, "Raw output:\n🔍 Checking prerequisites...\nkind: not found") {
+			t.Errorf("raw output missing from:\n%s", msg.output)
+		}
+	})
+
+	t.Run("setup succeeds", func(t *testing.T) {
+		m := scriptedWalkthrough(t, map[string]utils.ScriptedResult{
+			"setup": {Stdout: "✅ Lab setup completed\n"},
+		})
+
+		msg := m.streamLabOutput()().(labOutputMsg)
+		if msg.error != "" || msg.progress != 100 {
+			t.Errorf("got %+v", msg)
+		}
+	})
+
+	t.Run("script missing", func(t *testing.T) {
+		m := NewWalkthroughModel()
+		m.labScript = filepath.Join(t.TempDir(), "missing.sh")
+		m.executor = utils.NewScriptedExecutor(nil)
+
+		msg := m.streamLabOutput()().(labOutputMsg)
+		if msg.error != "📁 Lab script not found in expected location" {
+			t.Errorf("error = %q", msg.error)
+		}
+	})
+}
+
+func TestStreamLabCleanup(t *testing.T) {
+	t.Run("clean", func(t *testing.T) {
+		m := scriptedWalkthrough(t, map[string]utils.ScriptedResult{
+			"cleanup --non-interactive": {Stdout: "🧹 Deleting kind cluster netlab-osi\n"},
+		})
+		if err := utils.TrackLabResource(utils.LabResource{Type: utils.ResourceKindCluster, Name: labClusterName, Module: labModuleID}); err != nil {
+			t.Fatal(err)
+		}
+
+		msg := m.streamLabCleanup()().(labOutputMsg)
+		if !msg.success || msg.error != "" {
+			t.Fatalf("got %+v", msg)
+		}
+
+		state, err := utils.LoadLabState()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if left := state.ForModule(labModuleID); len(left) != 0 {
+			t.Errorf("lab state still tracks %v", left)
+		}
+	})
+
+	t.Run("warnings", func(t *testing.T) {
+		m := scriptedWalkthrough(t, map[string]utils.ScriptedResult{
+			"cleanup --non-interactive": {Stderr: "ERROR: unknown cluster \"netlab-osi\"\n", ExitCode: 1},
+		})
+		if err := utils.TrackLabResource(utils.LabResource{Type: utils.ResourceKindCluster, Name: labClusterName, Module: labModuleID}); err != nil {
+			t.Fatal(err)
+		}
+
+		msg := m.streamLabCleanup()().(labOutputMsg)
+		if !msg.success || !strings.HasPrefix(msg.output, "⚠️ Cleanup completed with warnings") {
+			t.Fatalf("got %+v", msg)
+		}
+
+		// A failed cleanup keeps the record so 'netlab cleanup' can retry
+		state, err := utils.LoadLabState()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(state.ForModule(labModuleID)) != 1 {
+			t.Errorf("lab state lost the cluster after a failed cleanup")
+		}
+	})
+}
@@ -0,0 +1,467 @@
+package tcpip
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Algorithm is a congestion control algorithm
+type Algorithm int
+
+const (
+	Reno Algorithm = iota
+	NewReno
+	Cubic
+	algorithmCount
+)
+
+var algorithmNames = [algorithmCount]string{"Reno", "NewReno", "CUBIC"}
+
+func (a Algorithm) String() string {
+	if a < 0 || a >= algorithmCount {
+		return fmt.Sprintf("Algorithm(%d)", int(a))
+	}
+	return algorithmNames[a]
+}
+
+const (
+	mss          = 1460 // Bytes per segment
+	initialCwnd  = 10   // Segments, as RFC 6928 allows
+	roundTrips   = 200  // How long a transfer runs, in base RTTs
+	maxSamples   = 2000 // Samples kept per transfer, at most
+	minRTO       = 0.2  // Seconds, as Linux uses
+	initialRTO   = 1.0  // Seconds, before the first RTT measurement
+	cubicC       = 0.4
+	cubicBeta    = 0.7
+	dupAckThresh = 3
+)
+
+// TransferConfig is a bulk transfer: the path it crosses and the sender's
+// algorithm
+type TransferConfig struct {
+	Algorithm  Algorithm
+	RTT        time.Duration // Round trip time with empty queues
+	Bandwidth  float64       // Bottleneck link, in Mbit/s
+	LossRate   float64       // Chance each segment is lost on the path, from 0 to 1
+	RecvWindow int           // Receive window, in bytes
+	Seed       int64         // Picks which segments are lost
+}
+
+// Sample is the sender's state at one moment of a transfer, in bytes
+type Sample struct {
+	Time     time.Duration
+	Cwnd     float64 // The highest since the previous sample
+	Ssthresh float64 // 0 until the first loss
+	InFlight float64 // The highest since the previous sample
+}
+
+// EventKind is what happened in a transfer event
+type EventKind int
+
+const (
+	EventFastRetransmit EventKind = iota
+	EventPartialAck
+	EventRecoveryEnd
+	EventTimeout
+	EventSlowStartExit
+)
+
+// TransferEvent is a loss recovery event during a transfer
+type TransferEvent struct {
+	Time    time.Duration
+	Kind    EventKind
+	Segment int // The segment retransmitted, or acknowledged up to
+	Detail  string
+}
+
+// Transfer is the outcome of a simulated bulk transfer
+type Transfer struct {
+	Config      TransferConfig
+	Duration    time.Duration
+	BDP         int // The path's bandwidth-delay product, in bytes
+	Queue       int // The bottleneck queue's capacity, in segments
+	Samples     []Sample
+	Events      []TransferEvent
+	Delivered   int64 // Bytes acknowledged
+	Lost        int   // Segments lost, on the path or to a full queue
+	QueueDrops  int   // Segments dropped by the full queue
+	Retransmits int
+}
+
+// Throughput is the goodput over the whole transfer, in Mbit/s
+func (t Transfer) Throughput() float64 {
+	if t.Duration <= 0 {
+		return 0
+	}
+	return float64(t.Delivered) * 8 / t.Duration.Seconds() / 1e6
+}
+
+// Count is how many events of a kind happened
+func (t Transfer) Count(kind EventKind) int {
+	n := 0
+	for _, e := range t.Events {
+		if e.Kind == kind {
+			n++
+		}
+	}
+	return n
+}
+
+// ackArrival is a cumulative ACK on its way back to the sender
+type ackArrival struct {
+	at   float64
+	ack  int     // The next segment the receiver expects
+	echo float64 // When the segment that triggered it was sent, as TCP timestamps echo
+}
+
+type ackQueue []ackArrival
+
+func (q ackQueue) Len() int            { return len(q) }
+func (q ackQueue) Less(i, j int) bool  { return q[i].at < q[j].at }
+func (q ackQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *ackQueue) Push(x interface{}) { *q = append(*q, x.(ackArrival)) }
+func (q *ackQueue) Pop() interface{} {
+	old := *q
+	a := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return a
+}
+
+// sender is a transfer in progress: the path, the receiver, and the sender's
+// window, with sequence numbers counted in segments
+type sender struct {
+	cfg      TransferConfig
+	out      *Transfer
+	rng      *rand.Rand
+	now      float64
+	end      float64
+	rtt      float64 // Base RTT, in seconds
+	tx       float64 // Time to put one segment on the bottleneck link
+	rwnd     float64 // Receive window, in segments
+	linkFree float64 // When the bottleneck link finishes what's queued
+	queued   []float64
+	acks     ackQueue
+
+	expected int          // The receiver's next in-order segment
+	received map[int]bool // Segments the receiver holds beyond it
+
+	una, nxt   int
+	maxSent    int
+	cwnd       float64
+	ssthresh   float64
+	dupAcks    int
+	recovering bool
+	recover    int // The highest segment sent when recovery began
+	srtt       float64
+	minRTT     float64
+	rttvar     float64
+	rto        float64
+	deadline   float64 // When the retransmission timer fires, 0 when stopped
+
+	wMax       float64 // CUBIC's window before its last reduction
+	epochStart float64 // When CUBIC's current growth curve began, -1 when not started
+	k          float64 // Seconds from epochStart until the curve reaches wMax
+
+	peakCwnd, peakFlight float64
+	nextSample           float64
+}
+
+// Simulate runs a bulk transfer for a fixed number of round trips, sending
+// as fast as the windows allow across a bottleneck link whose queue holds
+// one bandwidth-delay product
+func Simulate(cfg TransferConfig) Transfer {
+	rtt := cfg.RTT.Seconds()
+	bdp := cfg.Bandwidth * 1e6 / 8 * rtt
+	out := &Transfer{
+		Config:   cfg,
+		Duration: time.Duration(roundTrips) * cfg.RTT,
+		BDP:      int(bdp),
+		Queue:    max(int(bdp/mss), 4),
+	}
+	s := &sender{
+		cfg:        cfg,
+		out:        out,
+		rng:        rand.New(rand.NewSource(cfg.Seed)),
+		end:        out.Duration.Seconds(),
+		rtt:        rtt,
+		tx:         mss * 8 / (cfg.Bandwidth * 1e6),
+		rwnd:       float64(cfg.RecvWindow) / mss,
+		received:   map[int]bool{},
+		cwnd:       initialCwnd,
+		ssthresh:   math.Inf(1),
+		rto:        initialRTO,
+		epochStart: -1,
+	}
+	s.run()
+	out.Delivered = int64(s.una) * mss
+	return *out
+}
+
+func (s *sender) run() {
+	s.send()
+	s.sample(true)
+	for {
+		next := math.Inf(1)
+		if len(s.acks) > 0 {
+			next = s.acks[0].at
+		}
+		timeout := s.deadline > 0 && s.deadline < next
+		if timeout {
+			next = s.deadline
+		}
+		if next > s.end {
+			break
+		}
+		s.now = next
+		if timeout {
+			s.timeout()
+		} else {
+			s.onAck(heap.Pop(&s.acks).(ackArrival))
+		}
+		s.send()
+		s.sample(false)
+	}
+	s.now = s.end
+	s.sample(true)
+}
+
+// send fills the window with new segments
+func (s *sender) send() {
+	for float64(s.nxt-s.una) < math.Min(math.Floor(s.cwnd), math.Floor(s.rwnd)) {
+		s.transmit(s.nxt)
+		s.nxt++
+	}
+	s.peakFlight = math.Max(s.peakFlight, float64(s.nxt-s.una))
+	if s.deadline == 0 && s.nxt > s.una {
+		s.deadline = s.now + s.rto
+	}
+}
+
+// transmit puts one segment on the path. The bottleneck is first in, first
+// out, so the receiver sees segments in the order they're sent, and its ACK
+// can be worked out now and scheduled for when it would arrive.
+func (s *sender) transmit(seg int) {
+	if seg < s.maxSent {
+		s.out.Retransmits++
+	}
+	s.maxSent = max(s.maxSent, seg+1)
+
+	for len(s.queued) > 0 && s.queued[0] <= s.now {
+		s.queued = s.queued[1:]
+	}
+	if len(s.queued) >= s.out.Queue {
+		s.out.Lost++
+		s.out.QueueDrops++
+		return
+	}
+	departs := math.Max(s.now, s.linkFree) + s.tx
+	s.linkFree = departs
+	s.queued = append(s.queued, departs)
+	if s.rng.Float64() < s.cfg.LossRate {
+		s.out.Lost++
+		return
+	}
+
+	if seg == s.expected {
+		s.expected++
+		for s.received[s.expected] {
+			delete(s.received, s.expected)
+			s.expected++
+		}
+	} else if seg > s.expected {
+		s.received[seg] = true
+	}
+	heap.Push(&s.acks, ackArrival{at: departs + s.rtt, ack: s.expected, echo: s.now})
+}
+
+// onAck handles a cumulative ACK: new data acknowledged, or a duplicate
+func (s *sender) onAck(a ackArrival) {
+	ack := a.ack
+	switch {
+	case ack > s.una:
+		acked := ack - s.una
+		s.measure(s.now - a.echo)
+		s.una = ack
+		s.nxt = max(s.nxt, s.una)
+
+		switch {
+		case s.recovering && (s.cfg.Algorithm == Reno || ack > s.recover):
+			s.recovering = false
+			s.cwnd = s.ssthresh
+			s.epochStart = -1
+			s.event(EventRecoveryEnd, ack, fmt.Sprintf("ACK for everything up to segment %d: recovery ends, cwnd %s", ack, kb(s.cwnd)))
+		case s.recovering:
+			// NewReno: the ACK covers some of what was outstanding, so the
+			// next hole is lost too, and is retransmitted without waiting
+			// for three more duplicates
+			s.transmit(s.una)
+			s.cwnd = math.Max(s.cwnd-float64(acked)+1, 1)
+			s.event(EventPartialAck, s.una, fmt.Sprintf("Partial ACK up to segment %d: segment %d is lost too, retransmitted at once", ack, s.una))
+		default:
+			s.grow(acked)
+			s.dupAcks = 0
+		}
+		s.deadline = 0
+		if s.nxt > s.una {
+			s.deadline = s.now + s.rto
+		}
+
+	case ack == s.una && s.nxt > s.una:
+		s.dupAcks++
+		switch {
+		case s.recovering:
+			// Each duplicate means a segment left the network, so another
+			// may go in
+			s.cwnd++
+		case s.dupAcks == dupAckThresh && (s.cfg.Algorithm == Reno || ack > s.recover):
+			s.fastRetransmit()
+		}
+	}
+	s.peakCwnd = math.Max(s.peakCwnd, s.cwnd)
+}
+
+// fastRetransmit resends the segment three duplicate ACKs point at, and
+// enters fast recovery with a reduced window
+func (s *sender) fastRetransmit() {
+	before := s.ssthresh
+	s.reduce()
+	s.recover = s.nxt - 1
+	s.recovering = true
+	s.transmit(s.una)
+	s.cwnd = s.ssthresh + dupAckThresh
+	detail := fmt.Sprintf("3 duplicate ACKs: segment %d retransmitted, ssthresh %s, fast recovery with cwnd %s", s.una, kb(s.ssthresh), kb(s.cwnd))
+	if math.IsInf(before, 1) {
+		detail += "; slow start ends"
+	}
+	s.event(EventFastRetransmit, s.una, detail)
+}
+
+// timeout is the retransmission timer firing: the window collapses to one
+// segment and everything outstanding is sent again
+func (s *sender) timeout() {
+	s.reduce()
+	s.recover = s.nxt - 1
+	s.cwnd = 1
+	s.recovering = false
+	s.dupAcks = 0
+	s.nxt = s.una
+	s.epochStart = -1
+	s.rto = math.Min(s.rto*2, 60)
+	s.deadline = 0
+	s.event(EventTimeout, s.una, fmt.Sprintf("No ACK for segment %d: cwnd back to 1 segment, ssthresh %s, timer backs off to %.0f ms", s.una, kb(s.ssthresh), s.rto*1000))
+}
+
+// reduce sets ssthresh after a loss: half the data in flight for Reno and
+// NewReno, 70% of the window for CUBIC
+func (s *sender) reduce() {
+	if s.cfg.Algorithm == Cubic {
+		s.wMax = s.cwnd
+		s.ssthresh = math.Max(s.cwnd*cubicBeta, 2)
+		return
+	}
+	s.ssthresh = math.Max(float64(s.nxt-s.una)/2, 2)
+}
+
+// grow opens the window for newly acknowledged segments, unless the
+// receive window already limits the sender
+func (s *sender) grow(acked int) {
+	if s.cwnd >= s.rwnd {
+		return
+	}
+	if s.cwnd < s.ssthresh {
+		s.cwnd += float64(acked)
+		return
+	}
+	if s.cfg.Algorithm != Cubic {
+		s.cwnd += float64(acked) / s.cwnd
+		return
+	}
+
+	// CUBIC grows along a cubic curve centred on wMax, fast while far below
+	// it, flat near it, then probing faster beyond it
+	if s.epochStart < 0 {
+		s.epochStart = s.now
+		s.k = math.Cbrt(math.Max(s.wMax-s.cwnd, 0) / cubicC)
+	}
+	t := s.now - s.epochStart
+	target := cubicC*math.Pow(t+s.rtt-s.k, 3) + s.wMax
+	if target > s.cwnd {
+		s.cwnd += (target - s.cwnd) / s.cwnd * float64(acked)
+	}
+	// Never grow slower than Reno would
+	if reno := s.wMax*cubicBeta + 3*(1-cubicBeta)/(1+cubicBeta)*t/s.rtt; reno > s.cwnd {
+		s.cwnd = reno
+	}
+}
+
+// hystart ends CUBIC's slow start before it overflows the queue, as Linux
+// does, once round trips take noticeably longer than the shortest seen
+func (s *sender) hystart(rtt float64) {
+	if s.cfg.Algorithm != Cubic || !math.IsInf(s.ssthresh, 1) || s.cwnd < 16 {
+		return
+	}
+	if rise := math.Min(math.Max(s.minRTT/8, 0.004), 0.016); rtt < s.minRTT+rise {
+		return
+	}
+	s.ssthresh = s.cwnd
+	s.wMax = s.cwnd
+	s.event(EventSlowStartExit, s.una, fmt.Sprintf("HyStart: round trips grew from %.0f to %.0f ms as the queue filled, so slow start ends at cwnd %s before anything is lost", s.minRTT*1000, rtt*1000, kb(s.cwnd)))
+}
+
+// measure updates the RTT estimate and the retransmission timeout, as RFC
+// 6298 does
+func (s *sender) measure(rtt float64) {
+	if s.minRTT == 0 || rtt < s.minRTT {
+		s.minRTT = rtt
+	}
+	s.hystart(rtt)
+	if s.srtt == 0 {
+		s.srtt = rtt
+		s.rttvar = rtt / 2
+	} else {
+		s.rttvar = 0.75*s.rttvar + 0.25*math.Abs(s.srtt-rtt)
+		s.srtt = 0.875*s.srtt + 0.125*rtt
+	}
+	s.rto = math.Max(s.srtt+4*s.rttvar, minRTO)
+}
+
+func (s *sender) event(kind EventKind, seg int, detail string) {
+	s.out.Events = append(s.out.Events, TransferEvent{Time: seconds(s.now), Kind: kind, Segment: seg, Detail: detail})
+	s.sample(true)
+}
+
+// sample records the sender's state, at most maxSamples times over the
+// transfer unless forced
+func (s *sender) sample(force bool) {
+	s.peakCwnd = math.Max(s.peakCwnd, s.cwnd)
+	s.peakFlight = math.Max(s.peakFlight, float64(s.nxt-s.una))
+	if !force && s.now < s.nextSample {
+		return
+	}
+	ssthresh := s.ssthresh
+	if math.IsInf(ssthresh, 1) {
+		ssthresh = 0
+	}
+	s.out.Samples = append(s.out.Samples, Sample{
+		Time:     seconds(s.now),
+		Cwnd:     s.peakCwnd * mss,
+		Ssthresh: ssthresh * mss,
+		InFlight: s.peakFlight * mss,
+	})
+	s.peakCwnd = s.cwnd
+	s.peakFlight = float64(s.nxt - s.una)
+	s.nextSample = s.now + s.end/maxSamples
+}
+
+// seconds converts simulated time to a duration
+func seconds(t float64) time.Duration {
+	return time.Duration(t * float64(time.Second))
+}
+
+// kb formats a window in segments as kilobytes
+func kb(segments float64) string {
+	return fmt.Sprintf("%.0f KB", segments*mss/1000)
+}
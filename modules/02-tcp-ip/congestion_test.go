@@ -0,0 +1,102 @@
+package tcpip
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// path is a 40 ms, 10 Mbit/s path with a window that never limits the
+// sender
+func path(algorithm Algorithm, loss float64) TransferConfig {
+	return TransferConfig{Algorithm: algorithm, RTT: 40 * time.Millisecond, Bandwidth: 10, LossRate: loss, RecvWindow: 4096000, Seed: 1}
+}
+
+func TestSimulateDeterministic(t *testing.T) {
+	cfg := path(NewReno, 0.01)
+	if a, b := Simulate(cfg), Simulate(cfg); !reflect.DeepEqual(a, b) {
+		t.Error("the same config gave different transfers")
+	}
+	other := cfg
+	other.Seed = 2
+	if a, b := Simulate(cfg), Simulate(other); a.Delivered == b.Delivered && a.Lost == b.Lost {
+		t.Error("another seed lost the same segments")
+	}
+}
+
+func TestSimulateLimits(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      TransferConfig
+		min, max float64 // Throughput bounds, in Mbit/s
+	}{
+		{"link", path(Cubic, 0), 8, 10},
+		// 16 KB per 40 ms round trip is 3.2 Mbit/s
+		{"receive window", TransferConfig{Algorithm: Reno, RTT: 40 * time.Millisecond, Bandwidth: 10, RecvWindow: 16000}, 2.5, 3.2},
+		// MSS/RTT × 1.22/√0.02 is about 2.5 Mbit/s
+		{"random loss", path(Reno, 0.02), 0.5, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Simulate(tt.cfg)
+			if got := r.Throughput(); got < tt.min || got > tt.max {
+				t.Errorf("%.2f Mbit/s, want %.1f to %.1f", got, tt.min, tt.max)
+			}
+			if r.Duration != roundTrips*tt.cfg.RTT {
+				t.Errorf("ran for %s, want %d round trips", r.Duration, roundTrips)
+			}
+		})
+	}
+}
+
+func TestSimulateRecovery(t *testing.T) {
+	t.Run("receive window limited", func(t *testing.T) {
+		r := Simulate(TransferConfig{Algorithm: Reno, RTT: 40 * time.Millisecond, Bandwidth: 10, RecvWindow: 16000})
+		if r.Lost != 0 || len(r.Events) != 0 {
+			t.Errorf("%d lost, %d events; want none", r.Lost, len(r.Events))
+		}
+		for _, s := range r.Samples {
+			if s.InFlight > 16000 {
+				t.Fatalf("%.0f bytes in flight at %s, beyond the receive window", s.InFlight, s.Time)
+			}
+		}
+	})
+
+	t.Run("Reno has no partial ACKs", func(t *testing.T) {
+		r := Simulate(path(Reno, 0.01))
+		if r.Count(EventFastRetransmit) == 0 || r.Count(EventPartialAck) != 0 {
+			t.Errorf("%d fast retransmits, %d partial ACKs", r.Count(EventFastRetransmit), r.Count(EventPartialAck))
+		}
+	})
+
+	t.Run("NewReno retransmits on partial ACKs", func(t *testing.T) {
+		r := Simulate(path(NewReno, 0))
+		if r.Count(EventPartialAck) == 0 {
+			t.Error("no partial ACKs after slow start overflowed the queue")
+		}
+	})
+
+	t.Run("CUBIC leaves slow start early", func(t *testing.T) {
+		r := Simulate(path(Cubic, 0))
+		if len(r.Events) == 0 || r.Events[0].Kind != EventSlowStartExit {
+			t.Fatalf("first event %+v, want HyStart", r.Events)
+		}
+		if r.QueueDrops > 10 {
+			t.Errorf("%d queue drops", r.QueueDrops)
+		}
+	})
+
+	t.Run("fast retransmit halves the window", func(t *testing.T) {
+		r := Simulate(path(Reno, 0.001))
+		for i, s := range r.Samples {
+			if i > 0 && s.Ssthresh > 0 && r.Samples[i-1].Ssthresh == 0 {
+				if s.Ssthresh > r.Samples[i-1].InFlight/2+mss {
+					t.Errorf("ssthresh %.0f after %.0f in flight", s.Ssthresh, r.Samples[i-1].InFlight)
+				}
+				return
+			}
+		}
+		t.Error("ssthresh was never set")
+	})
+}
@@ -0,0 +1,233 @@
+// Package tcpip is the TCP/IP Stack Deep Dive learning module
+package tcpip
+
+import (
+	"embed"
+	"fmt"
+	"strings"
+
+	"netlab/internal/packs"
+	"netlab/pkg/components"
+	"netlab/pkg/styles"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+//go:embed README.md content
+var embedded embed.FS
+
+// screen is one of the module's tabs, in the order tab cycles through them
+type screen int
+
+const (
+	screenStates screen = iota
+	screenCongestion
+	screenLesson
+	screenQuiz
+	screenCount
+)
+
+var screenNames = map[screen]string{
+	screenStates:     "State Machine",
+	screenCongestion: "Congestion",
+	screenLesson:     "Lesson",
+	screenQuiz:       "Quiz",
+}
+
+// Model is the TUI for the TCP/IP Stack Deep Dive module: the TCP state
+// machine, the congestion control simulator, its README as the lesson, and its quiz
+type Model struct {
+	machine  machine
+	transfer transferSim
+	lesson   components.Document
+	quiz     components.Quiz
+	screen   screen
+	width    int
+	height   int
+	quitting bool
+}
+
+// NewModel creates a new TCP/IP Stack Deep Dive module model
+func NewModel() (Model, error) {
+	readme, err := embedded.ReadFile("README.md")
+	if err != nil {
+		return Model{}, err
+	}
+	questions, err := loadQuiz()
+	if err != nil {
+		return Model{}, err
+	}
+
+	return Model{
+		machine:  newMachine(),
+		transfer: newTransferSim(),
+		lesson:   components.NewDocument(string(readme)),
+		quiz:     components.NewQuiz(questions),
+	}, nil
+}
+
+// loadQuiz reads content/quiz.yaml
+func loadQuiz() ([]components.QuizQuestion, error) {
+	data, err := embedded.ReadFile("content/quiz.yaml")
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := packs.ParseQuiz(data)
+	if err != nil {
+		return nil, fmt.Errorf("content/quiz.yaml: %w", err)
+	}
+
+	questions := make([]components.QuizQuestion, len(parsed))
+	for i, q := range parsed {
+		questions[i] = components.QuizQuestion{
+			Question:    q.Question,
+			Options:     q.Options,
+			Answer:      q.Answer,
+			Explanation: q.Explanation,
+			SeeAlso:     q.Lesson,
+		}
+	}
+	return questions, nil
+}
+
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+// contentSize returns the space inside the frame
+func (m Model) contentSize() (width, height int) {
+	// Header (2), frame border (2), separator and help (2)
+	return m.width - 4, m.height - 6
+}
+
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.lesson.SetSize(m.contentSize())
+		m.machine.SetSize(m.contentSize())
+		m.transfer.SetSize(m.contentSize())
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "q", "esc":
+			m.quitting = true
+			return m, tea.Quit
+		case "tab":
+			m.screen = (m.screen + 1) % screenCount
+			return m, nil
+		case "shift+tab":
+			m.screen = (m.screen + screenCount - 1) % screenCount
+			return m, nil
+		}
+
+		if m.screen == screenQuiz {
+			m.quiz = m.quiz.Update(msg)
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	switch m.screen {
+	case screenStates:
+		m.machine, cmd = m.machine.Update(msg)
+	case screenCongestion:
+		m.transfer, cmd = m.transfer.Update(msg)
+	case screenLesson:
+		m.lesson, cmd = m.lesson.Update(msg)
+	}
+	return m, cmd
+}
+
+func (m Model) View() string {
+	if m.width < 60 || m.height < 20 {
+		return fmt.Sprintf("⚠️  Terminal too small (%dx%d), please resize to at least 60x20", m.width, m.height)
+	}
+
+	width, height := m.contentSize()
+	var body string
+	switch m.screen {
+	case screenStates:
+		body = m.machine.View()
+	case screenCongestion:
+		body = m.transfer.View()
+	case screenLesson:
+		body = m.lesson.View()
+	case screenQuiz:
+		body = m.quiz.View(width)
+	}
+
+	frame := lipgloss.NewStyle().
+		Width(m.width-2).
+		Height(height).
+		Padding(0, 1).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(styles.Border)
+
+	return lipgloss.JoinVertical(lipgloss.Left, m.headerView(), frame.Render(body), m.footerView())
+}
+
+func (m Model) headerView() string {
+	title := styles.H2.Copy().
+		Width(m.width-4).
+		Align(lipgloss.Center).
+		Margin(0, 0).
+		Render("NetLab TCP/IP Stack Deep Dive")
+
+	breadcrumb := styles.BodyMuted.Copy().
+		Margin(0, 0).
+		Render("NetLab > TCP/IP Stack Deep Dive > " + screenNames[m.screen])
+
+	return lipgloss.JoinVertical(lipgloss.Left, title, breadcrumb)
+}
+
+func (m Model) footerView() string {
+	next := styles.KeyBinding.Render("tab") + " " + strings.ToLower(screenNames[(m.screen+1)%screenCount])
+
+	var helpKeys []string
+	switch m.screen {
+	case screenStates:
+		helpKeys = append(m.machine.helpKeys(), next, styles.KeyBinding.Render("q")+" quit")
+	case screenCongestion:
+		helpKeys = append(m.transfer.helpKeys(), next, styles.KeyBinding.Render("q")+" quit")
+	case screenLesson:
+		helpKeys = []string{
+			styles.KeyBinding.Render("↑/↓") + " scroll",
+			next,
+			styles.KeyBinding.Render("q") + " quit",
+		}
+	case screenQuiz:
+		helpKeys = []string{
+			styles.KeyBinding.Render("↑/↓") + " choose",
+			styles.KeyBinding.Render("enter") + " answer",
+			next,
+			styles.KeyBinding.Render("q") + " quit",
+		}
+	}
+	helpText := styles.Help.Copy().UnsetMargins().Render(strings.Join(helpKeys, " • "))
+	if lipgloss.Width(helpText) > m.width-2 {
+		helpText = styles.Help.Copy().UnsetMargins().Render(strings.Join(helpKeys, "  "))
+	}
+
+	separator := styles.BodyDim.Render(strings.Repeat("─", m.width-2))
+	return lipgloss.JoinVertical(lipgloss.Left, separator, helpText)
+}
+
+// Run starts the TCP/IP Stack Deep Dive module
+func Run() error {
+	m, err := NewModel()
+	if err != nil {
+		return fmt.Errorf("loading module content: %w", err)
+	}
+
+	p := tea.NewProgram(
+		m,
+		tea.WithAltScreen(),
+		tea.WithMouseCellMotion(),
+	)
+	_, err = p.Run()
+	return err
+}
@@ -0,0 +1,58 @@
+package tcpip
+
+import (
+	"testing"
+
+	"netlab/internal/tuitest"
+)
+
+// handshake opens a connection: the server listens, the client connects, and
+// the three segments of the handshake are delivered
+var handshake = []string{"right", "l", "left", "c", "enter", "enter", "enter"}
+
+func TestModelView(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		keys          []string
+	}{
+		{"tcpip_100x30", 100, 30, nil},
+		{"tcpip_60x20", 60, 20, nil},
+		{"tcpip_59x20_too_small", 59, 20, nil},
+		{"tcpip_100x30_syn_sent", 100, 30, []string{"right", "l", "left", "c"}},
+		{"tcpip_100x30_established", 100, 30, handshake},
+		{"tcpip_60x20_established", 60, 20, handshake},
+		{"tcpip_100x30_invalid", 100, 30, []string{"x"}},
+		{"tcpip_100x30_refused", 100, 30, []string{"c", "enter", "enter"}},
+		{"tcpip_100x30_lost", 100, 30, []string{"right", "l", "left", "c", "d"}},
+		{"tcpip_100x30_time_wait", 100, 30, append(handshake, "x", "enter", "enter", "right", "x", "enter")},
+		{"tcpip_100x30_congestion", 100, 30, []string{"tab"}},
+		{"tcpip_60x20_congestion", 60, 20, []string{"tab"}},
+		{"tcpip_100x30_congestion_reno_loss", 100, 30, []string{"tab", "down", "down", "right", "right", "right", "up", "up", "up"}},
+		{"tcpip_100x30_congestion_small_window", 100, 30, []string{"tab", "left", "down", "down", "down"}},
+		{"tcpip_100x30_congestion_events", 100, 30, []string{"tab", "right", "right", "right", "up", "up", "up", "up", "pgdown"}},
+		{"tcpip_100x30_lesson", 100, 30, []string{"tab", "tab"}},
+		{"tcpip_100x30_quiz", 100, 30, []string{"tab", "tab", "tab"}},
+		{"tcpip_100x30_quiz_answered", 100, 30, []string{"tab", "tab", "tab", "enter"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewModel()
+			if err != nil {
+				t.Fatal(err)
+			}
+			tuitest.AssertView(t, tt.name, m, tt.width, tt.height, tuitest.Keys(tt.keys...)...)
+		})
+	}
+}
+
+func TestQuizContent(t *testing.T) {
+	questions, err := loadQuiz()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(questions) == 0 {
+		t.Error("quiz has no questions")
+	}
+}
@@ -0,0 +1,303 @@
+package tcpip
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"netlab/pkg/styles"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/reflow/wordwrap"
+	"github.com/muesli/reflow/wrap"
+)
+
+// endpointAddresses are what the client and server are shown as
+var endpointAddresses = [2]string{"10.0.0.1:49152", "10.0.0.2:80"}
+
+var (
+	currentStyle = lipgloss.NewStyle().Foreground(styles.Primary).Bold(true)
+	peerStyle    = lipgloss.NewStyle().Foreground(styles.Accent)
+)
+
+// actionKeys maps the keys that make the selected endpoint act to what they
+// do
+var actionKeys = map[string]Action{
+	"c": ActConnect,
+	"l": ActListen,
+	"x": ActClose,
+	"t": ActTimeout,
+	"s": ActSendSYN,
+	"a": ActSendACK,
+	"f": ActSendFIN,
+	"r": ActSendRST,
+}
+
+// ladderWidth is the width of the states ladder beside the events
+const ladderWidth = 28
+
+// machine is the TCP state machine: a client and a server that the learner
+// makes act, the segments on the wire between them, and what each event did
+// to either side and why
+type machine struct {
+	conn   Connection
+	side   int // The endpoint keys act on
+	notice string
+	body   viewport.Model
+	width  int
+}
+
+func newMachine() machine {
+	return machine{conn: NewConnection(), body: viewport.New(0, 0)}
+}
+
+// SetSize fits the state machine into width x height cells
+func (m *machine) SetSize(width, height int) {
+	m.width = width
+	m.body.Width = width
+	m.body.Height = height - 2 // The endpoints bar and the gap below it
+	m.body.SetContent(m.bodyView())
+}
+
+func (m machine) Update(msg tea.Msg) (machine, tea.Cmd) {
+	var cmd tea.Cmd
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		m.notice = ""
+		switch key := msg.String(); key {
+		case "left":
+			m.side = Client
+		case "right":
+			m.side = Server
+		case "enter":
+			if _, ok := m.conn.Deliver(); !ok {
+				m.notice = "Nothing is on the wire to deliver."
+			}
+		case "d":
+			if _, ok := m.conn.Drop(); !ok {
+				m.notice = "Nothing is on the wire to lose."
+			}
+		case "n":
+			m.conn = NewConnection()
+		case "up", "down", "pgup", "pgdown":
+			m.body, cmd = m.body.Update(msg)
+			return m, cmd
+		default:
+			action, ok := actionKeys[key]
+			if !ok {
+				return m, nil
+			}
+			m.conn.Do(m.side, action)
+		}
+		m.body.SetContent(m.bodyView())
+		m.body.GotoTop()
+		return m, nil
+
+	case tea.MouseMsg:
+		m.body, cmd = m.body.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+// helpKeys lists the keys for the footer; the rest are listed in the body
+func (m machine) helpKeys() []string {
+	key := styles.KeyBinding.Render
+	return []string{key("←/→") + " endpoint", key("enter") + " deliver"}
+}
+
+func (m machine) View() string {
+	return m.barView() + "\n\n" + m.body.View()
+}
+
+// barView shows both endpoints and their states, marking the one keys act
+// on
+func (m machine) barView() string {
+	parts := make([]string, 2)
+	for side, e := range m.conn.Endpoints {
+		text := fmt.Sprintf("%s %s  %s", e.Name, endpointAddresses[side], e.State)
+		if m.width < 80 {
+			text = e.Name + " " + e.State.String()
+		}
+		if side == m.side {
+			parts[side] = currentStyle.Render("▸ " + text)
+		} else {
+			parts[side] = styles.BodyMuted.Render("  " + text)
+		}
+	}
+	return parts[Client] + "    " + parts[Server]
+}
+
+// bodyView puts the states ladder beside the events when there's room, and
+// leaves it out when there isn't: the bar already shows both states
+func (m machine) bodyView() string {
+	if m.width < 80 {
+		return m.eventsView(m.width) + "\n\n" + m.keysView()
+	}
+	left := m.ladderView() + "\n\n" + m.keysView()
+	right := m.eventsView(m.width - ladderWidth - 3)
+	return lipgloss.JoinHorizontal(lipgloss.Top, lipgloss.NewStyle().Width(ladderWidth).Render(left), "   ", right)
+}
+
+// ladderView lists every state with a marker where each endpoint is, then
+// their sequence numbers
+func (m machine) ladderView() string {
+	cell := func(text string) string { return fmt.Sprintf("%8s", text) }
+	header := pad("", 12)
+	for side, e := range m.conn.Endpoints {
+		name := cell(e.Name)
+		if side == m.side {
+			name = currentStyle.Render(name)
+		}
+		header += name
+	}
+
+	lines := []string{styles.BodyMuted.Render(header)}
+	for s := Closed; s < stateCount; s++ {
+		name := styles.BodyDim.Render(pad(s.String(), 12))
+		var cells string
+		for side, e := range m.conn.Endpoints {
+			switch {
+			case e.State == s && side == m.side:
+				cells += currentStyle.Render(cell("●"))
+			case e.State == s:
+				cells += peerStyle.Render(cell("●"))
+			default:
+				cells += styles.BodyDim.Render(cell("·"))
+			}
+			if e.State == s {
+				name = pad(s.String(), 12)
+			}
+		}
+		lines = append(lines, name+cells)
+	}
+
+	lines = append(lines, "")
+	for _, row := range []struct {
+		name  string
+		value func(Endpoint) uint32
+	}{
+		{"snd.nxt", func(e Endpoint) uint32 { return e.SndNxt }},
+		{"rcv.nxt", func(e Endpoint) uint32 { return e.RcvNxt }},
+	} {
+		line := styles.BodyMuted.Render(pad(row.name, 12))
+		for _, e := range m.conn.Endpoints {
+			value := "—"
+			if v := row.value(e); v != 0 {
+				value = strconv.FormatUint(uint64(v), 10)
+			}
+			line += cell(value)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// keysView lists the keys that make the selected endpoint act
+func (m machine) keysView() string {
+	key := styles.KeyBinding.Render
+	lines := []string{
+		key("c") + " connect  " + key("l") + " listen  " + key("x") + " close",
+		key("s a f r") + " send SYN ACK FIN RST",
+		key("t") + " timer  " + key("d") + " lose  " + key("n") + " reset",
+	}
+	return strings.Join(lines, "\n")
+}
+
+// eventsView explains the last event, then lists the segments on the wire
+// and everything that happened, newest first
+func (m machine) eventsView(width int) string {
+	var sections []string
+	if m.notice != "" {
+		sections = append(sections, paragraph(styles.StatusInfo, m.notice, width))
+	}
+	sections = append(sections, m.lastView(width))
+
+	wire := []string{styles.H3.Render("On the wire")}
+	if len(m.conn.Wire) == 0 {
+		wire = append(wire, styles.BodyDim.Render("Nothing in flight"))
+	}
+	for i, f := range m.conn.Wire {
+		line := fmt.Sprintf("%s → %s  %s", m.conn.Endpoints[f.From].Name, m.conn.Endpoints[1-f.From].Name, f.Segment)
+		if i == 0 {
+			line = currentStyle.Render(line) + styles.BodyDim.Render("  next")
+		}
+		wire = append(wire, line)
+	}
+	sections = append(sections, strings.Join(wire, "\n"))
+
+	if len(m.conn.History) > 0 {
+		history := []string{styles.H3.Render("History")}
+		for i := len(m.conn.History) - 1; i >= 0; i-- {
+			entry := m.conn.History[i]
+			style := lipgloss.NewStyle()
+			switch {
+			case entry.Lost:
+				style = styles.BodyDim
+			case entry.Invalid:
+				style = styles.StatusWarning
+			}
+			history = append(history, paragraph(style, fmt.Sprintf("%d. %s", i+1, entrySummary(entry)), width))
+		}
+		sections = append(sections, strings.Join(history, "\n"))
+	}
+	return strings.Join(sections, "\n\n")
+}
+
+// lastView explains the most recent event, or how to start
+func (m machine) lastView(width int) string {
+	text := lipgloss.NewStyle()
+	if len(m.conn.History) == 0 {
+		return paragraph(styles.BodyMuted, "Both endpoints start CLOSED. Select the server with → and press l to listen, then select the client with ← and press c to connect. Each segment waits on the wire until enter delivers it.", width)
+	}
+
+	entry := m.conn.History[len(m.conn.History)-1]
+	if entry.Lost {
+		return paragraph(styles.StatusWarning, "✗ "+entrySummary(entry), width) + "\n" +
+			paragraph(text, "Neither endpoint notices. If the segment needed an answer, its sender sends it again when the retransmission timer fires (t). A lost ACK is only noticed when what it acknowledged comes again.", width)
+	}
+
+	title := paragraph(styles.StatusSuccess, "✓ "+entrySummary(entry), width)
+	if entry.Invalid {
+		title = paragraph(styles.StatusWarning, "✗ "+entrySummary(entry), width)
+	}
+	return title + "\n" + paragraph(text, entry.Why, width)
+}
+
+// entrySummary describes one history entry in a line
+func entrySummary(entry Entry) string {
+	if entry.Lost {
+		return "the network lost " + entry.Endpoint + "'s " + entry.Segment.String()
+	}
+	summary := entry.Endpoint + " " + entry.Event + ": "
+	switch {
+	case entry.From != entry.To:
+		summary += entry.From.String() + " → " + entry.To.String()
+	case entry.Invalid:
+		summary += "invalid in " + entry.From.String()
+	default:
+		summary += "stays " + entry.From.String()
+	}
+	for _, seg := range entry.Sent {
+		summary += ", sends " + seg.String()
+	}
+	return summary
+}
+
+// paragraph wraps text to the width at spaces only, since lipgloss also
+// breaks at hyphens and can overrun the width, then styles it
+func paragraph(style lipgloss.Style, text string, width int) string {
+	width = max(width, 1)
+	w := wordwrap.NewWriter(width)
+	w.Breakpoints = nil
+	w.Write([]byte(text))
+	w.Close()
+	return style.Copy().Width(width).Render(wrap.String(w.String(), width))
+}
+
+// pad right-pads text to a display width
+func pad(text string, width int) string {
+	return text + strings.Repeat(" ", max(width-lipgloss.Width(text), 0))
+}
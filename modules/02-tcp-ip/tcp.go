@@ -0,0 +1,548 @@
+package tcpip
+
+import (
+	"fmt"
+	"strings"
+)
+
+// State is a TCP endpoint's connection state, named as in RFC 9293
+type State int
+
+const (
+	Closed State = iota
+	Listen
+	SynSent
+	SynReceived
+	Established
+	FinWait1
+	FinWait2
+	CloseWait
+	Closing
+	LastAck
+	TimeWait
+	stateCount
+)
+
+var stateNames = [stateCount]string{
+	"CLOSED", "LISTEN", "SYN_SENT", "SYN_RCVD", "ESTABLISHED",
+	"FIN_WAIT_1", "FIN_WAIT_2", "CLOSE_WAIT", "CLOSING", "LAST_ACK", "TIME_WAIT",
+}
+
+func (s State) String() string {
+	if s < 0 || s >= stateCount {
+		return fmt.Sprintf("State(%d)", int(s))
+	}
+	return stateNames[s]
+}
+
+// Flags are a segment's control bits
+type Flags uint8
+
+const (
+	SYN Flags = 1 << iota
+	FIN
+	RST
+	ACK
+)
+
+// String joins the flags set, such as "SYN+ACK"
+func (f Flags) String() string {
+	var names []string
+	for _, flag := range []struct {
+		bit  Flags
+		name string
+	}{{SYN, "SYN"}, {FIN, "FIN"}, {RST, "RST"}, {ACK, "ACK"}} {
+		if f&flag.bit != 0 {
+			names = append(names, flag.name)
+		}
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, "+")
+}
+
+// Segment is a TCP segment without data: its flags and sequence numbers
+type Segment struct {
+	Flags Flags
+	Seq   uint32
+	Ack   uint32 // Only meaningful with ACK set
+}
+
+// Has reports whether all the given flags are set
+func (s Segment) Has(f Flags) bool {
+	return s.Flags&f == f
+}
+
+// Len is how many sequence numbers the segment takes: one each for SYN and
+// FIN
+func (s Segment) Len() uint32 {
+	var n uint32
+	if s.Has(SYN) {
+		n++
+	}
+	if s.Has(FIN) {
+		n++
+	}
+	return n
+}
+
+func (s Segment) String() string {
+	if s.Has(ACK) {
+		return fmt.Sprintf("%s seq=%d ack=%d", s.Flags, s.Seq, s.Ack)
+	}
+	return fmt.Sprintf("%s seq=%d", s.Flags, s.Seq)
+}
+
+// Transition is what one event did to an endpoint: the states it moved
+// between, the segments it sent and why
+type Transition struct {
+	Endpoint string
+	Event    string
+	From, To State
+	Sent     []Segment
+	Why      string
+	Invalid  bool // The event isn't allowed in From, and was refused or dropped
+}
+
+// Endpoint is one end of a connection: its state and sequence space
+type Endpoint struct {
+	Name    string
+	State   State
+	ISS     uint32 // Initial send sequence number
+	SndNxt  uint32 // Next sequence number to send
+	RcvNxt  uint32 // Next sequence number expected from the peer, 0 before its SYN
+	passive bool   // Reached SYN_RCVD from LISTEN, so a reset goes back there
+}
+
+// NewEndpoint creates a closed endpoint
+func NewEndpoint(name string, iss uint32) Endpoint {
+	return Endpoint{Name: name, ISS: iss, SndNxt: iss}
+}
+
+// closeTo ends the connection, leaving the endpoint closed or listening
+func (e *Endpoint) closeTo(state State) {
+	e.State = state
+	e.SndNxt = e.ISS
+	e.RcvNxt = 0
+	e.passive = false
+}
+
+// ack is an ACK of everything received so far
+func (e Endpoint) ack() Segment {
+	return Segment{Flags: ACK, Seq: e.SndNxt, Ack: e.RcvNxt}
+}
+
+// synAck is the endpoint's SYN, acknowledging the peer's
+func (e Endpoint) synAck() Segment {
+	return Segment{Flags: SYN | ACK, Seq: e.ISS, Ack: e.RcvNxt}
+}
+
+// finAck is the endpoint's FIN, once sent
+func (e Endpoint) finAck() Segment {
+	return Segment{Flags: FIN | ACK, Seq: e.SndNxt - 1, Ack: e.RcvNxt}
+}
+
+// resetFor is the RST answering a segment that belongs to no connection,
+// numbered so the sender accepts it
+func resetFor(seg Segment) Segment {
+	if seg.Has(ACK) {
+		return Segment{Flags: RST, Seq: seg.Ack}
+	}
+	return Segment{Flags: RST | ACK, Ack: seg.Seq + seg.Len()}
+}
+
+// Connect is the application's active open
+func (e *Endpoint) Connect() Transition {
+	t := Transition{Endpoint: e.Name, Event: "connect()", From: e.State}
+	switch e.State {
+	case Closed:
+		e.State = SynSent
+		e.SndNxt = e.ISS + 1
+		t.Sent = []Segment{{Flags: SYN, Seq: e.ISS}}
+		t.Why = fmt.Sprintf("Active open: the application calls connect(), so the endpoint sends a SYN with its initial sequence number, %d, and waits for the peer's SYN+ACK.", e.ISS)
+	case Listen:
+		t.Invalid = true
+		t.Why = "A listening socket accepts connections rather than making them, so connect() fails. Connecting needs a socket of its own."
+	case SynSent, SynReceived:
+		t.Invalid = true
+		t.Why = "A connection is already being set up on this socket, so connect() fails with EALREADY."
+	case Established, CloseWait:
+		t.Invalid = true
+		t.Why = "This socket is already connected, so connect() fails with EISCONN."
+	default:
+		t.Invalid = true
+		t.Why = fmt.Sprintf("The application already closed this connection, and it's finishing in %s. A new connection needs a new socket.", e.State)
+	}
+	t.To = e.State
+	return t
+}
+
+// Listen is the application's passive open
+func (e *Endpoint) Listen() Transition {
+	t := Transition{Endpoint: e.Name, Event: "listen()", From: e.State}
+	switch e.State {
+	case Closed:
+		e.State = Listen
+		t.Why = "Passive open: the application calls listen(), and the socket waits for a SYN."
+	case Listen:
+		t.Invalid = true
+		t.Why = "The socket is already listening."
+	default:
+		t.Invalid = true
+		t.Why = fmt.Sprintf("listen() needs a socket without a connection, and this one is in %s.", e.State)
+	}
+	t.To = e.State
+	return t
+}
+
+// Close is the application closing its side of the connection
+func (e *Endpoint) Close() Transition {
+	t := Transition{Endpoint: e.Name, Event: "close()", From: e.State}
+	switch e.State {
+	case Closed:
+		t.Invalid = true
+		t.Why = "There's no connection to close."
+	case Listen:
+		e.closeTo(Closed)
+		t.Why = "The application stops listening. SYNs that arrive from now on are refused with a RST."
+	case SynSent:
+		e.closeTo(Closed)
+		t.Why = "The application gives up before the peer answered, so the attempt is abandoned without sending anything."
+	case SynReceived, Established:
+		e.State = FinWait1
+		e.SndNxt++
+		t.Sent = []Segment{e.finAck()}
+		t.Why = "Active close: the application calls close(), so the endpoint sends a FIN and waits in FIN_WAIT_1 for it to be acknowledged. It can still receive until the peer closes too."
+	case CloseWait:
+		e.State = LastAck
+		e.SndNxt++
+		t.Sent = []Segment{e.finAck()}
+		t.Why = "The peer already closed its side, and now the application does too: the endpoint sends its own FIN and waits in LAST_ACK for the final ACK."
+	default:
+		t.Invalid = true
+		t.Why = fmt.Sprintf("The application already closed this side, and the connection is finishing in %s.", e.State)
+	}
+	t.To = e.State
+	return t
+}
+
+// Timeout fires the endpoint's timer: TIME_WAIT ends, or an unacknowledged
+// SYN or FIN is retransmitted
+func (e *Endpoint) Timeout() Transition {
+	t := Transition{Endpoint: e.Name, Event: "timer fires", From: e.State}
+	switch e.State {
+	case TimeWait:
+		e.closeTo(Closed)
+		t.Why = "Twice the maximum segment lifetime passed without the FIN coming again, so the connection is gone and its address and port can be reused."
+	case SynSent:
+		t.Sent = []Segment{{Flags: SYN, Seq: e.ISS}}
+		t.Why = "No SYN+ACK arrived before the retransmission timer fired, so the SYN is sent again."
+	case SynReceived:
+		t.Sent = []Segment{e.synAck()}
+		t.Why = "No ACK arrived for the SYN+ACK before the retransmission timer fired, so it's sent again."
+	case FinWait1, Closing, LastAck:
+		t.Sent = []Segment{e.finAck()}
+		t.Why = "Our FIN hasn't been acknowledged before the retransmission timer fired, so it's sent again."
+	default:
+		t.Invalid = true
+		t.Why = fmt.Sprintf("No timer is running in %s: nothing is waiting to be acknowledged or to expire.", e.State)
+	}
+	t.To = e.State
+	return t
+}
+
+// Forge builds a bare segment from the endpoint's current sequence numbers,
+// as a packet tool would send it, without going through the state machine
+func (e Endpoint) Forge(flags Flags) Segment {
+	if flags&(FIN|RST) != 0 && e.RcvNxt != 0 {
+		flags |= ACK
+	}
+	return Segment{Flags: flags, Seq: e.SndNxt, Ack: e.RcvNxt}
+}
+
+// Receive is a segment arriving from the peer
+func (e *Endpoint) Receive(seg Segment) Transition {
+	t := Transition{Endpoint: e.Name, Event: seg.String() + " arrives", From: e.State}
+	switch e.State {
+	case Closed:
+		e.receiveClosed(seg, &t)
+	case Listen:
+		e.receiveListen(seg, &t)
+	case SynSent:
+		e.receiveSynSent(seg, &t)
+	default:
+		e.receiveSynchronized(seg, &t)
+	}
+	t.To = e.State
+	return t
+}
+
+func (e *Endpoint) receiveClosed(seg Segment, t *Transition) {
+	t.Invalid = true
+	if seg.Has(RST) {
+		t.Why = "There's no connection for the RST to reset, so it's dropped."
+		return
+	}
+	t.Sent = []Segment{resetFor(seg)}
+	t.Why = "Nothing is listening here, so the segment is answered with a RST. For a SYN, this is the peer's connection refused."
+}
+
+func (e *Endpoint) receiveListen(seg Segment, t *Transition) {
+	switch {
+	case seg.Has(RST):
+		t.Invalid = true
+		t.Why = "A listening socket has no connection to reset, so the RST is dropped."
+	case seg.Has(ACK):
+		t.Invalid = true
+		t.Sent = []Segment{resetFor(seg)}
+		t.Why = "A listening socket hasn't sent anything for an ACK to acknowledge, so the segment belongs to no connection and is answered with a RST."
+	case seg.Has(SYN):
+		e.State = SynReceived
+		e.passive = true
+		e.SndNxt = e.ISS + 1
+		e.RcvNxt = seg.Seq + 1
+		t.Sent = []Segment{e.synAck()}
+		t.Why = fmt.Sprintf("A SYN reaches the listening socket: it notes the peer's sequence number, acknowledges it with ack=%d, sends its own SYN with sequence number %d, and waits in SYN_RCVD for the last ACK of the handshake.", e.RcvNxt, e.ISS)
+	default:
+		t.Invalid = true
+		t.Why = "A listening socket only acts on SYNs, so the segment is dropped."
+	}
+}
+
+func (e *Endpoint) receiveSynSent(seg Segment, t *Transition) {
+	switch {
+	case seg.Has(ACK) && seg.Ack != e.SndNxt:
+		t.Invalid = true
+		if seg.Has(RST) {
+			t.Why = fmt.Sprintf("The RST acknowledges %d, not our SYN (%d), so it could be from an old connection and is dropped.", seg.Ack, e.SndNxt)
+			return
+		}
+		t.Sent = []Segment{resetFor(seg)}
+		t.Why = fmt.Sprintf("The segment acknowledges %d, but our SYN needs ack=%d, so it belongs to some other connection and is answered with a RST.", seg.Ack, e.SndNxt)
+	case seg.Has(RST | ACK):
+		e.closeTo(Closed)
+		t.Why = "The peer refused the connection: the RST acknowledges our SYN, so connect() fails with connection refused."
+	case seg.Has(RST):
+		t.Invalid = true
+		t.Why = "A RST that doesn't acknowledge our SYN could be forged, so SYN_SENT drops it."
+	case seg.Has(SYN | ACK):
+		e.State = Established
+		e.RcvNxt = seg.Seq + 1
+		t.Sent = []Segment{e.ack()}
+		t.Why = "The SYN+ACK acknowledges our SYN and carries the peer's, so the endpoint acknowledges it and the connection is established on this side. The peer is established once that ACK arrives."
+	case seg.Has(SYN):
+		e.State = SynReceived
+		e.RcvNxt = seg.Seq + 1
+		t.Sent = []Segment{e.synAck()}
+		t.Why = "Both sides sent a SYN at once, a simultaneous open: the endpoint acknowledges the peer's SYN with a SYN+ACK and waits in SYN_RCVD for its own to be acknowledged."
+	default:
+		t.Invalid = true
+		t.Why = "SYN_SENT is waiting for the peer's SYN, so a segment without one is dropped."
+	}
+}
+
+// receiveSynchronized handles segments once both SYNs have been seen, from
+// SYN_RCVD on
+func (e *Endpoint) receiveSynchronized(seg Segment, t *Transition) {
+	switch {
+	case seg.Has(RST) && seg.Seq != e.RcvNxt:
+		t.Invalid = true
+		t.Why = fmt.Sprintf("The RST's sequence number %d isn't the %d expected next, so it's dropped: someone who can't see the connection can't reset it.", seg.Seq, e.RcvNxt)
+	case seg.Has(RST) && e.State == SynReceived && e.passive:
+		e.closeTo(Listen)
+		t.Why = "The peer reset the half-open connection, so the socket goes back to listening."
+	case seg.Has(RST):
+		e.closeTo(Closed)
+		t.Why = "The peer reset the connection: it's gone at once, without the FIN exchange, and the application sees connection reset by peer."
+
+	case seg.Has(SYN|ACK) && e.State == SynReceived && seg.Ack == e.SndNxt && seg.Seq+1 == e.RcvNxt:
+		e.State = Established
+		t.Why = "The peer's SYN+ACK acknowledges our SYN, completing the simultaneous open: each side's SYN has been acknowledged, so the connection is established without another ACK."
+	case seg.Has(SYN) && e.State == SynReceived && seg.Seq+1 == e.RcvNxt:
+		t.Sent = []Segment{e.synAck()}
+		t.Why = "The peer sent its SYN again, so our SYN+ACK was probably lost, and it's sent again."
+	case seg.Has(SYN):
+		t.Invalid = true
+		t.Sent = []Segment{e.ack()}
+		t.Why = "A SYN on a connection that's already synchronized could be a stale duplicate or forged, so rather than act on it, the endpoint answers with an ACK saying where it is: a challenge ACK."
+
+	case seg.Seq != e.RcvNxt && e.State == TimeWait && seg.Has(FIN):
+		t.Sent = []Segment{e.ack()}
+		t.Why = "The peer sent its FIN again because our ACK of it was lost. This is what TIME_WAIT waits for: the ACK is sent again."
+	case seg.Seq != e.RcvNxt:
+		t.Invalid = true
+		t.Sent = []Segment{e.ack()}
+		t.Why = fmt.Sprintf("Sequence number %d isn't the %d expected next, so the segment is a duplicate or out of the window. It's dropped, and an ACK tells the peer where we are.", seg.Seq, e.RcvNxt)
+	case !seg.Has(ACK):
+		t.Invalid = true
+		t.Why = "After the handshake every segment carries an ACK, so one without it is dropped."
+	case seg.Ack > e.SndNxt:
+		t.Invalid = true
+		t.Sent = []Segment{e.ack()}
+		t.Why = fmt.Sprintf("It acknowledges %d, beyond anything sent (the next sequence number is %d), so it's dropped with an ACK.", seg.Ack, e.SndNxt)
+	case e.State == SynReceived && seg.Ack != e.SndNxt:
+		t.Invalid = true
+		t.Sent = []Segment{resetFor(seg)}
+		t.Why = fmt.Sprintf("It acknowledges %d, but our SYN+ACK needs ack=%d, so it's answered with a RST.", seg.Ack, e.SndNxt)
+	default:
+		e.receiveAck(seg, t)
+	}
+}
+
+// receiveAck processes an acceptable segment's ACK, then its FIN
+func (e *Endpoint) receiveAck(seg Segment, t *Transition) {
+	var why []string
+	if seg.Ack == e.SndNxt {
+		switch e.State {
+		case SynReceived:
+			e.State = Established
+			why = append(why, "The ACK completes the three-way handshake: the connection is established, and accept() returns it.")
+		case FinWait1:
+			e.State = FinWait2
+			why = append(why, "The ACK covers our FIN, so our side is closed. The peer can still send until it closes too.")
+		case Closing:
+			e.State = TimeWait
+			why = append(why, "The ACK covers our FIN, and the peer's FIN was already acknowledged, so the endpoint waits in TIME_WAIT in case that ACK was lost.")
+		case LastAck:
+			e.closeTo(Closed)
+			t.Why = "The ACK covers our FIN, the last step of the close: the connection is gone."
+			return
+		}
+	} else if e.State == FinWait1 || e.State == Closing || e.State == LastAck {
+		why = append(why, fmt.Sprintf("The ACK is for %d, not our FIN (ack=%d), so the FIN is still waiting to be acknowledged.", seg.Ack, e.SndNxt))
+	}
+
+	if seg.Has(FIN) {
+		e.RcvNxt++
+		t.Sent = []Segment{e.ack()}
+		switch e.State {
+		case SynReceived, Established:
+			e.State = CloseWait
+			why = append(why, "The peer closed its side with a FIN, which is acknowledged. The endpoint waits in CLOSE_WAIT until its application closes too, and can still send until then.")
+		case FinWait1:
+			e.State = Closing
+			why = append(why, "The peer's FIN crossed ours, a simultaneous close: it's acknowledged, and the endpoint waits in CLOSING for the ACK of its own FIN.")
+		case FinWait2:
+			e.State = TimeWait
+			why = append(why, "The peer's FIN closes its side too. It's acknowledged, and the endpoint waits in TIME_WAIT for twice the maximum segment lifetime, in case the ACK is lost and the FIN comes again.")
+		default:
+			why = append(why, "The peer had already closed its side, so the FIN is just acknowledged.")
+		}
+	}
+
+	if len(why) == 0 {
+		why = append(why, fmt.Sprintf("The ACK acknowledges nothing new, so %s carries on unchanged.", e.State))
+	}
+	t.Why = strings.Join(why, " ")
+}
+
+// Client and Server index a connection's endpoints
+const (
+	Client = 0
+	Server = 1
+)
+
+// InFlight is a segment on the wire, sent by one of the endpoints
+type InFlight struct {
+	From    int
+	Segment Segment
+}
+
+// Action is something an endpoint is made to do: an application call, its
+// timer, or sending a forged segment
+type Action int
+
+const (
+	ActConnect Action = iota
+	ActListen
+	ActClose
+	ActTimeout
+	ActSendSYN
+	ActSendACK
+	ActSendFIN
+	ActSendRST
+)
+
+// Entry is one event in a connection's history: an endpoint's transition,
+// or a segment lost on the wire
+type Entry struct {
+	Transition
+	Side    int  // The endpoint that moved, or that sent the lost segment
+	Lost    bool // Only Segment is set, and no endpoint moved
+	Segment Segment
+}
+
+// Connection is a client and a server, the segments on the wire between
+// them, and everything that happened to either
+type Connection struct {
+	Endpoints [2]Endpoint
+	Wire      []InFlight // Oldest first, delivered in order
+	History   []Entry
+}
+
+// NewConnection creates a closed client and server
+func NewConnection() Connection {
+	return Connection{Endpoints: [2]Endpoint{NewEndpoint("client", 1000), NewEndpoint("server", 5000)}}
+}
+
+// Do makes one endpoint act, putting anything it sends on the wire
+func (c *Connection) Do(side int, action Action) Transition {
+	e := &c.Endpoints[side]
+	var t Transition
+	switch action {
+	case ActConnect:
+		t = e.Connect()
+	case ActListen:
+		t = e.Listen()
+	case ActClose:
+		t = e.Close()
+	case ActTimeout:
+		t = e.Timeout()
+	default:
+		flags := map[Action]Flags{ActSendSYN: SYN, ActSendACK: ACK, ActSendFIN: FIN, ActSendRST: RST}[action]
+		seg := e.Forge(flags)
+		t = Transition{
+			Endpoint: e.Name,
+			Event:    "sends " + seg.Flags.String() + " by hand",
+			From:     e.State,
+			To:       e.State,
+			Sent:     []Segment{seg},
+			Why:      fmt.Sprintf("The %s is sent from the %s's current sequence numbers without going through its state machine, as a packet tool such as scapy would, so the %s stays in %s.", seg.Flags, e.Name, e.Name, e.State),
+		}
+	}
+	c.record(side, t)
+	return t
+}
+
+// Deliver hands the oldest segment on the wire to the other endpoint
+func (c *Connection) Deliver() (Transition, bool) {
+	if len(c.Wire) == 0 {
+		return Transition{}, false
+	}
+	next := c.Wire[0]
+	c.Wire = c.Wire[1:]
+	to := 1 - next.From
+	t := c.Endpoints[to].Receive(next.Segment)
+	c.record(to, t)
+	return t, true
+}
+
+// Drop loses the oldest segment on the wire
+func (c *Connection) Drop() (InFlight, bool) {
+	if len(c.Wire) == 0 {
+		return InFlight{}, false
+	}
+	lost := c.Wire[0]
+	c.Wire = c.Wire[1:]
+	c.History = append(c.History, Entry{Transition: Transition{Endpoint: c.Endpoints[lost.From].Name}, Side: lost.From, Lost: true, Segment: lost.Segment})
+	return lost, true
+}
+
+// record logs a transition and puts what it sent on the wire
+func (c *Connection) record(side int, t Transition) {
+	c.History = append(c.History, Entry{Transition: t, Side: side})
+	for _, seg := range t.Sent {
+		c.Wire = append(c.Wire, InFlight{From: side, Segment: seg})
+	}
+}
@@ -0,0 +1,143 @@
+package tcpip
+
+import (
+	"strings"
+	"testing"
+)
+
+// play runs steps such as "client connect", "deliver" or "lose" against a
+// new connection
+func play(t *testing.T, steps ...string) Connection {
+	t.Helper()
+	actions := map[string]Action{
+		"connect": ActConnect, "listen": ActListen, "close": ActClose, "timer": ActTimeout,
+		"SYN": ActSendSYN, "ACK": ActSendACK, "FIN": ActSendFIN, "RST": ActSendRST,
+	}
+	c := NewConnection()
+	for _, step := range steps {
+		switch fields := strings.Fields(step); {
+		case step == "deliver":
+			if _, ok := c.Deliver(); !ok {
+				t.Fatalf("%q: nothing on the wire", step)
+			}
+		case step == "lose":
+			if _, ok := c.Drop(); !ok {
+				t.Fatalf("%q: nothing on the wire", step)
+			}
+		case len(fields) == 2 && (fields[0] == "client" || fields[0] == "server"):
+			side := Client
+			if fields[0] == "server" {
+				side = Server
+			}
+			c.Do(side, actions[fields[1]])
+		default:
+			t.Fatalf("bad step %q", step)
+		}
+	}
+	return c
+}
+
+var handshakeSteps = []string{"server listen", "client connect", "deliver", "deliver", "deliver"}
+
+func TestConnectionScenarios(t *testing.T) {
+	tests := []struct {
+		name    string
+		steps   []string
+		client  State
+		server  State
+		invalid int // How many events were invalid
+	}{
+		{"three-way handshake", handshakeSteps, Established, Established, 0},
+		{"half closed", append(handshakeSteps, "client close", "deliver", "deliver"), FinWait2, CloseWait, 0},
+		{"closed by the client", append(handshakeSteps, "client close", "deliver", "deliver", "server close", "deliver", "deliver"), TimeWait, Closed, 0},
+		{"TIME_WAIT expires", append(handshakeSteps, "client close", "deliver", "deliver", "server close", "deliver", "deliver", "client timer"), Closed, Closed, 0},
+		{"refused", []string{"client connect", "deliver", "deliver"}, Closed, Closed, 1},
+		{"simultaneous open", []string{"client connect", "server connect", "deliver", "deliver", "deliver", "deliver"}, Established, Established, 0},
+		{"simultaneous close", append(handshakeSteps, "client close", "server close", "deliver", "deliver"), Closing, Closing, 0},
+		{"simultaneous close acknowledged", append(handshakeSteps, "client close", "server close", "deliver", "deliver", "deliver", "deliver"), TimeWait, TimeWait, 0},
+		{"lost SYN+ACK", []string{"server listen", "client connect", "deliver", "lose", "client timer", "deliver", "deliver", "deliver"}, Established, Established, 0},
+		{"lost SYN", []string{"server listen", "client connect", "lose", "client timer", "deliver", "deliver", "deliver"}, Established, Established, 0},
+		{"lost last ACK", append(handshakeSteps, "client close", "deliver", "deliver", "server close", "deliver", "lose", "server timer", "deliver", "deliver"), TimeWait, Closed, 0},
+		{"SYN scan", []string{"server listen", "client SYN", "deliver", "deliver", "deliver"}, Closed, Listen, 1},
+		{"reset", append(handshakeSteps, "client RST", "deliver"), Established, Closed, 0},
+		{"close twice", append(handshakeSteps, "client close", "client close"), FinWait1, Established, 1},
+		{"SYN on an open connection", append(handshakeSteps, "client SYN", "deliver", "deliver"), Established, Established, 1},
+		{"FIN before the handshake", []string{"server listen", "client FIN", "deliver"}, Closed, Listen, 1},
+		{"close before the answer", []string{"server listen", "client connect", "client close", "deliver", "deliver", "deliver"}, Closed, Listen, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := play(t, tt.steps...)
+			client, server := c.Endpoints[Client].State, c.Endpoints[Server].State
+			if client != tt.client || server != tt.server {
+				t.Errorf("client %s, server %s; want %s, %s", client, server, tt.client, tt.server)
+			}
+			invalid := 0
+			for _, entry := range c.History {
+				if entry.Why == "" && !entry.Lost {
+					t.Errorf("%s %s: no explanation", entry.Endpoint, entry.Event)
+				}
+				if entry.Invalid {
+					invalid++
+				}
+			}
+			if invalid != tt.invalid {
+				t.Errorf("%d invalid events, want %d", invalid, tt.invalid)
+			}
+		})
+	}
+}
+
+func TestHandshakeSegments(t *testing.T) {
+	c := play(t, "server listen", "client connect")
+	var sent []string
+	for i := 0; i < 3; i++ {
+		sent = append(sent, c.Wire[0].Segment.String())
+		c.Deliver()
+	}
+	want := []string{"SYN seq=1000", "SYN+ACK seq=5000 ack=1001", "ACK seq=1001 ack=5001"}
+	if strings.Join(sent, ", ") != strings.Join(want, ", ") {
+		t.Errorf("handshake sent %q, want %q", sent, want)
+	}
+	if len(c.Wire) != 0 {
+		t.Errorf("%d segments left on the wire", len(c.Wire))
+	}
+}
+
+func TestInvalidEvents(t *testing.T) {
+	established := play(t, handshakeSteps...).Endpoints[Client]
+	tests := []struct {
+		name  string
+		state State
+		event func(e *Endpoint) Transition
+		why   string
+	}{
+		{"close when closed", Closed, (*Endpoint).Close, "no connection to close"},
+		{"connect when listening", Listen, (*Endpoint).Connect, "accepts connections rather than making them"},
+		{"connect when connected", Established, (*Endpoint).Connect, "EISCONN"},
+		{"listen when connected", Established, (*Endpoint).Listen, "listen() needs a socket without a connection"},
+		{"timer when established", Established, (*Endpoint).Timeout, "No timer is running"},
+		{"blind RST", Established, func(e *Endpoint) Transition { return e.Receive(Segment{Flags: RST, Seq: 9999}) }, "isn't the 5001 expected next"},
+		{"stale ACK", Established, func(e *Endpoint) Transition {
+			return e.Receive(Segment{Flags: ACK, Seq: 5001, Ack: 7000})
+		}, "beyond anything sent"},
+		{"ACK when listening", Listen, func(e *Endpoint) Transition { return e.Receive(Segment{Flags: ACK, Seq: 1, Ack: 2}) }, "answered with a RST"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewEndpoint("server", 5000)
+			switch tt.state {
+			case Listen:
+				e.Listen()
+			case Established:
+				e = established
+			}
+			tr := tt.event(&e)
+			if !tr.Invalid || tr.From != tt.state || tr.To != tt.state || !strings.Contains(tr.Why, tt.why) {
+				t.Errorf("got %s → %s, invalid %v: %q; want to stay in %s, explained with %q", tr.From, tr.To, tr.Invalid, tr.Why, tt.state, tt.why)
+			}
+		})
+	}
+}
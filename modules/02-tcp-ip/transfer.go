@@ -0,0 +1,473 @@
+package tcpip
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"netlab/pkg/styles"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// The settings ←/→ move between
+const (
+	settingAlgorithm = iota
+	settingRTT
+	settingBandwidth
+	settingLoss
+	settingWindow
+	settingCount
+)
+
+// The values +/- step through for each setting
+var (
+	rttChoices       = []time.Duration{5 * time.Millisecond, 10 * time.Millisecond, 20 * time.Millisecond, 40 * time.Millisecond, 80 * time.Millisecond, 160 * time.Millisecond, 300 * time.Millisecond}
+	bandwidthChoices = []float64{1, 5, 10, 20, 50, 100}
+	lossChoices      = []float64{0, 0.0001, 0.001, 0.005, 0.01, 0.02, 0.05}
+	windowChoices    = []int{16, 32, 64, 128, 256, 512, 1024, 4096}
+)
+
+// defaultChoices are CUBIC, 40 ms, 10 Mbit/s, no loss and a 256 KB window
+var defaultChoices = [settingCount]int{int(Cubic), 3, 2, 0, 4}
+
+// maxEvents is how many transfer events are listed
+const maxEvents = 40
+
+var (
+	queueStyle = lipgloss.NewStyle().Foreground(styles.Secondary)
+	limitStyle = styles.BodyDim
+)
+
+// transferSim is the congestion control simulator: a bulk transfer across a
+// path the learner configures, charted over time, with the loss recovery
+// events that shaped it
+type transferSim struct {
+	choices  [settingCount]int
+	selected int
+	seed     int64
+	result   Transfer
+	body     viewport.Model
+	width    int
+	height   int
+}
+
+func newTransferSim() transferSim {
+	t := transferSim{choices: defaultChoices, seed: 1, body: viewport.New(0, 0)}
+	t.result = Simulate(t.config())
+	return t
+}
+
+// config is the transfer the settings describe
+func (t transferSim) config() TransferConfig {
+	return TransferConfig{
+		Algorithm:  Algorithm(t.choices[settingAlgorithm]),
+		RTT:        rttChoices[t.choices[settingRTT]],
+		Bandwidth:  bandwidthChoices[t.choices[settingBandwidth]],
+		LossRate:   lossChoices[t.choices[settingLoss]],
+		RecvWindow: windowChoices[t.choices[settingWindow]] * 1000,
+		Seed:       t.seed,
+	}
+}
+
+// choiceCount is how many values a setting has
+func choiceCount(setting int) int {
+	switch setting {
+	case settingAlgorithm:
+		return int(algorithmCount)
+	case settingRTT:
+		return len(rttChoices)
+	case settingBandwidth:
+		return len(bandwidthChoices)
+	case settingLoss:
+		return len(lossChoices)
+	default:
+		return len(windowChoices)
+	}
+}
+
+// SetSize fits the simulator into width x height cells
+func (t *transferSim) SetSize(width, height int) {
+	t.width = width
+	t.height = height
+	t.body.Width = width
+	t.body.Height = height - 2 // The settings bar and the gap below it
+	t.body.SetContent(t.bodyView())
+}
+
+func (t transferSim) Update(msg tea.Msg) (transferSim, tea.Cmd) {
+	var cmd tea.Cmd
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "left":
+			t.selected = (t.selected + settingCount - 1) % settingCount
+			return t, nil
+		case "right":
+			t.selected = (t.selected + 1) % settingCount
+			return t, nil
+		case "up", "+", "=":
+			if t.selected == settingAlgorithm {
+				t.choices[t.selected] = (t.choices[t.selected] + 1) % choiceCount(t.selected)
+			} else {
+				t.choices[t.selected] = min(t.choices[t.selected]+1, choiceCount(t.selected)-1)
+			}
+		case "down", "-":
+			if t.selected == settingAlgorithm {
+				t.choices[t.selected] = (t.choices[t.selected] + choiceCount(t.selected) - 1) % choiceCount(t.selected)
+			} else {
+				t.choices[t.selected] = max(t.choices[t.selected]-1, 0)
+			}
+		case "r":
+			t.seed++
+		case "pgup", "pgdown":
+			t.body, cmd = t.body.Update(msg)
+			return t, cmd
+		default:
+			return t, nil
+		}
+		t.result = Simulate(t.config())
+		t.body.SetContent(t.bodyView())
+		t.body.GotoTop()
+		return t, nil
+
+	case tea.MouseMsg:
+		t.body, cmd = t.body.Update(msg)
+		return t, cmd
+	}
+	return t, nil
+}
+
+// helpKeys lists the keys for the footer; the rest are listed in the body
+func (t transferSim) helpKeys() []string {
+	key := styles.KeyBinding.Render
+	return []string{key("←/→") + " setting", key("↑/↓") + " change"}
+}
+
+func (t transferSim) View() string {
+	return t.settingsView() + "\n\n" + t.body.View()
+}
+
+// settingsView shows every setting, marking the one ↑/↓ change, with
+// shorter labels when narrow
+func (t transferSim) settingsView() string {
+	cfg := t.config()
+	values := [settingCount]string{
+		cfg.Algorithm.String(),
+		fmt.Sprintf("%d ms", cfg.RTT.Milliseconds()),
+		fmt.Sprintf("%g Mbit/s", cfg.Bandwidth),
+		fmt.Sprintf("%g%%", cfg.LossRate*100),
+		fmt.Sprintf("%d KB", cfg.RecvWindow/1000),
+	}
+	labels := [settingCount]string{"Algorithm ", "RTT ", "Bandwidth ", "Loss ", "Receive window "}
+	if t.width < 80 {
+		labels = [settingCount]string{"", "", "", "loss ", "rwnd "}
+	}
+
+	parts := make([]string, settingCount)
+	for i := range parts {
+		if i == t.selected {
+			parts[i] = currentStyle.Render("▸ " + labels[i] + values[i])
+		} else {
+			parts[i] = styles.BodyMuted.Render("  " + labels[i] + values[i])
+		}
+	}
+	return strings.Join(parts, "  ")
+}
+
+// bodyView charts the transfer, sizing the charts to fill the visible
+// height above the summary, then lists its events
+func (t transferSim) bodyView() string {
+	rows := t.body.Height - 10 // Titles, events row, time axis, and the summary
+	cwndRows := max(rows*3/5, 3)
+	flightRows := max(rows-cwndRows, 3)
+	plotWidth := max(t.width-9, 10)
+	cols := columns(t.result, plotWidth)
+	r := t.result
+
+	cwndTop := 0.0
+	for _, c := range cols {
+		cwndTop = math.Max(cwndTop, math.Max(c.Cwnd, c.Ssthresh))
+	}
+	rwnd := float64(r.Config.RecvWindow)
+	var rwndLine []float64
+	if rwnd <= cwndTop*1.25 {
+		cwndTop = math.Max(cwndTop, rwnd)
+		rwndLine = constant(rwnd, len(cols))
+	}
+	cwnd := make([]float64, len(cols))
+	ssthresh := make([]float64, len(cols))
+	flight := make([]float64, len(cols))
+	for i, c := range cols {
+		cwnd[i], ssthresh[i], flight[i] = c.Cwnd, c.Ssthresh, c.InFlight
+	}
+
+	pipe := float64(r.BDP)
+	full := pipe + float64(r.Queue*mss)
+	flightTop := full
+	for _, f := range flight {
+		flightTop = math.Max(flightTop, f)
+	}
+
+	legend := func(title string, items ...string) string {
+		return styles.H3.Render(title) + "  " + strings.Join(items, "  ")
+	}
+	sections := []string{
+		legend("Congestion window",
+			currentStyle.Render("█ cwnd"), peerStyle.Render("─ ssthresh"), limitStyle.Render("┄ receive window")) + "\n" +
+			plot(cwnd, cwndTop, cwndRows, currentStyle,
+				chartLine{ssthresh, "─", peerStyle}, chartLine{rwndLine, "┄", limitStyle}) + "\n" +
+			legend("Bytes in flight",
+				queueStyle.Render("█ in flight"), limitStyle.Render("┄ pipe, pipe + queue")) + "\n" +
+			plot(flight, flightTop, flightRows, queueStyle,
+				chartLine{constant(pipe, len(cols)), "┄", limitStyle}, chartLine{constant(full, len(cols)), "┄", limitStyle}) + "\n" +
+			markersView(r, plotWidth) + "\n" +
+			axisView(r.Duration, plotWidth),
+		t.summaryView(),
+		t.eventsView(),
+		t.keysView(),
+	}
+	return strings.Join(sections, "\n\n")
+}
+
+// summaryView gives the transfer's throughput and losses, and what limited
+// it
+func (t transferSim) summaryView() string {
+	r := t.result
+	cfg := r.Config
+	link := r.Throughput() / cfg.Bandwidth * 100
+	text := fmt.Sprintf("%s delivered %s in %.1f s, %d round trips: %.1f Mbit/s, %.0f%% of the link. %s, %s, %s lost (%d to the full queue).",
+		cfg.Algorithm, bytes(float64(r.Delivered)), r.Duration.Seconds(), roundTrips,
+		r.Throughput(), link,
+		plural(r.Count(EventFastRetransmit), "fast retransmit"), plural(r.Count(EventTimeout), "timeout"),
+		plural(r.Lost, "segment"), r.QueueDrops)
+	return paragraph(lipgloss.NewStyle(), text, t.width) + "\n" + paragraph(styles.StatusInfo, diagnosis(r), t.width)
+}
+
+// diagnosis says what held the transfer back, as someone debugging a slow
+// transfer would ask
+func diagnosis(r Transfer) string {
+	cfg := r.Config
+	rtt := cfg.RTT.Seconds()
+	switch {
+	case cfg.RecvWindow < r.BDP:
+		return fmt.Sprintf("The receive window is smaller than the path's bandwidth-delay product of %s, so the sender can't fill the pipe: one window per round trip is at most %.1f Mbit/s. Raise the receiver's buffer.",
+			bytes(float64(r.BDP)), float64(cfg.RecvWindow)*8/rtt/1e6)
+	case r.Count(EventTimeout) > 0:
+		return "Timeouts cost the most: the sender waits out the retransmission timer with nothing moving, then starts again from one segment."
+	case cfg.LossRate > 0:
+		mathis := mss * 8 / rtt * 1.22 / math.Sqrt(cfg.LossRate) / 1e6
+		return fmt.Sprintf("Random loss keeps cutting the window. Reno-style growth over this RTT sustains about %.1f Mbit/s at %g%% loss (MSS/RTT × 1.22/√loss), whatever the link's speed.",
+			mathis, cfg.LossRate*100)
+	default:
+		return fmt.Sprintf("Only the full queue drops segments. The window grows until the pipe (%s) and the queue (%d segments) are full, then backs off.",
+			bytes(float64(r.BDP)), r.Queue)
+	}
+}
+
+// eventsView lists the transfer's loss recovery events
+func (t transferSim) eventsView() string {
+	lines := []string{styles.H3.Render("Events")}
+	if len(t.result.Events) == 0 {
+		lines = append(lines, styles.BodyDim.Render("Nothing was lost: the window only ever grew."))
+	}
+	for i, e := range t.result.Events {
+		if i == maxEvents {
+			lines = append(lines, styles.BodyDim.Render(fmt.Sprintf("… and %d more", len(t.result.Events)-maxEvents)))
+			break
+		}
+		style := styles.BodyMuted
+		switch e.Kind {
+		case EventFastRetransmit:
+			style = styles.StatusWarning
+		case EventTimeout:
+			style = styles.StatusError
+		case EventRecoveryEnd:
+			style = styles.StatusSuccess
+		case EventSlowStartExit:
+			style = styles.StatusInfo
+		}
+		detail := strings.Split(paragraph(style, e.Detail, t.width-10), "\n")
+		for j, line := range detail {
+			prefix := strings.Repeat(" ", 10)
+			if j == 0 {
+				prefix = styles.BodyDim.Render(fmt.Sprintf("%6.2f s  ", e.Time.Seconds()))
+			}
+			lines = append(lines, prefix+line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// keysView lists the keys that aren't in the footer
+func (t transferSim) keysView() string {
+	key := styles.KeyBinding.Render
+	return key("+/-") + " change  " + key("r") + " other random losses  " + key("pgup/pgdn") + " scroll"
+}
+
+// chartLine is a level drawn across a chart, with no line where it's 0
+type chartLine struct {
+	values []float64
+	char   string
+	style  lipgloss.Style
+}
+
+// plot draws bars as columns of eighth blocks, rows high with top at the
+// top, then lines over them, with the scale to the left
+func plot(bars []float64, top float64, rows int, barStyle lipgloss.Style, lines ...chartLine) string {
+	const blocks = " ▁▂▃▄▅▆▇█"
+	top = math.Max(top, 1)
+	type cell struct {
+		char  string
+		style int // 0 for bars, then one per line
+	}
+	grid := make([][]cell, rows)
+	for row := range grid {
+		grid[row] = make([]cell, len(bars))
+		level := rows - 1 - row // Counted from the bottom
+		for col, v := range bars {
+			eighths := int(math.Round(v/top*float64(rows)*8)) - level*8
+			grid[row][col] = cell{string([]rune(blocks)[min(max(eighths, 0), 8)]), 0}
+		}
+	}
+	for i, line := range lines {
+		for col, v := range line.values {
+			if v <= 0 || col >= len(bars) {
+				continue
+			}
+			level := min(int(math.Ceil(v/top*float64(rows)))-1, rows-1)
+			grid[rows-1-max(level, 0)][col] = cell{line.char, i + 1}
+		}
+	}
+
+	styleOf := func(i int) lipgloss.Style {
+		if i == 0 {
+			return barStyle
+		}
+		return lines[i-1].style
+	}
+	out := make([]string, rows)
+	for row, cells := range grid {
+		label := ""
+		switch row {
+		case 0:
+			label = bytes(top)
+		case rows - 1:
+			label = "0"
+		}
+		var b strings.Builder
+		b.WriteString(styles.BodyDim.Render(fmt.Sprintf("%7s │", label)))
+		for start := 0; start < len(cells); {
+			end := start
+			var run strings.Builder
+			for end < len(cells) && cells[end].style == cells[start].style {
+				run.WriteString(cells[end].char)
+				end++
+			}
+			b.WriteString(styleOf(cells[start].style).Render(run.String()))
+			start = end
+		}
+		out[row] = b.String()
+	}
+	return strings.Join(out, "\n")
+}
+
+// columns buckets a transfer's samples into one per chart column: the
+// highest window and flight in each, and the last ssthresh, carrying the
+// previous column's values across columns with no samples
+func columns(r Transfer, width int) []Sample {
+	cols := make([]Sample, width)
+	var last Sample
+	next := 0
+	for col := range cols {
+		end := time.Duration(float64(r.Duration) * float64(col+1) / float64(width))
+		bucket := Sample{}
+		seen := false
+		for next < len(r.Samples) && (r.Samples[next].Time < end || col == width-1) {
+			s := r.Samples[next]
+			bucket.Cwnd = math.Max(bucket.Cwnd, s.Cwnd)
+			bucket.InFlight = math.Max(bucket.InFlight, s.InFlight)
+			bucket.Ssthresh = s.Ssthresh
+			seen = true
+			next++
+		}
+		if seen {
+			last = bucket
+		}
+		cols[col] = last
+	}
+	return cols
+}
+
+// markersView marks the columns where slow start ended (H), fast
+// retransmits (F) and timeouts (T) happened, timeouts first
+func markersView(r Transfer, width int) string {
+	marks := make([]int, width) // 0 for none, then in rising priority
+	chars := []string{" ", "H", "F", "T"}
+	markStyles := []lipgloss.Style{lipgloss.NewStyle(), styles.StatusInfo, styles.StatusWarning, styles.StatusError}
+	for _, e := range r.Events {
+		mark := 0
+		switch e.Kind {
+		case EventSlowStartExit:
+			mark = 1
+		case EventFastRetransmit:
+			mark = 2
+		case EventTimeout:
+			mark = 3
+		}
+		col := min(int(float64(e.Time)/float64(r.Duration)*float64(width)), width-1)
+		marks[col] = max(marks[col], mark)
+	}
+
+	var b strings.Builder
+	b.WriteString(styles.BodyDim.Render(fmt.Sprintf("%7s  ", "events")))
+	for _, mark := range marks {
+		b.WriteString(markStyles[mark].Render(chars[mark]))
+	}
+	return strings.TrimRight(b.String(), " ")
+}
+
+// axisView labels the time axis at the start, quarters and end
+func axisView(d time.Duration, width int) string {
+	line := []rune(strings.Repeat(" ", width))
+	for q := 0; q <= 4; q++ {
+		label := fmt.Sprintf("%.1f s", d.Seconds()*float64(q)/4)
+		if q == 0 {
+			label = "0"
+		}
+		at := min(width*q/4, width-len(label))
+		if q > 0 && q < 4 {
+			at -= len(label) / 2
+		}
+		copy(line[max(at, 0):], []rune(label))
+	}
+	return styles.BodyDim.Render(strings.Repeat(" ", 9) + strings.TrimRight(string(line), " "))
+}
+
+// constant is n copies of v
+func constant(v float64, n int) []float64 {
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = v
+	}
+	return values
+}
+
+// bytes formats a byte count in KB or MB
+func bytes(b float64) string {
+	if b >= 1e6 {
+		return fmt.Sprintf("%.1f MB", b/1e6)
+	}
+	return fmt.Sprintf("%.0f KB", b/1000)
+}
+
+// plural formats a count with a noun, adding s unless it's 1
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
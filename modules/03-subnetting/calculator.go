@@ -0,0 +1,223 @@
+package subnetting
+
+import (
+	"fmt"
+	"strings"
+
+	"netlab/pkg/styles"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// defaultCIDR is what the calculator starts with
+const defaultCIDR = "192.168.1.10/24"
+
+// labelWidth is the width of the label column in the results and bit grid
+const labelWidth = 14
+
+var (
+	networkBitStyle = lipgloss.NewStyle().Foreground(styles.Primary).Bold(true)
+	hostBitStyle    = lipgloss.NewStyle().Foreground(styles.Accent)
+)
+
+// Bit grid markers, drawn under the bits so the split doesn't rely on colour
+const (
+	networkMarker = "━"
+	hostMarker    = "┄"
+)
+
+// calculator is the live CIDR calculator: an input line, and the results
+// and bit grid for whatever it holds, scrollable when the terminal is short
+type calculator struct {
+	input  textinput.Model
+	subnet Subnet
+	err    error
+	body   viewport.Model
+	width  int
+}
+
+func newCalculator() calculator {
+	input := textinput.New()
+	input.Prompt = "CIDR › "
+	input.PromptStyle = styles.KeyBinding
+	input.Placeholder = defaultCIDR
+	input.CharLimit = 64
+	input.SetValue(defaultCIDR)
+	input.Focus()
+
+	c := calculator{input: input, body: viewport.New(0, 0)}
+	c.calculate()
+	return c
+}
+
+// SetSize fits the calculator into width x height cells
+func (c *calculator) SetSize(width, height int) {
+	c.width = width
+	c.input.Width = width - lipgloss.Width(c.input.Prompt) - 1
+	c.input.SetCursor(c.input.Position()) // Rescroll for the new width
+	c.body.Width = width
+	c.body.Height = height - 2 // Input line and the gap below it
+	c.body.SetContent(c.bodyView())
+}
+
+// calculate recomputes the results for the current input
+func (c *calculator) calculate() {
+	c.subnet, c.err = Calculate(c.input.Value())
+	c.body.SetContent(c.bodyView())
+}
+
+// setPrefixLength keeps the typed address and changes its prefix length
+func (c *calculator) setPrefixLength(bits int) {
+	if c.err != nil || bits < 0 || bits > c.subnet.Address.BitLen() {
+		return
+	}
+	c.input.SetValue(fmt.Sprintf("%s/%d", c.subnet.Address, bits))
+	c.input.CursorEnd()
+	c.calculate()
+}
+
+func (c calculator) Update(msg tea.Msg) (calculator, tea.Cmd) {
+	var cmd tea.Cmd
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "up":
+			c.setPrefixLength(c.subnet.Bits() + 1)
+			return c, nil
+		case "down":
+			c.setPrefixLength(c.subnet.Bits() - 1)
+			return c, nil
+		case "pgup", "pgdown":
+			c.body, cmd = c.body.Update(msg)
+			return c, cmd
+		}
+		c.input, cmd = c.input.Update(msg)
+		c.calculate()
+		return c, cmd
+
+	case tea.MouseMsg:
+		c.body, cmd = c.body.Update(msg)
+		return c, cmd
+	}
+
+	c.input, cmd = c.input.Update(msg)
+	return c, cmd
+}
+
+func (c calculator) View() string {
+	return c.input.View() + "\n\n" + c.body.View()
+}
+
+// bodyView renders the results and the bit grid
+func (c calculator) bodyView() string {
+	if c.err != nil {
+		return styles.StatusError.Render("⚠️  " + c.err.Error())
+	}
+	return c.resultsView() + "\n\n" + c.gridView()
+}
+
+func (c calculator) resultsView() string {
+	s := c.subnet
+	broadcast := "Broadcast"
+	if !s.Is4() {
+		broadcast = "Last address"
+	}
+
+	hosts := FormatCount(s.Hosts)
+	if s.Hosts.Cmp(s.Addresses) != 0 {
+		hosts += " of " + FormatCount(s.Addresses) + " addresses"
+	}
+
+	rows := [][2]string{
+		{"Address", fmt.Sprintf("%s/%d", s.Address, s.Bits())},
+		{"Network", s.Prefix.String()},
+		{broadcast, s.Last.String()},
+		{"Host range", fmt.Sprintf("%s – %s", s.FirstHost, s.LastHost)},
+		{"Netmask", s.Mask.String()},
+		{"Wildcard", s.Wildcard.String()},
+		{"Usable hosts", hosts},
+		{"Scope", s.Scope()},
+	}
+
+	label := styles.BodyMuted.Copy().Width(labelWidth)
+	value := lipgloss.NewStyle().Width(max(c.width-labelWidth, 1))
+	lines := make([]string, len(rows))
+	for i, row := range rows {
+		lines[i] = lipgloss.JoinHorizontal(lipgloss.Top, label.Render(row[0]), value.Render(row[1]))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// gridView shows the address bit by bit, network bits apart from host bits
+func (c calculator) gridView() string {
+	s := c.subnet
+	label := styles.BodyMuted.Copy().Width(labelWidth)
+	dot := styles.BodyDim.Render(".")
+	bits := s.Bits()
+	legend := strings.Repeat(" ", labelWidth) +
+		networkBitStyle.Render(networkMarker) + fmt.Sprintf(" network bits (%d)  ", bits) +
+		hostBitStyle.Render(hostMarker) + fmt.Sprintf(" host bits (%d)", s.Address.BitLen()-bits)
+
+	if s.Is4() {
+		return strings.Join([]string{
+			label.Render("Address") + colourBits(Binary(s.Address), bits, dot),
+			label.Render("Netmask") + colourBits(Binary(s.Mask), bits, dot),
+			label.Render("") + markers(4, 8, bits),
+			legend,
+		}, "\n")
+	}
+
+	// IPv6 has 8 groups of 16 bits; fit as many groups per row as we can
+	perRow := 8
+	for perRow > 1 && labelWidth+perRow*17-1 > c.width {
+		perRow /= 2
+	}
+	groups := Binary(s.Address)
+	colon := styles.BodyDim.Render(":")
+
+	var lines []string
+	for start := 0; start < len(groups); start += perRow {
+		offset := start * 16
+		rowLabel := fmt.Sprintf("Bits %d-%d", offset+1, offset+perRow*16)
+		lines = append(lines,
+			label.Render(rowLabel)+colourBits(groups[start:start+perRow], bits-offset, colon),
+			label.Render("")+markers(perRow, 16, bits-offset),
+		)
+	}
+	return strings.Join(append(lines, legend), "\n")
+}
+
+// colourBits joins groups of bits with sep, styling the first network bits
+// as network bits and the rest as host bits
+func colourBits(groups []string, network int, sep string) string {
+	rendered := make([]string, len(groups))
+	for i, g := range groups {
+		n := min(max(network-i*len(g), 0), len(g))
+		rendered[i] = networkBitStyle.Render(g[:n]) + hostBitStyle.Render(g[n:])
+	}
+	return strings.Join(rendered, sep)
+}
+
+// markers draws the row under the bits: networkMarker under network bits,
+// hostMarker under host bits, and under each separator the marker of the
+// bit before it
+func markers(groups, size, network int) string {
+	var b strings.Builder
+	for i := 0; i < groups*size; i++ {
+		if i > 0 && i%size == 0 {
+			b.WriteString(marker(i-1 < network))
+		}
+		b.WriteString(marker(i < network))
+	}
+	return b.String()
+}
+
+func marker(network bool) string {
+	if network {
+		return networkBitStyle.Render(networkMarker)
+	}
+	return hostBitStyle.Render(hostMarker)
+}
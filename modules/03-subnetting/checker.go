@@ -0,0 +1,254 @@
+package subnetting
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"netlab/pkg/styles"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// defaultRanges is what the overlap checker starts with: a cluster whose
+// VPN route lands inside its Service range
+const defaultRanges = "pods=10.244.0.0/16, services=10.96.0.0/12, nodes=172.18.0.0/16, vpn=10.100.0.0/16"
+
+// Checker input fields, in the order up and down move through them
+const (
+	fieldRanges = iota
+	fieldWithin
+	fieldConfig
+	fieldCount
+)
+
+// configLoadedMsg carries the ranges read from a cluster config
+type configLoadedMsg struct {
+	path   string
+	ranges []Range
+	err    error
+}
+
+// checker is the overlap checker: the cluster's ranges, optionally the
+// blocks to report free space in, and a cluster config to read ranges from
+type checker struct {
+	inputs [fieldCount]textinput.Model
+	focus  int
+	report OverlapReport
+	err    error
+	loaded configLoadedMsg
+	body   viewport.Model
+	width  int
+}
+
+func newChecker() checker {
+	var c checker
+	for i, prompt := range []string{"Ranges › ", "Within › ", "Config › "} {
+		input := textinput.New()
+		input.Prompt = prompt
+		input.PromptStyle = styles.KeyBinding
+		input.CharLimit = 512
+		c.inputs[i] = input
+	}
+	c.inputs[fieldRanges].SetValue(defaultRanges)
+	c.inputs[fieldWithin].Placeholder = "where to find free space, or blank for private ranges"
+	c.inputs[fieldConfig].Placeholder = "kind or kubeadm config file to load"
+	c.inputs[fieldRanges].Focus()
+	c.body = viewport.New(0, 0)
+	c.check()
+	return c
+}
+
+// SetSize fits the checker into width x height cells
+func (c *checker) SetSize(width, height int) {
+	c.width = width
+	for i := range c.inputs {
+		c.inputs[i].Width = width - lipgloss.Width(c.inputs[i].Prompt) - 1
+		c.inputs[i].SetCursor(c.inputs[i].Position()) // Rescroll for the new width
+	}
+	c.body.Width = width
+	c.body.Height = height - fieldCount - 1 // The inputs and the gap below them
+	c.body.SetContent(c.bodyView())
+}
+
+// check rechecks the current inputs
+func (c *checker) check() {
+	c.err = nil
+	ranges, err := ParseRanges(c.inputs[fieldRanges].Value())
+	if err != nil {
+		c.err = err
+		c.body.SetContent(c.bodyView())
+		return
+	}
+
+	var within []netip.Prefix
+	for _, field := range strings.FieldsFunc(c.inputs[fieldWithin].Value(), func(r rune) bool { return r == ',' || r == ' ' }) {
+		prefix, err := netip.ParsePrefix(field)
+		if err != nil {
+			c.err = fmt.Errorf("%q is not a valid CIDR block", field)
+			c.body.SetContent(c.bodyView())
+			return
+		}
+		within = append(within, prefix)
+	}
+
+	c.report = CheckOverlaps(ranges, within)
+	c.body.SetContent(c.bodyView())
+}
+
+func (c checker) Update(msg tea.Msg) (checker, tea.Cmd) {
+	var cmd tea.Cmd
+	switch msg := msg.(type) {
+	case configLoadedMsg:
+		c.loaded = msg
+		if msg.err == nil {
+			// Keep what's typed if it parses, replacing the config's ranges
+			current, err := ParseRanges(c.inputs[fieldRanges].Value())
+			if err != nil {
+				current = nil
+			}
+			c.inputs[fieldRanges].SetValue(FormatRanges(MergeRanges(current, msg.ranges)))
+		}
+		c.check()
+		return c, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "up", "down":
+			c.inputs[c.focus].Blur()
+			step := 1
+			if msg.String() == "up" {
+				step = fieldCount - 1
+			}
+			c.focus = (c.focus + step) % fieldCount
+			c.inputs[c.focus].Focus()
+			return c, textinput.Blink
+		case "pgup", "pgdown":
+			c.body, cmd = c.body.Update(msg)
+			return c, cmd
+		case "enter":
+			if c.focus == fieldConfig && strings.TrimSpace(c.inputs[fieldConfig].Value()) != "" {
+				return c, loadClusterConfig(strings.TrimSpace(c.inputs[fieldConfig].Value()))
+			}
+			return c, nil
+		}
+
+		c.inputs[c.focus], cmd = c.inputs[c.focus].Update(msg)
+		if c.focus != fieldConfig {
+			c.check()
+		}
+		return c, cmd
+
+	case tea.MouseMsg:
+		c.body, cmd = c.body.Update(msg)
+		return c, cmd
+	}
+
+	c.inputs[c.focus], cmd = c.inputs[c.focus].Update(msg)
+	return c, cmd
+}
+
+func (c checker) View() string {
+	lines := make([]string, len(c.inputs))
+	for i, input := range c.inputs {
+		lines[i] = input.View()
+	}
+	return strings.Join(lines, "\n") + "\n\n" + c.body.View()
+}
+
+// bodyView renders the ranges, their conflicts and the free space left
+func (c checker) bodyView() string {
+	var sections []string
+	switch {
+	case c.loaded.err != nil:
+		sections = append(sections, styles.StatusError.Render("⚠️  "+c.loaded.err.Error()))
+	case c.loaded.path != "":
+		sections = append(sections, styles.StatusSuccess.Render(fmt.Sprintf("✅ Loaded %s", c.loaded.path))+
+			styles.BodyMuted.Render(" "+describeLoaded(c.loaded.ranges)))
+	}
+	if c.err != nil {
+		return strings.Join(append(sections, styles.StatusError.Render("⚠️  "+c.err.Error())), "\n\n")
+	}
+
+	sections = append(sections, c.rangesView(), c.conflictsView())
+	for _, free := range c.report.Free {
+		sections = append(sections, c.freeView(free))
+	}
+	return strings.Join(sections, "\n\n")
+}
+
+func (c checker) rangesView() string {
+	nameWidth := len("Name")
+	for _, r := range c.report.Ranges {
+		nameWidth = max(nameWidth, lipgloss.Width(r.Name))
+	}
+	name := lipgloss.NewStyle().Width(nameWidth + 2)
+	block := lipgloss.NewStyle().Width(22)
+	showRange := c.width >= nameWidth+2+22+36
+
+	header := name.Render("Name") + block.Render("Block") + "Addresses"
+	if showRange {
+		header = name.Render("Name") + block.Render("Block") + "Range"
+	}
+	lines := []string{styles.BodyMuted.Render(header)}
+	for _, r := range c.report.Ranges {
+		s, _ := Calculate(r.Prefix.String())
+		detail := FormatCount(s.Addresses)
+		if showRange {
+			detail = fmt.Sprintf("%s – %s", s.Network, s.Last)
+		}
+		lines = append(lines, name.Render(r.Name)+block.Render(r.Prefix.String())+detail)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (c checker) conflictsView() string {
+	if len(c.report.Conflicts) == 0 {
+		return styles.StatusSuccess.Render("✅ No overlaps")
+	}
+	lines := []string{styles.StatusError.Render(fmt.Sprintf("❌ %d overlap(s)", len(c.report.Conflicts)))}
+	text := lipgloss.NewStyle().Width(max(c.width-2, 1))
+	for _, conflict := range c.report.Conflicts {
+		lines = append(lines, lipgloss.JoinHorizontal(lipgloss.Top, "  ", text.Render(conflict.String())))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (c checker) freeView(free FreeSpace) string {
+	total := blockSize(free.Within.Addr().BitLen() - free.Within.Bits())
+	title := styles.H3.Render("Free in "+free.Within.String()) +
+		styles.BodyMuted.Render(fmt.Sprintf("  %s of %s addresses", FormatCount(free.Free), FormatCount(total)))
+
+	blocks := "none"
+	if len(free.Blocks) > 0 {
+		names := make([]string, len(free.Blocks))
+		for i, b := range free.Blocks {
+			names[i] = b.String()
+		}
+		blocks = strings.Join(names, ", ")
+	}
+	return title + "\n" + lipgloss.NewStyle().Width(max(c.width, 3)).PaddingLeft(2).Render(blocks)
+}
+
+// describeLoaded names the ranges read from a config, noting defaults
+func describeLoaded(ranges []Range) string {
+	parts := make([]string, len(ranges))
+	for i, r := range ranges {
+		parts[i] = r.Name
+		if strings.HasSuffix(r.Source, "default") {
+			parts[i] += " (" + r.Source + ")"
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// loadClusterConfig reads the ranges from a kind or kubeadm config
+func loadClusterConfig(path string) tea.Cmd {
+	return func() tea.Msg {
+		ranges, err := ReadClusterConfig(path)
+		return configLoadedMsg{path: path, ranges: ranges, err: err}
+	}
+}
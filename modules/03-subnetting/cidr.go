@@ -0,0 +1,192 @@
+package subnetting
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"net/netip"
+	"strings"
+)
+
+// Subnet is everything the calculator shows about a CIDR block
+type Subnet struct {
+	Address   netip.Addr   // As typed, host bits included
+	Prefix    netip.Prefix // The block, with host bits cleared
+	Network   netip.Addr
+	Last      netip.Addr // The broadcast address for IPv4
+	FirstHost netip.Addr
+	LastHost  netip.Addr
+	Mask      netip.Addr
+	Wildcard  netip.Addr
+	Addresses *big.Int // Every address in the block
+	Hosts     *big.Int // Addresses that can be assigned to interfaces
+}
+
+// Calculate parses an address with an optional prefix length, such as
+// 192.168.1.10/24 or 2001:db8::/48. A bare address is a single host.
+func Calculate(input string) (Subnet, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return Subnet{}, errors.New("enter an address and prefix length, like 192.168.1.10/24")
+	}
+
+	var prefix netip.Prefix
+	if strings.Contains(input, "/") {
+		p, err := netip.ParsePrefix(input)
+		if err != nil {
+			return Subnet{}, fmt.Errorf("%q is not a valid CIDR block", input)
+		}
+		prefix = p
+	} else {
+		addr, err := netip.ParseAddr(input)
+		if err != nil {
+			return Subnet{}, fmt.Errorf("%q is not a valid IP address", input)
+		}
+		if addr.Zone() != "" {
+			return Subnet{}, errors.New("zoned IPv6 addresses are not supported")
+		}
+		prefix = netip.PrefixFrom(addr, addr.BitLen())
+	}
+
+	addr := prefix.Addr()
+	bits := addr.BitLen()
+	hostBits := bits - prefix.Bits()
+
+	s := Subnet{
+		Address:   addr,
+		Prefix:    prefix.Masked(),
+		Mask:      maskAddr(bits, prefix.Bits(), false),
+		Wildcard:  maskAddr(bits, prefix.Bits(), true),
+		Addresses: new(big.Int).Lsh(big.NewInt(1), uint(hostBits)),
+	}
+	s.Network = s.Prefix.Addr()
+	s.Last = orAddr(s.Network, s.Wildcard)
+	s.FirstHost, s.LastHost = s.Network, s.Last
+	s.Hosts = new(big.Int).Set(s.Addresses)
+
+	// IPv4 reserves the network and broadcast addresses, except on /31
+	// point-to-point links (RFC 3021) and single-host /32s. IPv6 has no
+	// broadcast, so every address in the block is usable.
+	if addr.Is4() && hostBits >= 2 {
+		s.FirstHost = s.Network.Next()
+		s.LastHost = s.Last.Prev()
+		s.Hosts.Sub(s.Hosts, big.NewInt(2))
+	}
+	return s, nil
+}
+
+// Is4 reports whether the subnet is IPv4
+func (s Subnet) Is4() bool {
+	return s.Address.Is4()
+}
+
+// Bits returns the prefix length
+func (s Subnet) Bits() int {
+	return s.Prefix.Bits()
+}
+
+// maskAddr builds a netmask with the first ones bits set, or its inverse
+// (the wildcard mask) when invert is set
+func maskAddr(bits, ones int, invert bool) netip.Addr {
+	b := make([]byte, bits/8)
+	for i := range b {
+		switch {
+		case ones >= 8:
+			b[i] = 0xff
+		case ones > 0:
+			b[i] = byte(0xff << (8 - ones))
+		}
+		ones -= 8
+		if invert {
+			b[i] = ^b[i]
+		}
+	}
+	addr, _ := netip.AddrFromSlice(b)
+	return addr
+}
+
+// orAddr sets the bits of mask in addr
+func orAddr(addr, mask netip.Addr) netip.Addr {
+	a, m := addr.AsSlice(), mask.AsSlice()
+	for i := range a {
+		a[i] |= m[i]
+	}
+	out, _ := netip.AddrFromSlice(a)
+	return out
+}
+
+// documentationPrefixes are reserved for examples (RFC 5737, RFC 3849)
+var documentationPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("192.0.2.0/24"),
+	netip.MustParsePrefix("198.51.100.0/24"),
+	netip.MustParsePrefix("203.0.113.0/24"),
+	netip.MustParsePrefix("2001:db8::/32"),
+}
+
+// cgnatPrefix is shared address space for carrier-grade NAT (RFC 6598)
+var cgnatPrefix = netip.MustParsePrefix("100.64.0.0/10")
+
+// Scope describes what kind of address space the block belongs to
+func (s Subnet) Scope() string {
+	addr := s.Network
+	for _, p := range documentationPrefixes {
+		if p.Contains(addr) {
+			return "Documentation (examples only)"
+		}
+	}
+	switch {
+	case addr.IsLoopback():
+		return "Loopback"
+	case addr.IsLinkLocalUnicast():
+		return "Link-local"
+	case addr.IsMulticast():
+		return "Multicast"
+	case addr.IsPrivate() && addr.Is4():
+		return "Private (RFC 1918)"
+	case addr.IsPrivate():
+		return "Unique local (RFC 4193)"
+	case cgnatPrefix.Contains(addr):
+		return "Carrier-grade NAT (RFC 6598)"
+	case addr.IsUnspecified():
+		return "Unspecified"
+	case addr.IsGlobalUnicast():
+		return "Public"
+	}
+	return "Reserved"
+}
+
+// Binary returns the address as bits, grouped in octets for IPv4 and
+// 16-bit groups for IPv6
+func Binary(addr netip.Addr) []string {
+	b := addr.AsSlice()
+	var groups []string
+	if addr.Is4() {
+		for _, octet := range b {
+			groups = append(groups, fmt.Sprintf("%08b", octet))
+		}
+		return groups
+	}
+	for i := 0; i < len(b); i += 2 {
+		groups = append(groups, fmt.Sprintf("%08b%08b", b[i], b[i+1]))
+	}
+	return groups
+}
+
+// FormatCount renders a count with thousands separators, adding a power of
+// two for the very large blocks IPv6 produces
+func FormatCount(n *big.Int) string {
+	digits := n.String()
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(d)
+	}
+	if n.BitLen() > 32 {
+		if pow := n.BitLen() - 1; new(big.Int).Lsh(big.NewInt(1), uint(pow)).Cmp(n) == 0 {
+			return fmt.Sprintf("2^%d (%s)", pow, b.String())
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,94 @@
+package subnetting
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCalculate(t *testing.T) {
+	tests := []struct {
+		input                                                 string
+		network, last, first, lastHost, mask, wildcard, hosts string
+	}{
+		{"192.168.1.10/24", "192.168.1.0/24", "192.168.1.255", "192.168.1.1", "192.168.1.254", "255.255.255.0", "0.0.0.255", "254"},
+		{"172.16.5.130/26", "172.16.5.128/26", "172.16.5.191", "172.16.5.129", "172.16.5.190", "255.255.255.192", "0.0.0.63", "62"},
+		{"10.0.0.0/8", "10.0.0.0/8", "10.255.255.255", "10.0.0.1", "10.255.255.254", "255.0.0.0", "0.255.255.255", "16777214"},
+		{"10.1.2.3/20", "10.1.0.0/20", "10.1.15.255", "10.1.0.1", "10.1.15.254", "255.255.240.0", "0.0.15.255", "4094"},
+		{"10.0.0.6/31", "10.0.0.6/31", "10.0.0.7", "10.0.0.6", "10.0.0.7", "255.255.255.254", "0.0.0.1", "2"},
+		{"10.0.0.6/32", "10.0.0.6/32", "10.0.0.6", "10.0.0.6", "10.0.0.6", "255.255.255.255", "0.0.0.0", "1"},
+		{"10.0.0.6", "10.0.0.6/32", "10.0.0.6", "10.0.0.6", "10.0.0.6", "255.255.255.255", "0.0.0.0", "1"},
+		{"0.0.0.0/0", "0.0.0.0/0", "255.255.255.255", "0.0.0.1", "255.255.255.254", "0.0.0.0", "255.255.255.255", "4294967294"},
+		{" 2001:db8:abcd:12::1/64 ", "2001:db8:abcd:12::/64", "2001:db8:abcd:12:ffff:ffff:ffff:ffff", "2001:db8:abcd:12::", "2001:db8:abcd:12:ffff:ffff:ffff:ffff", "ffff:ffff:ffff:ffff::", "::ffff:ffff:ffff:ffff", "18446744073709551616"},
+		{"fd00::1/127", "fd00::/127", "fd00::1", "fd00::", "fd00::1", "ffff:ffff:ffff:ffff:ffff:ffff:ffff:fffe", "::1", "2"},
+		{"::1", "::1/128", "::1", "::1", "::1", "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff", "::", "1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			s, err := Calculate(tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := []string{s.Prefix.String(), s.Last.String(), s.FirstHost.String(), s.LastHost.String(), s.Mask.String(), s.Wildcard.String(), s.Hosts.String()}
+			want := []string{tt.network, tt.last, tt.first, tt.lastHost, tt.mask, tt.wildcard, tt.hosts}
+			for i := range got {
+				if got[i] != want[i] {
+					t.Errorf("got %v, want %v", got, want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestCalculateRejectsInvalidInput(t *testing.T) {
+	tests := []struct{ input, want string }{
+		{"", "enter an address"},
+		{"192.168.1.0/33", "not a valid CIDR block"},
+		{"192.168.1/24", "not a valid CIDR block"},
+		{"300.1.1.1", "not a valid IP address"},
+		{"fe80::1%eth0", "zoned IPv6 addresses are not supported"},
+	}
+	for _, tt := range tests {
+		if _, err := Calculate(tt.input); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Calculate(%q) error = %v, want %q", tt.input, err, tt.want)
+		}
+	}
+}
+
+func TestScope(t *testing.T) {
+	tests := map[string]string{
+		"10.244.0.0/16":  "Private (RFC 1918)",
+		"100.64.1.0/24":  "Carrier-grade NAT (RFC 6598)",
+		"8.8.8.0/24":     "Public",
+		"127.0.0.1":      "Loopback",
+		"169.254.1.1/16": "Link-local",
+		"fd12:3456::/48": "Unique local (RFC 4193)",
+		"2001:db8::/32":  "Documentation (examples only)",
+		"2606:4700::/32": "Public",
+		"203.0.113.0/24": "Documentation (examples only)",
+		"ff02::1":        "Multicast",
+	}
+	for input, want := range tests {
+		s, err := Calculate(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := s.Scope(); got != want {
+			t.Errorf("Scope(%s) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestFormatCount(t *testing.T) {
+	for _, tt := range []struct{ input, want string }{
+		{"10.0.0.0/24", "254"},
+		{"10.0.0.0/8", "16,777,214"},
+		{"2001:db8::/64", "2^64 (18,446,744,073,709,551,616)"},
+	} {
+		s, _ := Calculate(tt.input)
+		if got := FormatCount(s.Hosts); got != tt.want {
+			t.Errorf("FormatCount(%s) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
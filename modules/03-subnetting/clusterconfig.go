@@ -0,0 +1,139 @@
+package subnetting
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// clusterConfig is the part of a kind Cluster or kubeadm
+// ClusterConfiguration that describes the cluster's networks
+type clusterConfig struct {
+	Kind       string `yaml:"kind"`
+	APIVersion string `yaml:"apiVersion"`
+	Networking struct {
+		IPFamily      string `yaml:"ipFamily"` // kind only
+		PodSubnet     string `yaml:"podSubnet"`
+		ServiceSubnet string `yaml:"serviceSubnet"`
+	} `yaml:"networking"`
+}
+
+// kindDefaults are the subnets kind uses when its config leaves them out,
+// by IP family
+var kindDefaults = map[string][2]string{
+	"ipv4": {"10.244.0.0/16", "10.96.0.0/16"},
+	"ipv6": {"fd00:10:244::/56", "fd00:10:96::/112"},
+	"dual": {"10.244.0.0/16,fd00:10:244::/56", "10.96.0.0/16,fd00:10:96::/112"},
+}
+
+// kubeadmServiceDefault is kubeadm's Service subnet when none is set. It has
+// no default pod subnet; that's left to the CNI plugin.
+const kubeadmServiceDefault = "10.96.0.0/12"
+
+// ReadClusterConfig reads the pod and Service subnets from a kind or
+// kubeadm config file
+func ReadClusterConfig(path string) ([]Range, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cluster config: %w", err)
+	}
+	ranges, err := ParseClusterConfig(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return ranges, nil
+}
+
+// ParseClusterConfig finds the first kind Cluster or kubeadm
+// ClusterConfiguration among the YAML documents in data and returns its pod
+// and Service subnets, filling in the tool's defaults for those left out
+func ParseClusterConfig(data []byte) ([]Range, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var cfg clusterConfig
+		err := dec.Decode(&cfg)
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("no kind Cluster or kubeadm ClusterConfiguration found")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid YAML: %w", err)
+		}
+
+		group, _, _ := strings.Cut(cfg.APIVersion, "/")
+		switch {
+		case group == "kind.x-k8s.io" && cfg.Kind == "Cluster":
+			return cfg.kindRanges()
+		case group == "kubeadm.k8s.io" && cfg.Kind == "ClusterConfiguration":
+			return cfg.kubeadmRanges()
+		}
+	}
+}
+
+func (c clusterConfig) kindRanges() ([]Range, error) {
+	family := c.Networking.IPFamily
+	if family == "" {
+		family = "ipv4"
+	}
+	defaults, ok := kindDefaults[family]
+	if !ok {
+		return nil, fmt.Errorf("unknown ipFamily %q", family)
+	}
+
+	pods, err := subnetRanges("pods", c.Networking.PodSubnet, defaults[0], "kind")
+	if err != nil {
+		return nil, err
+	}
+	services, err := subnetRanges("services", c.Networking.ServiceSubnet, defaults[1], "kind")
+	if err != nil {
+		return nil, err
+	}
+	return append(pods, services...), nil
+}
+
+func (c clusterConfig) kubeadmRanges() ([]Range, error) {
+	var ranges []Range
+	if c.Networking.PodSubnet != "" {
+		pods, err := subnetRanges("pods", c.Networking.PodSubnet, "", "kubeadm")
+		if err != nil {
+			return nil, err
+		}
+		ranges = pods
+	}
+	services, err := subnetRanges("services", c.Networking.ServiceSubnet, kubeadmServiceDefault, "kubeadm")
+	if err != nil {
+		return nil, err
+	}
+	return append(ranges, services...), nil
+}
+
+// subnetRanges parses a subnet setting, which is a comma-separated pair on
+// dual-stack clusters. The second block of a pair gets its family in its name.
+func subnetRanges(name, value, fallback, tool string) ([]Range, error) {
+	source := tool + " config"
+	if value == "" {
+		value, source = fallback, tool+" default"
+	}
+
+	var ranges []Range
+	for i, block := range strings.Split(value, ",") {
+		prefix, err := netip.ParsePrefix(strings.TrimSpace(block))
+		if err != nil {
+			return nil, fmt.Errorf("%s subnet %q is not a valid CIDR block", name, block)
+		}
+		r := Range{Name: name, Prefix: prefix.Masked(), Source: source}
+		if i > 0 {
+			r.Name += "-ipv4"
+			if prefix.Addr().Is6() {
+				r.Name = name + "-ipv6"
+			}
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges, nil
+}
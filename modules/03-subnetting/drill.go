@@ -0,0 +1,234 @@
+package subnetting
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"netlab/internal/utils"
+	"netlab/pkg/styles"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// moduleID is the module's ID in the registry and the progress file
+const moduleID = "03-subnetting"
+
+var binaryStyle = lipgloss.NewStyle().Foreground(styles.Primary)
+
+// drillProgressMsg carries the module's progress after it is loaded or a
+// drill result is recorded
+type drillProgressMsg struct {
+	progress utils.ModuleProgress
+	err      error
+}
+
+// drill asks generated problems one at a time, timing each answer and
+// explaining the wrong ones step by step
+type drill struct {
+	rng      *rand.Rand
+	now      func() time.Time
+	problem  Problem
+	number   int
+	started  time.Time
+	input    textinput.Model
+	answered bool
+	correct  bool
+	took     time.Duration
+	session  utils.DrillStats
+	allTime  utils.DrillStats
+	err      error // From loading or saving progress
+	body     viewport.Model
+	width    int
+	height   int
+}
+
+func newDrill(seed int64, now func() time.Time) drill {
+	input := textinput.New()
+	input.Prompt = "Answer › "
+	input.PromptStyle = styles.KeyBinding
+	input.CharLimit = 64
+	input.Focus()
+
+	d := drill{
+		rng:   rand.New(rand.NewSource(seed)),
+		now:   now,
+		input: input,
+		body:  viewport.New(0, 0),
+	}
+	d.next()
+	return d
+}
+
+// next moves on to a new problem and starts its clock
+func (d *drill) next() {
+	d.problem = newProblem(d.rng, drillKinds[d.rng.Intn(len(drillKinds))])
+	d.number++
+	d.answered = false
+	d.input.Reset()
+	d.input.Focus()
+	d.started = d.now()
+	d.layout()
+}
+
+// submit checks the answer and returns the command that records it
+func (d *drill) submit() tea.Cmd {
+	answer := strings.TrimSpace(d.input.Value())
+	if answer == "" {
+		return nil
+	}
+	d.answered = true
+	d.correct = d.problem.Check(answer)
+	d.took = d.now().Sub(d.started)
+	d.input.Blur()
+
+	d.session.Attempts++
+	d.session.TotalMS += d.took.Milliseconds()
+	if d.correct {
+		d.session.Correct++
+	}
+	d.layout()
+	return recordDrill(d.problem.Kind, d.correct, d.took)
+}
+
+// SetSize fits the drill into width x height cells
+func (d *drill) SetSize(width, height int) {
+	d.width = width
+	d.height = height
+	d.input.Width = width - lipgloss.Width(d.input.Prompt) - 1
+	d.layout()
+}
+
+// layout gives the explanation whatever room the question leaves
+func (d *drill) layout() {
+	d.body.Width = d.width
+	d.body.Height = max(d.height-lipgloss.Height(d.questionView())-1, 1)
+	d.body.SetContent(d.resultView())
+	d.body.GotoTop()
+}
+
+func (d drill) Init() tea.Cmd {
+	return loadDrillProgress
+}
+
+func (d drill) Update(msg tea.Msg) (drill, tea.Cmd) {
+	var cmd tea.Cmd
+	switch msg := msg.(type) {
+	case drillProgressMsg:
+		d.err = msg.err
+		if msg.err == nil {
+			d.allTime = msg.progress.Totals()
+		}
+		d.layout()
+		return d, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "enter":
+			if d.answered {
+				d.next()
+				return d, textinput.Blink
+			}
+			return d, d.submit()
+		case "pgup", "pgdown", "up", "down":
+			d.body, cmd = d.body.Update(msg)
+			return d, cmd
+		}
+		if d.answered {
+			return d, nil
+		}
+
+	case tea.MouseMsg:
+		d.body, cmd = d.body.Update(msg)
+		return d, cmd
+	}
+
+	d.input, cmd = d.input.Update(msg)
+	return d, cmd
+}
+
+func (d drill) View() string {
+	return d.questionView() + "\n\n" + d.body.View()
+}
+
+// questionView renders the problem, the score so far and the answer line
+func (d drill) questionView() string {
+	title := styles.H3.Render(fmt.Sprintf("Problem %d · %s", d.number, drillTitles[d.problem.Kind]))
+	score := styles.BodyMuted.Render(d.scoreView())
+	gap := max(d.width-lipgloss.Width(title)-lipgloss.Width(score), 1)
+	header := title + strings.Repeat(" ", gap) + score
+	if lipgloss.Width(header) > d.width {
+		header = title
+	}
+
+	question := lipgloss.NewStyle().Width(max(d.width, 1)).Render(d.problem.Question)
+	return lipgloss.JoinVertical(lipgloss.Left, header, "", question, "", d.input.View())
+}
+
+// scoreView sums up this session and, once loaded, every session before it
+func (d drill) scoreView() string {
+	score := fmt.Sprintf("Session %d/%d", d.session.Correct, d.session.Attempts)
+	if d.allTime.Attempts > 0 {
+		score += fmt.Sprintf(" · All time %d/%d", d.allTime.Correct, d.allTime.Attempts)
+		if d.allTime.FastestMS > 0 {
+			score += " · Best " + formatSeconds(time.Duration(d.allTime.FastestMS)*time.Millisecond)
+		}
+	}
+	return score
+}
+
+// resultView renders the verdict and, for a wrong answer, the worked steps
+func (d drill) resultView() string {
+	var lines []string
+	if d.err != nil {
+		lines = append(lines, styles.StatusWarning.Render("⚠️  Progress not saved: "+d.err.Error()), "")
+	}
+	if !d.answered {
+		return strings.Join(append(lines, styles.BodyDim.Render(d.problem.Hint+", then press enter")), "\n")
+	}
+
+	took := formatSeconds(d.took)
+	if d.correct {
+		lines = append(lines, styles.StatusSuccess.Render(fmt.Sprintf("✅ Correct! %s in %s", d.problem.Answer, took)))
+	} else {
+		lines = append(lines,
+			styles.StatusError.Render(fmt.Sprintf("❌ Not quite. The answer is %s (%s)", d.problem.Answer, took)),
+			"",
+			styles.H3.Render("Working it out"),
+		)
+		text := lipgloss.NewStyle().Width(max(d.width-3, 1))
+		for i, step := range d.problem.Steps {
+			lines = append(lines, lipgloss.JoinHorizontal(lipgloss.Top, fmt.Sprintf("%d. ", i+1), text.Render(step.Text)))
+			for _, line := range step.Lines {
+				lines = append(lines, "   "+binaryStyle.Render(line))
+			}
+		}
+	}
+	return strings.Join(append(lines, "", styles.BodyDim.Render("Press enter for the next problem")), "\n")
+}
+
+// formatSeconds renders a duration as seconds to one decimal place
+func formatSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.1fs", d.Seconds())
+}
+
+// loadDrillProgress reads the module's progress for the all-time score
+func loadDrillProgress() tea.Msg {
+	progress, err := utils.LoadProgress()
+	if err != nil {
+		return drillProgressMsg{err: err}
+	}
+	return drillProgressMsg{progress: *progress.Module(moduleID)}
+}
+
+// recordDrill adds an answer to the learner's progress
+func recordDrill(kind string, correct bool, took time.Duration) tea.Cmd {
+	return func() tea.Msg {
+		progress, err := utils.RecordDrill(moduleID, kind, correct, took)
+		return drillProgressMsg{progress: progress, err: err}
+	}
+}
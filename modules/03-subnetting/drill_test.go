@@ -0,0 +1,196 @@
+package subnetting
+
+import (
+	"fmt"
+	"math/rand"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"netlab/internal/tuitest"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestProblemAnswers(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		for _, kind := range drillKinds {
+			p := newProblem(r, kind)
+			if !p.Check(p.Answer) {
+				t.Fatalf("%s: %q does not accept its own answer %q", kind, p.Question, p.Answer)
+			}
+			if len(p.Steps) == 0 {
+				t.Fatalf("%s: %q has no steps", kind, p.Question)
+			}
+
+			switch kind {
+			case drillMembership:
+				// The calculator must agree on the network
+				question := strings.TrimSuffix(strings.TrimPrefix(p.Question, "Which subnet does "), " belong to?")
+				s, err := Calculate(question)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if s.Prefix.String() != p.Answer {
+					t.Fatalf("%q: answer %s, calculator says %s", p.Question, p.Answer, s.Prefix)
+				}
+			case drillSplit:
+				prefix := netip.MustParsePrefix(p.Answer)
+				if prefix != prefix.Masked() || prefix.Bits() > 30 {
+					t.Fatalf("%q: bad answer %s", p.Question, p.Answer)
+				}
+			case drillHosts:
+				// The VLSM planner must agree on the prefix
+				var hosts int
+				if _, err := fmt.Sscanf(p.Question, "What is the longest prefix (the smallest subnet) that fits %d", &hosts); err != nil {
+					t.Fatal(err)
+				}
+				plan := PlanVLSM(netip.MustParsePrefix("10.0.0.0/8"), []Requirement{{Name: "lan", Hosts: hosts}})
+				if want := fmt.Sprintf("/%d", plan.Subnets[0].Prefix.Bits()); p.Answer != want {
+					t.Fatalf("%q: answer %s, planner says %s", p.Question, p.Answer, want)
+				}
+				if hosts == 1 && !strings.HasSuffix(p.Question, "1 host?") {
+					t.Fatalf("%q: wrong plural", p.Question)
+				}
+			}
+		}
+	}
+}
+
+func TestProblemCheck(t *testing.T) {
+	split := Problem{Kind: drillSplit, Answer: "10.20.64.0/19"}
+	hosts := Problem{Kind: drillHosts, Answer: "/23"}
+
+	tests := []struct {
+		problem Problem
+		answer  string
+		want    bool
+	}{
+		{split, "10.20.64.0/19", true},
+		{split, "  10.20.64.0/19 ", true},
+		{split, "10.20.64.1/19", false},
+		{split, "10.20.64.0/20", false},
+		{split, "10.20.64.0", false},
+		{split, "subnet 3", false},
+		{hosts, "/23", true},
+		{hosts, "23", true},
+		{hosts, "/24", false},
+		{hosts, "255.255.254.0", false},
+	}
+	for _, tt := range tests {
+		if got := tt.problem.Check(tt.answer); got != tt.want {
+			t.Errorf("Check(%q) against %s = %v, want %v", tt.answer, tt.problem.Answer, got, tt.want)
+		}
+	}
+}
+
+func TestMembershipSteps(t *testing.T) {
+	// Find a problem in the fourth octet to compare with a worked example
+	r := rand.New(rand.NewSource(3))
+	var p Problem
+	for p.Kind == "" || !strings.Contains(p.Question, "/27 ") {
+		p = membershipProblem(r)
+	}
+
+	lines := p.Steps[0].Lines
+	if len(lines) != 3 || !strings.HasSuffix(lines[1], "11111111.11111111.11111111.11100000") {
+		t.Fatalf("unexpected binary lines %q", lines)
+	}
+	if !strings.Contains(p.Steps[1].Text, "blocks of 32 in the fourth octet") {
+		t.Errorf("unexpected shortcut %q", p.Steps[1].Text)
+	}
+}
+
+func TestBinaryMarker(t *testing.T) {
+	got := binaryMarker(16, 19)
+	want := strings.Repeat(" ", 17+8+1+8+1) + "^^^"
+	if got != want {
+		t.Errorf("binaryMarker(16, 19) =\n%q, want\n%q", got, want)
+	}
+}
+
+// testClock is a clock that moves on 7.5 seconds every time it is read
+func testClock() func() time.Time {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	return func() time.Time {
+		now = now.Add(7500 * time.Millisecond)
+		return now
+	}
+}
+
+func TestDrillView(t *testing.T) {
+	tests := []struct {
+		name string
+		seed int64
+		msgs []tea.Msg
+	}{
+		{"subnetting_100x30_drill", 1, nil},
+		{"subnetting_100x30_drill_wrong", 1, tuitest.Keys("2", "3", "enter")},
+		{"subnetting_100x30_drill_next", 1, tuitest.Keys("2", "3", "enter", "enter")},
+		{"subnetting_100x30_drill_split_wrong", 6, tuitest.Keys("1", "/", "3", "0", "enter")},
+		{"subnetting_100x30_drill_membership_wrong", 2, tuitest.Keys("1", "/", "2", "9", "enter")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewModel()
+			if err != nil {
+				t.Fatal(err)
+			}
+			m.drill = newDrill(tt.seed, testClock())
+			msgs := append(tuitest.Keys("shift+tab"), tt.msgs...)
+			tuitest.AssertView(t, tt.name, m, 100, 30, msgs...)
+		})
+	}
+}
+
+func TestDrillCorrectAnswer(t *testing.T) {
+	d := newDrill(1, testClock())
+	d.SetSize(96, 24)
+	answer := d.problem.Answer
+	for _, r := range answer {
+		d, _ = d.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	d, cmd := d.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if !d.answered || !d.correct || cmd == nil {
+		t.Fatalf("answered %v, correct %v, cmd %v", d.answered, d.correct, cmd != nil)
+	}
+	if d.took != 7500*time.Millisecond {
+		t.Errorf("took %v, want 7.5s", d.took)
+	}
+	if !strings.Contains(d.resultView(), "Correct! "+answer+" in 7.5s") {
+		t.Errorf("unexpected result:\n%s", d.resultView())
+	}
+}
+
+func TestDrillRecordsProgress(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	d := newDrill(1, testClock())
+	d.SetSize(96, 24)
+	for _, answer := range []string{"1.2.3.4/5", "nonsense"} {
+		d.input.SetValue(answer)
+		var cmd tea.Cmd
+		d, cmd = d.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		d, _ = d.Update(cmd())
+		d, _ = d.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	}
+
+	if d.err != nil {
+		t.Fatal(d.err)
+	}
+	if d.allTime.Attempts != 2 || d.allTime.Correct != 0 {
+		t.Errorf("all time %+v, want 2 attempts and none correct", d.allTime)
+	}
+	if d.session.Attempts != 2 {
+		t.Errorf("session %+v, want 2 attempts", d.session)
+	}
+
+	// A new drill picks up where the last left off
+	loaded, _ := newDrill(2, testClock()).Update(loadDrillProgress())
+	if loaded.allTime.Attempts != 2 {
+		t.Errorf("loaded %+v, want 2 attempts", loaded.allTime)
+	}
+}
@@ -0,0 +1,308 @@
+// Package subnetting is the Subnetting and CIDR learning module
+package subnetting
+
+import (
+	"embed"
+	"fmt"
+	"strings"
+	"time"
+
+	"netlab/internal/packs"
+	"netlab/pkg/components"
+	"netlab/pkg/styles"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+//go:embed README.md content
+var embedded embed.FS
+
+// screen is one of the module's tabs, in the order tab cycles through them
+type screen int
+
+const (
+	screenCalculator screen = iota
+	screenPlanner
+	screenOverlaps
+	screenLesson
+	screenQuiz
+	screenDrills
+	screenCount
+)
+
+var screenNames = map[screen]string{
+	screenCalculator: "Calculator",
+	screenPlanner:    "Planner",
+	screenOverlaps:   "Overlaps",
+	screenLesson:     "Lesson",
+	screenQuiz:       "Quiz",
+	screenDrills:     "Drills",
+}
+
+// Model is the TUI for the Subnetting and CIDR module: the CIDR
+// calculator, VLSM planner and overlap checker, its README as the lesson,
+// its quiz and practice drills
+type Model struct {
+	calculator calculator
+	planner    planner
+	checker    checker
+	drill      drill
+	lesson     components.Document
+	quiz       components.Quiz
+	screen     screen
+	width      int
+	height     int
+	quitting   bool
+}
+
+// NewModel creates a new Subnetting and CIDR module model
+func NewModel() (Model, error) {
+	readme, err := embedded.ReadFile("README.md")
+	if err != nil {
+		return Model{}, err
+	}
+	questions, err := loadQuiz()
+	if err != nil {
+		return Model{}, err
+	}
+
+	return Model{
+		calculator: newCalculator(),
+		planner:    newPlanner(),
+		checker:    newChecker(),
+		drill:      newDrill(time.Now().UnixNano(), time.Now),
+		lesson:     components.NewDocument(string(readme)),
+		quiz:       components.NewQuiz(questions),
+	}, nil
+}
+
+// loadQuiz reads content/quiz.yaml
+func loadQuiz() ([]components.QuizQuestion, error) {
+	data, err := embedded.ReadFile("content/quiz.yaml")
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := packs.ParseQuiz(data)
+	if err != nil {
+		return nil, fmt.Errorf("content/quiz.yaml: %w", err)
+	}
+
+	questions := make([]components.QuizQuestion, len(parsed))
+	for i, q := range parsed {
+		questions[i] = components.QuizQuestion{
+			Question:    q.Question,
+			Options:     q.Options,
+			Answer:      q.Answer,
+			Explanation: q.Explanation,
+			SeeAlso:     q.Lesson,
+		}
+	}
+	return questions, nil
+}
+
+func (m Model) Init() tea.Cmd {
+	return tea.Batch(textinput.Blink, m.drill.Init())
+}
+
+// contentSize returns the space inside the frame
+func (m Model) contentSize() (width, height int) {
+	// Header (2), frame border (2), separator and help (2)
+	return m.width - 4, m.height - 6
+}
+
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.lesson.SetSize(m.contentSize())
+		m.calculator.SetSize(m.contentSize())
+		m.planner.SetSize(m.contentSize())
+		m.checker.SetSize(m.contentSize())
+		m.drill.SetSize(m.contentSize())
+		return m, nil
+
+	case drillProgressMsg:
+		m.drill, _ = m.drill.Update(msg)
+		return m, nil
+
+	case planSavedMsg:
+		m.planner, _ = m.planner.Update(msg)
+		return m, nil
+
+	case configLoadedMsg:
+		m.checker, _ = m.checker.Update(msg)
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "esc":
+			m.quitting = true
+			return m, tea.Quit
+		case "q":
+			// Where there's an input, q is just a letter being typed
+			if m.screen == screenLesson || m.screen == screenQuiz {
+				m.quitting = true
+				return m, tea.Quit
+			}
+		case "tab":
+			m.screen = (m.screen + 1) % screenCount
+			return m, nil
+		case "shift+tab":
+			m.screen = (m.screen + screenCount - 1) % screenCount
+			return m, nil
+		}
+
+		if m.screen == screenQuiz {
+			m.quiz = m.quiz.Update(msg)
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	switch m.screen {
+	case screenCalculator:
+		m.calculator, cmd = m.calculator.Update(msg)
+	case screenPlanner:
+		m.planner, cmd = m.planner.Update(msg)
+	case screenOverlaps:
+		m.checker, cmd = m.checker.Update(msg)
+	case screenLesson:
+		m.lesson, cmd = m.lesson.Update(msg)
+	case screenDrills:
+		m.drill, cmd = m.drill.Update(msg)
+	}
+	return m, cmd
+}
+
+func (m Model) View() string {
+	if m.width < 60 || m.height < 20 {
+		return fmt.Sprintf("⚠️  Terminal too small (%dx%d), please resize to at least 60x20", m.width, m.height)
+	}
+
+	width, height := m.contentSize()
+	var body string
+	switch m.screen {
+	case screenCalculator:
+		body = m.calculator.View()
+	case screenPlanner:
+		body = m.planner.View()
+	case screenOverlaps:
+		body = m.checker.View()
+	case screenLesson:
+		body = m.lesson.View()
+	case screenQuiz:
+		body = m.quiz.View(width)
+	case screenDrills:
+		body = m.drill.View()
+	}
+
+	frame := lipgloss.NewStyle().
+		Width(m.width-2).
+		Height(height).
+		Padding(0, 1).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(styles.Border)
+
+	return lipgloss.JoinVertical(lipgloss.Left, m.headerView(), frame.Render(body), m.footerView())
+}
+
+func (m Model) headerView() string {
+	title := styles.H2.Copy().
+		Width(m.width-4).
+		Align(lipgloss.Center).
+		Margin(0, 0).
+		Render("NetLab Subnetting and CIDR")
+
+	breadcrumb := styles.BodyMuted.Copy().
+		Margin(0, 0).
+		Render("NetLab > Subnetting and CIDR > " + screenNames[m.screen])
+
+	return lipgloss.JoinVertical(lipgloss.Left, title, breadcrumb)
+}
+
+func (m Model) footerView() string {
+	next := styles.KeyBinding.Render("tab") + " " + strings.ToLower(screenNames[(m.screen+1)%screenCount])
+
+	var helpKeys []string
+	switch m.screen {
+	case screenCalculator:
+		helpKeys = []string{
+			styles.KeyBinding.Render("↑/↓") + " prefix length",
+			styles.KeyBinding.Render("pgup/pgdn") + " scroll",
+			next,
+			styles.KeyBinding.Render("esc") + " quit",
+		}
+	case screenPlanner:
+		helpKeys = []string{
+			styles.KeyBinding.Render("↑/↓") + " field",
+			styles.KeyBinding.Render("ctrl+s") + " save YAML",
+			styles.KeyBinding.Render("pgup/pgdn") + " scroll",
+			next,
+			styles.KeyBinding.Render("esc") + " quit",
+		}
+	case screenOverlaps:
+		helpKeys = []string{
+			styles.KeyBinding.Render("↑/↓") + " field",
+			styles.KeyBinding.Render("enter") + " load config",
+			styles.KeyBinding.Render("pgup/pgdn") + " scroll",
+			next,
+			styles.KeyBinding.Render("esc") + " quit",
+		}
+	case screenLesson:
+		helpKeys = []string{
+			styles.KeyBinding.Render("↑/↓") + " scroll",
+			next,
+			styles.KeyBinding.Render("q") + " quit",
+		}
+	case screenQuiz:
+		helpKeys = []string{
+			styles.KeyBinding.Render("↑/↓") + " choose",
+			styles.KeyBinding.Render("enter") + " answer",
+			next,
+			styles.KeyBinding.Render("q") + " quit",
+		}
+	case screenDrills:
+		helpKeys = []string{
+			styles.KeyBinding.Render("enter") + " answer/next",
+			styles.KeyBinding.Render("pgup/pgdn") + " scroll",
+			next,
+			styles.KeyBinding.Render("esc") + " quit",
+		}
+	}
+	helpText := styles.Help.Copy().UnsetMargins().Render(strings.Join(helpKeys, " • "))
+	if lipgloss.Width(helpText) > m.width-2 {
+		helpText = styles.Help.Copy().UnsetMargins().Render(strings.Join(helpKeys, "  "))
+	}
+	if lipgloss.Width(helpText) > m.width-2 {
+		// Paging is the least needed, as the mouse wheel scrolls too
+		var kept []string
+		for _, key := range helpKeys {
+			if !strings.Contains(key, "pgup/pgdn") {
+				kept = append(kept, key)
+			}
+		}
+		helpText = styles.Help.Copy().UnsetMargins().Render(strings.Join(kept, "  "))
+	}
+
+	separator := styles.BodyDim.Render(strings.Repeat("─", m.width-2))
+	return lipgloss.JoinVertical(lipgloss.Left, separator, helpText)
+}
+
+// Run starts the Subnetting and CIDR module
+func Run() error {
+	m, err := NewModel()
+	if err != nil {
+		return fmt.Errorf("loading module content: %w", err)
+	}
+
+	p := tea.NewProgram(
+		m,
+		tea.WithAltScreen(),
+		tea.WithMouseCellMotion(),
+	)
+	_, err = p.Run()
+	return err
+}
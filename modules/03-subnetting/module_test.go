@@ -0,0 +1,76 @@
+package subnetting
+
+import (
+	"strings"
+	"testing"
+
+	"netlab/internal/tuitest"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestModelView(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		msgs          []tea.Msg
+	}{
+		{"subnetting_100x30", 100, 30, nil},
+		{"subnetting_60x20", 60, 20, nil},
+		{"subnetting_59x20_too_small", 59, 20, nil},
+		{"subnetting_100x30_prefix_26", 100, 30, tuitest.Keys("up", "up")},
+		{"subnetting_100x30_typed_ipv6", 100, 30, append(clearInput(), tuitest.Keys("2", "0", "0", "1", ":", "d", "b", "8", ":", ":", "1", "/", "6", "4")...)},
+		{"subnetting_60x20_typed_ipv6", 60, 20, append(clearInput(), tuitest.Keys("2", "0", "0", "1", ":", "d", "b", "8", ":", ":", "1", "/", "6", "4")...)},
+		{"subnetting_100x30_invalid", 100, 30, tuitest.Keys("backspace", "backspace", "backspace", "3", "3")},
+		{"subnetting_100x30_planner", 100, 30, tuitest.Keys("tab")},
+		{"subnetting_60x20_planner", 60, 20, tuitest.Keys("tab")},
+		{"subnetting_100x30_planner_unfit", 100, 30, append(tuitest.Keys("tab", "down"), tuitest.Keys(strings.Split(", big=40000", "")...)...)},
+		{"subnetting_100x30_overlaps", 100, 30, tuitest.Keys("tab", "tab")},
+		{"subnetting_60x20_overlaps", 60, 20, tuitest.Keys("tab", "tab")},
+		{"subnetting_100x30_overlaps_within", 100, 30, append(tuitest.Keys("tab", "tab", "down"), tuitest.Keys(strings.Split("10.96.0.0/11", "")...)...)},
+		{"subnetting_100x30_lesson", 100, 30, tuitest.Keys("tab", "tab", "tab")},
+		{"subnetting_100x30_quiz", 100, 30, tuitest.Keys("tab", "tab", "tab", "tab")},
+		{"subnetting_100x30_quiz_answered", 100, 30, tuitest.Keys("tab", "tab", "tab", "tab", "enter")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewModel()
+			if err != nil {
+				t.Fatal(err)
+			}
+			tuitest.AssertView(t, tt.name, m, tt.width, tt.height, tt.msgs...)
+		})
+	}
+}
+
+// clearInput deletes the calculator's starting value
+func clearInput() []tea.Msg {
+	return tuitest.Keys(strings.Split(strings.Repeat("backspace ", len(defaultCIDR)), " ")[:len(defaultCIDR)]...)
+}
+
+func TestQuitKeys(t *testing.T) {
+	m, err := NewModel()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// q is typed into the calculator, but quits the other screens
+	calc := tuitest.Send(m, tuitest.Resize(100, 30), tuitest.Key("q")).(Model)
+	if calc.quitting || calc.calculator.input.Value() != defaultCIDR+"q" {
+		t.Errorf("q on the calculator: quitting %v, input %q", calc.quitting, calc.calculator.input.Value())
+	}
+	lesson := tuitest.Send(m, tuitest.Resize(100, 30), tuitest.Key("shift+tab"), tuitest.Key("shift+tab"), tuitest.Key("q")).(Model)
+	if !lesson.quitting {
+		t.Error("q on the lesson did not quit")
+	}
+}
+
+func TestQuizContent(t *testing.T) {
+	questions, err := loadQuiz()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(questions) == 0 {
+		t.Error("quiz has no questions")
+	}
+}
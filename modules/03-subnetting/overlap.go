@@ -0,0 +1,200 @@
+package subnetting
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"net/netip"
+	"sort"
+	"strings"
+)
+
+// Range is a named block the overlap checker compares, such as a
+// cluster's pod CIDR
+type Range struct {
+	Name   string
+	Prefix netip.Prefix
+	Source string // Where the range came from when it wasn't typed in
+}
+
+func (r Range) String() string {
+	return r.Name + " " + r.Prefix.String()
+}
+
+// Conflict is two ranges that share addresses. CIDR blocks can't partly
+// overlap: they're the same block, or one contains the other.
+type Conflict struct {
+	Outer Range
+	Inner Range
+}
+
+// Same reports whether both ranges are the same block
+func (c Conflict) Same() bool {
+	return c.Outer.Prefix == c.Inner.Prefix
+}
+
+func (c Conflict) String() string {
+	if c.Same() {
+		return fmt.Sprintf("%s and %s are both %s", c.Outer.Name, c.Inner.Name, c.Outer.Prefix)
+	}
+	return fmt.Sprintf("%s contains %s", c.Outer, c.Inner)
+}
+
+// FreeSpace is what's left of a block once the ranges are taken out
+type FreeSpace struct {
+	Within netip.Prefix
+	Blocks []netip.Prefix
+	Free   *big.Int // Addresses in Blocks
+}
+
+// OverlapReport is the result of checking a set of ranges
+type OverlapReport struct {
+	Ranges    []Range
+	Conflicts []Conflict
+	Free      []FreeSpace
+}
+
+// ulaPrefix is the IPv6 equivalent of the RFC 1918 ranges (RFC 4193)
+var ulaPrefix = netip.MustParsePrefix("fc00::/7")
+
+// ParseRanges reads a list like "pods=10.244.0.0/16, services=10.96.0.0/12".
+// Names and blocks may also be separated by a colon.
+func ParseRanges(input string) ([]Range, error) {
+	fields := strings.FieldsFunc(input, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+	if len(fields) == 0 {
+		return nil, errors.New("list the ranges to check, like pods=10.244.0.0/16, services=10.96.0.0/12")
+	}
+
+	var ranges []Range
+	seen := map[string]bool{}
+	for _, field := range fields {
+		name, block, ok := strings.Cut(field, "=")
+		if !ok {
+			// IPv6 blocks are full of colons, so only the first one counts
+			name, block, ok = strings.Cut(field, ":")
+		}
+		if !ok || name == "" {
+			return nil, fmt.Errorf("%q should be name=cidr", field)
+		}
+		prefix, err := netip.ParsePrefix(block)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %q is not a valid CIDR block", name, block)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("%s is listed twice", name)
+		}
+		seen[name] = true
+		ranges = append(ranges, Range{Name: name, Prefix: prefix.Masked()})
+	}
+	return ranges, nil
+}
+
+// MergeRanges adds extra to ranges, replacing any with the same name
+func MergeRanges(ranges, extra []Range) []Range {
+	merged := append([]Range(nil), ranges...)
+	for _, e := range extra {
+		replaced := false
+		for i := range merged {
+			if merged[i].Name == e.Name {
+				merged[i] = e
+				replaced = true
+			}
+		}
+		if !replaced {
+			merged = append(merged, e)
+		}
+	}
+	return merged
+}
+
+// FormatRanges writes ranges the way ParseRanges reads them
+func FormatRanges(ranges []Range) string {
+	fields := make([]string, len(ranges))
+	for i, r := range ranges {
+		fields[i] = r.Name + "=" + r.Prefix.String()
+	}
+	return strings.Join(fields, ", ")
+}
+
+// CheckOverlaps finds every pair of ranges that share addresses, and the
+// free space left in each of the within blocks. With no within blocks, the
+// free space is reported for the private ranges the ranges fall in.
+func CheckOverlaps(ranges []Range, within []netip.Prefix) OverlapReport {
+	report := OverlapReport{Ranges: ranges}
+	for i, a := range ranges {
+		for _, b := range ranges[i+1:] {
+			if !a.Prefix.Overlaps(b.Prefix) {
+				continue
+			}
+			outer, inner := a, b
+			if b.Prefix.Bits() < a.Prefix.Bits() {
+				outer, inner = b, a
+			}
+			report.Conflicts = append(report.Conflicts, Conflict{Outer: outer, Inner: inner})
+		}
+	}
+
+	if len(within) == 0 {
+		within = privateBlocksFor(ranges)
+	}
+	for _, w := range within {
+		report.Free = append(report.Free, freeSpace(w.Masked(), ranges))
+	}
+	return report
+}
+
+// privateBlocksFor returns the private ranges that any of the ranges
+// fall in, in the usual order
+func privateBlocksFor(ranges []Range) []netip.Prefix {
+	var blocks []netip.Prefix
+	for _, p := range append(append([]netip.Prefix(nil), privateRanges...), cgnatPrefix, ulaPrefix) {
+		for _, r := range ranges {
+			if p.Overlaps(r.Prefix) {
+				blocks = append(blocks, p)
+				break
+			}
+		}
+	}
+	return blocks
+}
+
+// freeSpace takes the ranges out of a block and sums up what's left
+func freeSpace(within netip.Prefix, ranges []Range) FreeSpace {
+	addrBits := within.Addr().BitLen()
+	start := addrInt(within.Addr())
+	end := new(big.Int).Add(start, blockSize(addrBits-within.Bits()))
+
+	// Every range either contains the whole block or sits inside it
+	type span struct{ from, to *big.Int }
+	var used []span
+	for _, r := range ranges {
+		switch {
+		case !within.Overlaps(r.Prefix):
+		case r.Prefix.Bits() <= within.Bits():
+			used = append(used, span{start, end})
+		default:
+			from := addrInt(r.Prefix.Addr())
+			used = append(used, span{from, new(big.Int).Add(from, blockSize(addrBits-r.Prefix.Bits()))})
+		}
+	}
+	sort.Slice(used, func(i, j int) bool { return used[i].from.Cmp(used[j].from) < 0 })
+
+	free := FreeSpace{Within: within, Free: new(big.Int)}
+	next := start
+	for _, u := range used {
+		if u.from.Cmp(next) > 0 {
+			free.Blocks = append(free.Blocks, rangePrefixes(next, u.from, addrBits)...)
+			free.Free.Add(free.Free, new(big.Int).Sub(u.from, next))
+		}
+		if u.to.Cmp(next) > 0 {
+			next = u.to
+		}
+	}
+	if next.Cmp(end) < 0 {
+		free.Blocks = append(free.Blocks, rangePrefixes(next, end, addrBits)...)
+		free.Free.Add(free.Free, new(big.Int).Sub(end, next))
+	}
+	return free
+}
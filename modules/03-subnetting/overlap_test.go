@@ -0,0 +1,194 @@
+package subnetting
+
+import (
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseRanges(t *testing.T) {
+	got, err := ParseRanges("pods=10.244.1.0/16, services:10.96.0.0/12 v6:fd00:10:244::/56")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := FormatRanges(got); s != "pods=10.244.0.0/16, services=10.96.0.0/12, v6=fd00:10:244::/56" {
+		t.Errorf("got %s", s)
+	}
+
+	for input, wantErr := range map[string]string{
+		"":                          "list the ranges to check",
+		"10.0.0.0/8":                `"10.0.0.0/8" should be name=cidr`,
+		"pods=10.244.0.0":           `pods: "10.244.0.0" is not a valid CIDR block`,
+		"a=10.0.0.0/8 a=10.0.0.0/9": "a is listed twice",
+	} {
+		if _, err := ParseRanges(input); err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("ParseRanges(%q) error = %v, want %q", input, err, wantErr)
+		}
+	}
+}
+
+func TestCheckOverlaps(t *testing.T) {
+	ranges, err := ParseRanges(defaultRanges + ", lab=10.100.0.0/16, office=192.168.1.0/24")
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := CheckOverlaps(ranges, nil)
+
+	var conflicts []string
+	for _, c := range report.Conflicts {
+		conflicts = append(conflicts, c.String())
+	}
+	want := []string{
+		"services 10.96.0.0/12 contains vpn 10.100.0.0/16",
+		"services 10.96.0.0/12 contains lab 10.100.0.0/16",
+		"vpn and lab are both 10.100.0.0/16",
+	}
+	if !reflect.DeepEqual(conflicts, want) {
+		t.Errorf("conflicts = %q, want %q", conflicts, want)
+	}
+
+	var within []string
+	for _, f := range report.Free {
+		within = append(within, f.Within.String())
+	}
+	if want := []string{"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16"}; !reflect.DeepEqual(within, want) {
+		t.Errorf("free space reported in %q, want %q", within, want)
+	}
+
+	// 10/8 less the Service /12 and the pod /16
+	if got := FormatCount(report.Free[0].Free); got != "15,663,104" {
+		t.Errorf("free in 10.0.0.0/8 = %s", got)
+	}
+}
+
+func TestFreeSpace(t *testing.T) {
+	tests := []struct {
+		within string
+		ranges string
+		want   []string
+	}{
+		{"10.0.0.0/24", "a=10.0.0.0/26, b=10.0.0.128/26", []string{"10.0.0.64/26", "10.0.0.192/26"}},
+		{"10.0.0.0/24", "a=10.0.0.0/25, b=10.0.0.0/26", []string{"10.0.0.128/25"}},
+		{"10.0.0.0/24", "a=10.0.0.0/8", nil},
+		{"10.0.0.0/24", "a=192.168.0.0/16", []string{"10.0.0.0/24"}},
+		{"fd00::/62", "a=fd00:0:0:1::/64", []string{"fd00::/64", "fd00:0:0:2::/63"}},
+	}
+	for _, tt := range tests {
+		ranges, err := ParseRanges(tt.ranges)
+		if err != nil {
+			t.Fatal(err)
+		}
+		free := freeSpace(netip.MustParsePrefix(tt.within), ranges)
+		var got []string
+		for _, b := range free.Blocks {
+			got = append(got, b.String())
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("free in %s less %s = %q, want %q", tt.within, tt.ranges, got, tt.want)
+		}
+	}
+}
+
+func TestParseClusterConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   []Range
+	}{
+		{
+			name:   "kind defaults",
+			config: "kind: Cluster\napiVersion: kind.x-k8s.io/v1alpha4\n",
+			want: []Range{
+				{"pods", netip.MustParsePrefix("10.244.0.0/16"), "kind default"},
+				{"services", netip.MustParsePrefix("10.96.0.0/16"), "kind default"},
+			},
+		},
+		{
+			name: "kind dual-stack",
+			config: `kind: Cluster
+apiVersion: kind.x-k8s.io/v1alpha4
+networking:
+  ipFamily: dual
+  podSubnet: 10.10.0.0/16,fd00:10:10::/56
+`,
+			want: []Range{
+				{"pods", netip.MustParsePrefix("10.10.0.0/16"), "kind config"},
+				{"pods-ipv6", netip.MustParsePrefix("fd00:10:10::/56"), "kind config"},
+				{"services", netip.MustParsePrefix("10.96.0.0/16"), "kind default"},
+				{"services-ipv6", netip.MustParsePrefix("fd00:10:96::/112"), "kind default"},
+			},
+		},
+		{
+			name: "kubeadm without a pod subnet",
+			config: `apiVersion: kubeadm.k8s.io/v1beta3
+kind: ClusterConfiguration
+`,
+			want: []Range{{"services", netip.MustParsePrefix("10.96.0.0/12"), "kubeadm default"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseClusterConfig([]byte(tt.config))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	for config, wantErr := range map[string]string{
+		"apiVersion: v1\nkind: ConfigMap\n": "no kind Cluster or kubeadm ClusterConfiguration found",
+		"kind: [":                           "invalid YAML",
+		"kind: Cluster\napiVersion: kind.x-k8s.io/v1alpha4\nnetworking:\n  ipFamily: ipv5\n":       `unknown ipFamily "ipv5"`,
+		"kind: Cluster\napiVersion: kind.x-k8s.io/v1alpha4\nnetworking:\n  podSubnet: 10.244/16\n": `pods subnet "10.244/16" is not a valid CIDR block`,
+	} {
+		if _, err := ParseClusterConfig([]byte(config)); err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("ParseClusterConfig(%q) error = %v, want %q", config, err, wantErr)
+		}
+	}
+}
+
+func TestReadClusterConfig(t *testing.T) {
+	// The config create_cluster gives kind, which leaves both to the defaults
+	kind, err := ReadClusterConfig("testdata/kind-netlab.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := FormatRanges(kind); got != "pods=10.244.0.0/16, services=10.96.0.0/16" {
+		t.Errorf("kind config gave %s", got)
+	}
+
+	// kubeadm configs often start with an InitConfiguration
+	kubeadm, err := ReadClusterConfig("testdata/kubeadm.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := FormatRanges(kubeadm); got != "pods=192.168.0.0/16, services=10.96.0.0/12" {
+		t.Errorf("kubeadm config gave %s", got)
+	}
+
+	if _, err := ReadClusterConfig("testdata/missing.yaml"); err == nil {
+		t.Error("missing config did not fail")
+	}
+}
+
+func TestCheckerLoadsConfig(t *testing.T) {
+	c := newChecker()
+	c.SetSize(96, 24)
+	c, _ = c.Update(loadClusterConfig("testdata/kind-netlab.yaml")())
+
+	want := "pods=10.244.0.0/16, services=10.96.0.0/16, nodes=172.18.0.0/16, vpn=10.100.0.0/16"
+	if got := c.inputs[fieldRanges].Value(); got != want {
+		t.Errorf("ranges = %q, want %q", got, want)
+	}
+	// kind's smaller Service range leaves room for the VPN
+	if len(c.report.Conflicts) != 0 {
+		t.Errorf("unexpected conflicts %v", c.report.Conflicts)
+	}
+	if view := c.bodyView(); !strings.Contains(view, "pods (kind default), services (kind default)") {
+		t.Errorf("load not reported:\n%s", view)
+	}
+}
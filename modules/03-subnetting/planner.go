@@ -0,0 +1,278 @@
+package subnetting
+
+import (
+	"fmt"
+	"math/big"
+	"net/netip"
+	"os"
+	"strconv"
+	"strings"
+
+	"netlab/pkg/styles"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// What the planner starts with: a typical cluster carved out of a /16
+const (
+	defaultParent       = "10.0.0.0/16"
+	defaultRequirements = "pods=8000, services=2000, nodes=200, vpn=50"
+)
+
+// planFile is where ctrl+s saves the plan, in the working directory
+const planFile = "vlsm-plan.yaml"
+
+// allocationColours tell neighbouring subnets apart in the address-space
+// bar, which also alternates glyphs so it reads without colour
+var allocationColours = []lipgloss.Color{styles.Primary, styles.Secondary, styles.Accent, styles.Success, styles.Info}
+
+var allocationGlyphs = []string{"█", "▓"}
+
+// planSavedMsg reports the outcome of saving the plan
+type planSavedMsg struct {
+	path string
+	err  error
+}
+
+// planner is the VLSM planner: a parent block and a list of named host
+// counts, and the allocation the planner makes from them
+type planner struct {
+	parent   textinput.Model
+	needs    textinput.Model
+	plan     Plan
+	err      error
+	saved    planSavedMsg
+	savePath string
+	body     viewport.Model
+	width    int
+}
+
+func newPlanner() planner {
+	parent := textinput.New()
+	parent.Prompt = "Block › "
+	parent.PromptStyle = styles.KeyBinding
+	parent.CharLimit = 64
+	parent.SetValue(defaultParent)
+	parent.Focus()
+
+	needs := textinput.New()
+	needs.Prompt = "Needs › "
+	needs.PromptStyle = styles.KeyBinding
+	needs.CharLimit = 512
+	needs.SetValue(defaultRequirements)
+
+	p := planner{parent: parent, needs: needs, savePath: planFile, body: viewport.New(0, 0)}
+	p.calculate()
+	return p
+}
+
+// SetSize fits the planner into width x height cells
+func (p *planner) SetSize(width, height int) {
+	p.width = width
+	p.parent.Width = width - lipgloss.Width(p.parent.Prompt) - 1
+	p.needs.Width = width - lipgloss.Width(p.needs.Prompt) - 1
+	// Rescroll for the new width
+	p.parent.SetCursor(p.parent.Position())
+	p.needs.SetCursor(p.needs.Position())
+	p.body.Width = width
+	p.body.Height = height - 3 // Both input lines and the gap below them
+	p.body.SetContent(p.bodyView())
+}
+
+// calculate replans for the current inputs
+func (p *planner) calculate() {
+	p.saved = planSavedMsg{}
+	p.err = nil
+	parent, err := netip.ParsePrefix(strings.TrimSpace(p.parent.Value()))
+	if err != nil {
+		p.err = fmt.Errorf("%q is not a valid CIDR block", strings.TrimSpace(p.parent.Value()))
+	} else if reqs, err := ParseRequirements(p.needs.Value()); err != nil {
+		p.err = err
+	} else {
+		p.plan = PlanVLSM(parent, reqs)
+	}
+	p.body.SetContent(p.bodyView())
+}
+
+func (p planner) Update(msg tea.Msg) (planner, tea.Cmd) {
+	var cmd tea.Cmd
+	switch msg := msg.(type) {
+	case planSavedMsg:
+		p.saved = msg
+		p.body.SetContent(p.bodyView())
+		return p, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "up", "down":
+			if p.parent.Focused() {
+				p.parent.Blur()
+				p.needs.Focus()
+			} else {
+				p.needs.Blur()
+				p.parent.Focus()
+			}
+			return p, textinput.Blink
+		case "pgup", "pgdown":
+			p.body, cmd = p.body.Update(msg)
+			return p, cmd
+		case "ctrl+s":
+			if p.err != nil {
+				return p, nil
+			}
+			return p, savePlan(p.plan, p.savePath)
+		}
+
+		if p.parent.Focused() {
+			p.parent, cmd = p.parent.Update(msg)
+		} else {
+			p.needs, cmd = p.needs.Update(msg)
+		}
+		p.calculate()
+		return p, cmd
+
+	case tea.MouseMsg:
+		p.body, cmd = p.body.Update(msg)
+		return p, cmd
+	}
+
+	if p.parent.Focused() {
+		p.parent, cmd = p.parent.Update(msg)
+	} else {
+		p.needs, cmd = p.needs.Update(msg)
+	}
+	return p, cmd
+}
+
+func (p planner) View() string {
+	return p.parent.View() + "\n" + p.needs.View() + "\n\n" + p.body.View()
+}
+
+// bodyView renders the allocation table, the address-space bar and
+// anything that didn't fit
+func (p planner) bodyView() string {
+	if p.err != nil {
+		return styles.StatusError.Render("⚠️  " + p.err.Error())
+	}
+
+	sections := []string{p.tableView(), p.barView()}
+	if len(p.plan.Unallocated) > 0 {
+		lines := []string{styles.StatusError.Render(fmt.Sprintf("⚠️  %d of %d subnets don't fit",
+			len(p.plan.Unallocated), len(p.plan.Unallocated)+len(p.plan.Subnets)))}
+		for _, u := range p.plan.Unallocated {
+			lines = append(lines, fmt.Sprintf("  %s (%d hosts) %s", u.Name, u.Hosts, u.Reason))
+		}
+		sections = append(sections, strings.Join(lines, "\n"))
+	}
+
+	free := "none"
+	if len(p.plan.Free) > 0 {
+		blocks := make([]string, len(p.plan.Free))
+		for i, f := range p.plan.Free {
+			blocks[i] = f.String()
+		}
+		free = strings.Join(blocks, ", ")
+	}
+	label := styles.BodyMuted.Copy().Width(labelWidth)
+	value := lipgloss.NewStyle().Width(max(p.width-labelWidth, 1))
+	sections = append(sections, lipgloss.JoinHorizontal(lipgloss.Top, label.Render("Free"), value.Render(free)))
+
+	switch {
+	case p.saved.err != nil:
+		sections = append(sections, styles.StatusError.Render("⚠️  Couldn't save the plan: "+p.saved.err.Error()))
+	case p.saved.path != "":
+		sections = append(sections, styles.StatusSuccess.Render("✅ Saved the plan to "+p.saved.path))
+	}
+	return strings.Join(sections, "\n\n")
+}
+
+// tableView lists the allocations in address order, leaving out the host
+// ranges when the terminal is narrow
+func (p planner) tableView() string {
+	nameWidth := len("Name")
+	for _, a := range p.plan.Subnets {
+		nameWidth = max(nameWidth, lipgloss.Width(a.Name))
+	}
+	columns := []int{nameWidth + 4, 10, 20, 12}
+	showRange := p.width >= nameWidth+4+10+20+12+33
+
+	row := func(swatch string, cells ...string) string {
+		out := swatch
+		for i, cell := range cells {
+			if i < len(columns) {
+				cell = lipgloss.NewStyle().Width(columns[i]).Render(cell)
+			}
+			out += cell
+		}
+		return out
+	}
+
+	header := []string{"Name", "Hosts", "Subnet", "Usable"}
+	if showRange {
+		header = append(header, "Host range")
+	}
+	lines := []string{styles.BodyMuted.Render(row("  ", header...))}
+	for i, a := range p.plan.Subnets {
+		cells := []string{a.Name, strconv.Itoa(a.Hosts), a.Prefix.String(), FormatCount(new(big.Int).SetUint64(a.Usable))}
+		if showRange {
+			s, _ := Calculate(a.Prefix.String())
+			cells = append(cells, fmt.Sprintf("%s – %s", s.FirstHost, s.LastHost))
+		}
+		lines = append(lines, row(allocationStyle(i).Render(allocationGlyphs[i%2])+" ", cells...))
+	}
+	if len(p.plan.Subnets) == 0 {
+		lines = append(lines, styles.BodyDim.Render("  Nothing fits in "+p.plan.Parent.String()))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// barView draws the parent block as a bar, each subnet taking its share
+func (p planner) barView() string {
+	width := max(p.width, 1)
+	cells := make([]string, width)
+	for i := range cells {
+		cells[i] = styles.BodyDim.Render("░")
+	}
+
+	start := addrInt(p.plan.Parent.Addr())
+	total := blockSize(p.plan.Parent.Addr().BitLen() - p.plan.Parent.Bits())
+	cell := func(offset *big.Int) int {
+		scaled := new(big.Int).Mul(offset, big.NewInt(int64(width)))
+		return int(scaled.Div(scaled, total).Int64())
+	}
+
+	used := new(big.Int)
+	for i, a := range p.plan.Subnets {
+		offset := new(big.Int).Sub(addrInt(a.Prefix.Addr()), start)
+		size := blockSize(a.Prefix.Addr().BitLen() - a.Prefix.Bits())
+		from := cell(offset)
+		to := cell(new(big.Int).Add(offset, size))
+		// Every subnet gets at least one cell, however small
+		for c := from; c < min(max(to, from+1), width); c++ {
+			cells[c] = allocationStyle(i).Render(allocationGlyphs[i%2])
+		}
+		used.Add(used, size)
+	}
+	percent := new(big.Int).Div(new(big.Int).Mul(used, big.NewInt(100)), total)
+	caption := styles.BodyMuted.Render(fmt.Sprintf("%s: %s of %s addresses allocated (%d%%), ░ free",
+		p.plan.Parent, FormatCount(used), FormatCount(total), percent.Int64()))
+	return strings.Join(cells, "") + "\n" + caption
+}
+
+func allocationStyle(i int) lipgloss.Style {
+	return lipgloss.NewStyle().Foreground(allocationColours[i%len(allocationColours)])
+}
+
+// savePlan writes the plan as YAML
+func savePlan(plan Plan, path string) tea.Cmd {
+	return func() tea.Msg {
+		data, err := plan.YAML()
+		if err == nil {
+			err = os.WriteFile(path, data, 0644)
+		}
+		return planSavedMsg{path: path, err: err}
+	}
+}
@@ -0,0 +1,246 @@
+package subnetting
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+	"math/rand"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+// Drill kinds, as recorded in the learner's progress
+const (
+	drillSplit      = "split"
+	drillMembership = "membership"
+	drillHosts      = "hosts"
+)
+
+// drillKinds are the kinds of practice problem, in the order they're introduced
+var drillKinds = []string{drillSplit, drillMembership, drillHosts}
+
+var drillTitles = map[string]string{
+	drillSplit:      "Split a block",
+	drillMembership: "Find the subnet",
+	drillHosts:      "Size a subnet",
+}
+
+// Step is one step of a worked answer, with optional preformatted lines
+// such as addresses written out in binary
+type Step struct {
+	Text  string
+	Lines []string
+}
+
+// Problem is a generated practice problem with its worked answer
+type Problem struct {
+	Kind     string
+	Question string
+	Hint     string // How to write the answer
+	Answer   string
+	Steps    []Step
+}
+
+// Check reports whether an answer is exactly right. Prefix lengths may be
+// given with or without the slash; addresses must be in canonical form.
+func (p Problem) Check(answer string) bool {
+	answer = strings.TrimSpace(answer)
+	if p.Kind == drillHosts {
+		return "/"+strings.TrimPrefix(answer, "/") == p.Answer
+	}
+	got, err := netip.ParsePrefix(answer)
+	if err != nil {
+		return false
+	}
+	return got == netip.MustParsePrefix(p.Answer)
+}
+
+// privateRanges are where drill problems take their addresses from, so
+// they look like the networks learners will meet in clusters
+var privateRanges = []netip.Prefix{
+	netip.MustParsePrefix("10.0.0.0/8"),
+	netip.MustParsePrefix("172.16.0.0/12"),
+	netip.MustParsePrefix("192.168.0.0/16"),
+}
+
+// newProblem generates a problem of the given kind
+func newProblem(r *rand.Rand, kind string) Problem {
+	switch kind {
+	case drillSplit:
+		return splitProblem(r)
+	case drillMembership:
+		return membershipProblem(r)
+	default:
+		return hostsProblem(r)
+	}
+}
+
+// randomBlock picks a random private IPv4 block with a prefix length
+// between minBits and maxBits
+func randomBlock(r *rand.Rand, minBits, maxBits int) netip.Prefix {
+	var candidates []netip.Prefix
+	for _, p := range privateRanges {
+		if p.Bits() <= maxBits {
+			candidates = append(candidates, p)
+		}
+	}
+	base := candidates[r.Intn(len(candidates))]
+	low := max(minBits, base.Bits())
+	length := low + r.Intn(maxBits-low+1)
+
+	host := r.Uint32() & (1<<(32-base.Bits()) - 1)
+	addr := fromUint32(toUint32(base.Addr()) | host)
+	return netip.PrefixFrom(addr, length).Masked()
+}
+
+// splitProblem asks for one of the subnets when a block is split evenly
+func splitProblem(r *rand.Rand) Problem {
+	block := randomBlock(r, 16, 26)
+	count := 3 + r.Intn(min(62, 1<<(30-block.Bits())-2))
+	borrowed := bits.Len(uint(count - 1))
+	length := block.Bits() + borrowed
+	index := r.Intn(count)
+	size := uint32(1) << (32 - length)
+	subnet := netip.PrefixFrom(fromUint32(toUint32(block.Addr())+uint32(index)*size), length)
+
+	need := fmt.Sprintf("%d subnets need %d borrowed bits: 2^%d = %d is enough", count, borrowed, borrowed, 1<<borrowed)
+	if 1<<borrowed != count {
+		need = fmt.Sprintf("%d subnets need %d borrowed bits: 2^%d = %d is too few, 2^%d = %d is enough",
+			count, borrowed, borrowed-1, 1<<(borrowed-1), borrowed, 1<<borrowed)
+	}
+
+	return Problem{
+		Kind:     drillSplit,
+		Question: fmt.Sprintf("Split %s into %d equal subnets. What is subnet #%d?", block, count, index+1),
+		Hint:     "Answer in CIDR notation, like 10.0.0.0/24",
+		Answer:   subnet.String(),
+		Steps: []Step{
+			{Text: need + "."},
+			{Text: fmt.Sprintf("/%d + %d = /%d, so each subnet spans 2^%d = %s addresses.",
+				block.Bits(), borrowed, length, 32-length, strconv.FormatUint(uint64(size), 10))},
+			{Text: fmt.Sprintf("Subnet #%d is number %d counting from zero, %0*b in binary. Write it into the borrowed bits:",
+				index+1, index, borrowed, index),
+				Lines: []string{
+					binaryLine(block.Addr().String(), block.Addr()),
+					binaryLine(subnet.Addr().String(), subnet.Addr()),
+					binaryMarker(block.Bits(), length),
+				}},
+			{Text: fmt.Sprintf("The answer is %s.", subnet)},
+		},
+	}
+}
+
+// membershipProblem asks which subnet an address with a prefix belongs to
+func membershipProblem(r *rand.Rand) Problem {
+	block := randomBlock(r, 8, 30)
+	addr := fromUint32(toUint32(block.Addr()) | r.Uint32()&(1<<(32-block.Bits())-1))
+	if addr == block.Addr() {
+		addr = addr.Next()
+	}
+	length := block.Bits()
+	mask := maskAddr(32, length, false)
+
+	steps := []Step{
+		{Text: fmt.Sprintf("A /%d keeps the first %d bits of the address and clears the other %d. AND the address with the mask:", length, length, 32-length),
+			Lines: []string{
+				binaryLine("Address", addr),
+				binaryLine("Mask", mask),
+				binaryLine("AND", block.Addr()),
+			}},
+	}
+	if length%8 != 0 {
+		octet := length / 8
+		size := 1 << (8 - length%8)
+		value := int(addr.As4()[octet])
+		start := value / size * size
+		steps = append(steps, Step{Text: fmt.Sprintf("Shortcut: a /%d moves in blocks of %d in the %s octet, and %d falls in %d-%d.",
+			length, size, ordinal(octet+1), value, start, start+size-1)})
+	}
+	steps = append(steps, Step{Text: fmt.Sprintf("The answer is %s.", block)})
+
+	return Problem{
+		Kind:     drillMembership,
+		Question: fmt.Sprintf("Which subnet does %s/%d belong to?", addr, length),
+		Hint:     "Answer in CIDR notation, like 10.0.0.0/24",
+		Answer:   block.String(),
+		Steps:    steps,
+	}
+}
+
+// hostsProblem asks for the longest prefix with room for a number of hosts.
+// Like the calculator and the VLSM planner, it counts a /31 as two hosts
+// and a /32 as one (RFC 3021).
+func hostsProblem(r *rand.Rand) Problem {
+	size := 2 + r.Intn(12)
+	fits := 1<<size - 2
+	fewer := 1<<(size-1) - 2
+	hosts := fewer + 1 + r.Intn(fits-fewer)
+	hostBits := hostBitsFor(hosts, true)
+	length := 32 - hostBits
+	mask := maskAddr(32, length, false)
+
+	noun := "hosts"
+	if hosts == 1 {
+		noun = "host"
+	}
+	var steps []Step
+	switch hosts {
+	case 1:
+		steps = append(steps, Step{Text: "A single host needs no network or broadcast address, so one address is enough: a host route."})
+	case 2:
+		steps = append(steps, Step{Text: "Two hosts fit a point-to-point link, which RFC 3021 lets drop the network and broadcast addresses, so 2 addresses are enough."})
+	default:
+		steps = append(steps,
+			Step{Text: fmt.Sprintf("%d hosts plus the network and broadcast addresses need %d addresses.", hosts, hosts+2)},
+			Step{Text: fmt.Sprintf("2^%d = %d is too few, 2^%d = %d is enough, so the subnet needs %d host bits.",
+				hostBits-1, 1<<(hostBits-1), hostBits, 1<<hostBits, hostBits)})
+	}
+	steps = append(steps, Step{Text: fmt.Sprintf("32 - %d = /%d, which is this mask:", hostBits, length),
+		Lines: []string{binaryLine(mask.String(), mask)}})
+
+	return Problem{
+		Kind:     drillHosts,
+		Question: fmt.Sprintf("What is the longest prefix (the smallest subnet) that fits %d %s?", hosts, noun),
+		Hint:     "Answer with a prefix length, like /24",
+		Answer:   fmt.Sprintf("/%d", length),
+		Steps:    steps,
+	}
+}
+
+// binaryLine writes an IPv4 address in dotted binary after a label
+func binaryLine(label string, addr netip.Addr) string {
+	return fmt.Sprintf("%-16s %s", label, strings.Join(Binary(addr), "."))
+}
+
+// binaryMarker underlines bits from..to of a binaryLine, counting the dots
+func binaryMarker(from, to int) string {
+	var b strings.Builder
+	b.WriteString(strings.Repeat(" ", 17))
+	for i := 0; i < to; i++ {
+		if i > 0 && i%8 == 0 {
+			b.WriteByte(' ')
+		}
+		if i < from {
+			b.WriteByte(' ')
+		} else {
+			b.WriteByte('^')
+		}
+	}
+	return strings.TrimRight(b.String(), " ")
+}
+
+func ordinal(n int) string {
+	return map[int]string{1: "first", 2: "second", 3: "third", 4: "fourth"}[n]
+}
+
+func toUint32(addr netip.Addr) uint32 {
+	b := addr.As4()
+	return binary.BigEndian.Uint32(b[:])
+}
+
+func fromUint32(n uint32) netip.Addr {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], n)
+	return netip.AddrFrom4(b)
+}
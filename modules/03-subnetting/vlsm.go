@@ -0,0 +1,207 @@
+package subnetting
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"math/bits"
+	"net/netip"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// maxRequirementHosts keeps requirements to sizes anyone would plan by
+// host count, so every block fits comfortably in a uint64
+const maxRequirementHosts = 1 << 48
+
+// Requirement is a named subnet the planner has to find room for
+type Requirement struct {
+	Name  string `yaml:"name"`
+	Hosts int    `yaml:"hosts"`
+}
+
+// Allocation is the subnet the planner gave a requirement
+type Allocation struct {
+	Name   string       `yaml:"name"`
+	Hosts  int          `yaml:"hosts"`
+	Prefix netip.Prefix `yaml:"cidr"`
+	Usable uint64       `yaml:"usable"`
+}
+
+// Unfit is a requirement the planner couldn't find room for
+type Unfit struct {
+	Name   string `yaml:"name"`
+	Hosts  int    `yaml:"hosts"`
+	Reason string `yaml:"reason"`
+}
+
+// Plan is a VLSM allocation of a parent block
+type Plan struct {
+	Parent      netip.Prefix   `yaml:"parent"`
+	Subnets     []Allocation   `yaml:"subnets"` // In address order
+	Unallocated []Unfit        `yaml:"unallocated,omitempty"`
+	Free        []netip.Prefix `yaml:"free,omitempty"` // What's left, as the fewest CIDR blocks
+}
+
+// ParseRequirements reads a list like "pods=4000, services=1000 nodes=250".
+// Names and counts may also be separated by a colon.
+func ParseRequirements(input string) ([]Requirement, error) {
+	fields := strings.FieldsFunc(input, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+	if len(fields) == 0 {
+		return nil, errors.New("list the subnets you need, like pods=4000, services=1000")
+	}
+
+	var reqs []Requirement
+	seen := map[string]bool{}
+	for _, field := range fields {
+		name, count, ok := strings.Cut(field, "=")
+		if !ok {
+			name, count, ok = strings.Cut(field, ":")
+		}
+		if !ok || name == "" {
+			return nil, fmt.Errorf("%q should be name=hosts", field)
+		}
+		hosts, err := strconv.Atoi(count)
+		if err != nil || hosts < 1 || hosts > maxRequirementHosts {
+			return nil, fmt.Errorf("%s: %q is not a host count", name, count)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("%s is listed twice", name)
+		}
+		seen[name] = true
+		reqs = append(reqs, Requirement{Name: name, Hosts: hosts})
+	}
+	return reqs, nil
+}
+
+// PlanVLSM allocates a subnet for each requirement from the parent block,
+// largest first. Placing the largest blocks first keeps every block aligned
+// with no gaps between them. Requirements that don't fit are listed in
+// Unallocated rather than failing the plan.
+func PlanVLSM(parent netip.Prefix, reqs []Requirement) Plan {
+	parent = parent.Masked()
+	plan := Plan{Parent: parent}
+
+	type sized struct {
+		Requirement
+		hostBits int
+	}
+	ordered := make([]sized, len(reqs))
+	for i, r := range reqs {
+		ordered[i] = sized{r, hostBitsFor(r.Hosts, parent.Addr().Is4())}
+	}
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].hostBits > ordered[j].hostBits
+	})
+
+	addrBits := parent.Addr().BitLen()
+	next := addrInt(parent.Addr())
+	end := new(big.Int).Add(next, blockSize(addrBits-parent.Bits()))
+	for _, r := range ordered {
+		length := addrBits - r.hostBits
+		size := blockSize(r.hostBits)
+		switch {
+		case length < parent.Bits():
+			plan.Unallocated = append(plan.Unallocated, Unfit{
+				Name:   r.Name,
+				Hosts:  r.Hosts,
+				Reason: fmt.Sprintf("needs a /%d, which is bigger than %s", length, parent),
+			})
+			continue
+		case new(big.Int).Add(next, size).Cmp(end) > 0:
+			left := new(big.Int).Sub(end, next)
+			plan.Unallocated = append(plan.Unallocated, Unfit{
+				Name:   r.Name,
+				Hosts:  r.Hosts,
+				Reason: fmt.Sprintf("needs a /%d (%s addresses) but only %s are left", length, FormatCount(size), FormatCount(left)),
+			})
+			continue
+		}
+
+		prefix := netip.PrefixFrom(intAddr(next, addrBits), length)
+		plan.Subnets = append(plan.Subnets, Allocation{
+			Name:   r.Name,
+			Hosts:  r.Hosts,
+			Prefix: prefix,
+			Usable: usableHosts(prefix),
+		})
+		next.Add(next, size)
+	}
+
+	plan.Free = rangePrefixes(next, end, addrBits)
+	return plan
+}
+
+// YAML renders the plan for saving, indented like the rest of our YAML
+func (p Plan) YAML() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(p); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// hostBitsFor returns how many host bits a subnet needs for a number of
+// hosts. IPv4 subnets lose the network and broadcast addresses, except on
+// a /31 or /32, just as Calculate counts them.
+func hostBitsFor(hosts int, is4 bool) int {
+	addresses := uint64(hosts)
+	if is4 && hosts > 2 {
+		addresses += 2
+	}
+	return bits.Len64(addresses - 1)
+}
+
+// usableHosts counts the assignable addresses in a prefix
+func usableHosts(p netip.Prefix) uint64 {
+	hostBits := p.Addr().BitLen() - p.Bits()
+	n := uint64(1) << hostBits
+	if p.Addr().Is4() && hostBits >= 2 {
+		n -= 2
+	}
+	return n
+}
+
+// rangePrefixes covers the addresses from start up to (but not including)
+// end with the fewest CIDR blocks
+func rangePrefixes(start, end *big.Int, addrBits int) []netip.Prefix {
+	var prefixes []netip.Prefix
+	next := new(big.Int).Set(start)
+	for next.Cmp(end) < 0 {
+		hostBits := addrBits
+		if next.Sign() != 0 {
+			hostBits = min(int(next.TrailingZeroBits()), addrBits)
+		}
+		for new(big.Int).Add(next, blockSize(hostBits)).Cmp(end) > 0 {
+			hostBits--
+		}
+		prefixes = append(prefixes, netip.PrefixFrom(intAddr(next, addrBits), addrBits-hostBits))
+		next.Add(next, blockSize(hostBits))
+	}
+	return prefixes
+}
+
+// blockSize is the number of addresses in a block with hostBits host bits
+func blockSize(hostBits int) *big.Int {
+	return new(big.Int).Lsh(big.NewInt(1), uint(hostBits))
+}
+
+func addrInt(addr netip.Addr) *big.Int {
+	return new(big.Int).SetBytes(addr.AsSlice())
+}
+
+func intAddr(n *big.Int, addrBits int) netip.Addr {
+	addr, _ := netip.AddrFromSlice(n.FillBytes(make([]byte, addrBits/8)))
+	return addr
+}
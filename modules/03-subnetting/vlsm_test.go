@@ -0,0 +1,181 @@
+package subnetting
+
+import (
+	"net/netip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"netlab/internal/tuitest"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestParseRequirements(t *testing.T) {
+	got, err := ParseRequirements("pods=4000, services:1000 nodes=250")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Requirement{{"pods", 4000}, {"services", 1000}, {"nodes", 250}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	for input, wantErr := range map[string]string{
+		"":                      "list the subnets you need",
+		"pods":                  `"pods" should be name=hosts`,
+		"=12":                   `"=12" should be name=hosts`,
+		"pods=lots":             `pods: "lots" is not a host count`,
+		"pods=0":                `pods: "0" is not a host count`,
+		"pods=1, pods=2":        "pods is listed twice",
+		"pods=1000000000000000": `pods: "1000000000000000" is not a host count`,
+	} {
+		if _, err := ParseRequirements(input); err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("ParseRequirements(%q) error = %v, want %q", input, err, wantErr)
+		}
+	}
+}
+
+func TestPlanVLSM(t *testing.T) {
+	tests := []struct {
+		name    string
+		parent  string
+		reqs    []Requirement
+		subnets []string
+		unfit   []string
+		free    []string
+	}{
+		{
+			name:    "largest first",
+			parent:  "192.168.1.0/24",
+			reqs:    []Requirement{{"lab", 10}, {"office", 100}, {"link", 2}, {"wifi", 50}},
+			subnets: []string{"office 192.168.1.0/25", "wifi 192.168.1.128/26", "lab 192.168.1.192/28", "link 192.168.1.208/31"},
+			free:    []string{"192.168.1.210/31", "192.168.1.212/30", "192.168.1.216/29", "192.168.1.224/27"},
+		},
+		{
+			name:    "exact fit",
+			parent:  "10.0.0.0/24",
+			reqs:    []Requirement{{"a", 126}, {"b", 126}},
+			subnets: []string{"a 10.0.0.0/25", "b 10.0.0.128/25"},
+		},
+		{
+			name:    "host bits in the parent are ignored",
+			parent:  "10.0.0.77/24",
+			reqs:    []Requirement{{"a", 1}},
+			subnets: []string{"a 10.0.0.0/32"},
+			free:    []string{"10.0.0.1/32", "10.0.0.2/31", "10.0.0.4/30", "10.0.0.8/29", "10.0.0.16/28", "10.0.0.32/27", "10.0.0.64/26", "10.0.0.128/25"},
+		},
+		{
+			name:    "smaller ones still fit after a big one doesn't",
+			parent:  "10.0.0.0/24",
+			reqs:    []Requirement{{"a", 200}, {"b", 100}, {"c", 60}},
+			subnets: []string{"a 10.0.0.0/24"},
+			unfit:   []string{"b needs a /25 (128 addresses) but only 0 are left", "c needs a /26 (64 addresses) but only 0 are left"},
+		},
+		{
+			name:    "bigger than the parent",
+			parent:  "10.0.0.0/24",
+			reqs:    []Requirement{{"huge", 1000}, {"small", 100}},
+			subnets: []string{"small 10.0.0.0/25"},
+			unfit:   []string{"huge needs a /22, which is bigger than 10.0.0.0/24"},
+			free:    []string{"10.0.0.128/25"},
+		},
+		{
+			name:    "IPv6 has no reserved addresses",
+			parent:  "2001:db8::/48",
+			reqs:    []Requirement{{"pods", 1 << 16}, {"nodes", 256}},
+			subnets: []string{"pods 2001:db8::/112", "nodes 2001:db8::1:0/120"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := PlanVLSM(netip.MustParsePrefix(tt.parent), tt.reqs)
+
+			var subnets, unfit, free []string
+			for _, a := range plan.Subnets {
+				subnets = append(subnets, a.Name+" "+a.Prefix.String())
+				if a.Usable < uint64(a.Hosts) {
+					t.Errorf("%s has %d usable addresses for %d hosts", a.Name, a.Usable, a.Hosts)
+				}
+			}
+			for _, u := range plan.Unallocated {
+				unfit = append(unfit, u.Name+" "+u.Reason)
+			}
+			for _, f := range plan.Free {
+				free = append(free, f.String())
+			}
+
+			if !reflect.DeepEqual(subnets, tt.subnets) {
+				t.Errorf("subnets = %q, want %q", subnets, tt.subnets)
+			}
+			if !reflect.DeepEqual(unfit, tt.unfit) {
+				t.Errorf("unallocated = %q, want %q", unfit, tt.unfit)
+			}
+			if tt.free != nil && !reflect.DeepEqual(free, tt.free) {
+				t.Errorf("free = %q, want %q", free, tt.free)
+			}
+		})
+	}
+}
+
+func TestPlanYAML(t *testing.T) {
+	plan := PlanVLSM(netip.MustParsePrefix("10.0.0.0/24"), []Requirement{{"pods", 100}, {"huge", 1000}})
+	data, err := plan.YAML()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `parent: 10.0.0.0/24
+subnets:
+  - name: pods
+    hosts: 100
+    cidr: 10.0.0.0/25
+    usable: 126
+unallocated:
+  - name: huge
+    hosts: 1000
+    reason: needs a /22, which is bigger than 10.0.0.0/24
+free:
+  - 10.0.0.128/25
+`
+	if string(data) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", data, want)
+	}
+
+	var back Plan
+	if err := yaml.Unmarshal(data, &back); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(back, plan) {
+		t.Errorf("round trip gave %+v, want %+v", back, plan)
+	}
+}
+
+func TestPlannerSave(t *testing.T) {
+	p := newPlanner()
+	p.SetSize(96, 24)
+	p.savePath = filepath.Join(t.TempDir(), planFile)
+
+	p, cmd := p.Update(tuitest.Key("ctrl+s"))
+	if cmd == nil {
+		t.Fatal("ctrl+s returned no command")
+	}
+	p, _ = p.Update(cmd())
+	if p.saved.err != nil {
+		t.Fatal(p.saved.err)
+	}
+
+	data, err := os.ReadFile(p.savePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "cidr: 10.0.0.0/19") {
+		t.Errorf("saved plan:\n%s", data)
+	}
+	if !strings.Contains(p.bodyView(), "Saved the plan to "+p.savePath) {
+		t.Error("save not reported")
+	}
+}
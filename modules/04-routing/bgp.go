@@ -0,0 +1,548 @@
+package routing
+
+import (
+	"bytes"
+	"fmt"
+	"net/netip"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// BGP origin codes, in the order the decision process prefers them
+const (
+	originIGP        = "igp"
+	originEGP        = "egp"
+	originIncomplete = "incomplete"
+)
+
+// Well-known communities
+const (
+	communityNoExport    = "no-export"
+	communityNoAdvertise = "no-advertise"
+)
+
+// defaultLocalPref is the LOCAL_PREF a path gets when no policy sets one
+const defaultLocalPref = 100
+
+// maxBGPRounds bounds convergence, for policies that make routes oscillate
+const maxBGPRounds = 64
+
+// Origination is a prefix a speaker advertises itself
+type Origination struct {
+	Prefix      netip.Prefix
+	Origin      string
+	MED         int
+	Communities []string
+}
+
+// Speaker is a BGP router in an autonomous system
+type Speaker struct {
+	Name      string
+	AS        int
+	ID        netip.Addr // Router ID, the last tie-breaker
+	Originate []Origination
+}
+
+// ImportPolicy is applied by a speaker to the paths it receives over a
+// session
+type ImportPolicy struct {
+	LocalPref      int            // Sets LOCAL_PREF when non-zero
+	CommunityPrefs map[string]int // Sets LOCAL_PREF for paths carrying a community
+	Communities    []string       // Added to every path
+	IGPCost        int            // The cost of reaching the peer inside the AS, for iBGP
+}
+
+// ExportPolicy is applied by a speaker to the paths it sends over a session
+type ExportPolicy struct {
+	MED         *int // Sets MED on paths sent over eBGP; nil keeps the MED of the speaker's own prefixes
+	Prepend     int  // Extra copies of the speaker's AS, to make paths less attractive
+	Communities []string
+	Deny        []string // Paths carrying any of these communities aren't sent
+}
+
+// Session is a BGP session between two speakers, with each side's policies
+type Session struct {
+	A, B   string
+	Import map[string]ImportPolicy // By the speaker applying it
+	Export map[string]ExportPolicy
+}
+
+// BGPNetwork is a set of speakers and the sessions between them
+type BGPNetwork struct {
+	Speakers []Speaker
+	Sessions []Session
+}
+
+// bgpFile is the YAML form of a BGP network. Each session lists its two
+// peers, and each peer's policies under its name.
+type bgpFile struct {
+	Speakers []struct {
+		Name      string `yaml:"name"`
+		AS        int    `yaml:"as"`
+		ID        string `yaml:"id"`
+		Originate []struct {
+			Prefix      string   `yaml:"prefix"`
+			Origin      string   `yaml:"origin"`
+			MED         int      `yaml:"med"`
+			Communities []string `yaml:"communities"`
+		} `yaml:"originate"`
+	} `yaml:"speakers"`
+	Sessions []struct {
+		Peers    []string `yaml:"peers"`
+		Policies map[string]struct {
+			Import struct {
+				LocalPref          int            `yaml:"local_pref"`
+				CommunityLocalPref map[string]int `yaml:"community_local_pref"`
+				Communities        []string       `yaml:"communities"`
+				IGPCost            int            `yaml:"igp_cost"`
+			} `yaml:"import"`
+			Export struct {
+				MED         *int     `yaml:"med"`
+				Prepend     int      `yaml:"prepend"`
+				Communities []string `yaml:"communities"`
+				Deny        []string `yaml:"deny_communities"`
+			} `yaml:"export"`
+		} `yaml:",inline"`
+	} `yaml:"sessions"`
+}
+
+// SampleBGP is the BGP network the module ships with: a data center AS with
+// two ISPs, and Kubernetes nodes peering with its top-of-rack switch
+func SampleBGP() (BGPNetwork, error) {
+	data, err := embedded.ReadFile("content/bgp.yaml")
+	if err != nil {
+		return BGPNetwork{}, err
+	}
+	network, err := ParseBGP(data)
+	if err != nil {
+		return BGPNetwork{}, fmt.Errorf("content/bgp.yaml: %w", err)
+	}
+	return network, nil
+}
+
+// ReadBGP reads a BGP network from a YAML file
+func ReadBGP(path string) (BGPNetwork, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return BGPNetwork{}, fmt.Errorf("failed to read BGP network: %w", err)
+	}
+	network, err := ParseBGP(data)
+	if err != nil {
+		return BGPNetwork{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return network, nil
+}
+
+// ParseBGP parses a BGP network
+func ParseBGP(data []byte) (BGPNetwork, error) {
+	var file bgpFile
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&file); err != nil {
+		return BGPNetwork{}, fmt.Errorf("invalid YAML: %w", err)
+	}
+	if len(file.Speakers) == 0 {
+		return BGPNetwork{}, fmt.Errorf("no speakers defined")
+	}
+
+	var n BGPNetwork
+	for _, fs := range file.Speakers {
+		if fs.Name == "" {
+			return BGPNetwork{}, fmt.Errorf("speaker %d has no name", len(n.Speakers)+1)
+		}
+		if _, ok := n.Speaker(fs.Name); ok {
+			return BGPNetwork{}, fmt.Errorf("speaker %s is defined twice", fs.Name)
+		}
+		if fs.AS < 1 || fs.AS > 4294967295 {
+			return BGPNetwork{}, fmt.Errorf("speaker %s: as should be an AS number from 1 to 4294967295", fs.Name)
+		}
+		id, err := netip.ParseAddr(fs.ID)
+		if err != nil || !id.Is4() {
+			return BGPNetwork{}, fmt.Errorf("speaker %s: id %q should be an IPv4 address, such as 10.0.0.1", fs.Name, fs.ID)
+		}
+
+		s := Speaker{Name: fs.Name, AS: fs.AS, ID: id}
+		for _, fo := range fs.Originate {
+			prefix, err := netip.ParsePrefix(fo.Prefix)
+			if err != nil {
+				return BGPNetwork{}, fmt.Errorf("speaker %s: %q is not a valid prefix", s.Name, fo.Prefix)
+			}
+			origin := fo.Origin
+			switch origin {
+			case "":
+				origin = originIGP
+			case originIGP, originEGP, originIncomplete:
+			default:
+				return BGPNetwork{}, fmt.Errorf("speaker %s: origin %q should be igp, egp or incomplete", s.Name, fo.Origin)
+			}
+			if err := checkCommunities(fo.Communities); err != nil {
+				return BGPNetwork{}, fmt.Errorf("speaker %s: %w", s.Name, err)
+			}
+			s.Originate = append(s.Originate, Origination{Prefix: prefix.Masked(), Origin: origin, MED: fo.MED, Communities: fo.Communities})
+		}
+		n.Speakers = append(n.Speakers, s)
+	}
+
+	for i, fs := range file.Sessions {
+		if len(fs.Peers) != 2 || fs.Peers[0] == fs.Peers[1] {
+			return BGPNetwork{}, fmt.Errorf("session %d: peers should name two different speakers", i+1)
+		}
+		for _, name := range fs.Peers {
+			if _, ok := n.Speaker(name); !ok {
+				return BGPNetwork{}, fmt.Errorf("session %d: no speaker named %s", i+1, name)
+			}
+		}
+		if _, ok := n.session(fs.Peers[0], fs.Peers[1]); ok {
+			return BGPNetwork{}, fmt.Errorf("session %d: %s and %s already have a session", i+1, fs.Peers[0], fs.Peers[1])
+		}
+
+		s := Session{A: fs.Peers[0], B: fs.Peers[1], Import: map[string]ImportPolicy{}, Export: map[string]ExportPolicy{}}
+		for name, p := range fs.Policies {
+			if name != s.A && name != s.B {
+				return BGPNetwork{}, fmt.Errorf("session %d: %s has policies but is not one of its peers", i+1, name)
+			}
+			if p.Export.Prepend < 0 || p.Import.IGPCost < 0 {
+				return BGPNetwork{}, fmt.Errorf("session %d: %s: prepend and igp_cost can't be negative", i+1, name)
+			}
+			communities := append(append(append([]string(nil), p.Import.Communities...), p.Export.Communities...), p.Export.Deny...)
+			for c := range p.Import.CommunityLocalPref {
+				communities = append(communities, c)
+			}
+			if err := checkCommunities(communities); err != nil {
+				return BGPNetwork{}, fmt.Errorf("session %d: %s: %w", i+1, name, err)
+			}
+			s.Import[name] = ImportPolicy{
+				LocalPref:      p.Import.LocalPref,
+				CommunityPrefs: p.Import.CommunityLocalPref,
+				Communities:    p.Import.Communities,
+				IGPCost:        p.Import.IGPCost,
+			}
+			s.Export[name] = ExportPolicy{MED: p.Export.MED, Prepend: p.Export.Prepend, Communities: p.Export.Communities, Deny: p.Export.Deny}
+		}
+		n.Sessions = append(n.Sessions, s)
+	}
+	return n, nil
+}
+
+// checkCommunities checks communities are written asn:value, or are one of
+// the well-known names
+func checkCommunities(communities []string) error {
+	for _, c := range communities {
+		if c == communityNoExport || c == communityNoAdvertise {
+			continue
+		}
+		asn, value, ok := strings.Cut(c, ":")
+		_, errA := strconv.ParseUint(asn, 10, 16)
+		_, errV := strconv.ParseUint(value, 10, 16)
+		if !ok || errA != nil || errV != nil {
+			return fmt.Errorf("community %q should be asn:value, such as 65000:100, or no-export or no-advertise", c)
+		}
+	}
+	return nil
+}
+
+// Speaker finds a speaker by name
+func (n BGPNetwork) Speaker(name string) (Speaker, bool) {
+	for _, s := range n.Speakers {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return Speaker{}, false
+}
+
+// Names lists the speakers' names
+func (n BGPNetwork) Names() []string {
+	names := make([]string, len(n.Speakers))
+	for i, s := range n.Speakers {
+		names[i] = s.Name
+	}
+	return names
+}
+
+// session finds the session between two speakers, in either order
+func (n BGPNetwork) session(a, b string) (Session, bool) {
+	for _, s := range n.Sessions {
+		if (s.A == a && s.B == b) || (s.A == b && s.B == a) {
+			return s, true
+		}
+	}
+	return Session{}, false
+}
+
+// Path is a route to a prefix as one speaker holds it, with its attributes
+type Path struct {
+	Prefix      netip.Prefix
+	From        string // The peer it was learned from, empty when originated here
+	PeerAS      int
+	PeerID      netip.Addr
+	EBGP        bool
+	ASPath      []int
+	Origin      string
+	LocalPref   int
+	MED         int
+	Communities []string
+	IGPCost     int
+}
+
+// Local reports whether the speaker originated the path itself
+func (p Path) Local() bool {
+	return p.From == ""
+}
+
+// Label names the path by the peer it came from
+func (p Path) Label() string {
+	if p.Local() {
+		return "local"
+	}
+	return p.From
+}
+
+// NeighbourAS is the AS the path was learned from, which MED is compared
+// within: the first in the AS_PATH
+func (p Path) NeighbourAS() int {
+	if len(p.ASPath) == 0 {
+		return 0
+	}
+	return p.ASPath[0]
+}
+
+// ASPathString writes the AS_PATH as BGP shows it, or "(local)" for an
+// empty one
+func (p Path) ASPathString() string {
+	if len(p.ASPath) == 0 {
+		return "(local)"
+	}
+	parts := make([]string, len(p.ASPath))
+	for i, as := range p.ASPath {
+		parts[i] = strconv.Itoa(as)
+	}
+	return strings.Join(parts, " ")
+}
+
+// hasCommunity reports whether the path carries a community
+func (p Path) hasCommunity(c string) bool {
+	for _, have := range p.Communities {
+		if have == c {
+			return true
+		}
+	}
+	return false
+}
+
+// Rejection is a path a speaker received but couldn't use
+type Rejection struct {
+	Path   Path
+	Reason string
+}
+
+// RIB is every speaker's decisions once the network has converged, by
+// speaker and then prefix
+type RIB struct {
+	Rounds    int
+	Decisions map[string]map[netip.Prefix]Decision
+}
+
+// Prefixes lists the prefixes a speaker has decisions for, in address order
+func (r RIB) Prefixes(speaker string) []netip.Prefix {
+	var prefixes []netip.Prefix
+	for p := range r.Decisions[speaker] {
+		prefixes = append(prefixes, p)
+	}
+	sort.Slice(prefixes, func(i, j int) bool {
+		a, b := prefixes[i], prefixes[j]
+		if c := a.Addr().Compare(b.Addr()); c != 0 {
+			return c < 0
+		}
+		return a.Bits() < b.Bits()
+	})
+	return prefixes
+}
+
+// received is what a speaker holds from its peers: for each prefix, the
+// path each peer sent, accepted or rejected
+type received map[netip.Prefix]map[string]Rejection
+
+// Converge runs BGP until no speaker's best paths change. In each round,
+// every speaker picks its best path to each prefix from what it originates
+// and has received, and sends it to its peers.
+func (n BGPNetwork) Converge() (RIB, error) {
+	in := map[string]received{}
+	for _, s := range n.Speakers {
+		in[s.Name] = received{}
+	}
+	for round := 1; round <= maxBGPRounds; round++ {
+		decisions := map[string]map[netip.Prefix]Decision{}
+		for _, s := range n.Speakers {
+			decisions[s.Name] = n.decide(s, in[s.Name])
+		}
+
+		next := map[string]received{}
+		for _, s := range n.Speakers {
+			next[s.Name] = received{}
+		}
+		for _, session := range n.Sessions {
+			n.advertise(session.A, session.B, session, decisions, next)
+			n.advertise(session.B, session.A, session, decisions, next)
+		}
+		if reflect.DeepEqual(next, in) {
+			return RIB{Rounds: round, Decisions: decisions}, nil
+		}
+		in = next
+	}
+	return RIB{}, fmt.Errorf("BGP did not converge after %d rounds: the policies make routes oscillate", maxBGPRounds)
+}
+
+// decide runs the decision process for every prefix a speaker originates or
+// has received
+func (n BGPNetwork) decide(s Speaker, in received) map[netip.Prefix]Decision {
+	candidates := map[netip.Prefix][]Path{}
+	rejected := map[netip.Prefix][]Rejection{}
+	for _, o := range s.Originate {
+		candidates[o.Prefix] = append(candidates[o.Prefix], Path{
+			Prefix:      o.Prefix,
+			Origin:      o.Origin,
+			LocalPref:   defaultLocalPref,
+			MED:         o.MED,
+			Communities: o.Communities,
+		})
+	}
+	for prefix, peers := range in {
+		names := make([]string, 0, len(peers))
+		for name := range peers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if r := peers[name]; r.Reason != "" {
+				rejected[prefix] = append(rejected[prefix], r)
+			} else {
+				candidates[prefix] = append(candidates[prefix], r.Path)
+			}
+		}
+	}
+
+	decisions := map[netip.Prefix]Decision{}
+	for prefix, paths := range candidates {
+		d := Decide(paths)
+		d.Rejected = rejected[prefix]
+		decisions[prefix] = d
+	}
+	for prefix, r := range rejected {
+		if _, ok := decisions[prefix]; !ok {
+			decisions[prefix] = Decision{Prefix: prefix, Best: -1, Rejected: r}
+		}
+	}
+	return decisions
+}
+
+// advertise sends a speaker's best paths to a peer, applying the rules for
+// what BGP passes on and both sides' policies. A path isn't sent back to
+// the peer it came from.
+func (n BGPNetwork) advertise(from, to string, session Session, decisions map[string]map[netip.Prefix]Decision, out map[string]received) {
+	sender, _ := n.Speaker(from)
+	receiver, _ := n.Speaker(to)
+	ebgp := sender.AS != receiver.AS
+	export := session.Export[from]
+	imp := session.Import[to]
+
+	for prefix, d := range decisions[from] {
+		best, ok := d.BestPath()
+		if !ok || best.From == to || best.hasCommunity(communityNoAdvertise) {
+			continue
+		}
+		if ebgp && best.hasCommunity(communityNoExport) {
+			continue
+		}
+		if !ebgp && !best.Local() && !best.EBGP {
+			continue // iBGP paths aren't passed to other iBGP peers
+		}
+		if denied(best, export.Deny) {
+			continue
+		}
+
+		p := Path{
+			Prefix:      prefix,
+			From:        from,
+			PeerAS:      sender.AS,
+			PeerID:      sender.ID,
+			EBGP:        ebgp,
+			ASPath:      best.ASPath,
+			Origin:      best.Origin,
+			LocalPref:   best.LocalPref,
+			MED:         best.MED,
+			Communities: append(append([]string(nil), best.Communities...), export.Communities...),
+		}
+		if ebgp {
+			path := make([]int, 0, len(best.ASPath)+1+export.Prepend)
+			for i := 0; i <= export.Prepend; i++ {
+				path = append(path, sender.AS)
+			}
+			p.ASPath = append(path, best.ASPath...)
+			p.LocalPref = defaultLocalPref // LOCAL_PREF stays inside an AS
+			// and MED only reaches the next one. A speaker's own prefixes
+			// keep the MED they were originated with unless the export sets one.
+			switch {
+			case export.MED != nil:
+				p.MED = *export.MED
+			case !best.Local():
+				p.MED = 0
+			}
+		} else {
+			p.IGPCost = imp.IGPCost
+		}
+
+		r := Rejection{Path: p}
+		for _, as := range p.ASPath {
+			if as == receiver.AS {
+				r.Reason = fmt.Sprintf("AS_PATH %s already contains AS %d, so taking it could loop", p.ASPathString(), as)
+			}
+		}
+		if r.Reason == "" {
+			r.Path = imp.apply(p)
+		}
+		if out[to][prefix] == nil {
+			out[to][prefix] = map[string]Rejection{}
+		}
+		out[to][prefix][from] = r
+	}
+}
+
+// denied reports whether a path carries any of the communities
+func denied(p Path, communities []string) bool {
+	for _, c := range communities {
+		if p.hasCommunity(c) {
+			return true
+		}
+	}
+	return false
+}
+
+// apply runs an import policy over a received path
+func (imp ImportPolicy) apply(p Path) Path {
+	for _, c := range imp.Communities {
+		if !p.hasCommunity(c) {
+			p.Communities = append(p.Communities, c)
+		}
+	}
+	if imp.LocalPref != 0 {
+		p.LocalPref = imp.LocalPref
+	}
+	// Community matches are the more specific, so they win over local_pref,
+	// and the highest wins when several match
+	matched := 0
+	for c, pref := range imp.CommunityPrefs {
+		if p.hasCommunity(c) {
+			matched = max(matched, pref)
+		}
+	}
+	if matched != 0 {
+		p.LocalPref = matched
+	}
+	return p
+}
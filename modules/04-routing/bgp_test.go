@@ -0,0 +1,168 @@
+package routing
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+	"testing"
+)
+
+func TestParseBGP(t *testing.T) {
+	network, err := SampleBGP()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(network.Speakers) != 11 || len(network.Sessions) != 15 {
+		t.Errorf("sample has %d speakers and %d sessions", len(network.Speakers), len(network.Sessions))
+	}
+	s, _ := network.session("border2", "isp2")
+	if s.Import["border2"].CommunityPrefs["64501:100"] != 200 || s.Export["border2"].Deny[0] != "65000:1" {
+		t.Errorf("border2's policies towards isp2: %+v %+v", s.Import["border2"], s.Export["border2"])
+	}
+
+	speakers := "speakers:\n  - {name: a, as: 65001, id: 10.0.0.1}\n  - {name: b, as: 65002, id: 10.0.0.2}\n"
+	for _, tt := range []struct{ yaml, err string }{
+		{"speakers: []", "no speakers defined"},
+		{"speakers:\n  - {name: a, as: 0, id: 10.0.0.1}", "speaker a: as should be"},
+		{"speakers:\n  - {name: a, as: 1, id: fe80::1}", "speaker a: id"},
+		{"speakers:\n  - {name: a, as: 1, id: 10.0.0.1, originate: [{prefix: 10.0.0.0/8, origin: bgp}]}", "speaker a: origin"},
+		{speakers + "sessions:\n  - peers: [a, c]", "session 1: no speaker named c"},
+		{speakers + "sessions:\n  - peers: [a, b]\n  - peers: [b, a]", "session 2: b and a already have"},
+		{speakers + "sessions:\n  - peers: [a, b]\n    c: {import: {local_pref: 5}}", "session 1: c has policies"},
+		{speakers + "sessions:\n  - peers: [a, b]\n    a: {export: {communities: [65001]}}", "session 1: a: community"},
+		{speakers + "sessions:\n  - peers: [a, b]\n    a: {export: {weight: 5}}", "invalid YAML"},
+	} {
+		if _, err := ParseBGP([]byte(tt.yaml)); err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("ParseBGP(%q) = %v, want %q", tt.yaml, err, tt.err)
+		}
+	}
+}
+
+func TestConverge(t *testing.T) {
+	network, err := SampleBGP()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rib, err := network.Converge()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		speaker, prefix string
+		from, path      string
+		decidedBy       string
+	}{
+		{"border1", "198.51.100.0/24", "border2", "64501 64700", "highest LOCAL_PREF"},
+		{"isp1", "198.51.100.0/24", "cdn-east", "64700", "lowest MED from the same AS"},
+		{"border2", "203.0.113.0/24", "border1", "64500 64600", "shortest AS_PATH"},
+		{"isp2", "203.0.113.0/24", "isp1", "64500 64600", "shortest AS_PATH"},
+		{"border1", "192.0.2.0/24", "isp1", "64500 64800", "eBGP over iBGP"},
+		{"tor", "192.0.2.0/24", "border1", "64500 64800", "lowest IGP cost to the next hop"},
+		{"transit", "192.0.2.0/24", "isp1", "64500 64800", "lowest router ID"},
+		{"node1", "10.244.1.0/24", "", "(local)", "only path"},
+	}
+	for _, tt := range tests {
+		d := rib.Decisions[tt.speaker][netip.MustParsePrefix(tt.prefix)]
+		best, ok := d.BestPath()
+		if !ok || best.From != tt.from || best.ASPathString() != tt.path || d.DecidedBy() != tt.decidedBy {
+			t.Errorf("%s to %s: from %q, AS_PATH %s, by %s; want %q, %s, %s", tt.speaker, tt.prefix, best.From, best.ASPathString(), d.DecidedBy(), tt.from, tt.path, tt.decidedBy)
+		}
+	}
+
+	// The nodes share an AS, so each rejects the other's routes
+	d := rib.Decisions["node2"][netip.MustParsePrefix("10.244.1.0/24")]
+	if len(d.Paths) != 0 || len(d.Rejected) != 1 || !strings.Contains(d.Rejected[0].Reason, "AS 64512") {
+		t.Errorf("node2's paths to node1's pods: %+v", d)
+	}
+	// no-export keeps the pod routes inside AS 65000, and the deny keeps one
+	// ISP's routes from reaching the other through it
+	for prefix := range rib.Decisions["isp1"] {
+		if strings.HasPrefix(prefix.String(), "10.244.") {
+			t.Errorf("isp1 learned %s", prefix)
+		}
+	}
+	for _, p := range rib.Decisions["isp1"][netip.MustParsePrefix("198.51.100.0/24")].Paths {
+		if p.From == "border1" {
+			t.Errorf("border1 leaked %s to isp1", p.ASPathString())
+		}
+	}
+	// LOCAL_PREF travels over iBGP, but not over eBGP
+	if best, _ := rib.Decisions["node1"][netip.MustParsePrefix("198.51.100.0/24")].BestPath(); best.LocalPref != defaultLocalPref {
+		t.Errorf("node1 got LOCAL_PREF %d from tor", best.LocalPref)
+	}
+}
+
+func TestDecide(t *testing.T) {
+	prefix := netip.MustParsePrefix("10.0.0.0/8")
+	path := func(from string, as ...int) Path {
+		return Path{Prefix: prefix, From: from, ASPath: as, Origin: originIGP, LocalPref: defaultLocalPref, EBGP: true,
+			PeerID: netip.MustParseAddr("10.0.0." + strings.TrimPrefix(from, "r"))}
+	}
+
+	// MED only compares paths from the same AS, so r3's lower MED can't
+	// beat r1, and r1 beats r2 on MED before the router ID is reached
+	paths := []Path{path("r2", 100, 300), path("r1", 100, 300), path("r3", 200, 300)}
+	paths[0].MED, paths[1].MED, paths[2].MED = 20, 10, 5
+	d := Decide(paths)
+	if d.DecidedBy() != "lowest router ID" || d.Paths[d.Best].From != "r1" {
+		t.Errorf("MED across ASes: best %s by %s", d.Paths[d.Best].From, d.DecidedBy())
+	}
+	if med := d.Steps[4]; med.Rule != "lowest MED from the same AS" || len(med.Eliminated) != 1 || med.Eliminated[0] != 0 {
+		t.Errorf("MED step %+v", med)
+	}
+
+	// ORIGIN comes before MED
+	paths = []Path{path("r1", 100), path("r2", 100)}
+	paths[0].Origin, paths[1].MED = originIncomplete, 50
+	if d := Decide(paths); d.DecidedBy() != "lowest ORIGIN" || d.Paths[d.Best].From != "r2" {
+		t.Errorf("ORIGIN: best %s by %s", d.Paths[d.Best].From, d.DecidedBy())
+	}
+
+	if d := Decide(nil); d.Best != -1 || d.DecidedBy() != "no usable path" {
+		t.Errorf("no paths: %+v", d)
+	}
+	lines := Decide([]Path{path("r1", 100)}).Explain()
+	if len(lines) != 1 || lines[0] != "Only one path, so the path from r1 is the best" {
+		t.Errorf("one path explained as %q", lines)
+	}
+}
+
+func TestOriginatedMED(t *testing.T) {
+	const network = `speakers:
+  - {name: a1, as: 65001, id: 10.0.0.1, originate: [{prefix: 10.0.0.0/8, med: 50}]}
+  - {name: a2, as: 65001, id: 10.0.0.2, originate: [{prefix: 10.0.0.0/8, med: 10}]}
+  - {name: c, as: 65002, id: 10.0.0.3}
+sessions:
+  - peers: [a1, c]
+%s  - peers: [a2, c]
+%s`
+	tests := []struct {
+		name      string
+		a1, a2    string // Policy lines for each session
+		from      string
+		decidedBy string
+	}{
+		// Without MED, the lower router ID would pick a1
+		{"originated MED", "", "", "a2", "lowest MED from the same AS"},
+		{"export MED wins", "", "    a2: {export: {med: 100}}\n", "a1", "lowest MED from the same AS"},
+		{"export MED of zero wins", "    a1: {export: {med: 0}}\n", "", "a1", "lowest MED from the same AS"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := ParseBGP([]byte(fmt.Sprintf(network, tt.a1, tt.a2)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			rib, err := n.Converge()
+			if err != nil {
+				t.Fatal(err)
+			}
+			d := rib.Decisions["c"][netip.MustParsePrefix("10.0.0.0/8")]
+			best, ok := d.BestPath()
+			if !ok || best.From != tt.from || d.DecidedBy() != tt.decidedBy {
+				t.Errorf("c chose %q by %s, want %q by %s", best.From, d.DecidedBy(), tt.from, tt.decidedBy)
+			}
+		})
+	}
+}
@@ -0,0 +1,239 @@
+package routing
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// DecisionStep is one tie-breaker of the best path decision process, and
+// the candidates it left in the running
+type DecisionStep struct {
+	Rule       string
+	Kept       []int  // Indexes into the decision's paths
+	Eliminated []int  // The candidates this step ruled out
+	Detail     string // What the kept candidates had that the others didn't
+}
+
+// Decision is a speaker's choice of best path to a prefix, with the steps
+// that made it
+type Decision struct {
+	Prefix   netip.Prefix
+	Paths    []Path
+	Rejected []Rejection
+	Steps    []DecisionStep // Stops once one path is left
+	Best     int            // Index of the best path, -1 with none
+}
+
+// BestPath returns the best path, if there is one
+func (d Decision) BestPath() (Path, bool) {
+	if d.Best < 0 || d.Best >= len(d.Paths) {
+		return Path{}, false
+	}
+	return d.Paths[d.Best], true
+}
+
+// DecidedBy names the step that picked the best path: the last one to rule
+// a candidate out, or "only path" when there was no choice
+func (d Decision) DecidedBy() string {
+	if len(d.Steps) == 0 {
+		if d.Best < 0 {
+			return "no usable path"
+		}
+		return "only path"
+	}
+	return d.Steps[len(d.Steps)-1].Rule
+}
+
+// Explain describes each step of the decision in a sentence, ending with
+// the verdict
+func (d Decision) Explain() []string {
+	names := func(indexes []int) string {
+		labels := make([]string, len(indexes))
+		for i, index := range indexes {
+			labels[i] = d.Paths[index].Label()
+		}
+		return strings.Join(labels, ", ")
+	}
+	rule := func(step DecisionStep) string {
+		if strings.HasPrefix(step.Rule, "eBGP") {
+			return step.Rule
+		}
+		return strings.ToUpper(step.Rule[:1]) + step.Rule[1:]
+	}
+
+	var lines []string
+	for _, step := range d.Steps {
+		if len(step.Eliminated) == 0 {
+			lines = append(lines, fmt.Sprintf("%s: all tie (%s)", rule(step), step.Detail))
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s: keeps %s (%s), rules out %s", rule(step), names(step.Kept), step.Detail, names(step.Eliminated)))
+	}
+	best, ok := d.BestPath()
+	from := "the path from " + best.Label()
+	if best.Local() {
+		from = "the locally originated path"
+	}
+	switch {
+	case !ok:
+		lines = append(lines, "No usable path, so the prefix is unreachable")
+	case len(d.Paths) == 1:
+		lines = append(lines, fmt.Sprintf("Only one path, so %s is the best", from))
+	default:
+		lines = append(lines, fmt.Sprintf("Best: %s, picked by %s", from, d.DecidedBy()))
+	}
+	return lines
+}
+
+// tieBreaker is one step of the decision process: it keeps the candidates
+// that are best by one attribute
+type tieBreaker struct {
+	rule string
+	keep func(paths []Path, running []int) (kept []int, detail string)
+}
+
+// tieBreakers are the steps of the decision process, in order
+var tieBreakers = []tieBreaker{
+	{"highest LOCAL_PREF", func(paths []Path, running []int) ([]int, string) {
+		kept := keepBest(paths, running, func(p Path) int { return -p.LocalPref })
+		return kept, fmt.Sprintf("LOCAL_PREF %d", paths[kept[0]].LocalPref)
+	}},
+	{"locally originated", func(paths []Path, running []int) ([]int, string) {
+		kept := keepBest(paths, running, func(p Path) int {
+			if p.Local() {
+				return 0
+			}
+			return 1
+		})
+		if paths[kept[0]].Local() {
+			return kept, "originated here"
+		}
+		return kept, "learned from a peer"
+	}},
+	{"shortest AS_PATH", func(paths []Path, running []int) ([]int, string) {
+		kept := keepBest(paths, running, func(p Path) int { return len(p.ASPath) })
+		return kept, fmt.Sprintf("AS_PATH length %d", len(paths[kept[0]].ASPath))
+	}},
+	{"lowest ORIGIN", func(paths []Path, running []int) ([]int, string) {
+		rank := map[string]int{originIGP: 0, originEGP: 1, originIncomplete: 2}
+		kept := keepBest(paths, running, func(p Path) int { return rank[p.Origin] })
+		return kept, "origin " + paths[kept[0]].Origin
+	}},
+	{"lowest MED from the same AS", func(paths []Path, running []int) ([]int, string) {
+		// MED only compares paths from the same neighbouring AS: each AS's
+		// lowest survives
+		lowest := map[int]int{}
+		for _, i := range running {
+			as := paths[i].NeighbourAS()
+			if med, ok := lowest[as]; !ok || paths[i].MED < med {
+				lowest[as] = paths[i].MED
+			}
+		}
+		var kept []int
+		var detail []string
+		for _, i := range running {
+			if paths[i].MED == lowest[paths[i].NeighbourAS()] {
+				kept = append(kept, i)
+			}
+		}
+		for _, i := range kept {
+			detail = append(detail, fmt.Sprintf("MED %d from AS %d", paths[i].MED, paths[i].NeighbourAS()))
+		}
+		return kept, strings.Join(dedupe(detail), ", ")
+	}},
+	{"eBGP over iBGP", func(paths []Path, running []int) ([]int, string) {
+		kept := keepBest(paths, running, func(p Path) int {
+			if p.EBGP {
+				return 0
+			}
+			return 1
+		})
+		if paths[kept[0]].EBGP {
+			return kept, "learned over eBGP"
+		}
+		return kept, "learned over iBGP"
+	}},
+	{"lowest IGP cost to the next hop", func(paths []Path, running []int) ([]int, string) {
+		kept := keepBest(paths, running, func(p Path) int { return p.IGPCost })
+		return kept, fmt.Sprintf("IGP cost %d", paths[kept[0]].IGPCost)
+	}},
+	{"lowest router ID", func(paths []Path, running []int) ([]int, string) {
+		best := running[0]
+		for _, i := range running[1:] {
+			if paths[i].PeerID.Less(paths[best].PeerID) {
+				best = i
+			}
+		}
+		return []int{best}, "router ID " + paths[best].PeerID.String()
+	}},
+}
+
+// keepBest keeps the candidates with the lowest score
+func keepBest(paths []Path, running []int, score func(Path) int) []int {
+	lowest := score(paths[running[0]])
+	for _, i := range running[1:] {
+		lowest = min(lowest, score(paths[i]))
+	}
+	var kept []int
+	for _, i := range running {
+		if score(paths[i]) == lowest {
+			kept = append(kept, i)
+		}
+	}
+	return kept
+}
+
+// dedupe drops repeated strings, keeping the first of each
+func dedupe(items []string) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, item := range items {
+		if !seen[item] {
+			seen[item] = true
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// Decide runs the best path decision process over the paths to one prefix,
+// recording each tie-breaker until one path is left
+func Decide(paths []Path) Decision {
+	d := Decision{Paths: paths, Best: -1}
+	if len(paths) == 0 {
+		return d
+	}
+	d.Prefix = paths[0].Prefix
+
+	running := make([]int, len(paths))
+	for i := range paths {
+		running[i] = i
+	}
+	for _, tb := range tieBreakers {
+		if len(running) == 1 {
+			break
+		}
+		kept, detail := tb.keep(paths, running)
+		step := DecisionStep{Rule: tb.rule, Kept: kept, Detail: detail}
+		for _, i := range running {
+			if !contains(kept, i) {
+				step.Eliminated = append(step.Eliminated, i)
+			}
+		}
+		d.Steps = append(d.Steps, step)
+		running = kept
+	}
+	d.Best = running[0]
+	return d
+}
+
+// contains reports whether a slice holds a value
+func contains(items []int, v int) bool {
+	for _, item := range items {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,320 @@
+package routing
+
+import (
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"netlab/pkg/styles"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Explainer input fields, in the order up and down move through them
+const (
+	bgpFieldSpeaker = iota
+	bgpFieldPrefix
+	bgpFieldFile
+	bgpFieldCount
+)
+
+// bgpLoadedMsg carries a BGP network read from a file
+type bgpLoadedMsg struct {
+	path    string
+	network BGPNetwork
+	err     error
+}
+
+// explainer is the BGP simulator: a network converged once, with the paths
+// one speaker holds to a prefix and the decision process that picked the
+// best, and every prefix the speaker knows with what decided it
+type explainer struct {
+	inputs   [bgpFieldCount]textinput.Model
+	focus    int
+	network  BGPNetwork
+	rib      RIB
+	ribErr   error
+	speaker  Speaker
+	decision Decision
+	err      error
+	loaded   bgpLoadedMsg
+	body     viewport.Model
+	width    int
+}
+
+func newExplainer(network BGPNetwork) explainer {
+	e := explainer{body: viewport.New(0, 0)}
+	for i, prompt := range []string{"Router › ", "Prefix › ", "File › "} {
+		input := textinput.New()
+		input.Prompt = prompt
+		input.PromptStyle = styles.KeyBinding
+		input.CharLimit = 256
+		e.inputs[i] = input
+	}
+	e.inputs[bgpFieldSpeaker].SetValue("border1")
+	e.inputs[bgpFieldSpeaker].Placeholder = "speaker to look at"
+	e.inputs[bgpFieldPrefix].SetValue("198.51.100.0/24")
+	e.inputs[bgpFieldPrefix].Placeholder = "prefix to explain"
+	e.inputs[bgpFieldFile].Placeholder = "BGP network YAML file to load"
+	e.inputs[bgpFieldSpeaker].Focus()
+	e.setNetwork(network)
+	return e
+}
+
+// SetSize fits the explainer into width x height cells
+func (e *explainer) SetSize(width, height int) {
+	e.width = width
+	for i := range e.inputs {
+		e.inputs[i].Width = width - lipgloss.Width(e.inputs[i].Prompt) - 1
+		e.inputs[i].SetCursor(e.inputs[i].Position()) // Rescroll for the new width
+	}
+	e.body.Width = width
+	e.body.Height = height - bgpFieldCount - 1 // The inputs and the gap below them
+	e.body.SetContent(e.bodyView())
+}
+
+// setNetwork converges a network and explains the current inputs with it
+func (e *explainer) setNetwork(network BGPNetwork) {
+	e.network = network
+	e.rib, e.ribErr = network.Converge()
+	e.explain()
+}
+
+// explain looks up the speaker and prefix in the inputs
+func (e *explainer) explain() {
+	e.err = nil
+	e.decision = Decision{Best: -1}
+	name := strings.TrimSpace(e.inputs[bgpFieldSpeaker].Value())
+	speaker, ok := e.network.Speaker(name)
+	e.speaker = speaker
+	switch {
+	case e.ribErr != nil:
+		e.err = e.ribErr
+	case !ok:
+		e.err = fmt.Errorf("no speaker named %q", name)
+	}
+	if e.err != nil {
+		e.body.SetContent(e.bodyView())
+		return
+	}
+
+	if value := strings.TrimSpace(e.inputs[bgpFieldPrefix].Value()); value != "" {
+		prefix, err := netip.ParsePrefix(value)
+		switch d, ok := e.rib.Decisions[speaker.Name][prefix.Masked()]; {
+		case err != nil:
+			e.err = fmt.Errorf("%q is not a valid prefix", value)
+		case !ok:
+			e.err = fmt.Errorf("%s has no paths to %s", speaker.Name, prefix.Masked())
+		default:
+			e.decision = d
+		}
+	}
+	e.body.SetContent(e.bodyView())
+}
+
+func (e explainer) Update(msg tea.Msg) (explainer, tea.Cmd) {
+	var cmd tea.Cmd
+	switch msg := msg.(type) {
+	case bgpLoadedMsg:
+		e.loaded = msg
+		if msg.err == nil {
+			e.setNetwork(msg.network)
+		} else {
+			e.body.SetContent(e.bodyView())
+		}
+		return e, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "up", "down":
+			e.inputs[e.focus].Blur()
+			step := 1
+			if msg.String() == "up" {
+				step = bgpFieldCount - 1
+			}
+			e.focus = (e.focus + step) % bgpFieldCount
+			e.inputs[e.focus].Focus()
+			return e, textinput.Blink
+		case "pgup", "pgdown":
+			e.body, cmd = e.body.Update(msg)
+			return e, cmd
+		case "enter":
+			if e.focus == bgpFieldFile && strings.TrimSpace(e.inputs[bgpFieldFile].Value()) != "" {
+				return e, loadBGP(strings.TrimSpace(e.inputs[bgpFieldFile].Value()))
+			}
+			return e, nil
+		}
+
+		e.inputs[e.focus], cmd = e.inputs[e.focus].Update(msg)
+		if e.focus != bgpFieldFile {
+			e.explain()
+		}
+		return e, cmd
+
+	case tea.MouseMsg:
+		e.body, cmd = e.body.Update(msg)
+		return e, cmd
+	}
+
+	e.inputs[e.focus], cmd = e.inputs[e.focus].Update(msg)
+	return e, cmd
+}
+
+func (e explainer) View() string {
+	lines := make([]string, len(e.inputs))
+	for i, input := range e.inputs {
+		lines[i] = input.View()
+	}
+	return strings.Join(lines, "\n") + "\n\n" + e.body.View()
+}
+
+// bodyView renders the decision for the prefix, then the speaker's routes
+func (e explainer) bodyView() string {
+	var sections []string
+	switch {
+	case e.loaded.err != nil:
+		sections = append(sections, styles.StatusError.Render("⚠️  "+e.loaded.err.Error()))
+	case e.loaded.path != "":
+		sections = append(sections, styles.StatusSuccess.Render("✅ Loaded "+e.loaded.path))
+	}
+	text := lipgloss.NewStyle().Width(max(e.width, 1))
+	sections = append(sections, styles.BodyMuted.Copy().Width(max(e.width, 1)).Render("Speakers: "+strings.Join(e.network.Names(), ", ")))
+	known := e.ribErr == nil && e.speaker.Name != ""
+	if known {
+		sections = append(sections, text.Render(fmt.Sprintf("%s is in AS %d with router ID %s. BGP converged in %d rounds.",
+			e.speaker.Name, e.speaker.AS, e.speaker.ID, e.rib.Rounds)))
+	}
+	switch {
+	case e.err != nil:
+		sections = append(sections, styles.StatusError.Copy().Width(max(e.width, 1)).Render("⚠️  "+e.err.Error()))
+	case e.decision.Prefix.IsValid():
+		sections = append(sections, e.decisionView())
+	}
+	if known {
+		sections = append(sections, e.ribView())
+	}
+	return strings.Join(sections, "\n\n")
+}
+
+// decisionView lists the candidate paths, the steps that chose between them
+// and the paths that were rejected
+func (e explainer) decisionView() string {
+	d := e.decision
+	lines := []string{styles.H3.Render(fmt.Sprintf("Paths to %s on %s", d.Prefix, e.speaker.Name))}
+
+	if len(d.Paths) > 0 {
+		columns := []column{
+			{title: "Path"},
+			{title: "AS_PATH"},
+			{title: "LOCAL_PREF"},
+			{title: "MED"},
+			{title: "Origin", optional: true},
+			{title: "Session", optional: true},
+			{title: "IGP", optional: true},
+			{title: "Communities", optional: true},
+		}
+		for i, p := range d.Paths {
+			label := p.Label()
+			if i == d.Best {
+				label += " ★"
+			}
+			session := "local"
+			switch {
+			case p.EBGP:
+				session = "eBGP"
+			case !p.Local():
+				session = "iBGP"
+			}
+			row := []string{label, p.ASPathString(), strconv.Itoa(p.LocalPref), strconv.Itoa(p.MED), p.Origin, session, strconv.Itoa(p.IGPCost), orDash(strings.Join(p.Communities, " "))}
+			for c := range columns {
+				columns[c].cells = append(columns[c].cells, row[c])
+			}
+		}
+		rows := renderColumns(columns, e.width)
+		lines = append(lines, styles.BodyMuted.Render(rows[0]))
+		for i, row := range rows[1:] {
+			if i == d.Best {
+				row = bestRowStyle.Render(row)
+			}
+			lines = append(lines, row)
+		}
+	}
+
+	text := lipgloss.NewStyle().Width(max(e.width-3, 1))
+	explanation := d.Explain()
+	lines = append(lines, "", styles.H3.Render("Decision"))
+	for i, line := range explanation[:len(explanation)-1] {
+		style := text
+		if len(d.Steps[i].Eliminated) == 0 {
+			style = styles.BodyDim.Copy().Width(max(e.width-3, 1))
+		}
+		lines = append(lines, lipgloss.JoinHorizontal(lipgloss.Top, fmt.Sprintf("%d. ", i+1), style.Render(line)))
+	}
+	verdict := explanation[len(explanation)-1]
+	if _, ok := d.BestPath(); ok {
+		lines = append(lines, styles.StatusSuccess.Copy().Width(max(e.width, 1)).Render("✅ "+verdict))
+	} else {
+		lines = append(lines, styles.StatusError.Copy().Width(max(e.width, 1)).Render("❌ "+verdict))
+	}
+
+	for _, r := range d.Rejected {
+		lines = append(lines, styles.StatusWarning.Copy().Width(max(e.width, 1)).Render(fmt.Sprintf("✗ Rejected the path from %s: %s", r.Path.Label(), r.Reason)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ribView lists every prefix the speaker has a decision for, with the best
+// path and what picked it
+func (e explainer) ribView() string {
+	columns := []column{
+		{title: "Prefix"},
+		{title: "Best"},
+		{title: "AS_PATH", optional: true},
+		{title: "Decided by"},
+	}
+	prefixes := e.rib.Prefixes(e.speaker.Name)
+	for _, prefix := range prefixes {
+		d := e.rib.Decisions[e.speaker.Name][prefix]
+		best, ok := d.BestPath()
+		row := []string{prefix.String(), "—", "—", d.DecidedBy()}
+		if ok {
+			row[1], row[2] = best.Label(), best.ASPathString()
+		}
+		for c := range columns {
+			columns[c].cells = append(columns[c].cells, row[c])
+		}
+	}
+
+	rows := renderColumns(columns, e.width)
+	lines := []string{styles.H3.Render("Routes on " + e.speaker.Name), styles.BodyMuted.Render(rows[0])}
+	for i, row := range rows[1:] {
+		switch d := e.rib.Decisions[e.speaker.Name][prefixes[i]]; {
+		case d.Prefix == e.decision.Prefix && d.Prefix.IsValid():
+			row = bestRowStyle.Render(row)
+		case d.Best < 0:
+			row = styles.BodyDim.Render(row)
+		}
+		lines = append(lines, row)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// orDash shows an empty cell as a dash
+func orDash(s string) string {
+	if s == "" {
+		return "—"
+	}
+	return s
+}
+
+// loadBGP reads a BGP network file
+func loadBGP(path string) tea.Cmd {
+	return func() tea.Msg {
+		network, err := ReadBGP(path)
+		return bgpLoadedMsg{path: path, network: network, err: err}
+	}
+}
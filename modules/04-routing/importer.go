@@ -0,0 +1,75 @@
+package routing
+
+import (
+	"fmt"
+	"strings"
+
+	"netlab/pkg/styles"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// tableImportedMsg carries a table parsed on the import screen, for the
+// simulator to use
+type tableImportedMsg struct {
+	table Table
+}
+
+// importer is the import screen: a text area to paste ip route show output
+// into, starting with the sample table
+type importer struct {
+	area   textarea.Model
+	sample string
+	err    error
+	width  int
+}
+
+func newImporter(sample string) importer {
+	area := textarea.New()
+	area.Placeholder = "default via 192.168.1.1 dev eth0"
+	area.ShowLineNumbers = true
+	area.CharLimit = 0
+	area.MaxHeight = 0
+	area.SetValue(strings.TrimRight(sample, "\n"))
+	area.Focus()
+	return importer{area: area, sample: sample}
+}
+
+// SetSize fits the importer into width x height cells
+func (im *importer) SetSize(width, height int) {
+	im.width = width
+	im.area.SetWidth(width)
+	im.area.SetHeight(max(height-3, 1)) // The instructions and status lines
+}
+
+func (im importer) Update(msg tea.Msg) (importer, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "ctrl+s":
+			table, err := ParseIPRoute(im.area.Value())
+			im.err = err
+			if err != nil {
+				return im, nil
+			}
+			return im, func() tea.Msg { return tableImportedMsg{table: table} }
+		case "ctrl+r":
+			im.area.SetValue(strings.TrimRight(im.sample, "\n"))
+			im.err = nil
+			return im, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	im.area, cmd = im.area.Update(msg)
+	return im, cmd
+}
+
+func (im importer) View() string {
+	intro := styles.BodyMuted.Render("Paste the output of ip route show, then press ctrl+s.")
+	status := styles.BodyDim.Render(fmt.Sprintf("%d lines", im.area.LineCount()))
+	if im.err != nil {
+		status = styles.StatusError.Render("⚠️  " + im.err.Error())
+	}
+	return intro + "\n" + im.area.View() + "\n\n" + status
+}
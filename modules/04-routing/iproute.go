@@ -0,0 +1,193 @@
+package routing
+
+import (
+	"bufio"
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+// routeOptions are the ip route keywords followed by a value. Anything else
+// is a flag, such as onlink or linkdown, and is skipped.
+var routeOptions = map[string]bool{
+	"via": true, "dev": true, "proto": true, "scope": true, "src": true,
+	"metric": true, "table": true, "pref": true, "mtu": true, "advmss": true,
+	"realm": true, "expires": true, "weight": true, "initcwnd": true,
+	"initrwnd": true, "hoplimit": true, "congctl": true, "rto_min": true,
+	"quickack": true, "features": true, "nhid": true, "tos": true,
+	"dsfield": true, "preference": true, "mtu_lock": true,
+}
+
+// routeTypes are the route types ip route prints before the destination.
+// Only unicast routes forward packets; the local table's types are not
+// lookups this simulator makes.
+var routeTypes = map[string]bool{
+	typeUnicast: true, typeBlackhole: true, typeUnreachable: true, typeProhibit: true,
+}
+
+// ParseIPRoute reads a routing table from the output of ip route show, or
+// ip -6 route show. Multipath routes become one route per nexthop.
+func ParseIPRoute(text string) (Table, error) {
+	var table Table
+	var multipath *Route // The route whose nexthop lines follow
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		if fields[0] == "nexthop" {
+			if multipath == nil {
+				return nil, fmt.Errorf("line %d: nexthop without a route before it", n)
+			}
+			hop := *multipath
+			if err := parseRouteOptions(&hop, fields[1:]); err != nil {
+				return nil, fmt.Errorf("line %d: %w", n, err)
+			}
+			if hop.Prefix.Bits() == 0 && hop.Gateway.Is6() {
+				hop.Prefix = netip.PrefixFrom(netip.IPv6Unspecified(), 0)
+			}
+			table = append(table, hop)
+			continue
+		}
+
+		route, err := parseRouteLine(fields)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		multipath = nil
+		if route.Type == typeUnicast && route.Interface == "" && !route.Gateway.IsValid() {
+			// The nexthop lines carry the gateways and interfaces
+			multipath = &route
+			continue
+		}
+		table = append(table, route)
+	}
+	if len(table) == 0 {
+		return nil, fmt.Errorf("no routes found; paste the output of ip route show")
+	}
+
+	// A default route without a gateway, such as default dev wg0, doesn't
+	// say which family it is. Give it IPv6 when every other route is IPv6,
+	// as in the output of ip -6 route show.
+	ipv6 := false
+	for _, r := range table {
+		if !familyUnknown(r) {
+			if r.Prefix.Addr().Is4() {
+				return table, nil
+			}
+			ipv6 = true
+		}
+	}
+	if ipv6 {
+		for i := range table {
+			if familyUnknown(table[i]) {
+				table[i].Prefix = netip.PrefixFrom(netip.IPv6Unspecified(), 0)
+			}
+		}
+	}
+	return table, nil
+}
+
+// familyUnknown reports a default route that was parsed as IPv4 only because
+// nothing on its line names a family
+func familyUnknown(r Route) bool {
+	return r.Prefix.Bits() == 0 && r.Prefix.Addr().Is4() && !r.Gateway.IsValid()
+}
+
+// parseRouteLine parses one route, given its fields
+func parseRouteLine(fields []string) (Route, error) {
+	route := Route{Type: typeUnicast}
+	if routeTypes[fields[0]] {
+		route.Type, fields = fields[0], fields[1:]
+	} else if isLocalType(fields[0]) {
+		return Route{}, fmt.Errorf("%s routes are from the local table; use ip route show without table local", fields[0])
+	}
+	if len(fields) == 0 {
+		return Route{}, fmt.Errorf("missing destination")
+	}
+
+	dest, options := fields[0], fields[1:]
+	if err := parseRouteOptions(&route, options); err != nil {
+		return Route{}, err
+	}
+
+	switch {
+	case dest == "default":
+		// ip -6 route prints default too; the gateway tells the families apart
+		route.Prefix = netip.PrefixFrom(netip.IPv4Unspecified(), 0)
+		if route.Gateway.Is6() {
+			route.Prefix = netip.PrefixFrom(netip.IPv6Unspecified(), 0)
+		}
+	case strings.Contains(dest, "/"):
+		prefix, err := netip.ParsePrefix(dest)
+		if err != nil {
+			return Route{}, fmt.Errorf("%q is not a valid destination", dest)
+		}
+		route.Prefix = prefix.Masked()
+	default:
+		// A bare address is a host route
+		addr, err := netip.ParseAddr(dest)
+		if err != nil {
+			return Route{}, fmt.Errorf("%q is not a valid destination", dest)
+		}
+		route.Prefix = netip.PrefixFrom(addr, addr.BitLen())
+	}
+
+	if route.Gateway.IsValid() && route.Gateway.Is4() != route.Prefix.Addr().Is4() && route.Prefix.Bits() > 0 {
+		return Route{}, fmt.Errorf("%s has a gateway from the other IP family", dest)
+	}
+	return route, nil
+}
+
+// parseRouteOptions fills in a route from the keywords after its destination
+func parseRouteOptions(route *Route, fields []string) error {
+	for i := 0; i < len(fields); i++ {
+		key := fields[i]
+		if !routeOptions[key] {
+			continue
+		}
+		if i+1 >= len(fields) {
+			return fmt.Errorf("%s needs a value", key)
+		}
+		i++
+		value := fields[i]
+
+		switch key {
+		case "via":
+			// ip prints "via inet6 fe80::1" for gateways of the other family
+			if (value == "inet" || value == "inet6") && i+1 < len(fields) {
+				i++
+				value = fields[i]
+			}
+			gw, err := netip.ParseAddr(value)
+			if err != nil {
+				return fmt.Errorf("gateway %q is not a valid address", value)
+			}
+			route.Gateway = gw
+		case "dev":
+			route.Interface = value
+		case "proto":
+			route.Protocol = value
+		case "metric", "preference":
+			metric, err := strconv.Atoi(value)
+			if err != nil || metric < 0 {
+				return fmt.Errorf("metric %q is not a number", value)
+			}
+			route.Metric = metric
+		}
+	}
+	return nil
+}
+
+// isLocalType reports whether a word is one of the local table's route types
+func isLocalType(word string) bool {
+	switch word {
+	case "local", "broadcast", "anycast", "multicast", "nat", "throw":
+		return true
+	}
+	return false
+}
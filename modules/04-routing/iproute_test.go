@@ -0,0 +1,104 @@
+package routing
+
+import (
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseIPRoute(t *testing.T) {
+	table, err := ParseIPRoute(`default via 192.168.1.1 dev wlp2s0 proto dhcp src 192.168.1.23 metric 600
+172.17.0.0/16 dev docker0 proto kernel scope link src 172.17.0.1 linkdown
+10.1.2.3 via 192.168.1.254 dev wlp2s0 onlink
+prohibit 10.66.0.0/16
+default via fe80::1 dev wlp2s0 proto ra metric 20600 pref medium
+fe80::/64 dev wlp2s0 proto kernel metric 1024 pref medium
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Table{
+		{Prefix: netip.MustParsePrefix("0.0.0.0/0"), Gateway: netip.MustParseAddr("192.168.1.1"), Interface: "wlp2s0", Metric: 600, Type: typeUnicast, Protocol: "dhcp"},
+		{Prefix: netip.MustParsePrefix("172.17.0.0/16"), Interface: "docker0", Type: typeUnicast, Protocol: "kernel"},
+		{Prefix: netip.MustParsePrefix("10.1.2.3/32"), Gateway: netip.MustParseAddr("192.168.1.254"), Interface: "wlp2s0", Type: typeUnicast},
+		{Prefix: netip.MustParsePrefix("10.66.0.0/16"), Type: typeProhibit},
+		{Prefix: netip.MustParsePrefix("::/0"), Gateway: netip.MustParseAddr("fe80::1"), Interface: "wlp2s0", Metric: 20600, Type: typeUnicast, Protocol: "ra"},
+		{Prefix: netip.MustParsePrefix("fe80::/64"), Interface: "wlp2s0", Metric: 1024, Type: typeUnicast, Protocol: "kernel"},
+	}
+	if !reflect.DeepEqual(table, want) {
+		t.Errorf("got %+v\nwant %+v", table, want)
+	}
+
+	var kinds []string
+	for _, r := range table {
+		kinds = append(kinds, r.Kind())
+	}
+	if want := []string{"default", "connected", "static", "prohibit", "default", "connected"}; !reflect.DeepEqual(kinds, want) {
+		t.Errorf("kinds = %q, want %q", kinds, want)
+	}
+}
+
+func TestParseIPRouteMultipath(t *testing.T) {
+	table, err := ParseIPRoute(`10.0.0.0/8 proto static metric 5
+	nexthop via 192.168.1.1 dev eth0 weight 1
+	nexthop via 192.168.2.1 dev eth1 weight 2
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(table) != 2 || table[1].Gateway.String() != "192.168.2.1" || table[1].Interface != "eth1" || table[1].Metric != 5 {
+		t.Errorf("got %+v", table)
+	}
+}
+
+func TestParseIPRouteDefaultFamily(t *testing.T) {
+	tests := []struct {
+		name   string
+		routes string
+		want   []string // Prefix of each route
+	}{
+		{"IPv4 table", "default dev ppp0 scope link\n10.64.64.64 dev ppp0 proto kernel scope link\n",
+			[]string{"0.0.0.0/0", "10.64.64.64/32"}},
+		{"ip -6 route", "default dev wg0 metric 1024 pref medium\nunreachable default dev lo metric 4096\nfe80::/64 dev eth0 proto kernel metric 256\n",
+			[]string{"::/0", "::/0", "fe80::/64"}},
+		{"IPv6 multipath", "default proto ra metric 1024\n\tnexthop via fe80::1 dev eth0 weight 1\n\tnexthop via fe80::2 dev eth1 weight 1\n",
+			[]string{"::/0", "::/0"}},
+		{"mixed families", "default dev wg0\n10.0.0.0/8 dev eth0\nfe80::/64 dev eth0\n",
+			[]string{"0.0.0.0/0", "10.0.0.0/8", "fe80::/64"}},
+		{"default alone", "default dev wg0\n", []string{"0.0.0.0/0"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table, err := ParseIPRoute(tt.routes)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, r := range table {
+				got = append(got, r.Prefix.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("prefixes = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseIPRouteErrors(t *testing.T) {
+	for input, wantErr := range map[string]string{
+		"":                                   "no routes found",
+		"10.0.0.0/33 dev eth0":               `line 1: "10.0.0.0/33" is not a valid destination`,
+		"default via 10.0.0.300 dev eth0":    `line 1: gateway "10.0.0.300" is not a valid address`,
+		"10.0.0.0/8 dev eth0 metric high":    `line 1: metric "high" is not a number`,
+		"10.0.0.0/8 dev":                     "line 1: dev needs a value",
+		"\tnexthop via 10.0.0.1 dev eth0":    "line 1: nexthop without a route before it",
+		"local 127.0.0.1 dev lo table local": "local routes are from the local table",
+		"10.0.0.0/8 via fe80::1 dev eth0":    "line 1: 10.0.0.0/8 has a gateway from the other IP family",
+	} {
+		if _, err := ParseIPRoute(input); err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("ParseIPRoute(%q) error = %v, want %q", input, err, wantErr)
+		}
+	}
+}
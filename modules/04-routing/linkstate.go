@@ -0,0 +1,315 @@
+package routing
+
+import (
+	"bufio"
+	"fmt"
+	"net/netip"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// OSPFLink is a point-to-point link between two OSPF routers, with the same
+// cost in both directions
+type OSPFLink struct {
+	A, B string
+	Cost int
+	Down bool
+}
+
+func (l OSPFLink) String() string {
+	return l.A + "–" + l.B
+}
+
+// Network is a prefix a router advertises, such as a LAN or loopback
+type Network struct {
+	Router string
+	Prefix netip.Prefix
+}
+
+// LinkState is an OSPF area: its routers, the weighted links between them,
+// and the networks each advertises
+type LinkState struct {
+	Routers  []string
+	Links    []OSPFLink
+	Networks []Network
+}
+
+// ParseLinkState reads an area written one item per line: "r1 r2 10" for a
+// link and its cost, and "r1 10.0.1.0/24" for a network r1 advertises.
+// Routers are created as they are named; # starts a comment.
+func ParseLinkState(text string) (LinkState, error) {
+	var ls LinkState
+	seen := map[string]bool{}
+	addRouter := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			ls.Routers = append(ls.Routers, name)
+		}
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for n := 1; scanner.Scan(); n++ {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		fields := strings.Fields(line)
+		switch len(fields) {
+		case 0:
+			continue
+		case 2:
+			prefix, err := netip.ParsePrefix(fields[1])
+			if err != nil {
+				return LinkState{}, fmt.Errorf("line %d: %q is not a valid network", n, fields[1])
+			}
+			addRouter(fields[0])
+			ls.Networks = append(ls.Networks, Network{Router: fields[0], Prefix: prefix.Masked()})
+		case 3:
+			cost, err := strconv.Atoi(fields[2])
+			if err != nil || cost < 1 || cost > 65535 {
+				return LinkState{}, fmt.Errorf("line %d: cost %q should be a number from 1 to 65535", n, fields[2])
+			}
+			if fields[0] == fields[1] {
+				return LinkState{}, fmt.Errorf("line %d: %s can't link to itself", n, fields[0])
+			}
+			if _, ok := ls.link(fields[0], fields[1]); ok {
+				return LinkState{}, fmt.Errorf("line %d: %s and %s are already linked", n, fields[0], fields[1])
+			}
+			addRouter(fields[0])
+			addRouter(fields[1])
+			ls.Links = append(ls.Links, OSPFLink{A: fields[0], B: fields[1], Cost: cost})
+		default:
+			return LinkState{}, fmt.Errorf(`line %d: write "router router cost" for a link or "router prefix" for a network`, n)
+		}
+	}
+	if len(ls.Links) == 0 {
+		return LinkState{}, fmt.Errorf("no links defined")
+	}
+	return ls, nil
+}
+
+// String writes the area back out in the form ParseLinkState reads. Links
+// that are down are commented out.
+func (ls LinkState) String() string {
+	var b strings.Builder
+	for _, l := range ls.Links {
+		if l.Down {
+			b.WriteString("# down: ")
+		}
+		fmt.Fprintf(&b, "%s %s %d\n", l.A, l.B, l.Cost)
+	}
+	for _, n := range ls.Networks {
+		fmt.Fprintf(&b, "%s %s\n", n.Router, n.Prefix)
+	}
+	return b.String()
+}
+
+// link finds the link between two routers, in either order
+func (ls LinkState) link(a, b string) (int, bool) {
+	for i, l := range ls.Links {
+		if (l.A == a && l.B == b) || (l.A == b && l.B == a) {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+// adjacency is a router's neighbour over a link that is up
+type adjacency struct {
+	Router string
+	Cost   int
+}
+
+// neighbours lists a router's neighbours over links that are up, by name
+func (ls LinkState) neighbours(router string) []adjacency {
+	var adj []adjacency
+	for _, l := range ls.Links {
+		switch {
+		case l.Down:
+		case l.A == router:
+			adj = append(adj, adjacency{l.B, l.Cost})
+		case l.B == router:
+			adj = append(adj, adjacency{l.A, l.Cost})
+		}
+	}
+	sort.Slice(adj, func(i, j int) bool { return adj[i].Router < adj[j].Router })
+	return adj
+}
+
+// Delivery is one LSA sent over one link during flooding
+type Delivery struct {
+	From, To string
+	Origin   string // The router whose LSA it is
+	New      bool   // Whether the receiver didn't have it yet
+}
+
+// FloodRound is one round of flooding, and which LSAs each router holds
+// once it is done
+type FloodRound struct {
+	Deliveries []Delivery
+	LSDB       map[string]map[string]bool // Router to the origins it has current LSAs from
+}
+
+// Flood simulates flooding new LSAs from the given origins. Every other
+// router starts with current LSAs from everyone else, so flooding from all
+// routers is the initial exchange and flooding from a link's two ends is
+// reconvergence after it changes. Round 0 is each origin holding its own new
+// LSA. In each round, every router sends the LSAs it learned in the last
+// round to its neighbours, except the one it learned them from.
+func (ls LinkState) Flood(origins []string) []FloodRound {
+	fresh := map[string]bool{}
+	for _, o := range origins {
+		fresh[o] = true
+	}
+	lsdb := map[string]map[string]bool{}
+	for _, r := range ls.Routers {
+		lsdb[r] = map[string]bool{}
+		for _, o := range ls.Routers {
+			lsdb[r][o] = !fresh[o] || r == o
+		}
+	}
+
+	type learned struct{ router, origin, from string }
+	var last []learned
+	for _, o := range ls.Routers {
+		if fresh[o] {
+			last = append(last, learned{o, o, ""})
+		}
+	}
+	rounds := []FloodRound{{LSDB: copyLSDB(lsdb)}}
+	for len(last) > 0 {
+		var round FloodRound
+		var next []learned
+		for _, l := range last {
+			for _, n := range ls.neighbours(l.router) {
+				if n.Router == l.from {
+					continue
+				}
+				d := Delivery{From: l.router, To: n.Router, Origin: l.origin, New: !lsdb[n.Router][l.origin]}
+				if d.New {
+					lsdb[n.Router][l.origin] = true
+					next = append(next, learned{n.Router, l.origin, l.router})
+				}
+				round.Deliveries = append(round.Deliveries, d)
+			}
+		}
+		round.LSDB = copyLSDB(lsdb)
+		rounds = append(rounds, round)
+		last = next
+	}
+	return rounds
+}
+
+func copyLSDB(lsdb map[string]map[string]bool) map[string]map[string]bool {
+	out := make(map[string]map[string]bool, len(lsdb))
+	for r, known := range lsdb {
+		out[r] = make(map[string]bool, len(known))
+		for o, ok := range known {
+			out[r][o] = ok
+		}
+	}
+	return out
+}
+
+// SPFNode is a router Dijkstra's algorithm has reached, tentatively or for
+// good
+type SPFNode struct {
+	Router   string
+	Cost     int
+	Parent   string // The router it is reached through, empty for the root
+	FirstHop string // The root's neighbour on the path, empty for the root
+}
+
+// SPFStep is one iteration of Dijkstra's algorithm: a router joins the
+// shortest-path tree and its neighbours' costs are relaxed
+type SPFStep struct {
+	Added      SPFNode
+	Tree       []SPFNode // The tree so far, in the order routers joined
+	Candidates []SPFNode // Routers reached but not yet in the tree, cheapest first
+	Updated    []string  // Candidates added or made cheaper by this step
+}
+
+// SPF runs Dijkstra's algorithm from a router, recording each iteration.
+// Ties go to the router whose name sorts first.
+func (ls LinkState) SPF(root string) []SPFStep {
+	tentative := map[string]SPFNode{root: {Router: root}}
+	inTree := map[string]bool{}
+	var tree []SPFNode
+	var steps []SPFStep
+	for len(tentative) > 0 {
+		var best SPFNode
+		first := true
+		for _, n := range tentative {
+			if first || n.Cost < best.Cost || (n.Cost == best.Cost && n.Router < best.Router) {
+				best, first = n, false
+			}
+		}
+		delete(tentative, best.Router)
+		inTree[best.Router] = true
+		tree = append(tree, best)
+
+		step := SPFStep{Added: best, Tree: append([]SPFNode(nil), tree...)}
+		for _, adj := range ls.neighbours(best.Router) {
+			if inTree[adj.Router] {
+				continue
+			}
+			cost := best.Cost + adj.Cost
+			if current, ok := tentative[adj.Router]; ok && current.Cost <= cost {
+				continue
+			}
+			firstHop := best.FirstHop
+			if best.Router == root {
+				firstHop = adj.Router
+			}
+			tentative[adj.Router] = SPFNode{Router: adj.Router, Cost: cost, Parent: best.Router, FirstHop: firstHop}
+			step.Updated = append(step.Updated, adj.Router)
+		}
+		for _, n := range tentative {
+			step.Candidates = append(step.Candidates, n)
+		}
+		sort.Slice(step.Candidates, func(i, j int) bool {
+			a, b := step.Candidates[i], step.Candidates[j]
+			return a.Cost < b.Cost || (a.Cost == b.Cost && a.Router < b.Router)
+		})
+		steps = append(steps, step)
+	}
+	return steps
+}
+
+// OSPFRoute is an entry in a router's table computed from its
+// shortest-path tree
+type OSPFRoute struct {
+	Prefix  netip.Prefix
+	Router  string // The router advertising it
+	Cost    int
+	NextHop string // The neighbour to send to, empty for the router's own networks
+}
+
+// Routes computes a router's routes to every network it can reach. When two
+// routers advertise the same prefix, the cheaper wins.
+func (ls LinkState) Routes(root string) []OSPFRoute {
+	steps := ls.SPF(root)
+	reached := map[string]SPFNode{}
+	if len(steps) > 0 {
+		for _, n := range steps[len(steps)-1].Tree {
+			reached[n.Router] = n
+		}
+	}
+
+	var routes []OSPFRoute
+	index := map[netip.Prefix]int{}
+	for _, network := range ls.Networks {
+		node, ok := reached[network.Router]
+		if !ok {
+			continue
+		}
+		route := OSPFRoute{Prefix: network.Prefix, Router: network.Router, Cost: node.Cost, NextHop: node.FirstHop}
+		if i, ok := index[network.Prefix]; ok {
+			if route.Cost < routes[i].Cost {
+				routes[i] = route
+			}
+			continue
+		}
+		index[network.Prefix] = len(routes)
+		routes = append(routes, route)
+	}
+	return routes
+}
@@ -0,0 +1,139 @@
+package routing
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+	"testing"
+)
+
+func sampleArea(t *testing.T) LinkState {
+	t.Helper()
+	data, err := embedded.ReadFile("content/ospf.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ls, err := ParseLinkState(string(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ls
+}
+
+func TestParseLinkState(t *testing.T) {
+	ls := sampleArea(t)
+	if len(ls.Routers) != 5 || len(ls.Links) != 6 || len(ls.Networks) != 5 {
+		t.Errorf("sample area has %d routers, %d links and %d networks", len(ls.Routers), len(ls.Links), len(ls.Networks))
+	}
+
+	// String round trips, keeping failed links as comments
+	ls.Links[0].Down = true
+	again, err := ParseLinkState(ls.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(again.Links) != 5 || !strings.HasPrefix(ls.String(), "# down: r1 r2 10\n") {
+		t.Errorf("round trip:\n%s", ls.String())
+	}
+
+	for _, tt := range []struct{ text, err string }{
+		{"r1 r2 0", "line 1: cost"},
+		{"r1 r1 5", "line 1: r1 can't link to itself"},
+		{"r1 r2 5\nr2 r1 7", "line 2: r2 and r1 are already linked"},
+		{"r1 r2 5\nr1 10.0.0.300/24", "line 2:"},
+		{"r1", "line 1: write"},
+		{"# nothing\nr1 10.0.1.0/24", "no links defined"},
+	} {
+		if _, err := ParseLinkState(tt.text); err == nil || !strings.HasPrefix(err.Error(), tt.err) {
+			t.Errorf("ParseLinkState(%q) = %v, want %q...", tt.text, err, tt.err)
+		}
+	}
+}
+
+func TestFlood(t *testing.T) {
+	ls := sampleArea(t)
+	rounds := ls.Flood(ls.Routers)
+	// r1's LSA takes longest to reach r4, two hops away
+	if len(rounds) != 4 {
+		t.Errorf("initial flooding took %d rounds, want 4", len(rounds)-1)
+	}
+	if rounds[0].LSDB["r1"]["r2"] || !rounds[1].LSDB["r1"]["r2"] || rounds[1].LSDB["r1"]["r4"] {
+		t.Errorf("r1 learns r2's LSA in round 1 and r4's later: %v", rounds[1].LSDB["r1"])
+	}
+	for r, known := range rounds[len(rounds)-1].LSDB {
+		for o, ok := range known {
+			if !ok {
+				t.Errorf("%s never gets %s's LSA", r, o)
+			}
+		}
+	}
+
+	// After a change only the link's ends have new LSAs to flood
+	rounds = ls.Flood([]string{"r3", "r5"})
+	if !rounds[0].LSDB["r1"]["r2"] || rounds[0].LSDB["r1"]["r3"] || !rounds[0].LSDB["r3"]["r3"] {
+		t.Errorf("reconvergence starts with %v", rounds[0].LSDB["r1"])
+	}
+	for _, d := range rounds[1].Deliveries {
+		if d.From != d.Origin {
+			t.Errorf("round 1 has %+v, only the origins send", d)
+		}
+	}
+
+	// A cut off router never hears the others
+	ls.Links = append(ls.Links, OSPFLink{A: "r6", B: "r5", Cost: 1, Down: true})
+	ls.Routers = append(ls.Routers, "r6")
+	last := ls.Flood(ls.Routers)
+	if known := last[len(last)-1].LSDB["r6"]; known["r1"] || !known["r6"] {
+		t.Errorf("partitioned r6 knows %v", known)
+	}
+}
+
+func TestSPF(t *testing.T) {
+	ls := sampleArea(t)
+	steps := ls.SPF("r1")
+	var order []string
+	for _, s := range steps {
+		order = append(order, fmt.Sprintf("%s:%d", s.Added.Router, s.Added.Cost))
+	}
+	// r2 and r5 tie at 10, and r4 gets cheaper twice before it joins
+	if got := strings.Join(order, " "); got != "r1:0 r3:5 r2:10 r5:10 r4:15" {
+		t.Errorf("SPF from r1 adds %s", got)
+	}
+	if c := steps[1].Candidates; len(c) != 3 || c[2].Router != "r4" || c[2].Cost != 25 {
+		t.Errorf("candidates after r3: %+v", c)
+	}
+	if u := steps[3].Updated; len(u) != 1 || u[0] != "r4" {
+		t.Errorf("r5 updates %v, want r4", u)
+	}
+	if last := steps[len(steps)-1].Tree[4]; last.Parent != "r5" || last.FirstHop != "r3" {
+		t.Errorf("r4 is reached %+v", last)
+	}
+}
+
+func TestRoutes(t *testing.T) {
+	ls := sampleArea(t)
+	format := func(routes []OSPFRoute) string {
+		var out []string
+		for _, r := range routes {
+			out = append(out, fmt.Sprintf("%s>%s:%d", r.Prefix, orConnected(r.NextHop), r.Cost))
+		}
+		return strings.Join(out, " ")
+	}
+	want := "10.0.1.0/24>connected:0 10.0.2.0/24>r2:10 10.0.3.0/24>r3:5 10.0.4.0/24>r3:15 10.0.5.0/24>r3:10"
+	if got := format(ls.Routes("r1")); got != want {
+		t.Errorf("routes on r1:\n got %s\nwant %s", got, want)
+	}
+
+	// Failing r3–r5 sends r4 and r5's networks through r2
+	ls.Links[4].Down = true
+	want = "10.0.1.0/24>connected:0 10.0.2.0/24>r2:10 10.0.3.0/24>r3:5 10.0.4.0/24>r2:20 10.0.5.0/24>r2:25"
+	if got := format(ls.Routes("r1")); got != want {
+		t.Errorf("routes on r1 with r3–r5 down:\n got %s\nwant %s", got, want)
+	}
+
+	// The cheaper of two routers advertising a prefix wins
+	ls.Networks = append(ls.Networks, Network{Router: "r2", Prefix: netip.MustParsePrefix("10.0.5.0/24")})
+	if got := ls.Routes("r1")[4]; got.Router != "r2" || got.Cost != 10 {
+		t.Errorf("anycast route %+v", got)
+	}
+}
@@ -0,0 +1,343 @@
+// Package routing is the Routing Protocols learning module
+package routing
+
+import (
+	"embed"
+	"fmt"
+	"strings"
+
+	"netlab/internal/packs"
+	"netlab/pkg/components"
+	"netlab/pkg/styles"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+//go:embed README.md content
+var embedded embed.FS
+
+// screen is one of the module's tabs, in the order tab cycles through them
+type screen int
+
+const (
+	screenLookup screen = iota
+	screenImport
+	screenTrace
+	screenOSPF
+	screenBGP
+	screenLesson
+	screenQuiz
+	screenCount
+)
+
+var screenNames = map[screen]string{
+	screenLookup: "Lookup",
+	screenImport: "Import",
+	screenTrace:  "Trace",
+	screenOSPF:   "OSPF",
+	screenBGP:    "BGP",
+	screenLesson: "Lesson",
+	screenQuiz:   "Quiz",
+}
+
+// Model is the TUI for the Routing Protocols module: the longest prefix
+// match simulator and its table import, the topology tracer, the OSPF
+// link-state simulator, the BGP path selection explainer, its README as the
+// lesson, and its quiz
+type Model struct {
+	simulator simulator
+	importer  importer
+	tracer    tracer
+	ospf      ospf
+	explainer explainer
+	lesson    components.Document
+	quiz      components.Quiz
+	screen    screen
+	width     int
+	height    int
+	quitting  bool
+}
+
+// NewModel creates a new Routing Protocols module model
+func NewModel() (Model, error) {
+	readme, err := embedded.ReadFile("README.md")
+	if err != nil {
+		return Model{}, err
+	}
+	questions, err := loadQuiz()
+	if err != nil {
+		return Model{}, err
+	}
+	sample, err := embedded.ReadFile("content/routes.txt")
+	if err != nil {
+		return Model{}, err
+	}
+	table, err := ParseIPRoute(string(sample))
+	if err != nil {
+		return Model{}, fmt.Errorf("content/routes.txt: %w", err)
+	}
+	topology, err := SampleTopology()
+	if err != nil {
+		return Model{}, err
+	}
+	area, err := embedded.ReadFile("content/ospf.txt")
+	if err != nil {
+		return Model{}, err
+	}
+	ls, err := ParseLinkState(string(area))
+	if err != nil {
+		return Model{}, fmt.Errorf("content/ospf.txt: %w", err)
+	}
+	network, err := SampleBGP()
+	if err != nil {
+		return Model{}, err
+	}
+
+	return Model{
+		simulator: newSimulator(table, "sample table"),
+		importer:  newImporter(string(sample)),
+		tracer:    newTracer(topology),
+		ospf:      newOSPF(ls),
+		explainer: newExplainer(network),
+		lesson:    components.NewDocument(string(readme)),
+		quiz:      components.NewQuiz(questions),
+	}, nil
+}
+
+// loadQuiz reads content/quiz.yaml
+func loadQuiz() ([]components.QuizQuestion, error) {
+	data, err := embedded.ReadFile("content/quiz.yaml")
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := packs.ParseQuiz(data)
+	if err != nil {
+		return nil, fmt.Errorf("content/quiz.yaml: %w", err)
+	}
+
+	questions := make([]components.QuizQuestion, len(parsed))
+	for i, q := range parsed {
+		questions[i] = components.QuizQuestion{
+			Question:    q.Question,
+			Options:     q.Options,
+			Answer:      q.Answer,
+			Explanation: q.Explanation,
+			SeeAlso:     q.Lesson,
+		}
+	}
+	return questions, nil
+}
+
+func (m Model) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+// contentSize returns the space inside the frame
+func (m Model) contentSize() (width, height int) {
+	// Header (2), frame border (2), separator and help (2)
+	return m.width - 4, m.height - 6
+}
+
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.lesson.SetSize(m.contentSize())
+		m.simulator.SetSize(m.contentSize())
+		m.importer.SetSize(m.contentSize())
+		m.tracer.SetSize(m.contentSize())
+		m.ospf.SetSize(m.contentSize())
+		m.explainer.SetSize(m.contentSize())
+		return m, nil
+
+	case topologyLoadedMsg:
+		m.tracer, _ = m.tracer.Update(msg)
+		return m, nil
+
+	case bgpLoadedMsg:
+		m.explainer, _ = m.explainer.Update(msg)
+		return m, nil
+
+	case tableImportedMsg:
+		m.simulator.SetTable(msg.table, "pasted table")
+		m.screen = screenLookup
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "esc":
+			m.quitting = true
+			return m, tea.Quit
+		case "q":
+			// Where there's an input, q is just a letter being typed
+			if m.screen == screenLesson || m.screen == screenQuiz {
+				m.quitting = true
+				return m, tea.Quit
+			}
+		case "tab":
+			m.screen = (m.screen + 1) % screenCount
+			return m, nil
+		case "shift+tab":
+			m.screen = (m.screen + screenCount - 1) % screenCount
+			return m, nil
+		}
+
+		if m.screen == screenQuiz {
+			m.quiz = m.quiz.Update(msg)
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	switch m.screen {
+	case screenLookup:
+		m.simulator, cmd = m.simulator.Update(msg)
+	case screenImport:
+		m.importer, cmd = m.importer.Update(msg)
+	case screenTrace:
+		m.tracer, cmd = m.tracer.Update(msg)
+	case screenOSPF:
+		m.ospf, cmd = m.ospf.Update(msg)
+	case screenBGP:
+		m.explainer, cmd = m.explainer.Update(msg)
+	case screenLesson:
+		m.lesson, cmd = m.lesson.Update(msg)
+	}
+	return m, cmd
+}
+
+func (m Model) View() string {
+	if m.width < 60 || m.height < 20 {
+		return fmt.Sprintf("⚠️  Terminal too small (%dx%d), please resize to at least 60x20", m.width, m.height)
+	}
+
+	width, height := m.contentSize()
+	var body string
+	switch m.screen {
+	case screenLookup:
+		body = m.simulator.View()
+	case screenImport:
+		body = m.importer.View()
+	case screenTrace:
+		body = m.tracer.View()
+	case screenOSPF:
+		body = m.ospf.View()
+	case screenBGP:
+		body = m.explainer.View()
+	case screenLesson:
+		body = m.lesson.View()
+	case screenQuiz:
+		body = m.quiz.View(width)
+	}
+
+	frame := lipgloss.NewStyle().
+		Width(m.width-2).
+		Height(height).
+		Padding(0, 1).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(styles.Border)
+
+	return lipgloss.JoinVertical(lipgloss.Left, m.headerView(), frame.Render(body), m.footerView())
+}
+
+func (m Model) headerView() string {
+	title := styles.H2.Copy().
+		Width(m.width-4).
+		Align(lipgloss.Center).
+		Margin(0, 0).
+		Render("NetLab Routing Protocols")
+
+	breadcrumb := styles.BodyMuted.Copy().
+		Margin(0, 0).
+		Render("NetLab > Routing Protocols > " + screenNames[m.screen])
+
+	return lipgloss.JoinVertical(lipgloss.Left, title, breadcrumb)
+}
+
+func (m Model) footerView() string {
+	next := styles.KeyBinding.Render("tab") + " " + strings.ToLower(screenNames[(m.screen+1)%screenCount])
+
+	var helpKeys []string
+	switch m.screen {
+	case screenLookup:
+		helpKeys = []string{
+			styles.KeyBinding.Render("↑/↓") + " scroll",
+			next,
+			styles.KeyBinding.Render("esc") + " quit",
+		}
+	case screenImport:
+		helpKeys = []string{
+			styles.KeyBinding.Render("ctrl+s") + " use table",
+			styles.KeyBinding.Render("ctrl+r") + " sample",
+			next,
+			styles.KeyBinding.Render("esc") + " quit",
+		}
+	case screenTrace:
+		helpKeys = []string{
+			styles.KeyBinding.Render("↑/↓") + " field",
+			styles.KeyBinding.Render("enter") + " load file",
+			styles.KeyBinding.Render("pgup/pgdn") + " scroll",
+			next,
+			styles.KeyBinding.Render("esc") + " quit",
+		}
+	case screenOSPF:
+		helpKeys = append(m.ospf.helpKeys(), next, styles.KeyBinding.Render("esc")+" quit")
+	case screenBGP:
+		helpKeys = []string{
+			styles.KeyBinding.Render("↑/↓") + " field",
+			styles.KeyBinding.Render("enter") + " load file",
+			styles.KeyBinding.Render("pgup/pgdn") + " scroll",
+			next,
+			styles.KeyBinding.Render("esc") + " quit",
+		}
+	case screenLesson:
+		helpKeys = []string{
+			styles.KeyBinding.Render("↑/↓") + " scroll",
+			next,
+			styles.KeyBinding.Render("q") + " quit",
+		}
+	case screenQuiz:
+		helpKeys = []string{
+			styles.KeyBinding.Render("↑/↓") + " choose",
+			styles.KeyBinding.Render("enter") + " answer",
+			next,
+			styles.KeyBinding.Render("q") + " quit",
+		}
+	}
+	helpText := styles.Help.Copy().UnsetMargins().Render(strings.Join(helpKeys, " • "))
+	if lipgloss.Width(helpText) > m.width-2 {
+		helpText = styles.Help.Copy().UnsetMargins().Render(strings.Join(helpKeys, "  "))
+	}
+	if lipgloss.Width(helpText) > m.width-2 {
+		// Paging is the least needed, as the mouse wheel scrolls too
+		var kept []string
+		for _, key := range helpKeys {
+			if !strings.Contains(key, "pgup/pgdn") {
+				kept = append(kept, key)
+			}
+		}
+		helpText = styles.Help.Copy().UnsetMargins().Render(strings.Join(kept, "  "))
+	}
+
+	separator := styles.BodyDim.Render(strings.Repeat("─", m.width-2))
+	return lipgloss.JoinVertical(lipgloss.Left, separator, helpText)
+}
+
+// Run starts the Routing Protocols module
+func Run() error {
+	m, err := NewModel()
+	if err != nil {
+		return fmt.Errorf("loading module content: %w", err)
+	}
+
+	p := tea.NewProgram(
+		m,
+		tea.WithAltScreen(),
+		tea.WithMouseCellMotion(),
+	)
+	_, err = p.Run()
+	return err
+}
@@ -0,0 +1,205 @@
+package routing
+
+import (
+	"strings"
+	"testing"
+
+	"netlab/internal/tuitest"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestModelView(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		msgs          []tea.Msg
+	}{
+		{"routing_100x30", 100, 30, nil},
+		{"routing_60x20", 60, 20, nil},
+		{"routing_59x20_too_small", 59, 20, nil},
+		{"routing_100x30_connected", 100, 30, typeDestination("192.168.1.40")},
+		{"routing_100x30_blackhole", 100, 30, typeDestination("10.99.1.1")},
+		{"routing_100x30_no_route", 100, 30, typeDestination("2001:db8::1")},
+		{"routing_100x30_invalid", 100, 30, typeDestination("10.244.1")},
+		{"routing_100x30_steps", 100, 30, tuitest.Keys("pgdown")},
+		{"routing_100x30_import", 100, 30, tuitest.Keys("tab")},
+		{"routing_60x20_import", 60, 20, tuitest.Keys("tab")},
+		{"routing_100x30_import_error", 100, 30, tuitest.Keys("tab", "enter", "v", "i", "a", "ctrl+s")},
+		{"routing_100x30_trace", 100, 30, tuitest.Keys("tab", "tab")},
+		{"routing_60x20_trace", 60, 20, tuitest.Keys("tab", "tab")},
+		{"routing_100x30_trace_loop", 100, 30, typeTraceDestination("172.16.5.5")},
+		{"routing_100x30_trace_blackhole", 100, 30, typeTraceDestination("10.1.2.3")},
+		{"routing_100x30_trace_ttl", 100, 30, append(tuitest.Keys("tab", "tab", "down", "down", "backspace", "backspace"), tuitest.Keys("3")...)},
+		{"routing_100x30_trace_reply", 100, 30, tuitest.Keys("tab", "tab", "pgdown")},
+		{"routing_100x30_ospf", 100, 30, tuitest.Keys("tab", "tab", "tab")},
+		{"routing_60x20_ospf", 60, 20, tuitest.Keys("tab", "tab", "tab")},
+		{"routing_100x30_ospf_flood", 100, 30, tuitest.Keys("tab", "tab", "tab", "v", "right")},
+		{"routing_100x30_ospf_spf", 100, 30, tuitest.Keys("tab", "tab", "tab", "v", "v", "right", "right", "right")},
+		{"routing_100x30_ospf_failed", 100, 30, tuitest.Keys("tab", "tab", "tab", "down", "down", "down", "down", "f", "v", "v", "v")},
+		{"routing_100x30_ospf_cost", 100, 30, tuitest.Keys("tab", "tab", "tab", "down", "+")},
+		{"routing_100x30_ospf_edit", 100, 30, tuitest.Keys("tab", "tab", "tab", "e")},
+		{"routing_100x30_bgp", 100, 30, tuitest.Keys("tab", "tab", "tab", "tab")},
+		{"routing_60x20_bgp", 60, 20, tuitest.Keys("tab", "tab", "tab", "tab")},
+		{"routing_100x30_bgp_igp", 100, 30, typeBGP("tor", "192.0.2.0/24")},
+		{"routing_100x30_bgp_med", 100, 30, typeBGP("isp1", "198.51.100.0/24")},
+		{"routing_100x30_bgp_loop", 100, 30, typeBGP("node2", "10.244.1.0/24")},
+		{"routing_100x30_bgp_invalid", 100, 30, typeBGP("tor", "10.244")},
+		{"routing_100x30_lesson", 100, 30, tuitest.Keys("tab", "tab", "tab", "tab", "tab")},
+		{"routing_100x30_quiz", 100, 30, tuitest.Keys("tab", "tab", "tab", "tab", "tab", "tab")},
+		{"routing_100x30_quiz_answered", 100, 30, tuitest.Keys("tab", "tab", "tab", "tab", "tab", "tab", "enter")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewModel()
+			if err != nil {
+				t.Fatal(err)
+			}
+			tuitest.AssertView(t, tt.name, m, tt.width, tt.height, tt.msgs...)
+		})
+	}
+}
+
+// typeDestination replaces the simulator's starting destination
+func typeDestination(dst string) []tea.Msg {
+	keys := strings.Split(strings.Repeat("backspace ", len(defaultDestination)), " ")[:len(defaultDestination)]
+	return tuitest.Keys(append(keys, strings.Split(dst, "")...)...)
+}
+
+// typeTraceDestination replaces the tracer's starting destination
+func typeTraceDestination(dst string) []tea.Msg {
+	keys := append([]string{"tab", "tab", "down", "backspace", "backspace", "backspace"}, strings.Split(dst, "")...)
+	return tuitest.Keys(keys...)
+}
+
+// typeBGP replaces the explainer's starting router and prefix
+func typeBGP(speaker, prefix string) []tea.Msg {
+	keys := []string{"tab", "tab", "tab", "tab"}
+	for range "border1" {
+		keys = append(keys, "backspace")
+	}
+	keys = append(append(keys, strings.Split(speaker, "")...), "down")
+	for range "198.51.100.0/24" {
+		keys = append(keys, "backspace")
+	}
+	return tuitest.Keys(append(keys, strings.Split(prefix, "")...)...)
+}
+
+func TestLoadBGP(t *testing.T) {
+	e := newExplainer(BGPNetwork{})
+	e.SetSize(96, 24)
+	e, _ = e.Update(loadBGP("content/bgp.yaml")())
+	if best, ok := e.decision.BestPath(); !ok || best.From != "border2" {
+		t.Errorf("best path after loading = %+v: %v", best, e.err)
+	}
+	e, _ = e.Update(loadBGP("testdata/missing.yaml")())
+	if e.loaded.err == nil || len(e.network.Speakers) == 0 {
+		t.Errorf("failed load: error %v, %d speakers kept", e.loaded.err, len(e.network.Speakers))
+	}
+}
+
+func TestLoadTopology(t *testing.T) {
+	tr := newTracer(Topology{})
+	tr.SetSize(96, 24)
+	tr, _ = tr.Update(loadTopology("content/topology.yaml")())
+	if tr.trip.Forward.Outcome != OutcomeDelivered {
+		t.Errorf("trace after loading = %s: %v", tr.trip.Forward.Outcome, tr.err)
+	}
+	tr, _ = tr.Update(loadTopology("testdata/missing.yaml")())
+	if tr.loaded.err == nil || len(tr.topology.Routers) == 0 {
+		t.Errorf("failed load: error %v, %d routers kept", tr.loaded.err, len(tr.topology.Routers))
+	}
+}
+
+func TestImportTable(t *testing.T) {
+	m, err := NewModel()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m = tuitest.Send(m, tuitest.Resize(100, 30), tuitest.Key("tab")).(Model)
+
+	// Replace the sample with a table of two routes, and use it
+	m.importer.area.SetValue("default via 10.0.0.1 dev eth0\n10.0.0.0/24 dev eth0 proto kernel scope link src 10.0.0.5")
+	var cmd tea.Cmd
+	m.importer, cmd = m.importer.Update(tuitest.Key("ctrl+s"))
+	if cmd == nil {
+		t.Fatalf("import failed: %v", m.importer.err)
+	}
+	updated, _ := m.Update(cmd())
+	m = updated.(Model)
+
+	if m.screen != screenLookup {
+		t.Errorf("screen after import = %s, want Lookup", screenNames[m.screen])
+	}
+	if len(m.simulator.table) != 2 || m.simulator.source != "pasted table" {
+		t.Errorf("simulator has %d routes from the %s", len(m.simulator.table), m.simulator.source)
+	}
+	// Only the default route matches the starting destination now
+	if r, ok := m.simulator.lookup.Route(); !ok || r.Kind() != "default" {
+		t.Errorf("lookup after import = %+v", r)
+	}
+}
+
+func TestQuitKeys(t *testing.T) {
+	m, err := NewModel()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// q is typed into the destination, but quits the lesson
+	lookup := tuitest.Send(m, tuitest.Resize(100, 30), tuitest.Key("q")).(Model)
+	if lookup.quitting || lookup.simulator.input.Value() != defaultDestination+"q" {
+		t.Errorf("q on the lookup: quitting %v, input %q", lookup.quitting, lookup.simulator.input.Value())
+	}
+	lesson := tuitest.Send(m, tuitest.Resize(100, 30), tuitest.Key("shift+tab"), tuitest.Key("shift+tab"), tuitest.Key("q")).(Model)
+	if lesson.screen != screenLesson {
+		t.Fatalf("two shift+tabs reach %s", screenNames[lesson.screen])
+	}
+	if !lesson.quitting {
+		t.Error("q on the lesson did not quit")
+	}
+}
+
+func TestOSPFReconverge(t *testing.T) {
+	m, err := NewModel()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m = tuitest.Send(m, tuitest.Resize(100, 30), tuitest.Key("tab"), tuitest.Key("tab"), tuitest.Key("tab")).(Model)
+	if m.screen != screenOSPF {
+		t.Fatalf("three tabs reach %s", screenNames[m.screen])
+	}
+	// The fifth link is r3–r5
+	for _, key := range []string{"down", "down", "down", "down", "f"} {
+		m.ospf, _ = m.ospf.Update(tuitest.Key(key))
+	}
+	if !m.ospf.ls.Links[4].Down || strings.Join(m.ospf.origins, " ") != "r3 r5" {
+		t.Fatalf("after f: links %+v, flooding from %v", m.ospf.ls.Links, m.ospf.origins)
+	}
+	if got := strings.Join(m.ospf.changed, " "); got != "r1 r3 r4 r5" {
+		t.Errorf("routes changed on %q", got)
+	}
+
+	// Editing replaces the area, and bad input leaves it as it was
+	m.ospf, _ = m.ospf.Update(tuitest.Key("e"))
+	m.ospf.area.SetValue("r1 r2 ten")
+	m.ospf, _ = m.ospf.Update(tuitest.Key("ctrl+s"))
+	if !m.ospf.editing || m.ospf.err == nil {
+		t.Fatalf("bad area applied: editing %v, err %v", m.ospf.editing, m.ospf.err)
+	}
+	m.ospf.area.SetValue("a b 1\nb c 1\nc 10.9.0.0/16")
+	m.ospf, _ = m.ospf.Update(tuitest.Key("ctrl+s"))
+	if m.ospf.editing || strings.Join(m.ospf.ls.Routers, " ") != "a b c" || m.ospf.root() != "a" {
+		t.Errorf("after edit: editing %v, routers %v, root %s", m.ospf.editing, m.ospf.ls.Routers, m.ospf.root())
+	}
+}
+
+func TestQuizContent(t *testing.T) {
+	questions, err := loadQuiz()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(questions) == 0 {
+		t.Error("quiz has no questions")
+	}
+}
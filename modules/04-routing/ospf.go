@@ -0,0 +1,636 @@
+package routing
+
+import (
+	"fmt"
+	"strings"
+
+	"netlab/pkg/styles"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ospfView is one of the OSPF screen's views, in the order v cycles through
+// them
+type ospfView int
+
+const (
+	viewArea ospfView = iota
+	viewFlood
+	viewSPF
+	viewRoutes
+	viewCount
+)
+
+var ospfViewNames = map[ospfView]string{
+	viewArea:   "Area",
+	viewFlood:  "Flooding",
+	viewSPF:    "SPF",
+	viewRoutes: "Routes",
+}
+
+// ospf is the link-state simulator: an area of routers and weighted links,
+// its LSAs flooding round by round, Dijkstra building a router's
+// shortest-path tree, and the routes that come out of it. Links can be
+// re-costed or failed to watch the area reconverge.
+type ospf struct {
+	ls      LinkState
+	view    ospfView
+	link    int // The selected link
+	router  int // The router whose tree and routes are shown
+	origins []string
+	rounds  []FloodRound
+	round   int
+	steps   []SPFStep
+	step    int
+	before  map[string][]OSPFRoute // Every router's routes before the last change
+	changed []string               // Routers whose routes the last change moved
+	status  string
+	editing bool
+	area    textarea.Model
+	err     error
+	body    viewport.Model
+	width   int
+}
+
+func newOSPF(ls LinkState) ospf {
+	area := textarea.New()
+	area.Placeholder = "r1 r2 10"
+	area.ShowLineNumbers = true
+	area.CharLimit = 0
+	area.MaxHeight = 0
+
+	o := ospf{ls: ls, area: area, body: viewport.New(0, 0)}
+	o.origins = ls.Routers
+	o.rounds = ls.Flood(o.origins)
+	o.spf()
+	return o
+}
+
+// SetSize fits the simulator into width x height cells
+func (o *ospf) SetSize(width, height int) {
+	o.width = width
+	o.area.SetWidth(width)
+	o.area.SetHeight(max(height-3, 1)) // The views bar, instructions and status lines
+	o.body.Width = width
+	o.body.Height = height - 2 // The views bar and the gap below it
+	o.body.SetContent(o.bodyView())
+}
+
+// root is the router whose shortest-path tree and routes are shown
+func (o ospf) root() string {
+	return o.ls.Routers[o.router]
+}
+
+// spf reruns Dijkstra from the shown router, back at its first step
+func (o *ospf) spf() {
+	o.steps = o.ls.SPF(o.root())
+	o.step = 0
+}
+
+// reconverge switches to a changed area: the given routers flood new LSAs,
+// every router reruns SPF, and the routers whose routes moved are noted
+func (o *ospf) reconverge(ls LinkState, origins []string, what string) {
+	root := o.root()
+	o.before = allRoutes(o.ls)
+	o.ls = ls
+	o.router = 0
+	for i, r := range ls.Routers {
+		if r == root {
+			o.router = i
+		}
+	}
+	o.link = min(o.link, len(ls.Links)-1)
+	o.origins = origins
+	o.rounds = ls.Flood(origins)
+	o.round = 0
+	o.spf()
+
+	after := allRoutes(ls)
+	o.changed = nil
+	for _, r := range ls.Routers {
+		if !sameRoutes(o.before[r], after[r]) {
+			o.changed = append(o.changed, r)
+		}
+	}
+	o.status = what + ": no routes changed"
+	if len(o.changed) > 0 {
+		o.status = what + ": routes changed on " + strings.Join(o.changed, ", ")
+	}
+}
+
+// setLink replaces a link and reconverges, flooding from its two ends
+func (o *ospf) setLink(link OSPFLink, what string) {
+	ls := o.ls
+	ls.Links = append([]OSPFLink(nil), o.ls.Links...)
+	ls.Links[o.link] = link
+	o.reconverge(ls, []string{link.A, link.B}, what)
+}
+
+func (o ospf) Update(msg tea.Msg) (ospf, tea.Cmd) {
+	var cmd tea.Cmd
+	if o.editing {
+		if msg, ok := msg.(tea.KeyMsg); ok && msg.String() == "ctrl+s" {
+			ls, err := ParseLinkState(o.area.Value())
+			o.err = err
+			if err != nil {
+				return o, nil
+			}
+			o.editing = false
+			o.area.Blur()
+			o.reconverge(ls, ls.Routers, "Area replaced")
+			o.body.SetContent(o.bodyView())
+			return o, nil
+		}
+		o.area, cmd = o.area.Update(msg)
+		return o, cmd
+	}
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		link := o.ls.Links[o.link]
+		switch key := msg.String(); key {
+		case "v":
+			o.view = (o.view + 1) % viewCount
+			o.body.GotoTop()
+		case "r":
+			o.router = (o.router + 1) % len(o.ls.Routers)
+			o.spf()
+		case "e":
+			o.editing = true
+			o.err = nil
+			o.area.SetValue(strings.TrimRight(o.ls.String(), "\n"))
+			return o, o.area.Focus()
+		case "left", "right":
+			step := 1
+			if key == "left" {
+				step = -1
+			}
+			switch o.view {
+			case viewFlood:
+				o.round = min(max(o.round+step, 0), len(o.rounds)-1)
+			case viewSPF:
+				o.step = min(max(o.step+step, 0), len(o.steps)-1)
+			}
+		case "up", "down":
+			if o.view != viewArea {
+				o.body, cmd = o.body.Update(msg)
+				return o, cmd
+			}
+			step := 1
+			if key == "up" {
+				step = len(o.ls.Links) - 1
+			}
+			o.link = (o.link + step) % len(o.ls.Links)
+		case "+", "=", "-":
+			cost := link.Cost + 1
+			if key == "-" {
+				cost = link.Cost - 1
+			}
+			if o.view == viewArea && cost >= 1 && cost <= 65535 {
+				what := fmt.Sprintf("Link %s cost %d → %d", link, link.Cost, cost)
+				link.Cost = cost
+				o.setLink(link, what)
+			}
+		case "f":
+			if o.view == viewArea {
+				what := "Link " + link.String() + " failed"
+				if link.Down {
+					what = "Link " + link.String() + " restored"
+				}
+				link.Down = !link.Down
+				o.setLink(link, what)
+			}
+		case "pgup", "pgdown":
+			o.body, cmd = o.body.Update(msg)
+			return o, cmd
+		}
+		o.body.SetContent(o.bodyView())
+		return o, nil
+
+	case tea.MouseMsg:
+		o.body, cmd = o.body.Update(msg)
+		return o, cmd
+	}
+	return o, nil
+}
+
+// helpKeys lists the keys the current view uses
+func (o ospf) helpKeys() []string {
+	key := styles.KeyBinding.Render
+	switch {
+	case o.editing:
+		return []string{key("ctrl+s") + " apply"}
+	case o.view == viewArea:
+		return []string{key("↑/↓") + " link", key("v") + " view"}
+	case o.view == viewFlood:
+		return []string{key("←/→") + " round", key("v") + " view"}
+	case o.view == viewSPF:
+		return []string{key("←/→") + " step", key("r") + " router", key("v") + " view"}
+	}
+	return []string{key("r") + " router", key("v") + " view"}
+}
+
+func (o ospf) View() string {
+	if o.editing {
+		intro := styles.BodyMuted.Render("Edit the area, then press ctrl+s to flood it.")
+		status := styles.BodyDim.Render(fmt.Sprintf("%d lines", o.area.LineCount()))
+		if o.err != nil {
+			status = styles.StatusError.Render("⚠️  " + o.err.Error())
+		}
+		return o.barView() + "\n" + intro + "\n" + o.area.View() + "\n" + status
+	}
+	return o.barView() + "\n\n" + o.body.View()
+}
+
+// barView names the views, marking the current one, and the router shown
+func (o ospf) barView() string {
+	names := make([]string, viewCount)
+	for v := ospfView(0); v < viewCount; v++ {
+		names[v] = styles.BodyDim.Render(ospfViewNames[v])
+		if v == o.view {
+			names[v] = nodeStyle.Render(ospfViewNames[v])
+		}
+	}
+	return strings.Join(names, styles.BodyDim.Render(" · ")) + styles.BodyMuted.Render("   router "+o.root())
+}
+
+// bodyView renders the current view, after what the last change did
+func (o ospf) bodyView() string {
+	var sections []string
+	if o.status != "" {
+		sections = append(sections, styles.StatusInfo.Copy().Width(max(o.width, 1)).Render("↻ "+o.status))
+	}
+	switch o.view {
+	case viewArea:
+		sections = append(sections, o.areaView())
+	case viewFlood:
+		sections = append(sections, o.floodView())
+	case viewSPF:
+		sections = append(sections, o.spfView())
+	case viewRoutes:
+		sections = append(sections, o.routesView())
+	}
+	return strings.Join(sections, "\n\n")
+}
+
+// areaView lists the links with their costs, and the networks each router
+// advertises
+func (o ospf) areaView() string {
+	names := make([]string, len(o.ls.Links))
+	width := 0
+	for i, l := range o.ls.Links {
+		names[i] = l.A + " ── " + l.B
+		width = max(width, lipgloss.Width(names[i]))
+	}
+
+	lines := []string{styles.BodyMuted.Render(fmt.Sprintf("%d routers, %d links", len(o.ls.Routers), len(o.ls.Links)))}
+	for i, l := range o.ls.Links {
+		line := fmt.Sprintf("  %s %5d", pad(names[i], width), l.Cost)
+		if i == o.link {
+			line = "▸" + line[1:]
+		}
+		switch {
+		case l.Down && i == o.link:
+			line = bestRowStyle.Render(line) + styles.StatusError.Render("  down")
+		case l.Down:
+			line = styles.BodyDim.Render(line) + styles.StatusError.Render("  down")
+		case i == o.link:
+			line = bestRowStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+
+	networks := map[string][]string{}
+	for _, n := range o.ls.Networks {
+		networks[n.Router] = append(networks[n.Router], n.Prefix.String())
+	}
+	var advertised []string
+	for _, r := range o.ls.Routers {
+		if len(networks[r]) > 0 {
+			advertised = append(advertised, r+" "+strings.Join(networks[r], ", "))
+		}
+	}
+	if len(advertised) > 0 {
+		lines = append(lines, "", styles.BodyMuted.Render("Networks"))
+		lines = append(lines, wrapItems(advertised, " · ", o.width)...)
+	}
+	hint := "+/- change the selected link's cost, f fails or restores it, and e edits the whole area."
+	return strings.Join(lines, "\n") + "\n\n" + styles.BodyDim.Copy().Width(max(o.width, 1)).Render(hint)
+}
+
+// floodView shows one round of flooding: each router's LSDB once it is done,
+// and the LSAs sent during it
+func (o ospf) floodView() string {
+	text := lipgloss.NewStyle().Width(max(o.width, 1))
+	round := o.rounds[o.round]
+	title := styles.H3.Render(fmt.Sprintf("Round %d of %d", o.round, len(o.rounds)-1))
+
+	var summary string
+	switch {
+	case o.round == 0 && len(o.origins) == len(o.ls.Routers):
+		summary = "Every router originates an LSA describing its links and networks, and knows only its own."
+	case o.round == 0:
+		summary = strings.Join(o.origins, " and ") + " notice the change and originate new LSAs. Everyone else still holds the old ones."
+	default:
+		fresh := 0
+		for _, d := range round.Deliveries {
+			if d.New {
+				fresh++
+			}
+		}
+		summary = fmt.Sprintf("%d LSAs sent, %d new. Routers pass on only what they just learned, and not back to where it came from.", len(round.Deliveries), fresh)
+	}
+	sections := []string{title + "\n" + text.Render(summary), o.lsdbView()}
+
+	if len(round.Deliveries) > 0 {
+		sections = append(sections, deliveriesView(round.Deliveries))
+	}
+	if o.round == len(o.rounds)-1 {
+		verdict := styles.StatusSuccess.Render(fmt.Sprintf("✅ Converged after %d round(s): every router holds the same LSDB", o.round))
+		for _, known := range round.LSDB {
+			for _, ok := range known {
+				if !ok {
+					verdict = styles.StatusWarning.Copy().Width(max(o.width, 1)).Render("⚠️  Flooding stopped with LSAs missing: the area is partitioned")
+				}
+			}
+		}
+		sections = append(sections, verdict)
+	}
+	return strings.Join(sections, "\n\n")
+}
+
+// lsdbView is a grid of which routers hold whose current LSA, marking those
+// learned this round
+func (o ospf) lsdbView() string {
+	width, label := 0, lipgloss.Width("LSDB")
+	for _, r := range o.ls.Routers {
+		width = max(width, lipgloss.Width(r))
+		label = max(label, lipgloss.Width(r))
+	}
+	lsdb := o.rounds[o.round].LSDB
+	var previous map[string]map[string]bool
+	if o.round > 0 {
+		previous = o.rounds[o.round-1].LSDB
+	}
+
+	header := pad("LSDB", label+2)
+	for _, origin := range o.ls.Routers {
+		header += pad(origin, width+1)
+	}
+	lines := []string{styles.BodyMuted.Render(strings.TrimRight(header, " "))}
+	for _, r := range o.ls.Routers {
+		line := pad(r, label+2)
+		for _, origin := range o.ls.Routers {
+			cell := pad("·", width+1)
+			switch {
+			case lsdb[r][origin] && previous != nil && !previous[r][origin]:
+				cell = matchRowStyle.Render(pad("✓", width+1))
+			case lsdb[r][origin]:
+				cell = pad("✓", width+1)
+			default:
+				cell = styles.BodyDim.Render(cell)
+			}
+			line += cell
+		}
+		lines = append(lines, strings.TrimRight(line, " "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// deliveriesView lists a round's LSAs by sender, dimming the duplicates
+func deliveriesView(deliveries []Delivery) string {
+	var lines []string
+	for i := 0; i < len(deliveries); {
+		d := deliveries[i]
+		var to []string
+		for ; i < len(deliveries) && deliveries[i].From == d.From && deliveries[i].Origin == d.Origin; i++ {
+			if deliveries[i].New {
+				to = append(to, deliveries[i].To)
+			} else {
+				to = append(to, styles.BodyDim.Render(deliveries[i].To+" (dup)"))
+			}
+		}
+		lines = append(lines, fmt.Sprintf("%s sends %s's LSA to %s", nodeStyle.Render(d.From), d.Origin, strings.Join(to, ", ")))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// spfView shows one iteration of Dijkstra's algorithm: the router that
+// joined the tree, the tree so far, and the candidates left
+func (o ospf) spfView() string {
+	text := lipgloss.NewStyle().Width(max(o.width, 1))
+	step := o.steps[o.step]
+	title := styles.H3.Render(fmt.Sprintf("SPF from %s, step %d of %d", o.root(), o.step+1, len(o.steps)))
+	what := fmt.Sprintf("%s is the root, at cost 0. Its neighbours become candidates at the cost of their links.", step.Added.Router)
+	if step.Added.Parent != "" {
+		what = fmt.Sprintf("%s is the cheapest candidate, so it joins the tree at cost %d through %s. Its neighbours are candidates at %d plus their link's cost.",
+			step.Added.Router, step.Added.Cost, step.Added.Parent, step.Added.Cost)
+	}
+	sections := []string{title + "\n" + text.Render(what), styles.BodyMuted.Render("Tree") + "\n" + treeView(step)}
+
+	previous := map[string]SPFNode{}
+	if o.step > 0 {
+		for _, n := range o.steps[o.step-1].Candidates {
+			previous[n.Router] = n
+		}
+	}
+	updated := map[string]bool{}
+	for _, r := range step.Updated {
+		updated[r] = true
+	}
+	width := 0
+	for _, r := range o.ls.Routers {
+		width = max(width, lipgloss.Width(r))
+	}
+	candidates := []string{styles.BodyMuted.Render("Candidates, cheapest first")}
+	for _, n := range step.Candidates {
+		line := fmt.Sprintf("  %s %5d  via %s", pad(n.Router, width), n.Cost, n.Parent)
+		if updated[n.Router] {
+			note := "  ← new"
+			if old, ok := previous[n.Router]; ok {
+				note = fmt.Sprintf("  ← was %d via %s", old.Cost, old.Parent)
+			}
+			line = matchRowStyle.Render(line + note)
+		}
+		candidates = append(candidates, line)
+	}
+	if len(step.Candidates) == 0 {
+		candidates = append(candidates, styles.BodyDim.Render("  none: the tree is complete"))
+	}
+	sections = append(sections, strings.Join(candidates, "\n"))
+
+	if o.step == len(o.steps)-1 && len(step.Tree) < len(o.ls.Routers) {
+		reached := map[string]bool{}
+		for _, n := range step.Tree {
+			reached[n.Router] = true
+		}
+		var unreachable []string
+		for _, r := range o.ls.Routers {
+			if !reached[r] {
+				unreachable = append(unreachable, r)
+			}
+		}
+		sections = append(sections, styles.StatusWarning.Copy().Width(max(o.width, 1)).Render("⚠️  No path to "+strings.Join(unreachable, ", ")))
+	}
+	return strings.Join(sections, "\n\n")
+}
+
+// treeView draws the shortest-path tree so far, marking the router that
+// just joined it
+func treeView(step SPFStep) string {
+	children := map[string][]SPFNode{}
+	for _, n := range step.Tree[1:] {
+		children[n.Parent] = append(children[n.Parent], n)
+	}
+	label := func(n SPFNode) string {
+		name := nodeStyle.Render(n.Router)
+		if n.Router == step.Added.Router {
+			name = matchRowStyle.Copy().Bold(true).Render(n.Router)
+		}
+		return name + styles.BodyMuted.Render(fmt.Sprintf(" %d", n.Cost))
+	}
+
+	lines := []string{label(step.Tree[0])}
+	var walk func(parent, indent string)
+	walk = func(parent, indent string) {
+		for i, n := range children[parent] {
+			branch, next := "├─ ", "│  "
+			if i == len(children[parent])-1 {
+				branch, next = "└─ ", "   "
+			}
+			lines = append(lines, styles.BodyDim.Render(indent+branch)+label(n))
+			walk(n.Router, indent+next)
+		}
+	}
+	walk(step.Tree[0].Router, "")
+	return strings.Join(lines, "\n")
+}
+
+// routesView lists the shown router's routes, marking those the last
+// change moved
+func (o ospf) routesView() string {
+	routes := o.ls.Routes(o.root())
+	var before map[string]OSPFRoute
+	if o.before != nil {
+		before = map[string]OSPFRoute{}
+		for _, r := range o.before[o.root()] {
+			before[r.Prefix.String()] = r
+		}
+	}
+
+	type row struct {
+		cells   [4]string
+		changed bool
+	}
+	var rows []row
+	seen := map[string]bool{}
+	for _, r := range routes {
+		prefix := r.Prefix.String()
+		seen[prefix] = true
+		rw := row{cells: [4]string{prefix, fmt.Sprint(r.Cost), orConnected(r.NextHop), ""}}
+		if before != nil {
+			old, ok := before[prefix]
+			switch {
+			case !ok:
+				rw.cells[3], rw.changed = "new", true
+			case old != r:
+				rw.cells[3], rw.changed = fmt.Sprintf("was %s, %d", orConnected(old.NextHop), old.Cost), true
+			}
+		}
+		rows = append(rows, rw)
+	}
+	for _, r := range o.before[o.root()] {
+		if !seen[r.Prefix.String()] {
+			rows = append(rows, row{cells: [4]string{r.Prefix.String(), "—", "unreachable", "removed"}, changed: true})
+		}
+	}
+
+	widths := [4]int{}
+	titles := [4]string{"Prefix", "Cost", "Next hop", ""}
+	for c := range titles {
+		widths[c] = lipgloss.Width(titles[c]) + 2
+		for _, rw := range rows {
+			widths[c] = max(widths[c], lipgloss.Width(rw.cells[c])+2)
+		}
+	}
+	render := func(cells [4]string) string {
+		var b strings.Builder
+		for c := range cells {
+			b.WriteString(pad(cells[c], widths[c]))
+		}
+		return strings.TrimRight(b.String(), " ")
+	}
+
+	lines := []string{styles.H3.Render("Routes on " + o.root()), styles.BodyMuted.Render(render(titles))}
+	for _, rw := range rows {
+		line := render(rw.cells)
+		if rw.changed {
+			line = matchRowStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+	if len(routes) == 0 {
+		lines = append(lines, styles.BodyDim.Render("No networks are advertised."))
+	}
+	note := "Fail a link or change its cost on the Area view, and the routes it moves are highlighted here."
+	if o.before != nil {
+		note = "Highlighted routes changed with the last change to the area."
+	}
+	return strings.Join(lines, "\n") + "\n\n" + styles.BodyDim.Copy().Width(max(o.width, 1)).Render(note)
+}
+
+// allRoutes computes every router's routes
+func allRoutes(ls LinkState) map[string][]OSPFRoute {
+	tables := make(map[string][]OSPFRoute, len(ls.Routers))
+	for _, r := range ls.Routers {
+		tables[r] = ls.Routes(r)
+	}
+	return tables
+}
+
+// sameRoutes reports whether two routing tables are the same
+func sameRoutes(a, b []OSPFRoute) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// orConnected names a next hop, where none means a connected network
+func orConnected(next string) string {
+	if next == "" {
+		return "connected"
+	}
+	return next
+}
+
+// wrapItems joins items into lines no wider than width, never breaking one
+func wrapItems(items []string, sep string, width int) []string {
+	var lines []string
+	line := ""
+	for _, item := range items {
+		switch {
+		case line == "":
+			line = item
+		case lipgloss.Width(line+sep+item) > width:
+			lines = append(lines, line)
+			line = item
+		default:
+			line += sep + item
+		}
+	}
+	return append(lines, line)
+}
+
+// pad left-aligns text in a cell of the given width
+func pad(text string, width int) string {
+	return text + strings.Repeat(" ", max(width-lipgloss.Width(text), 0))
+}
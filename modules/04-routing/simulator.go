@@ -0,0 +1,267 @@
+package routing
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"netlab/pkg/styles"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// defaultDestination is what the simulator starts with: a pod address that
+// three routes match
+const defaultDestination = "10.244.1.17"
+
+var (
+	bestRowStyle  = lipgloss.NewStyle().Foreground(styles.Primary).Bold(true)
+	matchRowStyle = lipgloss.NewStyle().Foreground(styles.Accent)
+	binaryStyle   = lipgloss.NewStyle().Foreground(styles.Primary)
+)
+
+// column is one column of the routes table, with its cells by row
+type column struct {
+	title    string
+	cells    []string
+	optional bool // Dropped when the table is too wide
+}
+
+// simulator is the longest prefix match simulator: a destination input, the
+// routing table marked with the routes that match it, and the steps that
+// pick the winner
+type simulator struct {
+	input  textinput.Model
+	table  Table
+	source string // Where the table came from
+	lookup Lookup
+	err    error
+	body   viewport.Model
+	width  int
+}
+
+func newSimulator(table Table, source string) simulator {
+	input := textinput.New()
+	input.Prompt = "Destination › "
+	input.PromptStyle = styles.KeyBinding
+	input.Placeholder = defaultDestination
+	input.CharLimit = 64
+	input.SetValue(defaultDestination)
+	input.Focus()
+
+	s := simulator{input: input, table: table, source: source, body: viewport.New(0, 0)}
+	s.route()
+	return s
+}
+
+// SetSize fits the simulator into width x height cells
+func (s *simulator) SetSize(width, height int) {
+	s.width = width
+	s.input.Width = width - lipgloss.Width(s.input.Prompt) - 1
+	s.input.SetCursor(s.input.Position()) // Rescroll for the new width
+	s.body.Width = width
+	s.body.Height = height - 2 // Input line and the gap below it
+	s.body.SetContent(s.bodyView())
+}
+
+// SetTable replaces the routing table and looks the destination up again
+func (s *simulator) SetTable(table Table, source string) {
+	s.table, s.source = table, source
+	s.route()
+	s.body.GotoTop()
+}
+
+// route looks up the current destination
+func (s *simulator) route() {
+	s.err = nil
+	s.lookup = Lookup{Best: -1}
+	if value := strings.TrimSpace(s.input.Value()); value != "" {
+		dst, err := netip.ParseAddr(value)
+		if err != nil {
+			s.err = fmt.Errorf("%q is not a valid IP address", value)
+		} else {
+			s.lookup = s.table.Lookup(dst)
+		}
+	}
+	s.body.SetContent(s.bodyView())
+}
+
+func (s simulator) Update(msg tea.Msg) (simulator, tea.Cmd) {
+	var cmd tea.Cmd
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "up", "down", "pgup", "pgdown":
+			s.body, cmd = s.body.Update(msg)
+			return s, cmd
+		}
+		s.input, cmd = s.input.Update(msg)
+		s.route()
+		return s, cmd
+
+	case tea.MouseMsg:
+		s.body, cmd = s.body.Update(msg)
+		return s, cmd
+	}
+
+	s.input, cmd = s.input.Update(msg)
+	return s, cmd
+}
+
+func (s simulator) View() string {
+	return s.input.View() + "\n\n" + s.body.View()
+}
+
+// bodyView renders the routes table and the lookup's steps
+func (s simulator) bodyView() string {
+	sections := []string{
+		styles.BodyMuted.Render(fmt.Sprintf("%d routes from the %s", len(s.table), s.source)),
+		s.tableView(),
+	}
+	switch {
+	case s.err != nil:
+		sections = append(sections, styles.StatusError.Render("⚠️  "+s.err.Error()))
+	case s.lookup.Destination.IsValid():
+		sections = append(sections, s.resultView(), s.stepsView())
+	default:
+		sections = append(sections, styles.BodyMuted.Render("Type a destination IP address to route it."))
+	}
+	return strings.Join(sections, "\n\n")
+}
+
+// tableView renders the routes, marking those that match the destination
+func (s simulator) tableView() string {
+	columns := []column{
+		{title: "Destination"},
+		{title: "Gateway", optional: true},
+		{title: "Interface"},
+		{title: "Metric"},
+		{title: "Kind", optional: true},
+		{title: "Match"},
+	}
+	for i, r := range s.table {
+		gateway := "—"
+		if r.Gateway.IsValid() {
+			gateway = r.Gateway.String()
+		}
+		iface := r.Interface
+		if iface == "" {
+			iface = "—"
+		}
+		row := []string{r.Destination(), gateway, iface, fmt.Sprint(r.Metric), r.Kind(), s.matchCell(i)}
+		for c := range columns {
+			columns[c].cells = append(columns[c].cells, row[c])
+		}
+	}
+
+	rows := renderColumns(columns, s.width)
+	lines := []string{styles.BodyMuted.Render(rows[0])}
+	for i := range s.table {
+		line := rows[i+1]
+		switch {
+		case i == s.lookup.Best || s.isTied(i):
+			line = bestRowStyle.Render(line)
+		case s.lookup.Destination.IsValid() && s.lookup.Candidates[i].Matched:
+			line = matchRowStyle.Render(line)
+		case s.lookup.Destination.IsValid():
+			line = styles.BodyDim.Render(line)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderColumns lays columns out as aligned rows, titles first, dropping
+// the optional columns, last first, until the rows fit the width
+func renderColumns(columns []column, width int) []string {
+	widths := make([]int, len(columns))
+	total := 0
+	for c, col := range columns {
+		widths[c] = lipgloss.Width(col.title)
+		for _, cell := range col.cells {
+			widths[c] = max(widths[c], lipgloss.Width(cell))
+		}
+		widths[c] += 2
+		total += widths[c]
+	}
+	for c := len(columns) - 1; c >= 0 && total > width; c-- {
+		if columns[c].optional {
+			total -= widths[c]
+			widths[c] = 0
+		}
+	}
+
+	render := func(cell func(c int) string) string {
+		var b strings.Builder
+		for c := range columns {
+			if widths[c] > 0 {
+				b.WriteString(lipgloss.NewStyle().Width(widths[c]).Render(cell(c)))
+			}
+		}
+		return strings.TrimRight(b.String(), " ")
+	}
+	rows := []string{render(func(c int) string { return columns[c].title })}
+	for i := range columns[0].cells {
+		rows = append(rows, render(func(c int) string { return columns[c].cells[i] }))
+	}
+	return rows
+}
+
+// matchCell says whether and how well route i matches the destination
+func (s simulator) matchCell(i int) string {
+	if !s.lookup.Destination.IsValid() {
+		return ""
+	}
+	c := s.lookup.Candidates[i]
+	bits := c.Route.Prefix.Bits()
+	switch {
+	case c.Route.Prefix.Addr().Is4() != s.lookup.Destination.Is4():
+		return "✗ other family"
+	case !c.Matched:
+		return fmt.Sprintf("✗ %d of %d bits", c.Common, bits)
+	case i == s.lookup.Best:
+		return fmt.Sprintf("✓ /%d best", bits)
+	case s.isTied(i):
+		return fmt.Sprintf("✓ /%d equal", bits)
+	}
+	return fmt.Sprintf("✓ /%d", bits)
+}
+
+// isTied reports whether route i is an equal-cost path to the best
+func (s simulator) isTied(i int) bool {
+	for _, t := range s.lookup.Tied {
+		if t == i {
+			return true
+		}
+	}
+	return false
+}
+
+// resultView sums up where the packet goes
+func (s simulator) resultView() string {
+	r, ok := s.lookup.Route()
+	if !ok {
+		return styles.StatusError.Render(fmt.Sprintf("❌ %s: no route to host", s.lookup.Destination))
+	}
+	if r.Type != typeUnicast {
+		return styles.StatusError.Render(fmt.Sprintf("❌ %s: dropped by %s %s", s.lookup.Destination, r.Type, r.Destination()))
+	}
+	return styles.StatusSuccess.Render(fmt.Sprintf("✅ %s → %s", s.lookup.Destination, r.Next())) +
+		styles.BodyMuted.Render(fmt.Sprintf("  (%s)", r.Destination()))
+}
+
+// stepsView numbers the lookup's steps
+func (s simulator) stepsView() string {
+	lines := []string{styles.H3.Render("Steps")}
+	text := lipgloss.NewStyle().Width(max(s.width-3, 1))
+	for i, step := range s.lookup.Steps {
+		lines = append(lines, lipgloss.JoinHorizontal(lipgloss.Top, fmt.Sprintf("%d. ", i+1), text.Render(step.Text)))
+		for _, line := range step.Lines {
+			lines = append(lines, "   "+binaryStyle.Render(line))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,233 @@
+package routing
+
+import (
+	"fmt"
+	"math/bits"
+	"net/netip"
+	"strings"
+)
+
+// Route types, as ip route names them. Anything but unicast drops the packet.
+const (
+	typeUnicast     = "unicast"
+	typeBlackhole   = "blackhole"
+	typeUnreachable = "unreachable"
+	typeProhibit    = "prohibit"
+)
+
+// Route is one entry in a routing table
+type Route struct {
+	Prefix    netip.Prefix
+	Gateway   netip.Addr // Unset for connected routes
+	Interface string
+	Metric    int
+	Type      string
+	Protocol  string // How the route was learned, such as kernel, dhcp or static
+}
+
+// Kind describes the route the way the lesson does: connected, static or
+// default, or the type of route that drops packets
+func (r Route) Kind() string {
+	switch {
+	case r.Type != typeUnicast:
+		return r.Type
+	case r.Prefix.Bits() == 0:
+		return "default"
+	case !r.Gateway.IsValid():
+		return "connected"
+	}
+	return "static"
+}
+
+// Destination is the route's prefix, or "default" for a default route
+func (r Route) Destination() string {
+	if r.Prefix.Bits() == 0 {
+		return "default"
+	}
+	return r.Prefix.String()
+}
+
+// Next describes where the route sends a packet
+func (r Route) Next() string {
+	switch {
+	case r.Type != typeUnicast:
+		return r.Type
+	case r.Gateway.IsValid():
+		return fmt.Sprintf("via %s dev %s", r.Gateway, r.Interface)
+	}
+	return "dev " + r.Interface + " (directly)"
+}
+
+// Table is a routing table, in the order the routes were listed
+type Table []Route
+
+// Candidate is a route as considered for one destination
+type Candidate struct {
+	Route   Route
+	Matched bool
+	Common  int // Leading bits the destination shares with the route's prefix
+}
+
+// Step is one step of a lookup, with optional preformatted lines such as
+// addresses in binary
+type Step struct {
+	Text  string
+	Lines []string
+}
+
+// Lookup is the result of looking up a destination, with every route
+// considered and the steps that picked the winner
+type Lookup struct {
+	Destination netip.Addr
+	Candidates  []Candidate // In table order
+	Best        int         // Index of the winning candidate, or -1 when none matched
+	Tied        []int       // Other candidates as good as the best, for ECMP
+	Steps       []Step
+}
+
+// Route returns the winning route
+func (l Lookup) Route() (Route, bool) {
+	if l.Best < 0 {
+		return Route{}, false
+	}
+	return l.Candidates[l.Best].Route, true
+}
+
+// Lookup finds the route for a destination: the longest matching prefix,
+// then the lowest metric. Routes that tie on both are equal-cost paths.
+func (t Table) Lookup(dst netip.Addr) Lookup {
+	dst = dst.Unmap()
+	l := Lookup{Destination: dst, Best: -1}
+
+	var matched []int
+	for i, r := range t {
+		c := Candidate{Route: r}
+		if r.Prefix.Addr().Is4() == dst.Is4() {
+			c.Common = commonBits(dst, r.Prefix.Addr())
+			c.Matched = r.Prefix.Contains(dst)
+		}
+		l.Candidates = append(l.Candidates, c)
+		if c.Matched {
+			matched = append(matched, i)
+		}
+	}
+
+	family := "IPv4"
+	if dst.Is6() {
+		family = "IPv6"
+	}
+	first := Step{Text: fmt.Sprintf("Look up %s in the %s routes.", dst, family)}
+	if dst.Is4() {
+		first.Lines = []string{binaryLine(dst.String(), dst)}
+	}
+	l.Steps = append(l.Steps, first)
+
+	if len(matched) == 0 {
+		l.Steps = append(l.Steps, Step{Text: "No route matches, not even a default route. The packet is dropped and the sender gets an ICMP network unreachable error."})
+		return l
+	}
+
+	names := make([]string, len(matched))
+	for i, idx := range matched {
+		names[i] = t[idx].Destination()
+	}
+	l.Steps = append(l.Steps, Step{Text: fmt.Sprintf("%d of %d routes contain it: %s.", len(matched), len(t), strings.Join(names, ", "))})
+
+	// Longest prefix first, then lowest metric
+	best := matched[0]
+	for _, idx := range matched[1:] {
+		r, b := t[idx], t[best]
+		if r.Prefix.Bits() > b.Prefix.Bits() || (r.Prefix.Bits() == b.Prefix.Bits() && r.Metric < b.Metric) {
+			best = idx
+		}
+	}
+	l.Best = best
+	winner := t[best]
+
+	longest := Step{Text: fmt.Sprintf("The longest prefix is /%d, the most specific route.", winner.Prefix.Bits())}
+	if dst.Is4() {
+		longest.Lines = []string{
+			binaryLine(dst.String(), dst),
+			binaryLine(winner.Prefix.Addr().String(), winner.Prefix.Addr()),
+			binaryMarker(winner.Prefix.Bits()),
+		}
+	}
+	if len(matched) == 1 {
+		longest.Text = fmt.Sprintf("Only %s matches, so it wins.", winner.Destination())
+	}
+	l.Steps = append(l.Steps, longest)
+
+	var sameLength []int
+	for _, idx := range matched {
+		if idx != best && t[idx].Prefix.Bits() == winner.Prefix.Bits() {
+			sameLength = append(sameLength, idx)
+			if t[idx].Metric == winner.Metric {
+				l.Tied = append(l.Tied, idx)
+			}
+		}
+	}
+	switch {
+	case len(l.Tied) > 0:
+		l.Steps = append(l.Steps, Step{Text: fmt.Sprintf("%d routes share that prefix and metric %d. Linux spreads flows across equal-cost paths like these (ECMP); the first is shown.",
+			len(l.Tied)+1, winner.Metric)})
+	case len(sameLength) > 0:
+		l.Steps = append(l.Steps, Step{Text: fmt.Sprintf("%d routes share that prefix, so the lowest metric, %d, breaks the tie.",
+			len(sameLength)+1, winner.Metric)})
+	}
+
+	var verdict string
+	switch winner.Kind() {
+	case "connected":
+		verdict = fmt.Sprintf("%s is a connected route: the destination is on the %s link, so the packet goes to it directly, using ARP or neighbour discovery to find its MAC address.", winner.Destination(), winner.Interface)
+	case typeBlackhole:
+		verdict = fmt.Sprintf("%s is a blackhole route: the packet is dropped silently.", winner.Destination())
+	case typeUnreachable:
+		verdict = fmt.Sprintf("%s is an unreachable route: the packet is dropped and the sender gets ICMP host unreachable.", winner.Destination())
+	case typeProhibit:
+		verdict = fmt.Sprintf("%s is a prohibit route: the packet is dropped and the sender gets ICMP administratively prohibited.", winner.Destination())
+	default:
+		verdict = fmt.Sprintf("Forward the packet to the gateway %s out of %s.", winner.Gateway, winner.Interface)
+		if !winner.Gateway.IsValid() {
+			// Only default routes get here without a gateway, through
+			// point-to-point devices such as ppp0 or wg0 that have one peer
+			verdict = fmt.Sprintf("It has no gateway, so the packet goes out of %s directly, as on a connected link.", winner.Interface)
+		}
+		if winner.Kind() == "default" {
+			verdict = "Nothing more specific matches, so the default route applies. " + verdict
+		}
+	}
+	l.Steps = append(l.Steps, Step{Text: verdict})
+	return l
+}
+
+// commonBits counts the leading bits two addresses of the same family share
+func commonBits(a, b netip.Addr) int {
+	x, y := a.AsSlice(), b.AsSlice()
+	n := 0
+	for i := range x {
+		if d := x[i] ^ y[i]; d != 0 {
+			return n + bits.LeadingZeros8(d)
+		}
+		n += 8
+	}
+	return n
+}
+
+// binaryLine writes an IPv4 address in dotted binary after a label
+func binaryLine(label string, addr netip.Addr) string {
+	b := addr.As4()
+	return fmt.Sprintf("%-15s %08b.%08b.%08b.%08b", label, b[0], b[1], b[2], b[3])
+}
+
+// binaryMarker underlines the first n bits of a binaryLine
+func binaryMarker(n int) string {
+	var b strings.Builder
+	b.WriteString(strings.Repeat(" ", 16))
+	for i := 0; i < n; i++ {
+		if i > 0 && i%8 == 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteByte('^')
+	}
+	return b.String()
+}
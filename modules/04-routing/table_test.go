@@ -0,0 +1,150 @@
+package routing
+
+import (
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func sampleTable(t *testing.T) Table {
+	t.Helper()
+	data, err := embedded.ReadFile("content/routes.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	table, err := ParseIPRoute(string(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return table
+}
+
+func TestLookup(t *testing.T) {
+	table := sampleTable(t)
+	tests := []struct {
+		dst     string
+		want    string // Winning route's destination and next hop
+		matched int
+	}{
+		{"10.244.1.17", "10.244.1.0/24 via 172.18.0.3 dev br-kind", 5},
+		{"10.244.2.5", "10.244.0.0/16 via 172.18.0.2 dev br-kind", 4},
+		{"10.8.0.9", "10.8.0.0/24 dev wg0 (directly)", 4},
+		{"10.99.1.1", "10.99.0.0/16 blackhole", 4},
+		{"10.244.9.1", "10.244.9.0/24 unreachable", 5},
+		{"192.168.1.40", "192.168.1.0/24 dev eth0 (directly)", 4},
+		{"1.1.1.1", "default via 192.168.1.1 dev eth0", 2},
+	}
+	for _, tt := range tests {
+		l := table.Lookup(netip.MustParseAddr(tt.dst))
+		r, ok := l.Route()
+		if !ok {
+			t.Errorf("%s: no route", tt.dst)
+			continue
+		}
+		if got := r.Destination() + " " + r.Next(); got != tt.want {
+			t.Errorf("%s routed by %s, want %s", tt.dst, got, tt.want)
+		}
+		matched := 0
+		for _, c := range l.Candidates {
+			if c.Matched {
+				matched++
+			}
+		}
+		if matched != tt.matched {
+			t.Errorf("%s matched %d routes, want %d", tt.dst, matched, tt.matched)
+		}
+	}
+}
+
+func TestLookupSteps(t *testing.T) {
+	table := sampleTable(t)
+
+	// Two connected routes to the same subnet; the metric picks eth0
+	l := table.Lookup(netip.MustParseAddr("192.168.1.40"))
+	var texts []string
+	for _, s := range l.Steps {
+		texts = append(texts, s.Text)
+	}
+	if !strings.Contains(strings.Join(texts, "\n"), "2 routes share that prefix, so the lowest metric, 100, breaks the tie.") {
+		t.Errorf("metric tie-break not explained:\n%s", strings.Join(texts, "\n"))
+	}
+
+	// No default route for IPv6, so nothing matches
+	l = table.Lookup(netip.MustParseAddr("2001:db8::1"))
+	if _, ok := l.Route(); ok {
+		t.Error("IPv6 destination matched an IPv4 route")
+	}
+	if last := l.Steps[len(l.Steps)-1].Text; !strings.Contains(last, "No route matches") {
+		t.Errorf("last step = %q", last)
+	}
+}
+
+func TestLookupEqualCost(t *testing.T) {
+	table, err := ParseIPRoute(`default proto static metric 10
+	nexthop via 10.0.0.1 dev eth0 weight 1
+	nexthop via 10.0.1.1 dev eth1 weight 1
+10.0.0.0/24 dev eth0 proto kernel scope link src 10.0.0.2
+10.0.1.0/24 dev eth1 proto kernel scope link src 10.0.1.2
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := table.Lookup(netip.MustParseAddr("8.8.8.8"))
+	if l.Best != 0 || !reflect.DeepEqual(l.Tied, []int{1}) {
+		t.Errorf("best %d, tied %v; want 0 and [1]", l.Best, l.Tied)
+	}
+}
+
+func TestLookupVerdict(t *testing.T) {
+	tests := []struct {
+		name   string
+		routes string
+		dst    string
+		want   string
+	}{
+		{"default via gateway", "default via 192.168.1.1 dev eth0\n", "1.1.1.1",
+			"Nothing more specific matches, so the default route applies. Forward the packet to the gateway 192.168.1.1 out of eth0."},
+		{"default without gateway", "default dev ppp0 scope link\n", "1.1.1.1",
+			"Nothing more specific matches, so the default route applies. It has no gateway, so the packet goes out of ppp0 directly, as on a connected link."},
+		{"IPv6 default without gateway", "default dev wg0 metric 1024\nfe80::/64 dev eth0 metric 256\n", "2001:db8::1",
+			"Nothing more specific matches, so the default route applies. It has no gateway, so the packet goes out of wg0 directly, as on a connected link."},
+		{"static", "10.0.0.0/8 via 192.168.1.254 dev eth0\n", "10.1.2.3",
+			"Forward the packet to the gateway 192.168.1.254 out of eth0."},
+		{"connected", "192.168.1.0/24 dev eth0 scope link\n", "192.168.1.9",
+			"192.168.1.0/24 is a connected route: the destination is on the eth0 link, so the packet goes to it directly, using ARP or neighbour discovery to find its MAC address."},
+		{"blackhole", "blackhole 10.99.0.0/16\n", "10.99.1.1",
+			"10.99.0.0/16 is a blackhole route: the packet is dropped silently."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table, err := ParseIPRoute(tt.routes)
+			if err != nil {
+				t.Fatal(err)
+			}
+			l := table.Lookup(netip.MustParseAddr(tt.dst))
+			if got := l.Steps[len(l.Steps)-1].Text; got != tt.want {
+				t.Errorf("verdict = %q\nwant %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommonBits(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"10.0.0.0", "10.0.0.0", 32},
+		{"10.244.1.17", "10.244.0.0", 23},
+		{"10.0.0.1", "172.18.0.0", 0},
+		{"192.168.1.1", "192.168.129.1", 16},
+		{"fd00::1", "fd00::", 127},
+	}
+	for _, tt := range tests {
+		if got := commonBits(netip.MustParseAddr(tt.a), netip.MustParseAddr(tt.b)); got != tt.want {
+			t.Errorf("commonBits(%s, %s) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,265 @@
+package routing
+
+import (
+	"bytes"
+	"fmt"
+	"net/netip"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Interface is a router's interface and its address on the attached subnet
+type Interface struct {
+	Name    string
+	Address netip.Prefix // The interface's address, with the subnet's prefix length
+}
+
+// Router is a node in a topology: a router or a host, with its interfaces
+// and routing table. The table includes the connected routes for its
+// interfaces.
+type Router struct {
+	Name       string
+	Interfaces []Interface
+	Table      Table
+}
+
+// Endpoint names one router's interface, written router:interface
+type Endpoint struct {
+	Router    string
+	Interface string
+}
+
+func (e Endpoint) String() string {
+	return e.Router + ":" + e.Interface
+}
+
+// Topology is a set of routers and the links between their interfaces. A
+// link with more than two endpoints is a shared segment, such as a LAN.
+type Topology struct {
+	Routers []Router
+	Links   [][]Endpoint
+}
+
+// topologyFile is the YAML form of a topology, with each router's routes
+// written as ip route show prints them
+type topologyFile struct {
+	Routers []struct {
+		Name       string `yaml:"name"`
+		Interfaces []struct {
+			Name    string `yaml:"name"`
+			Address string `yaml:"address"`
+		} `yaml:"interfaces"`
+		Routes string `yaml:"routes"`
+	} `yaml:"routers"`
+	Links [][]string `yaml:"links"`
+}
+
+// SampleTopology is the topology the module ships with: a home network with
+// two ISPs in front of a web server
+func SampleTopology() (Topology, error) {
+	data, err := embedded.ReadFile("content/topology.yaml")
+	if err != nil {
+		return Topology{}, err
+	}
+	topology, err := ParseTopology(data)
+	if err != nil {
+		return Topology{}, fmt.Errorf("content/topology.yaml: %w", err)
+	}
+	return topology, nil
+}
+
+// ReadTopology reads a topology from a YAML file
+func ReadTopology(path string) (Topology, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Topology{}, fmt.Errorf("failed to read topology: %w", err)
+	}
+	topology, err := ParseTopology(data)
+	if err != nil {
+		return Topology{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return topology, nil
+}
+
+// ParseTopology parses a topology, adding each router's connected routes
+// and filling in the interface of routes given only a gateway
+func ParseTopology(data []byte) (Topology, error) {
+	var file topologyFile
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&file); err != nil {
+		return Topology{}, fmt.Errorf("invalid YAML: %w", err)
+	}
+	if len(file.Routers) == 0 {
+		return Topology{}, fmt.Errorf("no routers defined")
+	}
+
+	var t Topology
+	for _, fr := range file.Routers {
+		if fr.Name == "" {
+			return Topology{}, fmt.Errorf("router %d has no name", len(t.Routers)+1)
+		}
+		if _, ok := t.Router(fr.Name); ok {
+			return Topology{}, fmt.Errorf("router %s is defined twice", fr.Name)
+		}
+
+		r := Router{Name: fr.Name}
+		for _, fi := range fr.Interfaces {
+			if _, ok := r.Interface(fi.Name); ok || fi.Name == "" {
+				return Topology{}, fmt.Errorf("router %s: interface names must be given and unique", r.Name)
+			}
+			addr, err := netip.ParsePrefix(fi.Address)
+			if err != nil {
+				return Topology{}, fmt.Errorf("router %s: interface %s: %q should be an address with a prefix length, such as 10.0.0.1/24", r.Name, fi.Name, fi.Address)
+			}
+			r.Interfaces = append(r.Interfaces, Interface{Name: fi.Name, Address: addr})
+			r.Table = append(r.Table, Route{Prefix: addr.Masked(), Interface: fi.Name, Type: typeUnicast, Protocol: "kernel"})
+		}
+
+		if strings.TrimSpace(fr.Routes) != "" {
+			routes, err := ParseIPRoute(fr.Routes)
+			if err != nil {
+				return Topology{}, fmt.Errorf("router %s: %w", r.Name, err)
+			}
+			for _, route := range routes {
+				if err := r.attach(&route); err != nil {
+					return Topology{}, fmt.Errorf("router %s: %w", r.Name, err)
+				}
+				r.Table = append(r.Table, route)
+			}
+		}
+		t.Routers = append(t.Routers, r)
+	}
+
+	linked := map[Endpoint]bool{}
+	for i, names := range file.Links {
+		if len(names) < 2 {
+			return Topology{}, fmt.Errorf("link %d: a link joins at least two interfaces", i+1)
+		}
+		var link []Endpoint
+		for _, name := range names {
+			routerName, ifaceName, ok := strings.Cut(name, ":")
+			if !ok {
+				return Topology{}, fmt.Errorf("link %d: %q should be router:interface", i+1, name)
+			}
+			e := Endpoint{Router: routerName, Interface: ifaceName}
+			r, ok := t.Router(e.Router)
+			if !ok {
+				return Topology{}, fmt.Errorf("link %d: no router named %s", i+1, e.Router)
+			}
+			if _, ok := r.Interface(e.Interface); !ok {
+				return Topology{}, fmt.Errorf("link %d: router %s has no interface %s", i+1, e.Router, e.Interface)
+			}
+			if linked[e] {
+				return Topology{}, fmt.Errorf("link %d: %s is already linked", i+1, e)
+			}
+			linked[e] = true
+			link = append(link, e)
+		}
+		t.Links = append(t.Links, link)
+	}
+	return t, nil
+}
+
+// attach fills in the interface of a route given only a gateway, and checks
+// that the interface a route names exists
+func (r Router) attach(route *Route) error {
+	if route.Interface != "" {
+		if _, ok := r.Interface(route.Interface); !ok {
+			return fmt.Errorf("route %s uses %s, which is not one of its interfaces", route.Destination(), route.Interface)
+		}
+		return nil
+	}
+	if !route.Gateway.IsValid() {
+		return nil
+	}
+	for _, iface := range r.Interfaces {
+		if iface.Address.Masked().Contains(route.Gateway) {
+			route.Interface = iface.Name
+			return nil
+		}
+	}
+	return fmt.Errorf("route %s: gateway %s is not on any of its subnets", route.Destination(), route.Gateway)
+}
+
+// Router finds a router by name
+func (t Topology) Router(name string) (Router, bool) {
+	for _, r := range t.Routers {
+		if r.Name == name {
+			return r, true
+		}
+	}
+	return Router{}, false
+}
+
+// Interface finds one of the router's interfaces by name
+func (r Router) Interface(name string) (Interface, bool) {
+	for _, iface := range r.Interfaces {
+		if iface.Name == name {
+			return iface, true
+		}
+	}
+	return Interface{}, false
+}
+
+// Owns reports whether one of the router's interfaces has the address
+func (r Router) Owns(addr netip.Addr) bool {
+	for _, iface := range r.Interfaces {
+		if iface.Address.Addr() == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// Names lists the routers' names
+func (t Topology) Names() []string {
+	names := make([]string, len(t.Routers))
+	for i, r := range t.Routers {
+		names[i] = r.Name
+	}
+	return names
+}
+
+// Resolve turns a destination into an address. Besides addresses, it takes
+// a router's name, meaning its first interface's address.
+func (t Topology) Resolve(dest string) (netip.Addr, error) {
+	if addr, err := netip.ParseAddr(dest); err == nil {
+		return addr.Unmap(), nil
+	}
+	r, ok := t.Router(dest)
+	if !ok {
+		return netip.Addr{}, fmt.Errorf("%q is neither an IP address nor a router", dest)
+	}
+	if len(r.Interfaces) == 0 {
+		return netip.Addr{}, fmt.Errorf("router %s has no interfaces to send to", dest)
+	}
+	return r.Interfaces[0].Address.Addr(), nil
+}
+
+// neighbour finds who owns an address on the link an interface is
+// attached to. It returns false for linked when the interface is attached
+// to nothing.
+func (t Topology) neighbour(from Endpoint, addr netip.Addr) (next Endpoint, found, linked bool) {
+	for _, link := range t.Links {
+		attached := false
+		for _, e := range link {
+			if e == from {
+				attached = true
+			}
+		}
+		if !attached {
+			continue
+		}
+		for _, e := range link {
+			r, _ := t.Router(e.Router)
+			if iface, _ := r.Interface(e.Interface); e != from && iface.Address.Addr() == addr {
+				return e, true, true
+			}
+		}
+		return Endpoint{}, false, true
+	}
+	return Endpoint{}, false, false
+}
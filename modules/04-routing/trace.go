@@ -0,0 +1,181 @@
+package routing
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// DefaultTTL is the TTL Linux sends packets with
+const DefaultTTL = 64
+
+// Trace outcomes
+const (
+	OutcomeDelivered   = "delivered"
+	OutcomeNoRoute     = "no route"
+	OutcomeDropped     = "dropped"
+	OutcomeTTLExceeded = "TTL exceeded"
+	OutcomeLoop        = "loop"
+	OutcomeUnreachable = "host unreachable"
+)
+
+// Hop is one router a packet passes through
+type Hop struct {
+	Router  string
+	In      string // Interface the packet arrived on, empty at the source
+	TTL     int    // TTL on arrival, or as sent at the source
+	Route   Route  // Route used to forward it, unset where it stopped
+	Out     string
+	NextHop netip.Addr
+}
+
+// Trace is a packet's path through a topology, hop by hop
+type Trace struct {
+	From        string
+	Source      netip.Addr // Address of the interface the packet left by
+	Destination netip.Addr
+	Hops        []Hop
+	Outcome     string
+	Reason      string
+	LoopStart   int // For a loop, the index of the first hop the packet returns to
+}
+
+// Path lists the routers a trace passed through
+func (tr Trace) Path() []string {
+	path := make([]string, len(tr.Hops))
+	for i, h := range tr.Hops {
+		path[i] = h.Router
+	}
+	return path
+}
+
+// RoundTrip is a packet's path to its destination and the reply's path back
+type RoundTrip struct {
+	Forward    Trace
+	Return     *Trace // Unset unless the packet was delivered
+	Asymmetric bool   // Whether a delivered reply came back by another path
+}
+
+// Trace sends a packet from a router to an address, with the given starting
+// TTL, and follows it until it is delivered or dropped
+func (t Topology) Trace(from string, dst netip.Addr, ttl int) (Trace, error) {
+	r, ok := t.Router(from)
+	if !ok {
+		return Trace{}, fmt.Errorf("no router named %s", from)
+	}
+	if ttl < 1 || ttl > 255 {
+		return Trace{}, fmt.Errorf("TTL must be between 1 and 255")
+	}
+
+	tr := Trace{From: from, Destination: dst, LoopStart: -1}
+	visited := map[string]int{}
+	hop := Hop{Router: r.Name, TTL: ttl}
+	for {
+		if r.Owns(dst) {
+			tr.Hops = append(tr.Hops, hop)
+			tr.Outcome = OutcomeDelivered
+			tr.Reason = fmt.Sprintf("%s owns %s, so it accepts the packet.", r.Name, dst)
+			return tr, nil
+		}
+		if first, seen := visited[r.Name]; seen {
+			tr.Reason = fmt.Sprintf("The packet is back at %s, so it circles %s until its TTL runs out and a router drops it with ICMP time exceeded.",
+				r.Name, strings.Join(append(tr.Path()[first:], r.Name), " → "))
+			tr.Hops = append(tr.Hops, hop)
+			tr.Outcome = OutcomeLoop
+			tr.LoopStart = first
+			return tr, nil
+		}
+		visited[r.Name] = len(tr.Hops)
+
+		// Every router but the sender decrements the TTL before forwarding
+		if hop.In != "" {
+			if hop.TTL <= 1 {
+				tr.Hops = append(tr.Hops, hop)
+				tr.Outcome = OutcomeTTLExceeded
+				tr.Reason = fmt.Sprintf("The TTL reaches 0 at %s, which drops the packet and sends ICMP time exceeded back to the source. Traceroute relies on this.", r.Name)
+				return tr, nil
+			}
+		}
+
+		lookup := r.Table.Lookup(dst)
+		route, ok := lookup.Route()
+		if !ok {
+			tr.Hops = append(tr.Hops, hop)
+			tr.Outcome = OutcomeNoRoute
+			tr.Reason = fmt.Sprintf("%s has no route to %s, so it drops the packet with ICMP network unreachable.", r.Name, dst)
+			return tr, nil
+		}
+		hop.Route = route
+		if route.Type != typeUnicast {
+			tr.Hops = append(tr.Hops, hop)
+			tr.Outcome = OutcomeDropped
+			tr.Reason = fmt.Sprintf("%s matches %s %s and drops the packet.", r.Name, route.Type, route.Destination())
+			return tr, nil
+		}
+
+		hop.Out = route.Interface
+		hop.NextHop = route.Gateway
+		if !hop.NextHop.IsValid() {
+			hop.NextHop = dst
+		}
+		if !tr.Source.IsValid() {
+			iface, _ := r.Interface(route.Interface)
+			tr.Source = iface.Address.Addr()
+		}
+		tr.Hops = append(tr.Hops, hop)
+
+		next, found, linked := t.neighbour(Endpoint{Router: r.Name, Interface: route.Interface}, hop.NextHop)
+		switch {
+		case !linked:
+			tr.Outcome = OutcomeUnreachable
+			tr.Reason = fmt.Sprintf("%s sends it out of %s, but nothing is connected to %s.", r.Name, route.Interface, route.Interface)
+			return tr, nil
+		case !found:
+			tr.Outcome = OutcomeUnreachable
+			tr.Reason = fmt.Sprintf("Nothing on %s's %s link has %s, so ARP gets no answer and the packet is dropped with ICMP host unreachable.",
+				r.Name, route.Interface, hop.NextHop)
+			return tr, nil
+		}
+
+		r, _ = t.Router(next.Router)
+		ttl := hop.TTL
+		if hop.In != "" {
+			ttl--
+		}
+		hop = Hop{Router: r.Name, In: next.Interface, TTL: ttl}
+	}
+}
+
+// RoundTrip traces a packet to its destination and, if it arrives, the
+// reply back to the address it was sent from. Replies that take a different
+// path make the round trip asymmetric.
+func (t Topology) RoundTrip(from string, dst netip.Addr, ttl int) (RoundTrip, error) {
+	forward, err := t.Trace(from, dst, ttl)
+	if err != nil {
+		return RoundTrip{}, err
+	}
+	rt := RoundTrip{Forward: forward}
+	if forward.Outcome != OutcomeDelivered || !forward.Source.IsValid() {
+		return rt, nil
+	}
+
+	last := forward.Hops[len(forward.Hops)-1].Router
+	back, err := t.Trace(last, forward.Source, DefaultTTL)
+	if err != nil {
+		return RoundTrip{}, err
+	}
+	rt.Return = &back
+	if back.Outcome != OutcomeDelivered {
+		return rt, nil
+	}
+
+	there, path := forward.Path(), back.Path()
+	rt.Asymmetric = len(there) != len(path)
+	for i := range there {
+		if rt.Asymmetric || there[i] != path[len(path)-1-i] {
+			rt.Asymmetric = true
+			break
+		}
+	}
+	return rt, nil
+}
@@ -0,0 +1,135 @@
+package routing
+
+import (
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTrace(t *testing.T) {
+	topology, err := SampleTopology()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		to      string
+		ttl     int
+		outcome string
+		path    string
+	}{
+		{"web", DefaultTTL, OutcomeDelivered, "laptop home ispa edge web"},
+		{"10.1.2.3", DefaultTTL, OutcomeDropped, "laptop home ispa edge"},
+		{"172.16.5.5", DefaultTTL, OutcomeLoop, "laptop home ispa edge ispa"},
+		{"8.8.8.8", DefaultTTL, OutcomeNoRoute, "laptop home ispa edge"},
+		{"198.51.100.99", DefaultTTL, OutcomeUnreachable, "laptop home ispa edge"},
+		{"web", 2, OutcomeTTLExceeded, "laptop home ispa"},
+		{"laptop", DefaultTTL, OutcomeDelivered, "laptop"},
+	}
+	for _, tt := range tests {
+		dst, err := topology.Resolve(tt.to)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tr, err := topology.Trace("laptop", dst, tt.ttl)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tr.Outcome != tt.outcome || strings.Join(tr.Path(), " ") != tt.path {
+			t.Errorf("laptop to %s with TTL %d: %s via %v, want %s via %s", tt.to, tt.ttl, tr.Outcome, tr.Path(), tt.outcome, tt.path)
+		}
+	}
+}
+
+func TestTraceTTL(t *testing.T) {
+	topology, err := SampleTopology()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr, err := topology.Trace("laptop", netip.MustParseAddr("198.51.100.10"), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The sender doesn't decrement; each router does before forwarding
+	var ttls []int
+	for _, h := range tr.Hops {
+		ttls = append(ttls, h.TTL)
+	}
+	if want := []int{3, 3, 2, 1}; !reflect.DeepEqual(ttls, want) || tr.Outcome != OutcomeTTLExceeded {
+		t.Errorf("TTLs %v, %s; want %v and TTL exceeded at edge", ttls, tr.Outcome, want)
+	}
+	if tr.Source != netip.MustParseAddr("192.168.1.23") {
+		t.Errorf("source = %s", tr.Source)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	topology, err := SampleTopology()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rt, err := topology.RoundTrip("laptop", netip.MustParseAddr("198.51.100.10"), DefaultTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rt.Return == nil || strings.Join(rt.Return.Path(), " ") != "web edge ispb home laptop" || !rt.Asymmetric {
+		t.Errorf("return %+v, asymmetric %v", rt.Return, rt.Asymmetric)
+	}
+
+	// ispa routes the home network straight back
+	rt, err = topology.RoundTrip("home", netip.MustParseAddr("100.64.0.1"), DefaultTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rt.Return == nil || rt.Asymmetric {
+		t.Errorf("home to ispa: return %+v, asymmetric %v", rt.Return, rt.Asymmetric)
+	}
+
+	if _, err := topology.RoundTrip("nowhere", netip.MustParseAddr("198.51.100.10"), DefaultTTL); err == nil {
+		t.Error("unknown router did not fail")
+	}
+}
+
+func TestParseTopology(t *testing.T) {
+	topology, err := ParseTopology([]byte(`routers:
+  - name: a
+    interfaces:
+      - {name: eth0, address: 10.0.0.1/24}
+    routes: |
+      10.1.0.0/16 via 10.0.0.2
+  - name: b
+    interfaces:
+      - {name: eth0, address: 10.0.0.2/24}
+links:
+  - [a:eth0, b:eth0]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, _ := topology.Router("a")
+	want := Table{
+		{Prefix: netip.MustParsePrefix("10.0.0.0/24"), Interface: "eth0", Type: typeUnicast, Protocol: "kernel"},
+		{Prefix: netip.MustParsePrefix("10.1.0.0/16"), Gateway: netip.MustParseAddr("10.0.0.2"), Interface: "eth0", Type: typeUnicast},
+	}
+	if !reflect.DeepEqual(a.Table, want) {
+		t.Errorf("a's table = %+v", a.Table)
+	}
+
+	router := "routers:\n  - name: a\n    interfaces:\n      - {name: eth0, address: 10.0.0.1/24}\n"
+	for input, wantErr := range map[string]string{
+		"routers: [":             "invalid YAML",
+		"routers: []":            "no routers defined",
+		router + "  - name: a\n": "router a is defined twice",
+		router + "    routes: 10.1.0.0/16 via 192.168.0.1\n":                                "router a: route 10.1.0.0/16: gateway 192.168.0.1 is not on any of its subnets",
+		router + "    routes: 10.1.0.0/16 dev eth9\n":                                       "router a: route 10.1.0.0/16 uses eth9, which is not one of its interfaces",
+		router + "links:\n  - [a:eth0]\n":                                                   "link 1: a link joins at least two interfaces",
+		router + "links:\n  - [a:eth0, b:eth0]\n":                                           "link 1: no router named b",
+		router + "links:\n  - [a:eth0, a-eth1]\n":                                           `link 1: "a-eth1" should be router:interface`,
+		router + "links:\n  - [a:eth0, a:eth1]\n":                                           "link 1: router a has no interface eth1",
+		"routers:\n  - name: a\n    interfaces:\n      - {name: eth0, address: 10.0.0.1}\n": "should be an address with a prefix length",
+	} {
+		if _, err := ParseTopology([]byte(input)); err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("ParseTopology(%q) error = %v, want %q", input, err, wantErr)
+		}
+	}
+}
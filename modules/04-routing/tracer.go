@@ -0,0 +1,260 @@
+package routing
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"netlab/pkg/styles"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Tracer input fields, in the order up and down move through them
+const (
+	fieldFrom = iota
+	fieldTo
+	fieldTTL
+	fieldFile
+	fieldCount
+)
+
+var nodeStyle = lipgloss.NewStyle().Foreground(styles.Primary).Bold(true)
+
+// topologyLoadedMsg carries a topology read from a file
+type topologyLoadedMsg struct {
+	path     string
+	topology Topology
+	err      error
+}
+
+// tracer is the topology simulator: where to send a packet from and to,
+// its TTL, and a topology file to load, with the packet's path drawn hop by
+// hop and the reply's path back
+type tracer struct {
+	inputs   [fieldCount]textinput.Model
+	focus    int
+	topology Topology
+	trip     RoundTrip
+	err      error
+	loaded   topologyLoadedMsg
+	body     viewport.Model
+	width    int
+}
+
+func newTracer(topology Topology) tracer {
+	t := tracer{topology: topology}
+	for i, prompt := range []string{"From › ", "To › ", "TTL › ", "File › "} {
+		input := textinput.New()
+		input.Prompt = prompt
+		input.PromptStyle = styles.KeyBinding
+		input.CharLimit = 256
+		t.inputs[i] = input
+	}
+	t.inputs[fieldFrom].SetValue("laptop")
+	t.inputs[fieldFrom].Placeholder = "router to send from"
+	t.inputs[fieldTo].SetValue("web")
+	t.inputs[fieldTo].Placeholder = "address or router to send to"
+	t.inputs[fieldTTL].SetValue(strconv.Itoa(DefaultTTL))
+	t.inputs[fieldFile].Placeholder = "topology YAML file to load"
+	t.inputs[fieldFrom].Focus()
+	t.body = viewport.New(0, 0)
+	t.trace()
+	return t
+}
+
+// SetSize fits the tracer into width x height cells
+func (t *tracer) SetSize(width, height int) {
+	t.width = width
+	for i := range t.inputs {
+		t.inputs[i].Width = width - lipgloss.Width(t.inputs[i].Prompt) - 1
+		t.inputs[i].SetCursor(t.inputs[i].Position()) // Rescroll for the new width
+	}
+	t.body.Width = width
+	t.body.Height = height - fieldCount - 1 // The inputs and the gap below them
+	t.body.SetContent(t.bodyView())
+}
+
+// trace retraces the packet for the current inputs
+func (t *tracer) trace() {
+	t.err = nil
+	t.trip = RoundTrip{}
+	ttl, err := strconv.Atoi(strings.TrimSpace(t.inputs[fieldTTL].Value()))
+	if err != nil {
+		t.err = fmt.Errorf("TTL must be a number")
+		t.body.SetContent(t.bodyView())
+		return
+	}
+	dst, err := t.topology.Resolve(strings.TrimSpace(t.inputs[fieldTo].Value()))
+	if err != nil {
+		t.err = err
+		t.body.SetContent(t.bodyView())
+		return
+	}
+	t.trip, t.err = t.topology.RoundTrip(strings.TrimSpace(t.inputs[fieldFrom].Value()), dst, ttl)
+	t.body.SetContent(t.bodyView())
+}
+
+func (t tracer) Update(msg tea.Msg) (tracer, tea.Cmd) {
+	var cmd tea.Cmd
+	switch msg := msg.(type) {
+	case topologyLoadedMsg:
+		t.loaded = msg
+		if msg.err == nil {
+			t.topology = msg.topology
+		}
+		t.trace()
+		return t, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "up", "down":
+			t.inputs[t.focus].Blur()
+			step := 1
+			if msg.String() == "up" {
+				step = fieldCount - 1
+			}
+			t.focus = (t.focus + step) % fieldCount
+			t.inputs[t.focus].Focus()
+			return t, textinput.Blink
+		case "pgup", "pgdown":
+			t.body, cmd = t.body.Update(msg)
+			return t, cmd
+		case "enter":
+			if t.focus == fieldFile && strings.TrimSpace(t.inputs[fieldFile].Value()) != "" {
+				return t, loadTopology(strings.TrimSpace(t.inputs[fieldFile].Value()))
+			}
+			return t, nil
+		}
+
+		t.inputs[t.focus], cmd = t.inputs[t.focus].Update(msg)
+		if t.focus != fieldFile {
+			t.trace()
+		}
+		return t, cmd
+
+	case tea.MouseMsg:
+		t.body, cmd = t.body.Update(msg)
+		return t, cmd
+	}
+
+	t.inputs[t.focus], cmd = t.inputs[t.focus].Update(msg)
+	return t, cmd
+}
+
+func (t tracer) View() string {
+	lines := make([]string, len(t.inputs))
+	for i, input := range t.inputs {
+		lines[i] = input.View()
+	}
+	return strings.Join(lines, "\n") + "\n\n" + t.body.View()
+}
+
+// bodyView renders the packet's path and the reply's
+func (t tracer) bodyView() string {
+	var sections []string
+	switch {
+	case t.loaded.err != nil:
+		sections = append(sections, styles.StatusError.Render("⚠️  "+t.loaded.err.Error()))
+	case t.loaded.path != "":
+		sections = append(sections, styles.StatusSuccess.Render("✅ Loaded "+t.loaded.path))
+	}
+	sections = append(sections, styles.BodyMuted.Render("Routers: "+strings.Join(t.topology.Names(), ", ")))
+	if t.err != nil {
+		return strings.Join(append(sections, styles.StatusError.Render("⚠️  "+t.err.Error())), "\n\n")
+	}
+
+	sections = append(sections, t.pathView(t.trip.Forward))
+	if back := t.trip.Return; back != nil {
+		sections = append(sections, t.returnView(*back))
+	}
+	return strings.Join(sections, "\n\n")
+}
+
+// pathView draws a trace as a column of routers joined by the routes and
+// links the packet took
+func (t tracer) pathView(tr Trace) string {
+	text := lipgloss.NewStyle().Width(max(t.width-3, 1))
+	var lines []string
+	for i, hop := range tr.Hops {
+		marker := "●"
+		last := i == len(tr.Hops)-1
+		switch {
+		case last && tr.Outcome == OutcomeLoop:
+			marker = "↺"
+		case last && tr.Outcome != OutcomeDelivered:
+			marker = "✖"
+		}
+		detail := fmt.Sprintf("TTL %d", hop.TTL)
+		if hop.In != "" {
+			detail = fmt.Sprintf("in %s, TTL %d", hop.In, hop.TTL)
+		}
+		lines = append(lines, nodeStyle.Render(marker+" "+hop.Router)+styles.BodyMuted.Render("  "+detail))
+		if hop.Route.Prefix.IsValid() {
+			lines = append(lines, styles.BodyDim.Render("│ ")+fmt.Sprintf("%s %s", hop.Route.Destination(), hop.Route.Next()))
+		}
+		if hop.Out != "" && !last {
+			next := tr.Hops[i+1]
+			lines = append(lines, styles.BodyDim.Render("▼ ")+styles.BodyMuted.Render(fmt.Sprintf("%s → %s:%s", hop.Out, next.Router, next.In)))
+		}
+	}
+
+	reason := lipgloss.JoinHorizontal(lipgloss.Top, "   ", text.Render(tr.Reason))
+	status := styles.StatusError.Render(fmt.Sprintf("❌ %s", strings.ToUpper(tr.Outcome[:1])+tr.Outcome[1:]))
+	if tr.Outcome == OutcomeDelivered {
+		status = styles.StatusSuccess.Render(fmt.Sprintf("✅ Delivered in %d hop(s)", len(tr.Hops)-1))
+	}
+	title := styles.H3.Render(fmt.Sprintf("%s → %s", tr.From, tr.Destination))
+	return title + "\n" + strings.Join(lines, "\n") + "\n" + status + "\n" + reason
+}
+
+// returnView sums up the reply's path, flagging it when it differs
+func (t tracer) returnView(back Trace) string {
+	title := styles.H3.Render(fmt.Sprintf("Reply %s → %s", back.From, back.Destination))
+	path := strings.Join(back.Path(), " → ")
+	text := lipgloss.NewStyle().Width(max(t.width, 1))
+	switch {
+	case back.Outcome != OutcomeDelivered:
+		return title + "\n" + text.Render(path) + "\n" + styles.StatusError.Render("❌ The reply never arrives") + "\n" +
+			lipgloss.JoinHorizontal(lipgloss.Top, "   ", lipgloss.NewStyle().Width(max(t.width-3, 1)).Render(back.Reason))
+	case t.trip.Asymmetric:
+		note := fmt.Sprintf("⚠️  Asymmetric: the reply passes %s, the request %s. Stateful firewalls and NAT on one path never see the other half of the connection.",
+			orNone(missing(back.Path(), t.trip.Forward.Path())), orNone(missing(t.trip.Forward.Path(), back.Path())))
+		return title + "\n" + text.Render(path) + "\n" + styles.StatusWarning.Copy().Width(max(t.width, 1)).Render(note)
+	}
+	return title + "\n" + text.Render(path) + "\n" + styles.StatusSuccess.Render("✅ Symmetric: the reply retraces the request's path")
+}
+
+// missing lists the routers in a path that aren't in another
+func missing(path, other []string) []string {
+	seen := map[string]bool{}
+	for _, r := range other {
+		seen[r] = true
+	}
+	var out []string
+	for _, r := range path {
+		if !seen[r] {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// orNone joins router names, or says there are none
+func orNone(names []string) string {
+	if len(names) == 0 {
+		return "no other router"
+	}
+	return strings.Join(names, ", ")
+}
+
+// loadTopology reads a topology file
+func loadTopology(path string) tea.Cmd {
+	return func() tea.Msg {
+		topology, err := ReadTopology(path)
+		return topologyLoadedMsg{path: path, topology: topology, err: err}
+	}
+}
@@ -0,0 +1,46 @@
+package components
+
+import (
+	"netlab/pkg/markdown"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Document is a scrollable view of Markdown content, re-wrapped whenever
+// it is resized. Use it for module READMEs and long-form lessons.
+type Document struct {
+	source   string
+	viewport viewport.Model
+}
+
+// NewDocument creates a document for Markdown source
+func NewDocument(source string) Document {
+	return Document{source: source, viewport: viewport.New(0, 0)}
+}
+
+// SetSize re-renders the content for a new size, keeping the scroll position
+func (d *Document) SetSize(width, height int) {
+	offset := d.viewport.YOffset
+	d.viewport.Width = width
+	d.viewport.Height = height
+	d.viewport.SetContent(markdown.Render(d.source, width))
+	d.viewport.SetYOffset(offset)
+}
+
+// Update scrolls the document with the keyboard or mouse wheel
+func (d Document) Update(msg tea.Msg) (Document, tea.Cmd) {
+	var cmd tea.Cmd
+	d.viewport, cmd = d.viewport.Update(msg)
+	return d, cmd
+}
+
+// View renders the visible part of the document
+func (d Document) View() string {
+	return d.viewport.View()
+}
+
+// ScrollPercent reports how far through the document the view is
+func (d Document) ScrollPercent() float64 {
+	return d.viewport.ScrollPercent()
+}
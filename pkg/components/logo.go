@@ -27,7 +27,7 @@ func RenderLogo() string {
 // RenderLogoWithTagline returns the logo with tagline
 func RenderLogoWithTagline() string {
 	logo := RenderLogo()
-	tagline := styles.BodyMuted.
+	tagline := styles.BodyMuted.Copy().
 		Align(lipgloss.Center).
 		Margin(0, 0, 2, 0).
 		Render("Interactive Networking Learning Environment")
@@ -38,7 +38,7 @@ func RenderLogoWithTagline() string {
 // RenderCompactLogo returns a smaller version for headers
 func RenderCompactLogo() string {
 	compactText := "╔═══ NETLAB ═══╗"
-	return styles.H2.
+	return styles.H2.Copy().
 		Align(lipgloss.Center).
 		Margin(0, 0, 1, 0).
 		Render(compactText)
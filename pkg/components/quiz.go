@@ -0,0 +1,134 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	"netlab/pkg/styles"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// QuizQuestion is one multiple-choice question
+type QuizQuestion struct {
+	Question    string
+	Options     []string
+	Answer      int    // Index into Options
+	Explanation string // Shown once the question is answered
+	SeeAlso     string // Where the topic is covered, e.g. a lesson title
+}
+
+// Quiz steps through multiple-choice questions one at a time and keeps
+// score. Pick with ↑/↓ or the number keys, enter to answer and move on,
+// and r to start again once finished.
+type Quiz struct {
+	questions []QuizQuestion
+	question  int
+	choice    int
+	answered  bool
+	correct   int
+	finished  bool
+}
+
+// NewQuiz creates a quiz over the given questions
+func NewQuiz(questions []QuizQuestion) Quiz {
+	return Quiz{questions: questions}
+}
+
+// Finished reports whether every question has been answered
+func (q Quiz) Finished() bool {
+	return q.finished
+}
+
+// Update handles a key press
+func (q Quiz) Update(msg tea.KeyMsg) Quiz {
+	if len(q.questions) == 0 {
+		return q
+	}
+	if q.finished {
+		if msg.String() == "r" {
+			return NewQuiz(q.questions)
+		}
+		return q
+	}
+
+	options := len(q.questions[q.question].Options)
+	switch msg.String() {
+	case "up", "k":
+		if !q.answered && q.choice > 0 {
+			q.choice--
+		}
+	case "down", "j":
+		if !q.answered && q.choice < options-1 {
+			q.choice++
+		}
+	case "enter", " ":
+		if !q.answered {
+			q.answered = true
+			if q.choice == q.questions[q.question].Answer {
+				q.correct++
+			}
+			break
+		}
+		if q.question == len(q.questions)-1 {
+			q.finished = true
+			break
+		}
+		q.question++
+		q.choice = 0
+		q.answered = false
+	default:
+		// Number keys pick an option directly
+		if n := msg.String(); len(n) == 1 && n[0] >= '1' && int(n[0]-'0') <= options && !q.answered {
+			q.choice = int(n[0] - '1')
+		}
+	}
+	return q
+}
+
+// View renders the current question, wrapped to width
+func (q Quiz) View(width int) string {
+	if len(q.questions) == 0 {
+		return styles.BodyMuted.Render("This quiz has no questions yet.")
+	}
+	if q.finished {
+		return styles.Highlight.Render(fmt.Sprintf("You scored %d/%d", q.correct, len(q.questions))) +
+			"\n\n" + styles.BodyMuted.Render("Press r to try again.")
+	}
+
+	current := q.questions[q.question]
+	wrap := lipgloss.NewStyle().Width(width)
+
+	var lines []string
+	lines = append(lines, styles.BodyMuted.Render(fmt.Sprintf("Question %d of %d", q.question+1, len(q.questions))))
+	lines = append(lines, wrap.Render(current.Question), "")
+
+	for i, option := range current.Options {
+		cursor := "  "
+		if i == q.choice {
+			cursor = "▶ "
+		}
+		line := fmt.Sprintf("%s%d. %s", cursor, i+1, option)
+		switch {
+		case q.answered && i == current.Answer:
+			line = styles.StatusSuccess.Render(line + " ✓")
+		case q.answered && i == q.choice:
+			line = styles.StatusError.Render(line + " ✗")
+		case i == q.choice:
+			line = styles.NavItemActive.Copy().UnsetPadding().Render(line)
+		}
+		lines = append(lines, line)
+	}
+
+	if q.answered {
+		lines = append(lines, "")
+		if current.Explanation != "" {
+			lines = append(lines, wrap.Render(current.Explanation))
+		}
+		if current.SeeAlso != "" {
+			lines = append(lines, styles.BodyMuted.Render("See the lesson: "+current.SeeAlso))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,416 @@
+// Package markdown renders the Markdown used in module READMEs and lessons
+// as styled terminal text. It covers headings, paragraphs, lists, block
+// quotes, fenced code, pipe tables and inline emphasis, code and links.
+// Links are numbered and listed as footnotes, since terminals can't follow them.
+package markdown
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"netlab/pkg/styles"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/reflow/truncate"
+	"github.com/muesli/reflow/wordwrap"
+	"github.com/muesli/reflow/wrap"
+)
+
+// minWidth keeps nested lists and tables readable on very narrow terminals
+const minWidth = 20
+
+var (
+	headingRe   = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	fenceRe     = regexp.MustCompile("^\\s*(```|~~~)\\s*([\\w+-]*)")
+	ruleRe      = regexp.MustCompile(`^\s*([-*_])(\s*([-*_]))\s*([-*_]\s*)+$`)
+	listRe      = regexp.MustCompile(`^(\s*)([-*+]|\d+[.)])\s+(.*)$`)
+	quoteRe     = regexp.MustCompile(`^\s*>\s?(.*)$`)
+	tableSepRe  = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+	codeSpanRe  = regexp.MustCompile("`([^`]+)`")
+	linkRe      = regexp.MustCompile(`(!?)\[([^\]]*)\]\(([^)\s]+)[^)]*\)|<(https?://[^>]+)>`)
+	boldRe      = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	italicRe    = regexp.MustCompile(`(^|[^\w*])\*([^*\s][^*]*)\*|(^|[^\w])_([^_\s][^_]*)_`)
+	placeholder = regexp.MustCompile("\x00(\\d+)\x00")
+)
+
+// Styles derived from the palette. Copies, so adjusting margins here never
+// leaks into the shared styles.
+var (
+	h1Style     = styles.H1.Copy().UnsetMargins().UnsetPadding()
+	h2Style     = styles.H2.Copy().UnsetMargins()
+	h3Style     = styles.H3.Copy()
+	boldStyle   = lipgloss.NewStyle().Bold(true)
+	italicStyle = lipgloss.NewStyle().Italic(true)
+	codeStyle   = lipgloss.NewStyle().Foreground(styles.Primary)
+	linkStyle   = lipgloss.NewStyle().Foreground(styles.Info).Underline(true)
+	refStyle    = styles.BodyDim.Copy()
+	quoteStyle  = styles.BodyMuted.Copy().Italic(true)
+	barStyle    = lipgloss.NewStyle().Foreground(styles.Secondary)
+	ruleStyle   = styles.BodyDim.Copy()
+	tableStyle  = lipgloss.NewStyle().Foreground(styles.Border)
+	headerStyle = styles.H3.Copy()
+	bulletStyle = lipgloss.NewStyle().Foreground(styles.Accent)
+	fenceStyle  = lipgloss.NewStyle().
+			Foreground(styles.Primary).
+			Border(lipgloss.NormalBorder(), false, false, false, true).
+			BorderForeground(styles.Border).
+			PaddingLeft(1)
+)
+
+// Render converts Markdown to styled text wrapped to width
+func Render(src string, width int) string {
+	if width < minWidth {
+		width = minWidth
+	}
+	r := &renderer{width: width, linkIndex: map[string]int{}}
+	r.render(strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n"))
+	r.footnotes()
+	return strings.TrimRight(strings.Join(r.blocks, "\n\n"), "\n")
+}
+
+type renderer struct {
+	width     int
+	blocks    []string
+	links     []string
+	linkIndex map[string]int
+}
+
+func (r *renderer) render(lines []string) {
+	var paragraph []string
+	flush := func() {
+		if len(paragraph) > 0 {
+			r.blocks = append(r.blocks, r.wrap(r.inline(strings.Join(paragraph, " ")), r.width))
+			paragraph = nil
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
+			flush()
+
+		case strings.HasPrefix(trimmed, "<!--"):
+			flush()
+			for i < len(lines) && !strings.Contains(lines[i], "-->") {
+				i++
+			}
+
+		case fenceRe.MatchString(line):
+			flush()
+			m := fenceRe.FindStringSubmatch(line)
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), m[1]); i++ {
+				code = append(code, lines[i])
+			}
+			r.blocks = append(r.blocks, r.codeBlock(m[2], code))
+
+		case headingRe.MatchString(line):
+			flush()
+			m := headingRe.FindStringSubmatch(line)
+			r.blocks = append(r.blocks, r.heading(len(m[1]), m[2]))
+
+		case ruleRe.MatchString(line):
+			flush()
+			r.blocks = append(r.blocks, ruleStyle.Render(strings.Repeat("─", r.width)))
+
+		case quoteRe.MatchString(line):
+			flush()
+			var quote []string
+			for ; i < len(lines) && quoteRe.MatchString(lines[i]); i++ {
+				quote = append(quote, quoteRe.FindStringSubmatch(lines[i])[1])
+			}
+			i--
+			r.blocks = append(r.blocks, r.blockQuote(quote))
+
+		case strings.Contains(line, "|") && i+1 < len(lines) && tableSepRe.MatchString(lines[i+1]) && strings.Contains(lines[i+1], "-"):
+			flush()
+			rows := [][]string{splitRow(line)}
+			align := columnAlignments(lines[i+1])
+			for i += 2; i < len(lines) && strings.Contains(lines[i], "|") && strings.TrimSpace(lines[i]) != ""; i++ {
+				rows = append(rows, splitRow(lines[i]))
+			}
+			i--
+			r.blocks = append(r.blocks, r.table(rows, align))
+
+		case listRe.MatchString(line):
+			flush()
+			var items []string
+			for ; i < len(lines); i++ {
+				if listRe.MatchString(lines[i]) {
+					items = append(items, lines[i])
+					continue
+				}
+				// Indented continuation of the previous item
+				if len(items) > 0 && strings.TrimSpace(lines[i]) != "" && strings.HasPrefix(lines[i], " ") {
+					items[len(items)-1] += " " + strings.TrimSpace(lines[i])
+					continue
+				}
+				break
+			}
+			i--
+			r.blocks = append(r.blocks, r.list(items))
+
+		default:
+			paragraph = append(paragraph, trimmed)
+		}
+	}
+	flush()
+}
+
+func (r *renderer) heading(level int, text string) string {
+	text = r.inline(text)
+	switch level {
+	case 1:
+		title := r.wrap(h1Style.Render(text), r.width)
+		return title + "\n" + h1Style.Render(strings.Repeat("═", min(lipgloss.Width(title), r.width)))
+	case 2:
+		title := r.wrap(h2Style.Render(text), r.width)
+		return title + "\n" + ruleStyle.Render(strings.Repeat("─", min(lipgloss.Width(title), r.width)))
+	default:
+		return r.wrap(h3Style.Render(text), r.width)
+	}
+}
+
+func (r *renderer) codeBlock(lang string, code []string) string {
+	inner := r.width - 2 // Border and padding
+	for i, line := range code {
+		line = strings.ReplaceAll(line, "\t", "    ")
+		if lipgloss.Width(line) > inner {
+			line = truncate.StringWithTail(line, uint(inner), "…")
+		}
+		code[i] = line
+	}
+	if len(code) == 0 {
+		code = []string{""}
+	}
+
+	block := fenceStyle.Render(strings.Join(code, "\n"))
+	if lang != "" {
+		block = styles.BodyDim.Render(lang) + "\n" + block
+	}
+	return block
+}
+
+func (r *renderer) blockQuote(lines []string) string {
+	body := r.wrap(quoteStyle.Render(r.inline(strings.Join(lines, " "))), r.width-2)
+	var out []string
+	for _, line := range strings.Split(body, "\n") {
+		out = append(out, barStyle.Render("│ ")+line)
+	}
+	return strings.Join(out, "\n")
+}
+
+// list renders items, indenting nested items by their leading spaces
+func (r *renderer) list(items []string) string {
+	var out []string
+	for _, item := range items {
+		m := listRe.FindStringSubmatch(item)
+		level := len(strings.ReplaceAll(m[1], "\t", "  ")) / 2
+		indent := strings.Repeat("  ", level)
+
+		marker := "•"
+		if level > 0 {
+			marker = "◦"
+		}
+		if m[2][0] >= '0' && m[2][0] <= '9' {
+			marker = m[2]
+		}
+
+		prefix := indent + bulletStyle.Render(marker) + " "
+		hang := strings.Repeat(" ", lipgloss.Width(prefix))
+		text := r.wrap(r.inline(m[3]), r.width-lipgloss.Width(prefix))
+
+		for j, line := range strings.Split(text, "\n") {
+			if j == 0 {
+				out = append(out, prefix+line)
+			} else {
+				out = append(out, hang+line)
+			}
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+// table draws a pipe table with box characters, shrinking the widest
+// columns until it fits
+func (r *renderer) table(rows [][]string, align []lipgloss.Position) string {
+	cols := 0
+	for _, row := range rows {
+		cols = max(cols, len(row))
+	}
+
+	cells := make([][]string, len(rows))
+	widths := make([]int, cols)
+	for i, row := range rows {
+		cells[i] = make([]string, cols)
+		for j := 0; j < cols; j++ {
+			if j < len(row) {
+				cells[i][j] = r.inline(row[j])
+			}
+			if i == 0 {
+				cells[i][j] = headerStyle.Render(cells[i][j])
+			}
+			widths[j] = max(widths[j], lipgloss.Width(cells[i][j]))
+		}
+	}
+
+	// Each column has a space either side and a separator
+	for total(widths)+3*cols+1 > r.width {
+		widest := 0
+		for j := range widths {
+			if widths[j] > widths[widest] {
+				widest = j
+			}
+		}
+		if widths[widest] <= 3 {
+			break
+		}
+		widths[widest]--
+	}
+
+	line := func(left, mid, right string) string {
+		parts := make([]string, cols)
+		for j, w := range widths {
+			parts[j] = strings.Repeat("─", w+2)
+		}
+		return tableStyle.Render(left + strings.Join(parts, mid) + right)
+	}
+	bar := tableStyle.Render("│")
+
+	var out []string
+	out = append(out, line("┌", "┬", "┐"))
+	for i, row := range cells {
+		parts := make([]string, cols)
+		for j, cell := range row {
+			if lipgloss.Width(cell) > widths[j] {
+				cell = truncate.StringWithTail(cell, uint(widths[j]), "…")
+			}
+			pos := lipgloss.Left
+			if j < len(align) {
+				pos = align[j]
+			}
+			parts[j] = " " + lipgloss.PlaceHorizontal(widths[j], pos, cell) + " "
+		}
+		out = append(out, bar+strings.Join(parts, bar)+bar)
+		if i == 0 {
+			out = append(out, line("├", "┼", "┤"))
+		}
+	}
+	out = append(out, line("└", "┴", "┘"))
+	return strings.Join(out, "\n")
+}
+
+// inline styles code spans, links, images and emphasis within one block
+func (r *renderer) inline(text string) string {
+	// Code spans are set aside first so nothing inside them is styled
+	var spans []string
+	text = codeSpanRe.ReplaceAllStringFunc(text, func(s string) string {
+		spans = append(spans, codeStyle.Render(codeSpanRe.FindStringSubmatch(s)[1]))
+		return fmt.Sprintf("\x00%d\x00", len(spans)-1)
+	})
+
+	// Links, images and autolinks in one pass so footnotes follow reading order
+	text = linkRe.ReplaceAllStringFunc(text, func(s string) string {
+		m := linkRe.FindStringSubmatch(s)
+		label, url := m[2], m[3]
+		switch {
+		case m[4] != "":
+			label, url = m[4], m[4]
+		case m[1] == "!":
+			if label == "" {
+				label = "image"
+			}
+			return italicStyle.Render("[image: "+label+"]") + refStyle.Render(fmt.Sprintf("[%d]", r.footnote(url)))
+		}
+		return linkStyle.Render(label) + refStyle.Render(fmt.Sprintf("[%d]", r.footnote(url)))
+	})
+
+	text = boldRe.ReplaceAllStringFunc(text, func(s string) string {
+		m := boldRe.FindStringSubmatch(s)
+		return boldStyle.Render(m[1] + m[2])
+	})
+	text = italicRe.ReplaceAllStringFunc(text, func(s string) string {
+		m := italicRe.FindStringSubmatch(s)
+		return m[1] + m[3] + italicStyle.Render(m[2]+m[4])
+	})
+
+	return placeholder.ReplaceAllStringFunc(text, func(s string) string {
+		var i int
+		fmt.Sscanf(placeholder.FindStringSubmatch(s)[1], "%d", &i)
+		return spans[i]
+	})
+}
+
+// footnote returns the reference number for a link target, reusing the
+// number when the same target appears again
+func (r *renderer) footnote(url string) int {
+	if n, ok := r.linkIndex[url]; ok {
+		return n
+	}
+	r.links = append(r.links, url)
+	r.linkIndex[url] = len(r.links)
+	return len(r.links)
+}
+
+func (r *renderer) footnotes() {
+	if len(r.links) == 0 {
+		return
+	}
+	lines := []string{h3Style.Render("Links")}
+	for i, url := range r.links {
+		ref := fmt.Sprintf("[%d] ", i+1)
+		lines = append(lines, refStyle.Render(ref)+r.wrap(linkStyle.Render(url), r.width-len(ref)))
+	}
+	r.blocks = append(r.blocks, ruleStyle.Render(strings.Repeat("─", r.width))+"\n"+strings.Join(lines, "\n"))
+}
+
+// wrap word-wraps styled text, hard-breaking words longer than the width
+func (r *renderer) wrap(text string, width int) string {
+	if width < 1 {
+		width = 1
+	}
+	// Only break at spaces: reflow's default hyphen breakpoint can overrun the width
+	w := wordwrap.NewWriter(width)
+	w.Breakpoints = nil
+	w.Write([]byte(text))
+	w.Close()
+	return wrap.String(w.String(), width)
+}
+
+func splitRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	line = strings.TrimSuffix(line, "|")
+	cells := strings.Split(line, "|")
+	for i := range cells {
+		cells[i] = strings.TrimSpace(cells[i])
+	}
+	return cells
+}
+
+func columnAlignments(sep string) []lipgloss.Position {
+	var align []lipgloss.Position
+	for _, cell := range splitRow(sep) {
+		switch {
+		case strings.HasPrefix(cell, ":") && strings.HasSuffix(cell, ":"):
+			align = append(align, lipgloss.Center)
+		case strings.HasSuffix(cell, ":"):
+			align = append(align, lipgloss.Right)
+		default:
+			align = append(align, lipgloss.Left)
+		}
+	}
+	return align
+}
+
+func total(widths []int) int {
+	sum := 0
+	for _, w := range widths {
+		sum += w
+	}
+	return sum
+}
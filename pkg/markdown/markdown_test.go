@@ -0,0 +1,48 @@
+package markdown
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"netlab/internal/tuitest"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestRender(t *testing.T) {
+	src, err := os.ReadFile("testdata/sample.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, width := range []int{80, 40} {
+		got := Render(string(src), width)
+		for i, line := range strings.Split(got, "\n") {
+			if w := lipgloss.Width(line); w > width {
+				t.Errorf("width %d: line %d is %d cells wide: %q", width, i+1, w, line)
+			}
+		}
+		tuitest.AssertGolden(t, "sample_"+strconv.Itoa(width), got)
+	}
+}
+
+func TestRenderFootnotes(t *testing.T) {
+	got := Render("See [a](https://a.io), ![b](b.png), [a again](https://a.io) and <https://c.io>.", 80)
+
+	for _, want := range []string{"a[1]", "[image: b][2]", "a again[1]", "https://c.io[3]", "[1] https://a.io", "[2] b.png", "[3] https://c.io"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in:\n%s", want, got)
+		}
+	}
+	if strings.Count(got, "https://a.io") != 1 {
+		t.Errorf("repeated link listed more than once:\n%s", got)
+	}
+}
+
+func TestRenderEmpty(t *testing.T) {
+	if got := Render("", 80); got != "" {
+		t.Errorf("Render(\"\") = %q", got)
+	}
+}
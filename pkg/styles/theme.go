@@ -238,3 +238,31 @@ var (
 			Padding(1, 2).
 			Margin(1, 0)
 )
+
+// OSI layer colours, so a header or PDU always looks the same wherever a
+// layer appears
+var LayerColors = map[int]lipgloss.Color{
+	7: lipgloss.Color("#EC4899"), // Pink - application data
+	6: lipgloss.Color("#A855F7"), // Violet - encryption and encoding
+	5: lipgloss.Color("#6366F1"), // Indigo - sessions
+	4: lipgloss.Color("#3B82F6"), // Blue - segments
+	3: lipgloss.Color("#10B981"), // Green - packets
+	2: lipgloss.Color("#F59E0B"), // Amber - frames
+	1: lipgloss.Color("#94A3B8"), // Gray - bits
+}
+
+// LayerBlock renders a solid label in a layer's colour
+func LayerBlock(layer int) lipgloss.Style {
+	return lipgloss.NewStyle().
+		Foreground(Background).
+		Background(LayerColors[layer]).
+		Bold(true).
+		Padding(0, 1)
+}
+
+// LayerText colours text with a layer's colour
+func LayerText(layer int) lipgloss.Style {
+	return lipgloss.NewStyle().
+		Foreground(LayerColors[layer]).
+		Bold(true)
+}
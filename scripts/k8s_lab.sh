@@ -10,6 +10,7 @@ CLUSTER_NAME="netlab-osi"
 NAMESPACE="default"
 CAPTURE_FILE="modules/01-osi-model/assets/https-nginx.pcap"
 ASSETS_DIR="modules/01-osi-model/assets"
+NON_INTERACTIVE="${NETLAB_NON_INTERACTIVE:-false}"
 
 # Colors for output
 RED='\033[0;31m'
@@ -477,7 +478,7 @@ show_lab_info() {
     echo "     kubectl exec busybox -- wget -qO- http://nginx/"
     echo ""
     echo "🧹 Cleanup:"
-    echo "  • Run 'kind delete cluster --name $CLUSTER_NAME' when finished"
+    echo "  • Run 'netlab cleanup' or '$0 cleanup' when finished"
     echo ""
 }
 
@@ -578,10 +579,17 @@ cleanup() {
         print_status "Cluster '$CLUSTER_NAME' was not found"
     fi
     
-    # Ask if user wants to stop Docker
     echo ""
     print_status "Lab cleanup completed."
     echo ""
+
+    # Never prompt when run from NetLab or without a terminal attached
+    if [ "$NON_INTERACTIVE" = "true" ] || [ ! -t 0 ]; then
+        print_status "Non-interactive mode: Docker left running"
+        return 0
+    fi
+
+    # Ask if user wants to stop Docker
     echo "Do you want to stop Docker as well?"
     echo "Note: This will stop Docker completely, which may affect other containers you have running."
     echo ""
@@ -606,8 +614,21 @@ main() {
     echo "=========================================="
     echo ""
     
+    # Parse flags
+    local action="setup"
+    for arg in "$@"; do
+        case "$arg" in
+            -y|--non-interactive)
+                NON_INTERACTIVE="true"
+                ;;
+            *)
+                action="$arg"
+                ;;
+        esac
+    done
+
     # Parse arguments
-    case "${1:-setup}" in
+    case "$action" in
         "setup")
             check_prerequisites
             create_assets_dir
@@ -625,10 +646,12 @@ main() {
             capture_packets
             ;;
         *)
-            echo "Usage: $0 [setup|cleanup|capture]"
+            echo "Usage: $0 [setup|cleanup|capture] [--non-interactive]"
             echo "  setup   - Create the full lab environment (default)"
             echo "  cleanup - Delete the kind cluster"
             echo "  capture - Re-run packet capture only"
+            echo ""
+            echo "  -y, --non-interactive  Never prompt (Docker is left running on cleanup)"
             exit 1
             ;;
     esac